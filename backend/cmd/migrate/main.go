@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/bipulkrdas/orgmind/backend/internal/config"
@@ -17,23 +18,48 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// Defaults for the graphs created during migration
+const (
+	defaultGraphName        = "My Knowledge Graph"
+	defaultGraphDescription = "Default graph created during migration"
+)
+
+// graphDefaults holds the name and description given to each migrated user's graph,
+// and the prefix of its Zep graph ID
+type graphDefaults struct {
+	Name             string
+	Description      string
+	ZepGraphIDPrefix string
+}
+
 func main() {
 	// Parse command line flags
 	migrateExisting := flag.Bool("migrate-existing-documents", false, "Migrate existing documents to default graphs")
 	dryRun := flag.Bool("dry-run", false, "Show what would be migrated without making changes")
+	graphName := flag.String("default-graph-name", defaultGraphName, "Name of the graph created for each migrated user")
+	graphDescription := flag.String("default-graph-description", defaultGraphDescription, "Description of the graph created for each migrated user (empty for none)")
 	flag.Parse()
 
 	if !*migrateExisting {
-		fmt.Println("Usage: go run cmd/migrate/main.go --migrate-existing-documents [--dry-run]")
+		fmt.Println("Usage: go run cmd/migrate/main.go --migrate-existing-documents [--dry-run] [--default-graph-name NAME] [--default-graph-description DESCRIPTION]")
 		fmt.Println("\nThis script creates default graphs for users with existing documents.")
 		os.Exit(1)
 	}
 
+	defaults := graphDefaults{
+		Name:        strings.TrimSpace(*graphName),
+		Description: strings.TrimSpace(*graphDescription),
+	}
+	if defaults.Name == "" {
+		log.Fatalf("--default-graph-name cannot be empty")
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	defaults.ZepGraphIDPrefix = cfg.ZepGraphIDPrefix
 
 	// Connect to database
 	dbCfg := database.Config{
@@ -57,8 +83,8 @@ func main() {
 	graphRepo := repository.NewGraphRepository(db.DB)
 	docRepo := repository.NewDocumentRepository(db.DB)
 
-	// Initialize Zep service
-	zepSvc, err := service.NewZepService(cfg.ZepAPIKey)
+	// Initialize Zep service (no visualizations are built here, so nodes keep the default style)
+	zepSvc, err := service.NewZepService(cfg.ZepAPIKey, service.NodeStyle{})
 	if err != nil {
 		log.Fatalf("Failed to initialize Zep service: %v", err)
 	}
@@ -68,13 +94,13 @@ func main() {
 	if *dryRun {
 		fmt.Println("\n=== DRY RUN MODE - No changes will be made ===")
 		fmt.Println()
-		if err := dryRunMigration(ctx, db.DB, graphRepo, docRepo); err != nil {
+		if err := dryRunMigration(ctx, db.DB, graphRepo, docRepo, defaults); err != nil {
 			log.Fatalf("Dry run failed: %v", err)
 		}
 	} else {
 		fmt.Println("\n=== STARTING MIGRATION ===")
 		fmt.Println()
-		if err := migrateExistingDocuments(ctx, db.DB, graphRepo, docRepo, zepSvc); err != nil {
+		if err := migrateExistingDocuments(ctx, db.DB, graphRepo, docRepo, zepSvc, defaults); err != nil {
 			log.Fatalf("Migration failed: %v", err)
 		}
 		fmt.Println("\n=== MIGRATION COMPLETED SUCCESSFULLY ===")
@@ -82,7 +108,7 @@ func main() {
 }
 
 // dryRunMigration shows what would be migrated without making changes
-func dryRunMigration(ctx context.Context, db *sqlx.DB, graphRepo repository.GraphRepository, docRepo repository.DocumentRepository) error {
+func dryRunMigration(ctx context.Context, db *sqlx.DB, graphRepo repository.GraphRepository, docRepo repository.DocumentRepository, defaults graphDefaults) error {
 	users, err := findUsersWithoutGraphs(ctx, db)
 	if err != nil {
 		return fmt.Errorf("failed to find users: %w", err)
@@ -104,14 +130,14 @@ func dryRunMigration(ctx context.Context, db *sqlx.DB, graphRepo repository.Grap
 
 		fmt.Printf("%d. User: %s (ID: %s)\n", i+1, user.Email, user.ID)
 		fmt.Printf("   Documents: %d\n", docCount)
-		fmt.Printf("   Would create: Default graph with %d document(s)\n\n", docCount)
+		fmt.Printf("   Would create: Graph %q with %d document(s)\n\n", defaults.Name, docCount)
 	}
 
 	return nil
 }
 
 // migrateExistingDocuments creates default graphs for users with documents
-func migrateExistingDocuments(ctx context.Context, db *sqlx.DB, graphRepo repository.GraphRepository, docRepo repository.DocumentRepository, zepSvc service.ZepService) error {
+func migrateExistingDocuments(ctx context.Context, db *sqlx.DB, graphRepo repository.GraphRepository, docRepo repository.DocumentRepository, zepSvc service.ZepService, defaults graphDefaults) error {
 	// Find all users with documents but no graphs
 	users, err := findUsersWithoutGraphs(ctx, db)
 	if err != nil {
@@ -131,7 +157,7 @@ func migrateExistingDocuments(ctx context.Context, db *sqlx.DB, graphRepo reposi
 	for i, user := range users {
 		fmt.Printf("[%d/%d] Processing user: %s (ID: %s)\n", i+1, len(users), user.Email, user.ID)
 
-		if err := migrateUser(ctx, db, graphRepo, docRepo, zepSvc, user); err != nil {
+		if err := migrateUser(ctx, db, graphRepo, docRepo, zepSvc, user, defaults); err != nil {
 			log.Printf("ERROR: Failed to migrate user %s: %v\n", user.Email, err)
 			failureCount++
 			continue
@@ -154,7 +180,7 @@ func migrateExistingDocuments(ctx context.Context, db *sqlx.DB, graphRepo reposi
 }
 
 // migrateUser creates a default graph for a single user and migrates their documents
-func migrateUser(ctx context.Context, db *sqlx.DB, graphRepo repository.GraphRepository, docRepo repository.DocumentRepository, zepSvc service.ZepService, user *models.User) error {
+func migrateUser(ctx context.Context, db *sqlx.DB, graphRepo repository.GraphRepository, docRepo repository.DocumentRepository, zepSvc service.ZepService, user *models.User, defaults graphDefaults) error {
 	// Start a transaction
 	tx, err := db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -164,7 +190,7 @@ func migrateUser(ctx context.Context, db *sqlx.DB, graphRepo repository.GraphRep
 
 	// Generate graph ID
 	graphID := uuid.New().String()
-	zepGraphID := fmt.Sprintf("graph-%s", graphID)
+	zepGraphID := defaults.ZepGraphIDPrefix + graphID
 
 	// Note: Current Zep SDK doesn't have explicit CreateGraph method
 	// The graph is implicitly created when we add data to it
@@ -177,8 +203,8 @@ func migrateUser(ctx context.Context, db *sqlx.DB, graphRepo repository.GraphRep
 		ID:            graphID,
 		CreatorID:     user.ID,
 		ZepGraphID:    zepGraphID,
-		Name:          "My Knowledge Graph",
-		Description:   stringPtr("Default graph created during migration"),
+		Name:          defaults.Name,
+		Description:   optionalString(defaults.Description),
 		DocumentCount: 0,
 		CreatedAt:     now,
 		UpdatedAt:     now,
@@ -209,7 +235,7 @@ func migrateUser(ctx context.Context, db *sqlx.DB, graphRepo repository.GraphRep
 		ID:        membershipID,
 		GraphID:   graphID,
 		UserID:    user.ID,
-		Role:      "owner",
+		Role:      models.RoleOwner,
 		CreatedAt: now,
 	}
 
@@ -308,7 +334,10 @@ func countUserDocuments(ctx context.Context, db *sqlx.DB, userID string) (int, e
 	return count, nil
 }
 
-// stringPtr returns a pointer to a string
-func stringPtr(s string) *string {
+// optionalString returns a pointer to s, or nil if s is empty
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
 	return &s
 }
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/config"
+	"github.com/bipulkrdas/orgmind/backend/internal/database"
+	"github.com/bipulkrdas/orgmind/backend/internal/service"
+	"github.com/jmoiron/sqlx"
+)
+
+// defaultMinAge skips Zep graphs whose creation may still be in progress
+const defaultMinAge = time.Hour
+
+// dbGraph is a graph row's local and Zep IDs
+type dbGraph struct {
+	ID         string `db:"id"`
+	ZepGraphID string `db:"zep_graph_id"`
+}
+
+// reconcileOptions controls which orphaned Zep graphs are deleted
+type reconcileOptions struct {
+	DryRun bool
+	Prefix string
+	MinAge time.Duration
+}
+
+func main() {
+	// Parse command line flags
+	dryRun := flag.Bool("dry-run", false, "Show what would be deleted without making changes")
+	prefix := flag.String("prefix", "", "Only delete orphaned Zep graphs whose ID starts with this prefix (default: ZEP_GRAPH_ID_PREFIX; empty for all)")
+	minAge := flag.Duration("min-age", defaultMinAge, "Only delete orphaned Zep graphs created at least this long ago")
+	flag.Parse()
+
+	if *minAge < 0 {
+		fmt.Println("Usage: go run cmd/reconcile-zep/main.go [--dry-run] [--prefix PREFIX] [--min-age DURATION]")
+		fmt.Println("\n--min-age cannot be negative.")
+		os.Exit(1)
+	}
+
+	opts := reconcileOptions{
+		DryRun: *dryRun,
+		Prefix: *prefix,
+		MinAge: *minAge,
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Without --prefix, only consider graphs created by this environment's server
+	if !flagSet("prefix") {
+		opts.Prefix = cfg.ZepGraphIDPrefix
+	}
+
+	// Connect to database
+	dbCfg := database.Config{
+		DatabaseURL:     cfg.DatabaseURL,
+		MaxOpenConns:    5,
+		MaxIdleConns:    1,
+		ConnMaxLifetime: 5 * time.Minute,
+		RetryAttempts:   3,
+		RetryDelay:      2 * time.Second,
+	}
+
+	db, err := database.Connect(dbCfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	fmt.Println("Connected to database successfully")
+
+	// Initialize Zep service (no visualizations are built here, so nodes keep the default style)
+	zepSvc, err := service.NewZepService(cfg.ZepAPIKey, service.NodeStyle{})
+	if err != nil {
+		log.Fatalf("Failed to initialize Zep service: %v", err)
+	}
+
+	ctx := context.Background()
+	if opts.DryRun {
+		fmt.Println("\n=== DRY RUN MODE - No changes will be made ===")
+		fmt.Println()
+	}
+
+	if err := reconcile(ctx, db.DB, zepSvc, opts); err != nil {
+		log.Fatalf("Reconciliation failed: %v", err)
+	}
+}
+
+// reconcile compares the graphs in the database with those in Zep. Zep graphs without
+// a database row are deleted; database graphs whose Zep graph is missing are reported
+// for manual follow-up, since deleting them would lose their documents.
+func reconcile(ctx context.Context, db *sqlx.DB, zepSvc service.ZepService, opts reconcileOptions) error {
+	graphs, err := listDBGraphs(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	// A graph created after this point is in Zep without a row yet; --min-age keeps it from being deleted
+	zepGraphs, err := zepSvc.ListGraphs(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Found %d graph(s) in the database and %d in Zep\n\n", len(graphs), len(zepGraphs))
+
+	known := make(map[string]bool, len(graphs))
+	for _, graph := range graphs {
+		known[graph.ZepGraphID] = true
+	}
+
+	inZep := make(map[string]bool, len(zepGraphs))
+	var orphans []service.ZepGraphInfo
+	skipped := 0
+	cutoff := time.Now().Add(-opts.MinAge)
+
+	for _, zepGraph := range zepGraphs {
+		inZep[zepGraph.GraphID] = true
+		if known[zepGraph.GraphID] {
+			continue
+		}
+
+		switch {
+		case !strings.HasPrefix(zepGraph.GraphID, opts.Prefix):
+			fmt.Printf("Skipping Zep graph %s: ID doesn't start with %q\n", zepGraph.GraphID, opts.Prefix)
+			skipped++
+		case zepGraph.CreatedAt.IsZero():
+			fmt.Printf("Skipping Zep graph %s: creation time unknown\n", zepGraph.GraphID)
+			skipped++
+		case zepGraph.CreatedAt.After(cutoff):
+			fmt.Printf("Skipping Zep graph %s: created %s, newer than --min-age\n", zepGraph.GraphID, zepGraph.CreatedAt.Format(time.RFC3339))
+			skipped++
+		default:
+			orphans = append(orphans, zepGraph)
+		}
+	}
+
+	missing := 0
+	for _, graph := range graphs {
+		if !inZep[graph.ZepGraphID] {
+			fmt.Printf("MISSING: Graph %s has no Zep graph %s\n", graph.ID, graph.ZepGraphID)
+			missing++
+		}
+	}
+
+	deleted := 0
+	failed := 0
+	for _, orphan := range orphans {
+		if opts.DryRun {
+			fmt.Printf("Would delete orphaned Zep graph %s (%q, created %s)\n", orphan.GraphID, orphan.Name, orphan.CreatedAt.Format(time.RFC3339))
+			continue
+		}
+
+		if err := zepSvc.DeleteGraph(ctx, orphan.GraphID); err != nil {
+			log.Printf("ERROR: Failed to delete orphaned Zep graph %s: %v\n", orphan.GraphID, err)
+			failed++
+			continue
+		}
+
+		fmt.Printf("✓ Deleted orphaned Zep graph %s (%q)\n", orphan.GraphID, orphan.Name)
+		deleted++
+	}
+
+	fmt.Printf("\nReconciliation Summary:\n")
+	fmt.Printf("  Orphaned Zep graphs:       %d\n", len(orphans))
+	if !opts.DryRun {
+		fmt.Printf("    Deleted:                 %d\n", deleted)
+		fmt.Printf("    Failed:                  %d\n", failed)
+	}
+	fmt.Printf("  Skipped Zep graphs:        %d\n", skipped)
+	fmt.Printf("  Graphs missing from Zep:   %d\n", missing)
+
+	if failed > 0 {
+		return fmt.Errorf("reconciliation completed with %d failure(s)", failed)
+	}
+
+	return nil
+}
+
+// listDBGraphs returns the local and Zep IDs of every graph in the database
+func listDBGraphs(ctx context.Context, db *sqlx.DB) ([]dbGraph, error) {
+	query := `
+		SELECT id, zep_graph_id
+		FROM graphs
+		ORDER BY created_at ASC
+	`
+
+	var graphs []dbGraph
+	err := db.SelectContext(ctx, &graphs, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query graphs: %w", err)
+	}
+
+	return graphs, nil
+}
+
+// flagSet reports whether the named flag was passed on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
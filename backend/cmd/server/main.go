@@ -18,6 +18,8 @@ import (
 	"github.com/bipulkrdas/orgmind/backend/internal/router"
 	"github.com/bipulkrdas/orgmind/backend/internal/service"
 	"github.com/bipulkrdas/orgmind/backend/internal/storage"
+	"github.com/bipulkrdas/orgmind/backend/internal/tracing"
+	"github.com/bipulkrdas/orgmind/backend/pkg/utils"
 )
 
 func main() {
@@ -28,10 +30,17 @@ func main() {
 	}
 
 	log.Println("Configuration loaded successfully")
+	log.Printf("Environment: %s", cfg.Environment)
 	log.Printf("Server will run on port: %s", cfg.ServerPort)
 	log.Printf("Database URL configured: %s", maskDatabaseURL(cfg.DatabaseURL))
 	log.Printf("JWT expiration: %d hours", cfg.JWTExpirationHours)
 
+	jwtKeys, err := loadJWTKeys(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+	log.Printf("JWT signing algorithm: %s", jwtKeys.Algorithm())
+
 	// Initialize database connection
 	log.Println("Connecting to database...")
 	db, err := database.Connect(database.Config{
@@ -71,26 +80,49 @@ func main() {
 	resetTokenRepo := repository.NewPasswordResetTokenRepository(db.DB)
 	graphRepo := repository.NewGraphRepository(db.DB)
 	geminiStoreRepo := repository.NewGeminiStoreRepository(db.DB)
+	uploadSessionRepo := repository.NewUploadSessionRepository(db.DB)
+	txManager := repository.NewTxManager(db.DB)
 
-	// Initialize storage service (S3)
-	log.Println("Initializing storage service...")
-	storageService, err := storage.NewS3StorageService(ctx, storage.S3Config{
-		Region:          cfg.AWSRegion,
-		Bucket:          cfg.AWSS3Bucket,
-		AccessKeyID:     cfg.AWSAccessKeyID,
-		SecretAccessKey: cfg.AWSSecretAccessKey,
+	// Start tracing, so the external calls made while initializing are traced too
+	shutdownTracing, err := tracing.Configure(tracing.Config{
+		Exporter:      cfg.TracingExporter,
+		OTLPEndpoint:  cfg.TracingOTLPEndpoint,
+		ServiceName:   cfg.TracingServiceName,
+		SamplePercent: cfg.TracingSamplePercent,
 	})
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	if cfg.TracingExporter != tracing.ExporterNone {
+		log.Printf("Tracing requests with the %s exporter", cfg.TracingExporter)
+	}
+
+	// Initialize storage service (S3 or local filesystem)
+	log.Printf("Initializing storage service (%s)...", cfg.StorageBackend)
+	storageService, err := newStorageService(ctx, cfg)
 	if err != nil {
 		log.Fatalf("Failed to initialize storage service: %v", err)
 	}
+	if cfg.TracingExporter != tracing.ExporterNone {
+		storageService = storage.NewTracedStorageService(storageService)
+	}
 	log.Println("Storage service initialized successfully")
 
 	// Initialize Zep service
 	log.Println("Initializing Zep service...")
-	zepService, err := service.NewZepService(cfg.ZepAPIKey)
+	zepService, err := service.NewZepService(cfg.ZepAPIKey, service.NodeStyle{
+		LabelColors:  cfg.GraphNodeColors,
+		DefaultColor: cfg.GraphNodeDefaultColor,
+		BaseSize:     float64(cfg.GraphNodeBaseSize),
+		SizePerLabel: float64(cfg.GraphNodeSizePerLabel),
+		SizePerScore: float64(cfg.GraphNodeSizePerScore),
+	})
 	if err != nil {
 		log.Fatalf("Failed to initialize Zep service: %v", err)
 	}
+	if cfg.TracingExporter != tracing.ExporterNone {
+		zepService = service.NewTracedZepService(zepService)
+	}
 	log.Println("Zep service initialized successfully")
 
 	// Initialize Gemini service
@@ -122,18 +154,22 @@ func main() {
 		}
 		log.Println("Gemini service initialized successfully")
 
-		// Initialize File Search store
+		// Initialize File Search store. If Gemini is unavailable the server still starts, and
+		// the store is initialized when chat or an upload first needs it.
 		log.Printf("Initializing Gemini File Search store: %s", cfg.GeminiStoreName)
 		storeID, err := geminiSvc.InitializeStore(ctx, cfg.GeminiStoreName)
 		if err != nil {
-			log.Fatalf("Failed to initialize Gemini File Search store: %v", err)
+			log.Printf("Warning: failed to initialize Gemini File Search store, will retry on first use: %v", err)
+		} else {
+			// Store the returned store ID in config for use by other services
+			cfg.GeminiStoreID = storeID
+			log.Printf("Gemini File Search store initialized successfully: %s", storeID)
 		}
 
-		// Store the returned store ID in config for use by other services
-		cfg.GeminiStoreID = storeID
-		log.Printf("Gemini File Search store initialized successfully: %s", storeID)
-
 		geminiService = geminiSvc
+		if cfg.TracingExporter != tracing.ExporterNone {
+			geminiService = service.NewTracedGeminiService(geminiSvc)
+		}
 	} else {
 		log.Println("GEMINI_API_KEY not set, skipping Gemini service initialization")
 		log.Println("AI chat features will not be available")
@@ -142,30 +178,74 @@ func main() {
 
 	// Initialize extraction service
 	log.Println("Initializing extraction service...")
-	extractionService := extraction.NewExtractionRouter(extraction.DefaultConfig())
+	extractionService := extraction.NewExtractionRouter(&extraction.ExtractionConfig{
+		MaxFileSize:       int64(cfg.MaxUploadSizeMB) * 1024 * 1024,
+		ExtractionTimeout: time.Duration(cfg.ExtractionTimeoutSeconds) * time.Second,
+		MaxConcurrent:     cfg.ExtractionMaxConcurrent,
+		MaxQueueDepth:     cfg.ExtractionMaxQueueDepth,
+		MaxMemoryPerFile:  int64(cfg.ExtractionMaxMemoryPerFileMB) * 1024 * 1024,
+
+		MaxExtractedTextBytes: cfg.ExtractionMaxTextMB * 1024 * 1024,
+
+		MaxArchiveEntries:   cfg.ExtractionMaxArchiveEntries,
+		MaxArchiveEntrySize: int64(cfg.ExtractionMaxArchiveEntrySizeMB) * 1024 * 1024,
+		MaxDecompressedSize: int64(cfg.ExtractionMaxDecompressedSizeMB) * 1024 * 1024,
+
+		MarkdownPreserveStructure: cfg.ExtractionMarkdownStructure,
+
+		MaxSpreadsheetRows:        cfg.ExtractionMaxSpreadsheetRows,
+		FailOnSpreadsheetRowLimit: cfg.ExtractionSpreadsheetRowLimitMode == "fail",
+		ExtractLinks:              cfg.ExtractionLinks,
+		ExtractFootnotes:          cfg.ExtractionFootnotes,
+
+		EnabledFormats:  cfg.ExtractionEnabledFormats,
+		DisabledFormats: cfg.ExtractionDisabledFormats,
+
+		ContentTypeAliases: cfg.ExtractionContentTypeAliases,
+	})
 	log.Println("Extraction service initialized successfully")
 
 	// Initialize business services
 	log.Println("Initializing business services...")
-	authService := service.NewAuthService(userRepo, resetTokenRepo, cfg)
-	graphService := service.NewGraphService(graphRepo, zepService)
-	processingService := service.NewProcessingService(documentRepo, zepService)
-	documentService := service.NewDocumentService(documentRepo, graphRepo, storageService, processingService, graphService, extractionService, geminiService)
+	authService := service.NewAuthService(userRepo, resetTokenRepo, graphRepo, documentRepo, txManager, storageService, zepService, cfg, jwtKeys)
+	graphService := service.NewGraphService(graphRepo, userRepo, txManager, zepService, cfg.MaxGraphsPerUser, cfg.ZepGraphIDPrefix, cfg.UniqueGraphNames, jwtKeys)
+	processingService := service.NewProcessingService(documentRepo, zepService, service.RetryPolicy{
+		MaxAttempts: cfg.DocumentRetryMaxAttempts,
+		BaseDelay:   time.Duration(cfg.DocumentRetryBaseDelaySeconds) * time.Second,
+	}, time.Duration(cfg.DocumentProcessingTimeoutSeconds)*time.Second)
+	documentService := service.NewDocumentService(documentRepo, graphRepo, storageService, processingService, graphService, extractionService, geminiService, uploadSessionRepo, time.Duration(cfg.UploadSessionTTLHours)*time.Hour, service.NewBulkPool(cfg.BulkOperationConcurrency), int64(cfg.MaxUploadSizeMB)*1024*1024)
 
 	// Initialize chat repository and service
 	chatRepo := repository.NewChatRepository(db.DB)
-	chatService := service.NewChatService(chatRepo, graphRepo, geminiService)
+	chatBackend := service.NewGeminiChatBackend(geminiService)
+	if cfg.ChatBackend == config.ChatBackendZep {
+		chatBackend = service.NewZepChatBackend(zepService)
+		log.Println("Chat answers come from Zep memory search (CHAT_BACKEND=zep)")
+	}
+	chatService := service.NewChatService(chatRepo, graphRepo, documentRepo, txManager, chatBackend, cfg.ChatContentSanitization, cfg.ChatSummaryMaxLength, int64(cfg.GeminiMonthlyTokenCapPerGraph), int64(cfg.GeminiMonthlyRequestCapPerGraph))
+	exportService := service.NewExportService(userRepo, graphRepo, documentRepo, chatRepo, storageService)
 
 	// Initialize handlers
 	log.Println("Initializing handlers...")
-	authHandler := handler.NewAuthHandler(authService)
+	authHandler := handler.NewAuthHandler(authService, exportService, jwtKeys)
 	documentHandler := handler.NewDocumentHandler(documentService)
-	graphHandler := handler.NewGraphHandler(graphService, documentService, zepService)
-	chatHandler := handler.NewChatHandler(chatService, graphService)
+	graphHandler := handler.NewGraphHandler(graphService, documentService, chatService, zepService)
+	chatHandler := handler.NewChatHandler(chatService, graphService, time.Duration(cfg.ChatStreamKeepAliveSeconds)*time.Second)
+	extractionHandler := handler.NewExtractionHandler(extractionService)
+
+	// Readiness checks; Gemini is only checked when it is configured
+	healthChecks := map[string]handler.HealthCheck{
+		"database": db.HealthCheck,
+		"zep":      zepService.HealthCheck,
+	}
+	if geminiService != nil {
+		healthChecks["gemini"] = geminiService.HealthCheck
+	}
+	healthHandler := handler.NewHealthHandler(healthChecks)
 
 	// Set up router with all handlers
 	log.Println("Setting up router...")
-	appRouter := router.NewRouter(authHandler, documentHandler, graphHandler, chatHandler, cfg)
+	appRouter := router.NewRouter(authHandler, documentHandler, graphHandler, chatHandler, extractionHandler, healthHandler, graphService, cfg, jwtKeys)
 	ginEngine := appRouter.Setup()
 
 	// Create HTTP server
@@ -185,6 +265,16 @@ func main() {
 		}
 	}()
 
+	// Retry documents whose processing failed transiently until the server shuts down
+	retryCtx, stopRetries := context.WithCancel(context.Background())
+	defer stopRetries()
+	if cfg.DocumentRetryMaxAttempts > 1 {
+		go service.RunDocumentRetries(retryCtx, documentService, time.Duration(cfg.DocumentRetryIntervalSeconds)*time.Second)
+	}
+
+	// Discard the parts of resumable uploads that were never completed
+	go service.RunUploadCleanup(retryCtx, documentService, time.Hour)
+
 	log.Println("Server started successfully")
 	log.Printf("OrgMind backend is running on http://localhost:%s", cfg.ServerPort)
 
@@ -194,6 +284,7 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopRetries()
 
 	// Create shutdown context with timeout
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -204,6 +295,11 @@ func main() {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
 
+	// Send the spans still waiting to be exported
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Printf("Failed to flush traces: %v", err)
+	}
+
 	log.Println("Server exited successfully")
 }
 
@@ -214,3 +310,59 @@ func maskDatabaseURL(url string) string {
 	}
 	return "***"
 }
+
+// newStorageService creates the document storage backend selected by STORAGE_BACKEND
+func newStorageService(ctx context.Context, cfg *config.Config) (storage.StorageService, error) {
+	if cfg.StorageBackend == "filesystem" {
+		log.Printf("Storing documents on the local filesystem under %s", cfg.StorageFilesystemRoot)
+		return storage.NewFilesystemStorageService(cfg.StorageFilesystemRoot, cfg.StorageKeyLayout)
+	}
+
+	return storage.NewS3StorageService(ctx, storage.S3Config{
+		Region:          cfg.AWSRegion,
+		Bucket:          cfg.AWSS3Bucket,
+		AccessKeyID:     cfg.AWSAccessKeyID,
+		SecretAccessKey: cfg.AWSSecretAccessKey,
+		Endpoint:        cfg.AWSS3Endpoint,
+		UsePathStyle:    cfg.AWSS3PathStyle,
+
+		ServerSideEncryption: cfg.AWSS3ServerSideEncryption,
+		KMSKeyID:             cfg.AWSS3KMSKeyID,
+		StorageClass:         cfg.AWSS3StorageClass,
+
+		KeyLayout: cfg.StorageKeyLayout,
+	})
+}
+
+// loadJWTKeys builds the token signing and verification keys, reading RS256 keys from their PEM files
+func loadJWTKeys(cfg *config.Config) (*utils.JWTKeys, error) {
+	opts := utils.JWTKeyOptions{
+		Algorithm:        cfg.JWTAlgorithm,
+		KeyID:            cfg.JWTKeyID,
+		Secret:           cfg.JWTSecret,
+		VerificationKeys: make(map[string][]byte, len(cfg.JWTVerificationKeys)),
+	}
+
+	if cfg.JWTAlgorithm != utils.JWTAlgorithmRS256 {
+		for kid, secret := range cfg.JWTVerificationKeys {
+			opts.VerificationKeys[kid] = []byte(secret)
+		}
+		return utils.NewJWTKeys(opts)
+	}
+
+	privateKeyPEM, err := os.ReadFile(cfg.JWTPrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT private key: %w", err)
+	}
+	opts.PrivateKeyPEM = privateKeyPEM
+
+	for kid, path := range cfg.JWTVerificationKeys {
+		publicKeyPEM, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT verification key %q: %w", kid, err)
+		}
+		opts.VerificationKeys[kid] = publicKeyPEM
+	}
+
+	return utils.NewJWTKeys(opts)
+}
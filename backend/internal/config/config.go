@@ -4,28 +4,80 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
+// Chat content sanitization policies
+const (
+	ChatSanitizationNone   = "none"   // Store message content as written; clients escape it when rendering
+	ChatSanitizationEscape = "escape" // HTML-escape message content before storing it
+)
+
+// Chat backends, which retrieve from the graphs' documents to answer chat messages
+const (
+	ChatBackendGemini = "gemini" // Gemini File Search over the uploaded documents, answered by Gemini
+	ChatBackendZep    = "zep"    // Zep memory search, answered with the matching passages
+)
+
+// maxZepGraphIDPrefixLength keeps prefixed Zep graph IDs (prefix + UUID) reasonably short
+const maxZepGraphIDPrefixLength = 32
+
+// zepGraphIDPrefixPattern matches valid Zep graph ID prefixes
+var zepGraphIDPrefixPattern = regexp.MustCompile(fmt.Sprintf(`^[A-Za-z0-9_-]{1,%d}$`, maxZepGraphIDPrefixLength))
+
+// hexColorPattern matches the #rrggbb colors of graph visualization nodes
+var hexColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
 // Config holds all application configuration
 type Config struct {
 	// Server
-	ServerPort string
+	ServerPort  string
+	Environment string // "development" (default), "staging" or "production"
+	LogLevel    string // "debug" logs every request; defaults to debug in development, info elsewhere
+	RequireTLS  bool   // Reject plain-HTTP requests and non-TLS endpoints; defaults to on outside development
+
+	// Tracing of requests and their Zep, Gemini and storage calls
+	TracingExporter      string // "none" (default), "log" or "otlp"
+	TracingOTLPEndpoint  string // OTLP/HTTP traces endpoint of an OpenTelemetry collector
+	TracingServiceName   string // service.name of exported spans
+	TracingSamplePercent int    // Percentage of new traces recorded (0-100)
+
+	// CORS
+	CORSAllowedOrigins   []string // Defaults to "*" in development; required elsewhere
+	CORSAllowCredentials bool
 
 	// Database
 	DatabaseURL string
 
 	// JWT
-	JWTSecret          string
-	JWTExpirationHours int
+	JWTSecret           string
+	JWTExpirationHours  int
+	JWTAlgorithm        string            // "HS256" (default) or "RS256"
+	JWTKeyID            string            // kid header of issued tokens, used for key rotation
+	JWTPrivateKeyFile   string            // PEM RSA private key file, required for RS256
+	JWTVerificationKeys map[string]string // Rotated-out keys by kid: secrets (HS256) or PEM public key files (RS256)
+
+	// Storage backend
+	StorageBackend        string // "s3" or "filesystem"; required unless ENVIRONMENT selects a default
+	StorageFilesystemRoot string // Root directory for the filesystem backend
+	StorageKeyLayout      string // Layout of storage keys, e.g. "{graphId}/{documentId}/{filename}"
 
 	// AWS S3
 	AWSRegion          string
 	AWSAccessKeyID     string
 	AWSSecretAccessKey string
 	AWSS3Bucket        string
+	AWSS3Endpoint      string // Custom endpoint for S3-compatible stores (e.g. MinIO)
+	AWSS3PathStyle     bool   // Use path-style addressing (required by most S3-compatible stores)
+
+	// S3 encryption at rest and storage class (empty = bucket defaults)
+	AWSS3ServerSideEncryption string // "AES256" (SSE-S3) or "aws:kms" (SSE-KMS)
+	AWSS3KMSKeyID             string // KMS key ID or ARN, used with "aws:kms"
+	AWSS3StorageClass         string // e.g. STANDARD, STANDARD_IA, INTELLIGENT_TIERING
 
 	// Zep Cloud
 	ZepAPIKey string
@@ -53,6 +105,89 @@ type Config struct {
 
 	// OAuth Redirect
 	OAuthRedirectURL string
+
+	// Document Extraction
+	MaxUploadSizeMB              int // Maximum file size accepted for upload and extraction
+	ExtractionTimeoutSeconds     int // Upper bound on the per-file extraction timeout
+	ExtractionMaxConcurrent      int // Maximum extractions running in parallel
+	ExtractionMaxQueueDepth      int // Maximum extractions waiting for a slot (0 = unlimited)
+	ExtractionMaxMemoryPerFileMB int // Maximum memory usage per file extraction
+	ExtractionMaxTextMB          int // Maximum extracted text size before truncation (0 = unlimited)
+
+	// Archive limits for DOCX, XLSX, PPTX and EPUB uploads
+	ExtractionMaxArchiveEntries     int // Maximum number of entries in an archive
+	ExtractionMaxArchiveEntrySizeMB int // Maximum decompressed size of a single entry
+	ExtractionMaxDecompressedSizeMB int // Maximum total decompressed size of an archive
+
+	// Convert Markdown tables to header: value rows and keep blockquote attribution
+	ExtractionMarkdownStructure bool
+
+	// Row cap for CSV and XLSX files (0 = unlimited); past it the text is truncated, or the
+	// upload fails when the mode is "fail"
+	ExtractionMaxSpreadsheetRows      int
+	ExtractionSpreadsheetRowLimitMode string
+
+	// List hyperlinks after the extracted text of HTML, Markdown, DOCX and PDF documents
+	ExtractionLinks bool
+
+	// List DOCX footnotes and endnotes, and footnotes recovered from PDF pages, after the extracted text
+	ExtractionFootnotes bool
+
+	// Format policy for uploads: MIME types or extensions (e.g. ".epub")
+	ExtractionEnabledFormats  []string // Only these formats are accepted (empty = all)
+	ExtractionDisabledFormats []string // These formats are rejected
+
+	// Nonstandard MIME types mapped to the supported type they stand for, added to the built-in aliases
+	ExtractionContentTypeAliases map[string]string
+
+	// Usage limits
+	MaxGraphsPerUser int // Maximum graphs a user can create (0 = unlimited)
+
+	// Reject graph names the creator already uses, ignoring case
+	UniqueGraphNames bool
+
+	// Signup restrictions
+	AllowedEmailDomains []string // Email domains allowed to create accounts (empty = unrestricted)
+
+	// Chat
+	ChatContentSanitization string // ChatSanitizationNone (default) or ChatSanitizationEscape
+	ChatSummaryMaxLength    int    // Maximum length of thread summaries generated from the first message
+
+	// Seconds between SSE keep-alive pings while a chat stream waits for its first chunk (0 = disabled)
+	ChatStreamKeepAliveSeconds int
+
+	// Backend answering chat messages: ChatBackendGemini (default) or ChatBackendZep
+	ChatBackend string
+
+	// Prefix of the Zep graph IDs created for new graphs, to namespace environments sharing a Zep project
+	ZepGraphIDPrefix string
+
+	// Automatic retries of documents whose processing failed transiently
+	DocumentRetryMaxAttempts      int // Processing attempts, including the first, before giving up (<= 1 disables retries)
+	DocumentRetryBaseDelaySeconds int // Delay before the first retry, doubled for each further attempt
+	DocumentRetryIntervalSeconds  int // How often to look for documents due for a retry
+
+	// Upper bound on processing a document into Zep; slower runs fail and are retried
+	DocumentProcessingTimeoutSeconds int
+
+	// How long a resumable upload can take before its unfinished parts are discarded
+	UploadSessionTTLHours int
+
+	// Documents reprocessed at once by bulk operations (graph reindexes, retry passes), shared by all of them
+	BulkOperationConcurrency int
+
+	// Graph visualization node style: hex colors of entity labels (merged over the built-in
+	// palette) and of unlabeled nodes, and node size = base + per label + score * per score
+	GraphNodeColors       map[string]string
+	GraphNodeDefaultColor string
+	GraphNodeBaseSize     int
+	GraphNodeSizePerLabel int
+	GraphNodeSizePerScore int
+
+	// Monthly chat budget of each graph; chat is unavailable for the rest of the month once
+	// either is used up (0 = unlimited)
+	GeminiMonthlyTokenCapPerGraph   int // Gemini tokens, input + output
+	GeminiMonthlyRequestCapPerGraph int // Chat responses generated
 }
 
 // Load reads configuration from environment variables
@@ -61,15 +196,54 @@ func Load() (*Config, error) {
 	// This works whether you run from project root or backend directory
 	loadEnvFile()
 
+	environment, err := parseEnvironment(getEnv("ENVIRONMENT", EnvDevelopment))
+	if err != nil {
+		return nil, err
+	}
+	defaults := profiles[environment]
+
+	// Without an explicit ENVIRONMENT, don't guess where documents are stored: a deployment
+	// that forgot ENVIRONMENT would otherwise silently keep them on local disk
+	if os.Getenv("ENVIRONMENT") == "" {
+		defaults.StorageBackend = ""
+	}
+
 	cfg := &Config{
-		ServerPort:            getEnv("SERVER_PORT", "8080"),
-		DatabaseURL:           getEnv("DATABASE_URL", ""),
-		JWTSecret:             getEnv("JWT_SECRET", ""),
-		JWTExpirationHours:    getEnvAsInt("JWT_EXPIRATION_HOURS", 24),
-		AWSRegion:             getEnv("AWS_REGION", ""),
-		AWSAccessKeyID:        getEnv("AWS_ACCESS_KEY_ID", ""),
-		AWSSecretAccessKey:    getEnv("AWS_SECRET_ACCESS_KEY", ""),
-		AWSS3Bucket:           getEnv("AWS_S3_BUCKET", ""),
+		ServerPort:  getEnv("SERVER_PORT", "8080"),
+		Environment: environment,
+		LogLevel:    getEnv("LOG_LEVEL", defaults.LogLevel),
+		RequireTLS:  getEnvAsBool("REQUIRE_TLS", defaults.RequireTLS),
+
+		TracingExporter:      getEnv("TRACING_EXPORTER", "none"),
+		TracingOTLPEndpoint:  getEnv("TRACING_OTLP_ENDPOINT", "http://localhost:4318/v1/traces"),
+		TracingServiceName:   getEnv("TRACING_SERVICE_NAME", "orgmind-backend"),
+		TracingSamplePercent: getEnvAsInt("TRACING_SAMPLE_PERCENT", 100),
+
+		CORSAllowedOrigins:   getEnvAsList("CORS_ALLOWED_ORIGINS", defaults.CORSAllowedOrigins),
+		CORSAllowCredentials: getEnvAsBool("CORS_ALLOW_CREDENTIALS", true),
+
+		DatabaseURL:        getEnv("DATABASE_URL", ""),
+		JWTSecret:          getEnv("JWT_SECRET", ""),
+		JWTExpirationHours: getEnvAsInt("JWT_EXPIRATION_HOURS", 24),
+		JWTAlgorithm:       getEnv("JWT_ALGORITHM", "HS256"),
+		JWTKeyID:           getEnv("JWT_KEY_ID", ""),
+		JWTPrivateKeyFile:  getEnv("JWT_PRIVATE_KEY_FILE", ""),
+
+		StorageBackend:        getEnv("STORAGE_BACKEND", defaults.StorageBackend),
+		StorageFilesystemRoot: getEnv("STORAGE_FILESYSTEM_ROOT", "./data/storage"),
+		StorageKeyLayout:      getEnv("STORAGE_KEY_LAYOUT", "{userId}/{documentId}"),
+
+		AWSRegion:          getEnv("AWS_REGION", ""),
+		AWSAccessKeyID:     getEnv("AWS_ACCESS_KEY_ID", ""),
+		AWSSecretAccessKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
+		AWSS3Bucket:        getEnv("AWS_S3_BUCKET", ""),
+		AWSS3Endpoint:      getEnv("AWS_S3_ENDPOINT", ""),
+		AWSS3PathStyle:     getEnvAsBool("AWS_S3_PATH_STYLE", false),
+
+		AWSS3ServerSideEncryption: getEnv("AWS_S3_SERVER_SIDE_ENCRYPTION", ""),
+		AWSS3KMSKeyID:             getEnv("AWS_S3_KMS_KEY_ID", ""),
+		AWSS3StorageClass:         getEnv("AWS_S3_STORAGE_CLASS", ""),
+
 		ZepAPIKey:             getEnv("ZEP_API_KEY", ""),
 		ZepAPIURL:             getEnv("ZEP_API_URL", "https://api.getzep.com/api/v2"),
 		GeminiAPIKey:          getEnv("GEMINI_API_KEY", ""),
@@ -85,7 +259,82 @@ func Load() (*Config, error) {
 		Office365ClientID:     getEnv("OFFICE365_CLIENT_ID", ""),
 		Office365ClientSecret: getEnv("OFFICE365_CLIENT_SECRET", ""),
 		OAuthRedirectURL:      getEnv("OAUTH_REDIRECT_URL", ""),
+
+		MaxUploadSizeMB:              getEnvAsInt("MAX_UPLOAD_SIZE_MB", 50),
+		ExtractionTimeoutSeconds:     getEnvAsInt("EXTRACTION_TIMEOUT_SECONDS", 30),
+		ExtractionMaxConcurrent:      getEnvAsInt("EXTRACTION_MAX_CONCURRENT", 10),
+		ExtractionMaxQueueDepth:      getEnvAsInt("EXTRACTION_MAX_QUEUE_DEPTH", 50),
+		ExtractionMaxMemoryPerFileMB: getEnvAsInt("EXTRACTION_MAX_MEMORY_PER_FILE_MB", 100),
+		ExtractionMaxTextMB:          getEnvAsInt("EXTRACTION_MAX_TEXT_MB", 10),
+
+		ExtractionMaxArchiveEntries:     getEnvAsInt("EXTRACTION_MAX_ARCHIVE_ENTRIES", 10000),
+		ExtractionMaxArchiveEntrySizeMB: getEnvAsInt("EXTRACTION_MAX_ARCHIVE_ENTRY_SIZE_MB", 50),
+		ExtractionMaxDecompressedSizeMB: getEnvAsInt("EXTRACTION_MAX_DECOMPRESSED_SIZE_MB", 100),
+		ExtractionMarkdownStructure:     getEnvAsBool("EXTRACTION_MARKDOWN_STRUCTURE", false),
+		ExtractionEnabledFormats:        getEnvAsList("EXTRACTION_ENABLED_FORMATS", ""),
+		ExtractionDisabledFormats:       getEnvAsList("EXTRACTION_DISABLED_FORMATS", ""),
+
+		ExtractionMaxSpreadsheetRows:      getEnvAsInt("EXTRACTION_MAX_SPREADSHEET_ROWS", 100000),
+		ExtractionSpreadsheetRowLimitMode: getEnv("EXTRACTION_SPREADSHEET_ROW_LIMIT_MODE", "truncate"),
+		ExtractionLinks:                   getEnvAsBool("EXTRACTION_LINKS", false),
+		ExtractionFootnotes:               getEnvAsBool("EXTRACTION_FOOTNOTES", false),
+
+		MaxGraphsPerUser: getEnvAsInt("MAX_GRAPHS_PER_USER", 50),
+
+		UniqueGraphNames: getEnvAsBool("UNIQUE_GRAPH_NAMES", false),
+
+		AllowedEmailDomains: getEnvAsList("ALLOWED_EMAIL_DOMAINS", ""),
+
+		ChatContentSanitization: getEnv("CHAT_CONTENT_SANITIZATION", ChatSanitizationNone),
+		ChatSummaryMaxLength:    getEnvAsInt("CHAT_SUMMARY_MAX_LENGTH", 100),
+
+		ChatStreamKeepAliveSeconds: getEnvAsInt("CHAT_STREAM_KEEPALIVE_SECONDS", 15),
+
+		ChatBackend: getEnv("CHAT_BACKEND", ChatBackendGemini),
+
+		ZepGraphIDPrefix: getEnv("ZEP_GRAPH_ID_PREFIX", "graph-"),
+
+		DocumentRetryMaxAttempts:      getEnvAsInt("DOCUMENT_RETRY_MAX_ATTEMPTS", 5),
+		DocumentRetryBaseDelaySeconds: getEnvAsInt("DOCUMENT_RETRY_BASE_DELAY_SECONDS", 60),
+		DocumentRetryIntervalSeconds:  getEnvAsInt("DOCUMENT_RETRY_INTERVAL_SECONDS", 60),
+
+		DocumentProcessingTimeoutSeconds: getEnvAsInt("DOCUMENT_PROCESSING_TIMEOUT_SECONDS", 600),
+
+		UploadSessionTTLHours: getEnvAsInt("UPLOAD_SESSION_TTL_HOURS", 24),
+
+		BulkOperationConcurrency: getEnvAsInt("BULK_OPERATION_CONCURRENCY", 4),
+
+		GraphNodeDefaultColor: getEnv("GRAPH_NODE_DEFAULT_COLOR", "#3b82f6"),
+		GraphNodeBaseSize:     getEnvAsInt("GRAPH_NODE_BASE_SIZE", 10),
+		GraphNodeSizePerLabel: getEnvAsInt("GRAPH_NODE_SIZE_PER_LABEL", 2),
+		GraphNodeSizePerScore: getEnvAsInt("GRAPH_NODE_SIZE_PER_SCORE", 10),
+
+		GeminiMonthlyTokenCapPerGraph:   getEnvAsInt("GEMINI_MONTHLY_TOKEN_CAP_PER_GRAPH", 0),
+		GeminiMonthlyRequestCapPerGraph: getEnvAsInt("GEMINI_MONTHLY_REQUEST_CAP_PER_GRAPH", 0),
+	}
+
+	// Domains are compared case-insensitively; accept "@example.com" as well as "example.com"
+	for i, domain := range cfg.AllowedEmailDomains {
+		cfg.AllowedEmailDomains[i] = strings.ToLower(strings.TrimPrefix(domain, "@"))
+	}
+
+	jwtVerificationKeys, err := getEnvAsMap("JWT_VERIFICATION_KEYS")
+	if err != nil {
+		return nil, err
 	}
+	cfg.JWTVerificationKeys = jwtVerificationKeys
+
+	graphNodeColors, err := getEnvAsMap("GRAPH_NODE_COLORS")
+	if err != nil {
+		return nil, err
+	}
+	cfg.GraphNodeColors = graphNodeColors
+
+	contentTypeAliases, err := getEnvAsMap("EXTRACTION_CONTENT_TYPE_ALIASES")
+	if err != nil {
+		return nil, err
+	}
+	cfg.ExtractionContentTypeAliases = contentTypeAliases
 
 	// Validate required fields
 	if err := cfg.Validate(); err != nil {
@@ -99,7 +348,16 @@ func Load() (*Config, error) {
 func (c *Config) Validate() error {
 	required := map[string]string{
 		"DATABASE_URL": c.DatabaseURL,
-		"JWT_SECRET":   c.JWTSecret,
+	}
+
+	// HS256 signs with a shared secret, RS256 with a private key
+	switch c.JWTAlgorithm {
+	case "HS256":
+		required["JWT_SECRET"] = c.JWTSecret
+	case "RS256":
+		required["JWT_PRIVATE_KEY_FILE"] = c.JWTPrivateKeyFile
+	default:
+		return fmt.Errorf("environment variable JWT_ALGORITHM must be \"HS256\" or \"RS256\", got %q", c.JWTAlgorithm)
 	}
 
 	for key, value := range required {
@@ -108,6 +366,139 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.ChatContentSanitization != ChatSanitizationNone && c.ChatContentSanitization != ChatSanitizationEscape {
+		return fmt.Errorf("environment variable CHAT_CONTENT_SANITIZATION must be %q or %q, got %q", ChatSanitizationNone, ChatSanitizationEscape, c.ChatContentSanitization)
+	}
+
+	if c.ChatBackend != ChatBackendGemini && c.ChatBackend != ChatBackendZep {
+		return fmt.Errorf("environment variable CHAT_BACKEND must be %q or %q, got %q", ChatBackendGemini, ChatBackendZep, c.ChatBackend)
+	}
+
+	// Summaries are stored in a VARCHAR(200) column
+	if c.ChatSummaryMaxLength < 10 || c.ChatSummaryMaxLength > 200 {
+		return fmt.Errorf("environment variable CHAT_SUMMARY_MAX_LENGTH must be between 10 and 200, got %d", c.ChatSummaryMaxLength)
+	}
+
+	if !zepGraphIDPrefixPattern.MatchString(c.ZepGraphIDPrefix) {
+		return fmt.Errorf("environment variable ZEP_GRAPH_ID_PREFIX must be 1-%d letters, digits, hyphens or underscores, got %q", maxZepGraphIDPrefixLength, c.ZepGraphIDPrefix)
+	}
+
+	if c.DocumentRetryMaxAttempts < 0 {
+		return fmt.Errorf("environment variable DOCUMENT_RETRY_MAX_ATTEMPTS cannot be negative")
+	}
+
+	if c.DocumentRetryMaxAttempts > 1 && (c.DocumentRetryBaseDelaySeconds <= 0 || c.DocumentRetryIntervalSeconds <= 0) {
+		return fmt.Errorf("environment variables DOCUMENT_RETRY_BASE_DELAY_SECONDS and DOCUMENT_RETRY_INTERVAL_SECONDS must be positive when retries are enabled")
+	}
+
+	if c.ChatStreamKeepAliveSeconds < 0 {
+		return fmt.Errorf("environment variable CHAT_STREAM_KEEPALIVE_SECONDS cannot be negative")
+	}
+
+	if c.GeminiMonthlyTokenCapPerGraph < 0 || c.GeminiMonthlyRequestCapPerGraph < 0 {
+		return fmt.Errorf("environment variables GEMINI_MONTHLY_TOKEN_CAP_PER_GRAPH and GEMINI_MONTHLY_REQUEST_CAP_PER_GRAPH cannot be negative")
+	}
+
+	if c.TracingExporter != "none" && c.TracingExporter != "log" && c.TracingExporter != "otlp" {
+		return fmt.Errorf("environment variable TRACING_EXPORTER must be \"none\", \"log\" or \"otlp\", got %q", c.TracingExporter)
+	}
+
+	if c.TracingExporter == "otlp" && !strings.HasPrefix(c.TracingOTLPEndpoint, "http://") && !strings.HasPrefix(c.TracingOTLPEndpoint, "https://") {
+		return fmt.Errorf("environment variable TRACING_OTLP_ENDPOINT must be an http(s) URL, got %q", c.TracingOTLPEndpoint)
+	}
+
+	if c.TracingSamplePercent < 0 || c.TracingSamplePercent > 100 {
+		return fmt.Errorf("environment variable TRACING_SAMPLE_PERCENT must be between 0 and 100, got %d", c.TracingSamplePercent)
+	}
+
+	if c.StorageBackend == "" {
+		return fmt.Errorf("environment variable STORAGE_BACKEND is required when ENVIRONMENT is not set; set it to \"s3\" or \"filesystem\", or set ENVIRONMENT to pick a default")
+	}
+	if c.StorageBackend != "s3" && c.StorageBackend != "filesystem" {
+		return fmt.Errorf("environment variable STORAGE_BACKEND must be \"s3\" or \"filesystem\", got %q", c.StorageBackend)
+	}
+
+	// Extraction limits must be positive; a zero concurrency limit would block every upload
+	positive := map[string]int{
+		"MAX_UPLOAD_SIZE_MB":                   c.MaxUploadSizeMB,
+		"EXTRACTION_TIMEOUT_SECONDS":           c.ExtractionTimeoutSeconds,
+		"EXTRACTION_MAX_CONCURRENT":            c.ExtractionMaxConcurrent,
+		"EXTRACTION_MAX_MEMORY_PER_FILE_MB":    c.ExtractionMaxMemoryPerFileMB,
+		"EXTRACTION_MAX_ARCHIVE_ENTRIES":       c.ExtractionMaxArchiveEntries,
+		"EXTRACTION_MAX_ARCHIVE_ENTRY_SIZE_MB": c.ExtractionMaxArchiveEntrySizeMB,
+		"EXTRACTION_MAX_DECOMPRESSED_SIZE_MB":  c.ExtractionMaxDecompressedSizeMB,
+		"DOCUMENT_PROCESSING_TIMEOUT_SECONDS":  c.DocumentProcessingTimeoutSeconds,
+		"UPLOAD_SESSION_TTL_HOURS":             c.UploadSessionTTLHours,
+		"BULK_OPERATION_CONCURRENCY":           c.BulkOperationConcurrency,
+	}
+
+	for key, value := range positive {
+		if value <= 0 {
+			return fmt.Errorf("environment variable %s must be a positive integer", key)
+		}
+	}
+
+	if c.ExtractionMaxQueueDepth < 0 {
+		return fmt.Errorf("environment variable EXTRACTION_MAX_QUEUE_DEPTH cannot be negative")
+	}
+
+	if c.ExtractionMaxTextMB < 0 {
+		return fmt.Errorf("environment variable EXTRACTION_MAX_TEXT_MB cannot be negative")
+	}
+
+	if c.ExtractionMaxSpreadsheetRows < 0 {
+		return fmt.Errorf("environment variable EXTRACTION_MAX_SPREADSHEET_ROWS cannot be negative")
+	}
+
+	for alias, contentType := range c.ExtractionContentTypeAliases {
+		if !strings.Contains(alias, "/") || !strings.Contains(contentType, "/") {
+			return fmt.Errorf("environment variable EXTRACTION_CONTENT_TYPE_ALIASES must map MIME types to MIME types, got %q=%q", alias, contentType)
+		}
+	}
+
+	if c.ExtractionSpreadsheetRowLimitMode != "truncate" && c.ExtractionSpreadsheetRowLimitMode != "fail" {
+		return fmt.Errorf("environment variable EXTRACTION_SPREADSHEET_ROW_LIMIT_MODE must be \"truncate\" or \"fail\", got %q", c.ExtractionSpreadsheetRowLimitMode)
+	}
+
+	if c.MaxGraphsPerUser < 0 {
+		return fmt.Errorf("environment variable MAX_GRAPHS_PER_USER cannot be negative")
+	}
+
+	if !hexColorPattern.MatchString(c.GraphNodeDefaultColor) {
+		return fmt.Errorf("environment variable GRAPH_NODE_DEFAULT_COLOR must be a #rrggbb color, got %q", c.GraphNodeDefaultColor)
+	}
+	for label, color := range c.GraphNodeColors {
+		if !hexColorPattern.MatchString(color) {
+			return fmt.Errorf("environment variable GRAPH_NODE_COLORS has an invalid color %q for %q; use #rrggbb", color, label)
+		}
+	}
+
+	if c.GraphNodeBaseSize <= 0 || c.GraphNodeSizePerLabel < 0 || c.GraphNodeSizePerScore < 0 {
+		return fmt.Errorf("environment variable GRAPH_NODE_BASE_SIZE must be positive, and GRAPH_NODE_SIZE_PER_LABEL and GRAPH_NODE_SIZE_PER_SCORE cannot be negative")
+	}
+
+	for _, domain := range c.AllowedEmailDomains {
+		if strings.ContainsAny(domain, "@ ") || !strings.Contains(domain, ".") {
+			return fmt.Errorf("environment variable ALLOWED_EMAIL_DOMAINS contains an invalid domain %q", domain)
+		}
+	}
+
+	if len(c.CORSAllowedOrigins) == 0 {
+		return fmt.Errorf("environment variable CORS_ALLOWED_ORIGINS is required in %s", c.Environment)
+	}
+
+	if c.RequireTLS {
+		if err := c.validateTLS(); err != nil {
+			return err
+		}
+	}
+
+	if c.IsProduction() {
+		if err := c.validateProduction(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -174,6 +565,62 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return value
 }
 
+// getEnvAsList retrieves an environment variable as a comma-separated list or splits the default value
+func getEnvAsList(key, defaultValue string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, defaultValue), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvAsMap retrieves an environment variable holding comma-separated key=value pairs
+func getEnvAsMap(key string) (map[string]string, error) {
+	result := make(map[string]string)
+
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return result, nil
+	}
+
+	for _, pair := range strings.Split(valueStr, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		k, v, ok := strings.Cut(pair, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" || v == "" {
+			return nil, fmt.Errorf("environment variable %s must be a comma-separated list of key=value pairs", key)
+		}
+		if _, exists := result[k]; exists {
+			return nil, fmt.Errorf("environment variable %s contains duplicate key %q", key, k)
+		}
+
+		result[k] = v
+	}
+
+	return result, nil
+}
+
+// getEnvAsBool retrieves an environment variable as a boolean or returns a default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
 // loadEnvFile attempts to load .env file from multiple possible locations
 // This ensures it works whether running from project root or backend directory
 func loadEnvFile() {
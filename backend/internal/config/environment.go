@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Deployment environments selected by ENVIRONMENT
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// placeholderJWTSecret is the example secret from .env.example, which must never reach production
+const placeholderJWTSecret = "your-secret-key-here-change-this-in-production"
+
+// minProductionSecretLength is the minimum JWT secret length accepted in production
+const minProductionSecretLength = 32
+
+// profile holds the defaults that differ between environments.
+// Explicitly set environment variables always take precedence.
+type profile struct {
+	StorageBackend     string
+	LogLevel           string
+	CORSAllowedOrigins string
+	RequireTLS         bool
+}
+
+// profiles maps each environment to its defaults: development favours a zero-setup
+// local stack, while staging and production require explicit origins and TLS
+var profiles = map[string]profile{
+	EnvDevelopment: {
+		StorageBackend:     "filesystem",
+		LogLevel:           "debug",
+		CORSAllowedOrigins: "*",
+		RequireTLS:         false,
+	},
+	EnvStaging: {
+		StorageBackend:     "s3",
+		LogLevel:           "info",
+		CORSAllowedOrigins: "",
+		RequireTLS:         true,
+	},
+	EnvProduction: {
+		StorageBackend:     "s3",
+		LogLevel:           "info",
+		CORSAllowedOrigins: "",
+		RequireTLS:         true,
+	},
+}
+
+// parseEnvironment normalizes ENVIRONMENT, accepting the short forms dev and prod
+func parseEnvironment(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "dev", EnvDevelopment:
+		return EnvDevelopment, nil
+	case EnvStaging:
+		return EnvStaging, nil
+	case "prod", EnvProduction:
+		return EnvProduction, nil
+	default:
+		return "", fmt.Errorf("environment variable ENVIRONMENT must be development, staging or production, got %q", value)
+	}
+}
+
+// IsDevelopment reports whether the application runs in the development environment
+func (c *Config) IsDevelopment() bool {
+	return c.Environment == EnvDevelopment
+}
+
+// IsProduction reports whether the application runs in the production environment
+func (c *Config) IsProduction() bool {
+	return c.Environment == EnvProduction
+}
+
+// validateTLS checks that every configured endpoint uses TLS when REQUIRE_TLS is enabled
+func (c *Config) validateTLS() error {
+	if strings.Contains(c.DatabaseURL, "sslmode=disable") {
+		return fmt.Errorf("DATABASE_URL must not use sslmode=disable when REQUIRE_TLS is enabled")
+	}
+
+	urls := map[string]string{
+		"OAUTH_REDIRECT_URL": c.OAuthRedirectURL,
+		"AWS_S3_ENDPOINT":    c.AWSS3Endpoint,
+	}
+	for key, value := range urls {
+		if value != "" && !isHTTPS(value) {
+			return fmt.Errorf("environment variable %s must use https when REQUIRE_TLS is enabled", key)
+		}
+	}
+
+	for _, origin := range c.CORSAllowedOrigins {
+		if origin != "*" && !isHTTPS(origin) {
+			return fmt.Errorf("CORS origin %q must use https when REQUIRE_TLS is enabled", origin)
+		}
+	}
+
+	return nil
+}
+
+// validateProduction applies the stricter checks that guard against development
+// defaults leaking into production
+func (c *Config) validateProduction() error {
+	if c.JWTAlgorithm == "HS256" {
+		if c.JWTSecret == placeholderJWTSecret {
+			return fmt.Errorf("JWT_SECRET is set to the example value; generate one with: openssl rand -base64 32")
+		}
+		if len(c.JWTSecret) < minProductionSecretLength {
+			return fmt.Errorf("JWT_SECRET must be at least %d characters in production", minProductionSecretLength)
+		}
+	}
+
+	for _, origin := range c.CORSAllowedOrigins {
+		if origin == "*" {
+			return fmt.Errorf("CORS_ALLOWED_ORIGINS must list explicit origins in production, not \"*\"")
+		}
+	}
+
+	if !c.RequireTLS {
+		return fmt.Errorf("REQUIRE_TLS cannot be disabled in production")
+	}
+
+	return nil
+}
+
+// isHTTPS reports whether rawURL is an https URL
+func isHTTPS(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	return err == nil && parsed.Scheme == "https" && parsed.Host != ""
+}
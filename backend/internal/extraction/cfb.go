@@ -0,0 +1,309 @@
+package extraction
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+)
+
+// Special sector numbers in compound file allocation tables
+const (
+	cfbMaxRegularSector = 0xFFFFFFFA
+	cfbEndOfChain       = 0xFFFFFFFE
+	cfbNoStream         = 0xFFFFFFFF
+)
+
+// Directory entry object types
+const (
+	cfbTypeStream = 2
+	cfbTypeRoot   = 5
+)
+
+// cfbDirEntrySize is the size of a directory entry in bytes
+const cfbDirEntrySize = 128
+
+// errCFBStreamNotFound is returned when a compound file has no stream with the requested name
+var errCFBStreamNotFound = errors.New("stream not found")
+
+// cfbEntry is a directory entry of a compound file
+type cfbEntry struct {
+	name        string
+	objectType  byte
+	left        uint32
+	right       uint32
+	child       uint32
+	startSector uint32
+	size        uint64
+}
+
+// compoundFile is a read-only view of an OLE compound file (MS-CFB), the container
+// of legacy Office documents. Only what's needed to read top-level streams is parsed.
+type compoundFile struct {
+	data           []byte
+	sectorSize     int
+	miniSectorSize int
+	miniCutoff     uint64
+	fat            []uint32
+	miniFAT        []uint32
+	miniStream     []byte
+	entries        []cfbEntry
+}
+
+// openCompoundFile parses the header, allocation tables and directory of a compound file
+func openCompoundFile(data []byte) (*compoundFile, error) {
+	if len(data) < 512 || !isOLECompoundFile(data) {
+		return nil, fmt.Errorf("not an OLE compound file")
+	}
+
+	sectorShift := binary.LittleEndian.Uint16(data[0x1E:])
+	miniSectorShift := binary.LittleEndian.Uint16(data[0x20:])
+	if sectorShift != 9 && sectorShift != 12 {
+		return nil, fmt.Errorf("invalid sector size")
+	}
+	if miniSectorShift != 6 {
+		return nil, fmt.Errorf("invalid mini sector size")
+	}
+
+	cf := &compoundFile{
+		data:           data,
+		sectorSize:     1 << sectorShift,
+		miniSectorSize: 1 << miniSectorShift,
+		miniCutoff:     uint64(binary.LittleEndian.Uint32(data[0x38:])),
+	}
+
+	if err := cf.readFAT(); err != nil {
+		return nil, err
+	}
+
+	// Directory; only version 4 headers record its sector count
+	dirLimit := len(data)
+	if dirSectors := int(binary.LittleEndian.Uint32(data[0x28:])); dirSectors > 0 && dirSectors*cf.sectorSize < dirLimit {
+		dirLimit = dirSectors * cf.sectorSize
+	}
+	dirData, err := cf.readChain(binary.LittleEndian.Uint32(data[0x30:]), cf.fat, cf.sector, dirLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	version3 := binary.LittleEndian.Uint16(data[0x1A:]) == 3
+	for offset := 0; offset+cfbDirEntrySize <= len(dirData); offset += cfbDirEntrySize {
+		entry := parseCFBEntry(dirData[offset : offset+cfbDirEntrySize])
+		// Version 3 files may leave garbage in the high half of the stream size
+		if version3 {
+			entry.size &= 0xFFFFFFFF
+		}
+		cf.entries = append(cf.entries, entry)
+	}
+	if len(cf.entries) == 0 || cf.entries[0].objectType != cfbTypeRoot {
+		return nil, fmt.Errorf("missing root directory entry")
+	}
+
+	// Mini FAT and mini stream hold the streams smaller than the cutoff
+	if firstMiniFAT := binary.LittleEndian.Uint32(data[0x3C:]); firstMiniFAT <= cfbMaxRegularSector {
+		miniFATLimit := len(data)
+		if miniFATSectors := int(binary.LittleEndian.Uint32(data[0x40:])); miniFATSectors > 0 && miniFATSectors*cf.sectorSize < miniFATLimit {
+			miniFATLimit = miniFATSectors * cf.sectorSize
+		}
+		miniFATData, err := cf.readChain(firstMiniFAT, cf.fat, cf.sector, miniFATLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mini FAT: %w", err)
+		}
+		cf.miniFAT = bytesToUint32s(miniFATData)
+
+		root := cf.entries[0]
+		miniStream, err := cf.readChain(root.startSector, cf.fat, cf.sector, chainLimit(root.size, len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mini stream: %w", err)
+		}
+		if root.size < uint64(len(miniStream)) {
+			miniStream = miniStream[:root.size]
+		}
+		cf.miniStream = miniStream
+	}
+
+	return cf, nil
+}
+
+// readFAT collects the FAT sectors listed in the header and any DIFAT sectors and reads the FAT
+func (cf *compoundFile) readFAT() error {
+	numFATSectors := int(binary.LittleEndian.Uint32(cf.data[0x2C:]))
+	totalSectors := (len(cf.data) - cf.sectorSize) / cf.sectorSize
+	if numFATSectors <= 0 || numFATSectors > totalSectors {
+		return fmt.Errorf("invalid FAT sector count")
+	}
+
+	fatSectors := make([]uint32, 0, numFATSectors)
+	for i := 0; i < 109 && len(fatSectors) < numFATSectors; i++ {
+		fatSectors = append(fatSectors, binary.LittleEndian.Uint32(cf.data[0x4C+i*4:]))
+	}
+
+	// Files with more than 109 FAT sectors continue the list in a chain of DIFAT sectors,
+	// each ending with the number of the next one
+	difatSector := binary.LittleEndian.Uint32(cf.data[0x44:])
+	entriesPerSector := cf.sectorSize/4 - 1
+	for visited := 0; len(fatSectors) < numFATSectors && difatSector <= cfbMaxRegularSector; visited++ {
+		if visited > totalSectors {
+			return fmt.Errorf("DIFAT chain loops")
+		}
+		sector, err := cf.sector(difatSector)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < entriesPerSector && len(fatSectors) < numFATSectors; i++ {
+			fatSectors = append(fatSectors, binary.LittleEndian.Uint32(sector[i*4:]))
+		}
+		difatSector = binary.LittleEndian.Uint32(sector[entriesPerSector*4:])
+	}
+	if len(fatSectors) < numFATSectors {
+		return fmt.Errorf("DIFAT lists %d of %d FAT sectors", len(fatSectors), numFATSectors)
+	}
+
+	// A FAT sector listed twice would let chains revisit sectors without tripping loop checks
+	seen := make(map[uint32]bool, numFATSectors)
+	cf.fat = make([]uint32, 0, numFATSectors*cf.sectorSize/4)
+	for _, fatSector := range fatSectors {
+		if seen[fatSector] {
+			return fmt.Errorf("FAT sector %d is listed more than once", fatSector)
+		}
+		seen[fatSector] = true
+		sector, err := cf.sector(fatSector)
+		if err != nil {
+			return fmt.Errorf("failed to read FAT: %w", err)
+		}
+		cf.fat = append(cf.fat, bytesToUint32s(sector)...)
+	}
+
+	return nil
+}
+
+// stream returns the contents of the named stream in the root storage
+func (cf *compoundFile) stream(name string) ([]byte, error) {
+	entry, ok := cf.rootChild(name)
+	if !ok || entry.objectType != cfbTypeStream {
+		return nil, fmt.Errorf("%w: %s", errCFBStreamNotFound, name)
+	}
+
+	var data []byte
+	var err error
+	if entry.size < cf.miniCutoff {
+		data, err = cf.readChain(entry.startSector, cf.miniFAT, cf.miniSector, chainLimit(entry.size, len(cf.miniStream)))
+	} else {
+		data, err = cf.readChain(entry.startSector, cf.fat, cf.sector, chainLimit(entry.size, len(cf.data)))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stream %s: %w", name, err)
+	}
+
+	if entry.size > uint64(len(data)) {
+		return nil, fmt.Errorf("stream %s is truncated", name)
+	}
+	return data[:entry.size], nil
+}
+
+// rootChild finds an entry directly under the root storage. Children of a storage form
+// a tree linked through the left and right sibling IDs, rooted at the storage's child ID.
+func (cf *compoundFile) rootChild(name string) (cfbEntry, bool) {
+	pending := []uint32{cf.entries[0].child}
+	for visited := 0; len(pending) > 0 && visited <= len(cf.entries); visited++ {
+		id := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if id == cfbNoStream || int(id) >= len(cf.entries) {
+			continue
+		}
+
+		entry := cf.entries[id]
+		if entry.name == name {
+			return entry, true
+		}
+		pending = append(pending, entry.left, entry.right)
+	}
+
+	return cfbEntry{}, false
+}
+
+// readChain concatenates the sectors of the chain starting at start, following table.
+// Reading stops once limit bytes are collected, and a chain that revisits a sector is
+// rejected, so a crafted table can't make the result outgrow the file.
+func (cf *compoundFile) readChain(start uint32, table []uint32, read func(uint32) ([]byte, error), limit int) ([]byte, error) {
+	var data []byte
+	visited := make(map[uint32]bool)
+	for sector := start; sector != cfbEndOfChain && len(data) < limit; {
+		if sector > cfbMaxRegularSector || int(sector) >= len(table) {
+			return nil, fmt.Errorf("invalid sector %d in chain", sector)
+		}
+		if visited[sector] {
+			return nil, fmt.Errorf("sector chain loops")
+		}
+		visited[sector] = true
+
+		content, err := read(sector)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, content...)
+		sector = table[sector]
+	}
+
+	return data, nil
+}
+
+// chainLimit caps a declared stream size by the size of the data holding its sectors
+func chainLimit(declared uint64, available int) int {
+	if declared < uint64(available) {
+		return int(declared)
+	}
+	return available
+}
+
+// sector returns the contents of a regular sector; sector 0 follows the header
+func (cf *compoundFile) sector(id uint32) ([]byte, error) {
+	offset := (int(id) + 1) * cf.sectorSize
+	if id > cfbMaxRegularSector || offset+cf.sectorSize > len(cf.data) {
+		return nil, fmt.Errorf("sector %d is out of range", id)
+	}
+	return cf.data[offset : offset+cf.sectorSize], nil
+}
+
+// miniSector returns the contents of a sector of the mini stream
+func (cf *compoundFile) miniSector(id uint32) ([]byte, error) {
+	offset := int(id) * cf.miniSectorSize
+	if offset+cf.miniSectorSize > len(cf.miniStream) {
+		return nil, fmt.Errorf("mini sector %d is out of range", id)
+	}
+	return cf.miniStream[offset : offset+cf.miniSectorSize], nil
+}
+
+// parseCFBEntry decodes a 128-byte directory entry
+func parseCFBEntry(raw []byte) cfbEntry {
+	// The name is UTF-16LE; its length in bytes includes the terminating null
+	nameLength := int(binary.LittleEndian.Uint16(raw[64:])) / 2
+	if nameLength > 32 {
+		nameLength = 32
+	}
+	if nameLength > 0 {
+		nameLength--
+	}
+	name := make([]uint16, nameLength)
+	for i := range name {
+		name[i] = binary.LittleEndian.Uint16(raw[i*2:])
+	}
+
+	return cfbEntry{
+		name:        string(utf16.Decode(name)),
+		objectType:  raw[66],
+		left:        binary.LittleEndian.Uint32(raw[68:]),
+		right:       binary.LittleEndian.Uint32(raw[72:]),
+		child:       binary.LittleEndian.Uint32(raw[76:]),
+		startSector: binary.LittleEndian.Uint32(raw[116:]),
+		size:        binary.LittleEndian.Uint64(raw[120:]),
+	}
+}
+
+// bytesToUint32s decodes data as a little-endian uint32 array
+func bytesToUint32s(data []byte) []uint32 {
+	values := make([]uint32, len(data)/4)
+	for i := range values {
+		values[i] = binary.LittleEndian.Uint32(data[i*4:])
+	}
+	return values
+}
@@ -0,0 +1,72 @@
+package extraction
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// buildCompoundFile returns a version 3 compound file with 512-byte sectors whose header
+// lists fatSectors as the FAT, directory chain starting at dirStart, and the given sectors
+func buildCompoundFile(fatSectors []uint32, dirStart uint32, sectors [][]byte) []byte {
+	data := make([]byte, 512*(len(sectors)+1))
+	copy(data, oleSignature)
+	binary.LittleEndian.PutUint16(data[0x1A:], 3)
+	binary.LittleEndian.PutUint16(data[0x1C:], 0xFFFE)
+	binary.LittleEndian.PutUint16(data[0x1E:], 9)
+	binary.LittleEndian.PutUint16(data[0x20:], 6)
+	binary.LittleEndian.PutUint32(data[0x2C:], uint32(len(fatSectors)))
+	binary.LittleEndian.PutUint32(data[0x30:], dirStart)
+	binary.LittleEndian.PutUint32(data[0x38:], 4096)
+	binary.LittleEndian.PutUint32(data[0x3C:], cfbEndOfChain)
+	binary.LittleEndian.PutUint32(data[0x44:], cfbEndOfChain)
+	for i := 0; i < 109; i++ {
+		id := uint32(cfbNoStream)
+		if i < len(fatSectors) {
+			id = fatSectors[i]
+		}
+		binary.LittleEndian.PutUint32(data[0x4C+i*4:], id)
+	}
+	for i, sector := range sectors {
+		copy(data[512*(i+1):], sector)
+	}
+	return data
+}
+
+// fatSector encodes a FAT sector with the given leading entries, the rest free
+func fatSector(entries ...uint32) []byte {
+	sector := make([]byte, 512)
+	for i := 0; i < 128; i++ {
+		entry := uint32(cfbNoStream)
+		if i < len(entries) {
+			entry = entries[i]
+		}
+		binary.LittleEndian.PutUint32(sector[i*4:], entry)
+	}
+	return sector
+}
+
+func TestOpenCompoundFileSelfLoopingChain(t *testing.T) {
+	// Sector 0 is the FAT and sector 1 the directory, whose chain points back at itself
+	data := buildCompoundFile([]uint32{0}, 1, [][]byte{
+		fatSector(0xFFFFFFFD, 1),
+		make([]byte, 512),
+	})
+
+	_, err := openCompoundFile(data)
+	if err == nil || !strings.Contains(err.Error(), "loops") {
+		t.Fatalf("got error %v, want sector chain loop", err)
+	}
+}
+
+func TestOpenCompoundFileDuplicateFATSector(t *testing.T) {
+	data := buildCompoundFile([]uint32{0, 0}, 1, [][]byte{
+		fatSector(0xFFFFFFFD, cfbEndOfChain),
+		make([]byte, 512),
+	})
+
+	_, err := openCompoundFile(data)
+	if err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Fatalf("got error %v, want duplicate FAT sector", err)
+	}
+}
@@ -9,41 +9,53 @@ import (
 // ExtractionQueue manages concurrent extraction operations
 // Requirements: 5.5 - Ensure thread-safe extraction operations
 type ExtractionQueue struct {
-	semaphore chan struct{}
-	active    int
-	mu        sync.Mutex
-	metrics   *internalMetrics
+	semaphore     chan struct{}
+	maxQueueDepth int // Maximum number of waiting extractions (0 = unlimited)
+	active        int
+	mu            sync.Mutex
+	metrics       *internalMetrics
 }
 
 // ExtractionMetrics tracks extraction statistics (exported for reading)
 type ExtractionMetrics struct {
-	TotalExtractions  int64
-	ActiveExtractions int
-	FailedExtractions int64
-	QueuedExtractions int
+	TotalExtractions    int64
+	ActiveExtractions   int
+	FailedExtractions   int64
+	QueuedExtractions   int
+	RejectedExtractions int64
+	MaxQueueDepth       int
 }
 
 // internalMetrics holds the internal metrics with mutex
 type internalMetrics struct {
-	mu                sync.RWMutex
-	totalExtractions  int64
-	activeExtractions int
-	failedExtractions int64
-	queuedExtractions int
+	mu                  sync.RWMutex
+	totalExtractions    int64
+	activeExtractions   int
+	failedExtractions   int64
+	queuedExtractions   int
+	rejectedExtractions int64
 }
 
 // NewExtractionQueue creates a new extraction queue with max concurrent operations
-func NewExtractionQueue(maxConcurrent int) *ExtractionQueue {
+// and a maximum number of waiting operations (0 = unlimited)
+func NewExtractionQueue(maxConcurrent, maxQueueDepth int) *ExtractionQueue {
 	return &ExtractionQueue{
-		semaphore: make(chan struct{}, maxConcurrent),
-		metrics:   &internalMetrics{},
+		semaphore:     make(chan struct{}, maxConcurrent),
+		maxQueueDepth: maxQueueDepth,
+		metrics:       &internalMetrics{},
 	}
 }
 
 // Acquire acquires a slot in the extraction queue
+// Returns ErrExtractionBusy without waiting if all slots are taken and the queue is full
 func (eq *ExtractionQueue) Acquire(ctx context.Context) error {
-	// Increment queued count
+	// Increment queued count, rejecting immediately when the queue is saturated
 	eq.metrics.mu.Lock()
+	if eq.maxQueueDepth > 0 && eq.metrics.queuedExtractions >= eq.maxQueueDepth && len(eq.semaphore) == cap(eq.semaphore) {
+		eq.metrics.rejectedExtractions++
+		eq.metrics.mu.Unlock()
+		return ErrExtractionBusy
+	}
 	eq.metrics.queuedExtractions++
 	eq.metrics.mu.Unlock()
 
@@ -97,10 +109,12 @@ func (eq *ExtractionQueue) GetMetrics() ExtractionMetrics {
 	defer eq.metrics.mu.RUnlock()
 
 	return ExtractionMetrics{
-		TotalExtractions:  eq.metrics.totalExtractions,
-		ActiveExtractions: eq.metrics.activeExtractions,
-		FailedExtractions: eq.metrics.failedExtractions,
-		QueuedExtractions: eq.metrics.queuedExtractions,
+		TotalExtractions:    eq.metrics.totalExtractions,
+		ActiveExtractions:   eq.metrics.activeExtractions,
+		FailedExtractions:   eq.metrics.failedExtractions,
+		QueuedExtractions:   eq.metrics.queuedExtractions,
+		RejectedExtractions: eq.metrics.rejectedExtractions,
+		MaxQueueDepth:       eq.maxQueueDepth,
 	}
 }
 
@@ -10,11 +10,14 @@ import (
 )
 
 // CSVExtractor handles CSV files
-type CSVExtractor struct{}
+type CSVExtractor struct {
+	rowLimit spreadsheetRowLimit
+}
 
-// NewCSVExtractor creates a new CSV extractor
-func NewCSVExtractor() *CSVExtractor {
-	return &CSVExtractor{}
+// NewCSVExtractor creates a new CSV extractor. Files with more than maxRows data rows
+// (0 = unlimited) are truncated with a notice, or rejected when failOnRowLimit is set.
+func NewCSVExtractor(maxRows int, failOnRowLimit bool) *CSVExtractor {
+	return &CSVExtractor{rowLimit: spreadsheetRowLimit{maxRows: maxRows, failOnLimit: failOnRowLimit}}
 }
 
 // Extract extracts text from CSV files
@@ -26,6 +29,13 @@ func (e *CSVExtractor) Extract(ctx context.Context, data []byte) (string, error)
 	default:
 	}
 
+	// Convert to UTF-8 so UTF-16 exports (e.g. from Excel) parse correctly
+	text, err := ensureUTF8(data)
+	if err != nil {
+		return "", err
+	}
+	data = []byte(text)
+
 	// Try to detect delimiter
 	delimiter := detectCSVDelimiter(data)
 
@@ -35,24 +45,12 @@ func (e *CSVExtractor) Extract(ctx context.Context, data []byte) (string, error)
 	reader.TrimLeadingSpace = true
 	reader.LazyQuotes = true // Be lenient with quotes
 
-	// Read all records
-	records, err := reader.ReadAll()
-	if err != nil {
-		return "", fmt.Errorf("failed to parse CSV: %w", err)
-	}
-
-	if len(records) == 0 {
-		return "", nil
-	}
-
-	// Extract text from CSV
+	// Extract text from CSV, reading records one at a time so the row cap bounds the work
 	var result strings.Builder
+	var header []string
+	dataRows := 0
 
-	// Check if first row is a header (heuristic: all strings, no numbers)
-	hasHeader := isLikelyHeader(records[0])
-
-	// Process records
-	for i, record := range records {
+	for i := 0; ; i++ {
 		// Check for context cancellation periodically
 		select {
 		case <-ctx.Done():
@@ -60,7 +58,18 @@ func (e *CSVExtractor) Extract(ctx context.Context, data []byte) (string, error)
 		default:
 		}
 
-		if i == 0 && hasHeader {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to parse CSV: %w", err)
+		}
+
+		// Check if first row is a header (heuristic: all strings, no numbers)
+		if i == 0 && isLikelyHeader(record) {
+			header = record
+
 			// Format header row
 			result.WriteString("Headers: ")
 			result.WriteString(strings.Join(record, ", "))
@@ -68,17 +77,28 @@ func (e *CSVExtractor) Extract(ctx context.Context, data []byte) (string, error)
 			continue
 		}
 
+		// Stop at the row cap, or reject the file if so configured
+		if e.rowLimit.reached(dataRows) {
+			if err := e.rowLimit.exceededError(); err != nil {
+				return "", err
+			}
+			result.WriteString("\n")
+			result.WriteString(e.rowLimit.truncationNotice())
+			break
+		}
+		dataRows++
+
 		// Format data rows
-		if hasHeader && i > 0 {
+		if header != nil {
 			// Format as key-value pairs using header
 			result.WriteString(fmt.Sprintf("Row %d:\n", i))
 			for j, cell := range record {
-				if j < len(records[0]) {
-					header := records[0][j]
-					if header == "" {
-						header = fmt.Sprintf("Column%d", j+1)
+				if j < len(header) {
+					name := header[j]
+					if name == "" {
+						name = fmt.Sprintf("Column%d", j+1)
 					}
-					result.WriteString(fmt.Sprintf("  %s: %s\n", header, cell))
+					result.WriteString(fmt.Sprintf("  %s: %s\n", name, cell))
 				}
 			}
 			result.WriteString("\n")
@@ -0,0 +1,394 @@
+package extraction
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Word binary File Information Block (FIB) fields used for text extraction
+const (
+	docFIBIdent          = 0xA5EC // wIdent of Word 97 and later documents
+	docFIBMinNFib        = 0x00C1 // nFib of Word 97; older versions use a different layout
+	docFIBFlagEncrypted  = 0x0100 // fEncrypted
+	docFIBFlagTableOne   = 0x0200 // fWhichTblStm: piece table is in 1Table rather than 0Table
+	docFIBOffsetFlags    = 0x000A
+	docFIBOffsetCcpText  = 0x004C // Length of the main document text in characters
+	docFIBOffsetFcClx    = 0x01A2 // Offset of the piece table in the table stream
+	docFIBOffsetLcbClx   = 0x01A6 // Size of the piece table
+	docFIBMinSize        = 0x01AA
+	docPieceCompressed   = 0x40000000 // FcCompressed: piece text is 8-bit rather than UTF-16
+	docPieceDescSize     = 8
+	docClxPropertyMarker = 0x01 // Prc: property modifiers preceding the piece table
+	docClxPieceTable     = 0x02 // Pcdt: the piece table
+)
+
+// DocExtractor handles legacy Word binary (.doc) document extraction
+type DocExtractor struct{}
+
+// NewDocExtractor creates a new .doc extractor
+func NewDocExtractor() *DocExtractor {
+	return &DocExtractor{}
+}
+
+// Extract extracts the main document text from .doc files (Word 97 and later).
+// The text is assembled from the piece table, which maps character positions to runs
+// of 8-bit or UTF-16 text in the WordDocument stream. Headers, footnotes and comments
+// are stored after the main text and are not included.
+func (e *DocExtractor) Extract(ctx context.Context, data []byte) (string, error) {
+	// Check for context cancellation
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	default:
+	}
+
+	// Validate file size
+	if len(data) < 512 {
+		return "", fmt.Errorf("%w: file too small to be a valid .doc document", ErrCorruptedFile)
+	}
+
+	// Check for OLE magic number (doc is an OLE compound file)
+	if !isOLECompoundFile(data) {
+		return "", fmt.Errorf("%w: invalid .doc header - file may be corrupted or not a .doc", ErrCorruptedFile)
+	}
+
+	cf, err := openCompoundFile(data)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrCorruptedFile, err)
+	}
+
+	wordDocument, err := cf.stream("WordDocument")
+	if err != nil {
+		if errors.Is(err, errCFBStreamNotFound) {
+			// Encrypted .docx files are OLE containers too, and are sometimes saved as .doc
+			if _, infoErr := cf.stream("EncryptionInfo"); infoErr == nil {
+				return "", fmt.Errorf("%w: document is encrypted", ErrPasswordProtected)
+			}
+			return "", fmt.Errorf("%w: no WordDocument stream - file may not be a Word document", ErrCorruptedFile)
+		}
+		return "", fmt.Errorf("%w: %v", ErrCorruptedFile, err)
+	}
+
+	// Parse the File Information Block at the start of the WordDocument stream
+	if len(wordDocument) < docFIBMinSize {
+		return "", fmt.Errorf("%w: WordDocument stream is too small", ErrCorruptedFile)
+	}
+	if binary.LittleEndian.Uint16(wordDocument[0:]) != docFIBIdent {
+		return "", fmt.Errorf("%w: invalid Word document identifier", ErrCorruptedFile)
+	}
+	if binary.LittleEndian.Uint16(wordDocument[2:]) < docFIBMinNFib {
+		return "", fmt.Errorf("%w: Word 95 and older documents are not supported", ErrUnsupportedFormat)
+	}
+
+	flags := binary.LittleEndian.Uint16(wordDocument[docFIBOffsetFlags:])
+	if flags&docFIBFlagEncrypted != 0 {
+		return "", fmt.Errorf("%w: document is encrypted", ErrPasswordProtected)
+	}
+
+	tableName := "0Table"
+	if flags&docFIBFlagTableOne != 0 {
+		tableName = "1Table"
+	}
+	table, err := cf.stream(tableName)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrCorruptedFile, err)
+	}
+
+	ccpText := binary.LittleEndian.Uint32(wordDocument[docFIBOffsetCcpText:])
+	fcClx := binary.LittleEndian.Uint32(wordDocument[docFIBOffsetFcClx:])
+	lcbClx := binary.LittleEndian.Uint32(wordDocument[docFIBOffsetLcbClx:])
+	if uint64(fcClx)+uint64(lcbClx) > uint64(len(table)) {
+		return "", fmt.Errorf("%w: piece table is out of range", ErrCorruptedFile)
+	}
+
+	text, err := e.readPieces(ctx, wordDocument, table[fcClx:fcClx+lcbClx], ccpText)
+	if err != nil {
+		return "", err
+	}
+
+	// Normalize whitespace while preserving paragraph breaks
+	return normalizeWhitespace(cleanDocText(text)), nil
+}
+
+// ExtractWithMetadata extracts text and the summary information (title, author, ...) from .doc files
+func (e *DocExtractor) ExtractWithMetadata(ctx context.Context, data []byte) (ExtractionResult, error) {
+	text, err := e.Extract(ctx, data)
+	if err != nil {
+		return ExtractionResult{Text: text}, err
+	}
+
+	metadata := make(map[string]any)
+	if cf, err := openCompoundFile(data); err == nil {
+		if summary, err := cf.stream("\x05SummaryInformation"); err == nil {
+			metadata = readSummaryInformation(summary)
+		}
+	}
+
+	return ExtractionResult{Text: text, Metadata: metadata}, nil
+}
+
+// readPieces decodes the first ccpText characters described by the piece table in clx
+func (e *DocExtractor) readPieces(ctx context.Context, wordDocument, clx []byte, ccpText uint32) (string, error) {
+	// Skip property modifiers (Prc) to reach the piece table (Pcdt)
+	pos := 0
+	for pos < len(clx) && clx[pos] == docClxPropertyMarker {
+		if pos+3 > len(clx) {
+			return "", fmt.Errorf("%w: truncated piece table", ErrCorruptedFile)
+		}
+		pos += 3 + int(binary.LittleEndian.Uint16(clx[pos+1:]))
+	}
+	if pos+5 > len(clx) || clx[pos] != docClxPieceTable {
+		return "", fmt.Errorf("%w: piece table not found", ErrCorruptedFile)
+	}
+
+	plcPcd := clx[pos+5:]
+	if lcb := int(binary.LittleEndian.Uint32(clx[pos+1:])); lcb <= len(plcPcd) {
+		plcPcd = plcPcd[:lcb]
+	}
+
+	// PlcPcd is n+1 character positions followed by n 8-byte piece descriptors
+	n := (len(plcPcd) - 4) / (4 + docPieceDescSize)
+	if n <= 0 {
+		return "", nil
+	}
+
+	var result strings.Builder
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		default:
+		}
+
+		cpStart := binary.LittleEndian.Uint32(plcPcd[i*4:])
+		cpEnd := binary.LittleEndian.Uint32(plcPcd[(i+1)*4:])
+		if cpStart >= ccpText {
+			break
+		}
+		if cpEnd > ccpText {
+			cpEnd = ccpText
+		}
+		if cpEnd <= cpStart {
+			continue
+		}
+		length := int(cpEnd - cpStart)
+
+		descriptor := plcPcd[(n+1)*4+i*docPieceDescSize:]
+		fc := binary.LittleEndian.Uint32(descriptor[2:])
+
+		if fc&docPieceCompressed != 0 {
+			// 8-bit text, stored at half the offset
+			offset := int(fc&^docPieceCompressed) / 2
+			if offset+length > len(wordDocument) {
+				return "", fmt.Errorf("%w: text piece is out of range", ErrCorruptedFile)
+			}
+			decoded, err := charmap.Windows1252.NewDecoder().Bytes(wordDocument[offset : offset+length])
+			if err != nil {
+				return "", fmt.Errorf("%w: %v", ErrExtractionFailed, err)
+			}
+			result.Write(decoded)
+		} else {
+			offset := int(fc)
+			if offset+length*2 > len(wordDocument) {
+				return "", fmt.Errorf("%w: text piece is out of range", ErrCorruptedFile)
+			}
+			units := make([]uint16, length)
+			for j := range units {
+				units[j] = binary.LittleEndian.Uint16(wordDocument[offset+j*2:])
+			}
+			result.WriteString(string(utf16.Decode(units)))
+		}
+	}
+
+	return result.String(), nil
+}
+
+// cleanDocText converts Word's control characters to plain text. Fields are stored as
+// begin (0x13), instructions, separator (0x14), result, end (0x15); only the result is kept.
+func cleanDocText(text string) string {
+	var result strings.Builder
+	result.Grow(len(text))
+
+	// Each open field records whether its instructions are still being read
+	var fields []bool
+
+	for _, r := range text {
+		switch r {
+		case 0x13:
+			fields = append(fields, true)
+			continue
+		case 0x14:
+			if len(fields) > 0 {
+				fields[len(fields)-1] = false
+			}
+			continue
+		case 0x15:
+			if len(fields) > 0 {
+				fields = fields[:len(fields)-1]
+			}
+			continue
+		}
+
+		// Skip field instructions, including those of nested fields
+		inInstructions := false
+		for _, instructions := range fields {
+			if instructions {
+				inInstructions = true
+				break
+			}
+		}
+		if inInstructions {
+			continue
+		}
+
+		switch r {
+		case '\r', 0x0B, 0x0C, 0x0E:
+			// Paragraph mark, line break, page break, column break
+			result.WriteByte('\n')
+		case 0x07:
+			// Table cell or row end mark
+			result.WriteByte('\t')
+		case 0x1E:
+			// Non-breaking hyphen
+			result.WriteByte('-')
+		case 0xA0:
+			result.WriteByte(' ')
+		case 0x01, 0x02, 0x03, 0x04, 0x05, 0x08, 0x1F:
+			// Embedded objects, footnote and comment anchors, drawings, optional hyphens
+		default:
+			if r >= 0x20 || r == '\t' || r == '\n' {
+				result.WriteRune(r)
+			}
+		}
+	}
+
+	return result.String()
+}
+
+// Summary information property IDs (PIDSI_*) surfaced as metadata
+var summaryInformationFields = map[uint32]string{
+	2:  "title",
+	3:  "subject",
+	4:  "author",
+	5:  "keywords",
+	6:  "description",
+	8:  "lastModifiedBy",
+	12: "creationDate",
+	13: "modDate",
+	14: "pageCount",
+	15: "wordCount",
+}
+
+// Property value types used by the summary information stream
+const (
+	vtI2       = 0x02
+	vtI4       = 0x03
+	vtLPStr    = 0x1E
+	vtFiletime = 0x40
+)
+
+// readSummaryInformation decodes the \x05SummaryInformation property set stream.
+// Unreadable properties are skipped, so the result may be empty.
+func readSummaryInformation(data []byte) map[string]any {
+	metadata := make(map[string]any)
+
+	// Header: byte order, version, system ID, CLSID, property set count, then the first set's FMTID and offset
+	if len(data) < 48 || binary.LittleEndian.Uint16(data[0:]) != 0xFFFE {
+		return metadata
+	}
+	sectionOffset := int(binary.LittleEndian.Uint32(data[44:]))
+	if sectionOffset+8 > len(data) {
+		return metadata
+	}
+	section := data[sectionOffset:]
+	if size := int(binary.LittleEndian.Uint32(section[0:])); size <= len(section) {
+		section = section[:size]
+	}
+	// The declared size must at least cover the size and property count
+	if len(section) < 8 {
+		return metadata
+	}
+
+	count := int(binary.LittleEndian.Uint32(section[4:]))
+	if 8+count*8 > len(section) {
+		return metadata
+	}
+
+	// Strings are in the code page given by property 1, Windows-1252 unless stated otherwise
+	type property struct {
+		id     uint32
+		offset int
+	}
+	properties := make([]property, count)
+	codePage := 1252
+	for i := range properties {
+		properties[i] = property{
+			id:     binary.LittleEndian.Uint32(section[8+i*8:]),
+			offset: int(binary.LittleEndian.Uint32(section[12+i*8:])),
+		}
+		if p := properties[i]; p.id == 1 && p.offset+6 <= len(section) &&
+			binary.LittleEndian.Uint32(section[p.offset:])&0xFFFF == vtI2 {
+			codePage = int(binary.LittleEndian.Uint16(section[p.offset+4:]))
+		}
+	}
+
+	for _, p := range properties {
+		key, ok := summaryInformationFields[p.id]
+		if !ok || p.offset+8 > len(section) {
+			continue
+		}
+
+		value := section[p.offset+4:]
+		switch binary.LittleEndian.Uint32(section[p.offset:]) & 0xFFFF {
+		case vtLPStr:
+			if len(value) < 4 {
+				continue
+			}
+			size := int(binary.LittleEndian.Uint32(value))
+			if 4+size > len(value) {
+				continue
+			}
+			raw := []byte(strings.TrimRight(string(value[4:4+size]), "\x00"))
+			if codePage != 65001 {
+				decoded, err := charmap.Windows1252.NewDecoder().Bytes(raw)
+				if err != nil {
+					continue
+				}
+				raw = decoded
+			}
+			if s := strings.TrimSpace(string(raw)); s != "" {
+				metadata[key] = s
+			}
+		case vtI4:
+			if len(value) < 4 {
+				continue
+			}
+			if n := int32(binary.LittleEndian.Uint32(value)); n > 0 {
+				metadata[key] = int(n)
+			}
+		case vtFiletime:
+			// 100-nanosecond intervals since 1601-01-01 UTC
+			if len(value) < 8 {
+				continue
+			}
+			ticks := binary.LittleEndian.Uint64(value)
+			if ticks == 0 {
+				continue
+			}
+			const unixEpochTicks = 116444736000000000
+			if ticks > unixEpochTicks {
+				seconds := int64((ticks - unixEpochTicks) / 10000000)
+				metadata[key] = time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+			}
+		}
+	}
+
+	return metadata
+}
@@ -0,0 +1,39 @@
+package extraction
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestReadSummaryInformationMalformedSectionSize(t *testing.T) {
+	// Property set header pointing at a section at offset 48
+	data := make([]byte, 64)
+	binary.LittleEndian.PutUint16(data[0:], 0xFFFE)
+	binary.LittleEndian.PutUint32(data[44:], 48)
+
+	for _, size := range []uint32{0, 4, 7} {
+		binary.LittleEndian.PutUint32(data[48:], size)
+		binary.LittleEndian.PutUint32(data[52:], 1)
+
+		if metadata := readSummaryInformation(data); len(metadata) != 0 {
+			t.Errorf("section size %d: got metadata %v, want none", size, metadata)
+		}
+	}
+}
+
+func TestReadSummaryInformationTruncatedFiletime(t *testing.T) {
+	// One creation date property whose FILETIME is cut short by the end of the section
+	data := make([]byte, 72)
+	binary.LittleEndian.PutUint16(data[0:], 0xFFFE)
+	binary.LittleEndian.PutUint32(data[44:], 48)
+	binary.LittleEndian.PutUint32(data[48:], 24)
+	binary.LittleEndian.PutUint32(data[52:], 1)
+	binary.LittleEndian.PutUint32(data[56:], 12)
+	binary.LittleEndian.PutUint32(data[60:], 16)
+	binary.LittleEndian.PutUint32(data[64:], vtFiletime)
+	binary.LittleEndian.PutUint32(data[68:], 0xFFFFFFFF)
+
+	if metadata := readSummaryInformation(data); len(metadata) != 0 {
+		t.Errorf("got metadata %v, want none", metadata)
+	}
+}
@@ -1,8 +1,10 @@
 package extraction
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
+	"encoding/xml"
 	"fmt"
 	"strings"
 
@@ -10,11 +12,16 @@ import (
 )
 
 // DocxExtractor handles .docx document extraction
-type DocxExtractor struct{}
+type DocxExtractor struct {
+	// Append the document's footnotes and endnotes after the body text
+	extractFootnotes bool
+}
 
-// NewDocxExtractor creates a new .docx extractor
-func NewDocxExtractor() *DocxExtractor {
-	return &DocxExtractor{}
+// NewDocxExtractor creates a new .docx extractor. With extractFootnotes, the footnotes and
+// endnotes Word keeps apart from the body are listed in "Footnotes:" and "Endnotes:"
+// sections after the text.
+func NewDocxExtractor(extractFootnotes bool) *DocxExtractor {
+	return &DocxExtractor{extractFootnotes: extractFootnotes}
 }
 
 // Extract extracts text from .docx files
@@ -31,11 +38,23 @@ func (e *DocxExtractor) Extract(ctx context.Context, data []byte) (string, error
 		return "", fmt.Errorf("%w: file too small to be a valid .docx document", ErrCorruptedFile)
 	}
 
+	// Encrypted Office documents are OLE compound files rather than ZIP archives
+	if isOLECompoundFile(data) {
+		return "", fmt.Errorf("%w: .docx file is encrypted", ErrPasswordProtected)
+	}
+
 	// Check for ZIP magic number (docx is a ZIP archive)
 	if !bytes.HasPrefix(data, []byte("PK")) {
 		return "", fmt.Errorf("%w: invalid .docx header - file may be corrupted or not a .docx", ErrCorruptedFile)
 	}
 
+	// Reject archives that exceed the decompression limits before parsing
+	if zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err == nil {
+		if err := checkArchiveLimits(ctx, zipReader); err != nil {
+			return "", err
+		}
+	}
+
 	// Create a reader from the byte slice
 	reader := bytes.NewReader(data)
 
@@ -79,5 +98,139 @@ func (e *DocxExtractor) Extract(ctx context.Context, data []byte) (string, error
 	// Normalize whitespace while preserving paragraph breaks
 	text = normalizeWhitespace(text)
 
+	if e.extractFootnotes {
+		text = appendDocxNotes(ctx, data, text)
+	}
+
 	return text, nil
 }
+
+// appendDocxNotes lists the footnotes and endnotes of a .docx document after its text.
+// Notes are best-effort: parts that are missing or can't be read are skipped.
+func appendDocxNotes(ctx context.Context, data []byte, text string) string {
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return text
+	}
+
+	footnotes := readDocxNotes(ctx, zipReader, "word/footnotes.xml", "footnote")
+	endnotes := readDocxNotes(ctx, zipReader, "word/endnotes.xml", "endnote")
+
+	text = appendNotes(text, "Footnotes", numberNotes(footnotes))
+	return appendNotes(text, "Endnotes", numberNotes(endnotes))
+}
+
+// readDocxNotes returns the text of each note (w:footnote or w:endnote element) in a notes
+// part, in order. The separator notes Word adds to every notes part are skipped.
+func readDocxNotes(ctx context.Context, zipReader *zip.Reader, name, element string) []string {
+	content := readZipEntry(ctx, zipReader, name)
+	if content == nil {
+		return nil
+	}
+
+	var notes []string
+	var note strings.Builder
+	inNote, inText := false, false
+
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		// A malformed part keeps the notes read before the error
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case element:
+				inNote = isRegularDocxNote(t)
+				note.Reset()
+			case "t":
+				inText = inNote
+			case "tab", "br", "cr":
+				if inNote {
+					note.WriteString(" ")
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case element:
+				if text := strings.Join(strings.Fields(note.String()), " "); inNote && text != "" {
+					notes = append(notes, text)
+				}
+				inNote = false
+			case "t":
+				inText = false
+			case "p":
+				if inNote {
+					note.WriteString(" ")
+				}
+			}
+		case xml.CharData:
+			if inText {
+				note.Write(t)
+			}
+		}
+	}
+
+	return notes
+}
+
+// isRegularDocxNote reports whether a w:footnote or w:endnote element holds note text
+// rather than being a separator, whose w:type is set
+func isRegularDocxNote(element xml.StartElement) bool {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == "type" {
+			return attr.Value == "normal"
+		}
+	}
+	return true
+}
+
+// ExtractWithMetadata extracts text and the document properties from .docx files
+func (e *DocxExtractor) ExtractWithMetadata(ctx context.Context, data []byte) (ExtractionResult, error) {
+	text, err := e.Extract(ctx, data)
+	if err != nil {
+		return ExtractionResult{Text: text}, err
+	}
+
+	return ExtractionResult{Text: text, Metadata: readOOXMLMetadata(ctx, data)}, nil
+}
+
+// docxRelationships is the relationships part of a .docx document body
+type docxRelationships struct {
+	Relationships []struct {
+		Type       string `xml:"Type,attr"`
+		Target     string `xml:"Target,attr"`
+		TargetMode string `xml:"TargetMode,attr"`
+	} `xml:"Relationship"`
+}
+
+// ExtractLinks extracts the external hyperlink targets of a .docx document, which Word
+// keeps in the relationships of the document body rather than in the text
+func (e *DocxExtractor) ExtractLinks(ctx context.Context, data []byte) ([]string, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to open .docx archive - %v", ErrCorruptedFile, err)
+	}
+
+	relsData := readZipEntry(ctx, zipReader, "word/_rels/document.xml.rels")
+	if relsData == nil {
+		return nil, nil
+	}
+
+	var rels docxRelationships
+	if err := xml.Unmarshal(relsData, &rels); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse .docx relationships - %v", ErrCorruptedFile, err)
+	}
+
+	var targets []string
+	for _, rel := range rels.Relationships {
+		if strings.HasSuffix(rel.Type, "/hyperlink") && rel.TargetMode == "External" {
+			targets = append(targets, rel.Target)
+		}
+	}
+
+	return normalizeLinks(targets), nil
+}
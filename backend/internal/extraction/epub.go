@@ -5,8 +5,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
-	"io"
 	"strings"
 
 	"golang.org/x/net/html"
@@ -46,9 +46,17 @@ func (e *EPUBExtractor) Extract(ctx context.Context, data []byte) (string, error
 		return "", fmt.Errorf("%w: failed to open EPUB archive - %v", ErrCorruptedFile, err)
 	}
 
+	// Reject archives with too many entries or oversized entries before reading any
+	if err := checkArchiveLimits(ctx, zipReader); err != nil {
+		return "", err
+	}
+
 	// Find and parse the content.opf file to get reading order
-	contentOPF, err := e.findContentOPF(zipReader)
+	contentOPF, err := e.findContentOPF(ctx, zipReader)
 	if err != nil {
+		if errors.Is(err, ErrMemoryLimit) {
+			return "", err
+		}
 		return "", fmt.Errorf("%w: failed to find content.opf - %v", ErrCorruptedFile, err)
 	}
 
@@ -74,9 +82,12 @@ func (e *EPUBExtractor) Extract(ctx context.Context, data []byte) (string, error
 		}
 
 		// Extract text from this chapter
-		chapterText, err := e.extractChapter(zipReader, itemRef)
+		chapterText, err := e.extractChapter(ctx, zipReader, itemRef)
 		if err != nil {
-			// Log error but continue with other chapters
+			// Abort on zip bombs, otherwise skip the chapter and continue with others
+			if errors.Is(err, ErrMemoryLimit) {
+				return "", err
+			}
 			continue
 		}
 
@@ -103,18 +114,15 @@ func (e *EPUBExtractor) Extract(ctx context.Context, data []byte) (string, error
 }
 
 // findContentOPF locates the content.opf file in the EPUB
-func (e *EPUBExtractor) findContentOPF(zipReader *zip.Reader) ([]byte, error) {
+func (e *EPUBExtractor) findContentOPF(ctx context.Context, zipReader *zip.Reader) ([]byte, error) {
 	// First, try to read container.xml to find the OPF location
 	for _, file := range zipReader.File {
 		if strings.HasSuffix(file.Name, "META-INF/container.xml") {
-			rc, err := file.Open()
-			if err != nil {
-				continue
-			}
-			defer rc.Close()
-
-			data, err := io.ReadAll(rc)
+			data, err := readZipFile(ctx, file)
 			if err != nil {
+				if errors.Is(err, ErrMemoryLimit) {
+					return nil, err
+				}
 				continue
 			}
 
@@ -124,13 +132,7 @@ func (e *EPUBExtractor) findContentOPF(zipReader *zip.Reader) ([]byte, error) {
 				// Find and read the OPF file
 				for _, f := range zipReader.File {
 					if strings.HasSuffix(f.Name, opfPath) || f.Name == opfPath {
-						rc, err := f.Open()
-						if err != nil {
-							continue
-						}
-						defer rc.Close()
-
-						return io.ReadAll(rc)
+						return readZipFile(ctx, f)
 					}
 				}
 			}
@@ -140,13 +142,7 @@ func (e *EPUBExtractor) findContentOPF(zipReader *zip.Reader) ([]byte, error) {
 	// Fallback: search for any .opf file
 	for _, file := range zipReader.File {
 		if strings.HasSuffix(file.Name, ".opf") {
-			rc, err := file.Open()
-			if err != nil {
-				continue
-			}
-			defer rc.Close()
-
-			return io.ReadAll(rc)
+			return readZipFile(ctx, file)
 		}
 	}
 
@@ -214,17 +210,11 @@ func (e *EPUBExtractor) parseSpine(opfData []byte) ([]string, error) {
 }
 
 // extractChapter extracts text from a single chapter (XHTML file)
-func (e *EPUBExtractor) extractChapter(zipReader *zip.Reader, chapterPath string) (string, error) {
+func (e *EPUBExtractor) extractChapter(ctx context.Context, zipReader *zip.Reader, chapterPath string) (string, error) {
 	// Find the chapter file
 	for _, file := range zipReader.File {
 		if strings.HasSuffix(file.Name, chapterPath) || file.Name == chapterPath {
-			rc, err := file.Open()
-			if err != nil {
-				return "", err
-			}
-			defer rc.Close()
-
-			data, err := io.ReadAll(rc)
+			data, err := readZipFile(ctx, file)
 			if err != nil {
 				return "", err
 			}
@@ -279,3 +269,74 @@ func (e *EPUBExtractor) extractTextFromHTML(htmlData []byte) (string, error) {
 	extractText(doc)
 	return result.String(), nil
 }
+
+// ExtractWithMetadata extracts text and the Dublin Core metadata from EPUB files
+func (e *EPUBExtractor) ExtractWithMetadata(ctx context.Context, data []byte) (ExtractionResult, error) {
+	text, err := e.Extract(ctx, data)
+	if err != nil {
+		return ExtractionResult{Text: text}, err
+	}
+
+	// Extract already validated the archive, so failures here only mean missing metadata
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return ExtractionResult{Text: text}, nil
+	}
+
+	contentOPF, err := e.findContentOPF(ctx, zipReader)
+	if err != nil {
+		return ExtractionResult{Text: text}, nil
+	}
+
+	return ExtractionResult{Text: text, Metadata: e.parseMetadata(contentOPF)}, nil
+}
+
+// parseMetadata extracts the Dublin Core metadata and chapter count from the OPF file
+func (e *EPUBExtractor) parseMetadata(opfData []byte) map[string]any {
+	type Package struct {
+		Metadata struct {
+			Titles     []string `xml:"title"`
+			Creators   []string `xml:"creator"`
+			Subjects   []string `xml:"subject"`
+			Language   string   `xml:"language"`
+			Publisher  string   `xml:"publisher"`
+			Date       string   `xml:"date"`
+			Identifier string   `xml:"identifier"`
+		} `xml:"metadata"`
+	}
+
+	metadata := make(map[string]any)
+
+	var pkg Package
+	if err := xml.Unmarshal(opfData, &pkg); err != nil {
+		return metadata
+	}
+
+	if len(pkg.Metadata.Titles) > 0 {
+		metadata["title"] = strings.TrimSpace(pkg.Metadata.Titles[0])
+	}
+	if len(pkg.Metadata.Creators) > 0 {
+		metadata["author"] = strings.Join(pkg.Metadata.Creators, ", ")
+	}
+	if len(pkg.Metadata.Subjects) > 0 {
+		metadata["subject"] = strings.Join(pkg.Metadata.Subjects, ", ")
+	}
+
+	fields := map[string]string{
+		"language":     pkg.Metadata.Language,
+		"publisher":    pkg.Metadata.Publisher,
+		"creationDate": pkg.Metadata.Date,
+		"identifier":   pkg.Metadata.Identifier,
+	}
+	for key, value := range fields {
+		if value = strings.TrimSpace(value); value != "" {
+			metadata[key] = value
+		}
+	}
+
+	if spine, err := e.parseSpine(opfData); err == nil {
+		metadata["chapterCount"] = len(spine)
+	}
+
+	return metadata
+}
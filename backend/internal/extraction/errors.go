@@ -16,6 +16,9 @@ var (
 	ErrInvalidFormat     = errors.New("file format validation failed")
 	ErrEmptyFile         = errors.New("file is empty")
 	ErrMemoryLimit       = errors.New("extraction exceeded memory limit")
+	ErrExtractionBusy    = errors.New("extraction queue is full")
+
+	ErrSpreadsheetTooLarge = errors.New("spreadsheet exceeds maximum row count")
 )
 
 // ExtractionError wraps extraction errors with additional context
@@ -104,6 +107,9 @@ const (
 	ErrTypeInvalidFormat     = "invalid_format"
 	ErrTypeEmptyFile         = "empty_file"
 	ErrTypeMemoryLimit       = "memory_limit_exceeded"
+	ErrTypeBusy              = "extraction_busy"
+
+	ErrTypeSpreadsheetTooLarge = "spreadsheet_too_large"
 )
 
 // WrapUnsupportedFormat wraps an unsupported format error with context
@@ -115,7 +121,7 @@ func WrapUnsupportedFormat(contentType string, fileSize int64, supportedFormats
 		fileSize,
 	)
 
-	formatList := "PDF, Word (.docx), Excel (.xlsx), PowerPoint (.pptx), Plain Text, Markdown, HTML, JSON, CSV, EPUB, RTF"
+	formatList := "PDF, Word (.doc, .docx), Excel (.xlsx), PowerPoint (.pptx), Plain Text, Markdown, HTML, JSON, CSV, EPUB, RTF"
 	if len(supportedFormats) > 0 {
 		formatList = fmt.Sprintf("%v", supportedFormats)
 	}
@@ -299,6 +305,56 @@ func WrapMemoryLimit(contentType string, fileSize int64, filename string) *Extra
 	return err
 }
 
+// WrapExtractionBusy wraps a queue-full error with context
+func WrapExtractionBusy(contentType string, fileSize int64, filename string, queueDepth int) *ExtractionError {
+	err := NewExtractionError(
+		ErrTypeBusy,
+		ErrExtractionBusy,
+		contentType,
+		fileSize,
+	)
+
+	err.WithFilename(filename)
+	err.WithUserMessage(
+		"The server is processing many documents right now. Please try again shortly.",
+	)
+	err.WithTechnicalDetails(fmt.Sprintf(
+		"File: %s, Content-Type: %s, Size: %d bytes, Queue depth: %d",
+		filename,
+		contentType,
+		fileSize,
+		queueDepth,
+	))
+	err.WithRetryable(true)
+
+	return err
+}
+
+// WrapSpreadsheetTooLarge wraps a row cap error with context
+func WrapSpreadsheetTooLarge(contentType string, fileSize int64, filename string, maxRows int) *ExtractionError {
+	err := NewExtractionError(
+		ErrTypeSpreadsheetTooLarge,
+		ErrSpreadsheetTooLarge,
+		contentType,
+		fileSize,
+	)
+
+	err.WithFilename(filename)
+	err.WithUserMessage(fmt.Sprintf(
+		"The spreadsheet has more than %d rows, the maximum that can be processed. Please split it into smaller files.",
+		maxRows,
+	))
+	err.WithTechnicalDetails(fmt.Sprintf(
+		"File: %s, Content-Type: %s, Size: %d bytes, Max rows: %d",
+		filename,
+		contentType,
+		fileSize,
+		maxRows,
+	))
+
+	return err
+}
+
 // WrapGenericExtractionError wraps a generic extraction error with context
 func WrapGenericExtractionError(contentType string, fileSize int64, filename string, originalErr error) *ExtractionError {
 	err := NewExtractionError(
@@ -345,13 +401,17 @@ func GetUserFriendlyMessage(err error) string {
 	case errors.Is(err, ErrExtractionTimeout):
 		return "Text extraction took too long. Please try with a smaller file."
 	case errors.Is(err, ErrFileTooLarge):
-		return "File size exceeds the maximum allowed size of 50MB."
+		return "File size exceeds the maximum allowed size."
 	case errors.Is(err, ErrInvalidFormat):
 		return "The file format doesn't match its extension. Please check the file."
 	case errors.Is(err, ErrEmptyFile):
 		return "The file is empty. Please upload a file with content."
 	case errors.Is(err, ErrMemoryLimit):
 		return "The file is too complex to process. Please try with a simpler document."
+	case errors.Is(err, ErrExtractionBusy):
+		return "The server is processing many documents right now. Please try again shortly."
+	case errors.Is(err, ErrSpreadsheetTooLarge):
+		return "The spreadsheet has too many rows to process. Please split it into smaller files."
 	default:
 		return "Failed to process the document. Please try again or contact support if the problem persists."
 	}
@@ -364,6 +424,6 @@ func IsRetryable(err error) bool {
 		return extractionErr.Retryable
 	}
 
-	// Timeouts and memory limits are generally retryable
-	return errors.Is(err, ErrExtractionTimeout) || errors.Is(err, ErrMemoryLimit)
+	// Timeouts, memory limits and a full queue are generally retryable
+	return errors.Is(err, ErrExtractionTimeout) || errors.Is(err, ErrMemoryLimit) || errors.Is(err, ErrExtractionBusy)
 }
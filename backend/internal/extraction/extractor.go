@@ -13,22 +13,107 @@ type ExtractionService interface {
 	// Check if format is supported
 	IsSupported(contentType string) bool
 
+	// Normalize a content type, mapping nonstandard aliases to the supported type they stand for
+	CanonicalContentType(contentType string) string
+
 	// Get list of supported formats
 	SupportedFormats() []string
+
+	// Get the supported formats with their capabilities and limits, ordered by name
+	Formats() []FormatInfo
+
+	// Identify the extraction logic together with the configured options that change its output
+	Version() int
+
+	// Get the maximum size of the text kept from a document (0 = unlimited)
+	MaxTextBytes() int
+
+	// Extract text together with structured document metadata (title, author, page count, ...)
+	// Metadata is nil for formats that do not carry any
+	ExtractWithMetadata(ctx context.Context, data []byte, contentType string) (ExtractionResult, error)
 }
 
+// ExtractorVersion identifies the current extraction logic. Increase it when extractors
+// change the text they produce for existing files (e.g. footnotes are now kept), so documents
+// extracted earlier can be re-extracted. Documents record ExtractionService.Version, which
+// also reflects the configured options that change the text.
+const ExtractorVersion = 1
+
 // Extractor is a format-specific extractor
 type Extractor interface {
 	// Extract text from document bytes
 	Extract(ctx context.Context, data []byte) (string, error)
 }
 
+// ExtractionResult holds extracted text together with structured document metadata
+type ExtractionResult struct {
+	Text     string
+	Metadata map[string]any
+}
+
+// MetadataExtractor is an optional interface for extractors of formats that carry
+// structure worth surfacing (page count, sheet names, title, ...). The router
+// type-asserts for it and falls back to plain Extract otherwise.
+type MetadataExtractor interface {
+	Extractor
+
+	// Extract text and metadata from document bytes
+	ExtractWithMetadata(ctx context.Context, data []byte) (ExtractionResult, error)
+}
+
+// LinkExtractor is an optional interface for extractors of formats that carry hyperlinks.
+// When ExtractionConfig.ExtractLinks is set, the router type-asserts for it and lists the
+// links after the extracted text and in the metadata.
+type LinkExtractor interface {
+	Extractor
+
+	// Extract the targets of the document's hyperlinks, in document order
+	ExtractLinks(ctx context.Context, data []byte) ([]string, error)
+}
+
 // ExtractionConfig holds configuration for text extraction
 type ExtractionConfig struct {
 	MaxFileSize       int64
 	ExtractionTimeout time.Duration
 	MaxConcurrent     int
+	MaxQueueDepth     int   // Maximum extractions waiting for a slot before failing fast (0 = unlimited)
 	MaxMemoryPerFile  int64 // Maximum memory usage per file extraction
+
+	// Maximum size of extracted text; longer text is truncated at a sentence boundary (0 = unlimited)
+	MaxExtractedTextBytes int
+
+	// Limits for archive-based formats (DOCX, XLSX, PPTX, EPUB) to guard against zip bombs
+	MaxArchiveEntries   int   // Maximum number of entries in an archive
+	MaxArchiveEntrySize int64 // Maximum decompressed size of a single entry
+	MaxDecompressedSize int64 // Maximum total decompressed size of all entries read
+
+	// Convert Markdown tables to header: value rows and keep blockquote attribution
+	MarkdownPreserveStructure bool
+
+	// Maximum rows read from a CSV or XLSX file (0 = unlimited). Past the cap the text is
+	// truncated with a notice, or extraction fails with ErrSpreadsheetTooLarge if
+	// FailOnSpreadsheetRowLimit is set.
+	MaxSpreadsheetRows        int
+	FailOnSpreadsheetRowLimit bool
+
+	// List the hyperlinks of HTML, Markdown, DOCX and PDF documents after their text and in
+	// the "links" metadata, so linked resources become entities in the knowledge graph
+	ExtractLinks bool
+
+	// List the footnotes and endnotes of DOCX documents, and footnotes recovered from the
+	// bottom of PDF pages, in sections after the text, so cited references aren't lost
+	ExtractFootnotes bool
+
+	// Format policy. Entries are MIME types ("application/epub+zip") or extensions (".epub").
+	// When EnabledFormats is non-empty only the listed formats are registered; DisabledFormats
+	// are never registered. Unregistered formats report as unsupported.
+	EnabledFormats  []string
+	DisabledFormats []string
+
+	// Nonstandard MIME types some clients send, mapped to the content type of the extractor
+	// that handles them (e.g. "application/x-pdf" to "application/pdf"). Merged over
+	// BuiltinContentTypeAliases, so an entry can also redirect a built-in alias.
+	ContentTypeAliases map[string]string
 }
 
 // DefaultConfig returns default extraction configuration
@@ -37,14 +122,42 @@ func DefaultConfig() *ExtractionConfig {
 		MaxFileSize:       50 * 1024 * 1024, // 50MB
 		ExtractionTimeout: 30 * time.Second,
 		MaxConcurrent:     10,
+		MaxQueueDepth:     50,
 		MaxMemoryPerFile:  100 * 1024 * 1024, // 100MB per file
+
+		MaxExtractedTextBytes: 10 * 1024 * 1024, // 10MB of text
+
+		MaxArchiveEntries:   10000,
+		MaxArchiveEntrySize: 50 * 1024 * 1024,  // 50MB per entry
+		MaxDecompressedSize: 100 * 1024 * 1024, // 100MB per archive
+
+		MaxSpreadsheetRows: 100000,
 	}
 }
 
 // FormatInfo contains metadata about a supported format
 type FormatInfo struct {
-	Name       string
-	Extensions []string
-	MimeType   string
-	Extractor  string
+	Name       string   `json:"name"`
+	Extensions []string `json:"extensions"`
+	MimeType   string   `json:"mimeType"`
+	Extractor  string   `json:"extractor"`
+
+	// What extraction keeps from files of the format
+	Capabilities FormatCapabilities `json:"capabilities"`
+
+	// Effective limits for files of the format, set from the configuration on registration
+	MaxFileSizeBytes  int64 `json:"maxFileSizeBytes"`
+	MaxTimeoutSeconds int   `json:"maxTimeoutSeconds"` // Timeout for a file of the maximum size
+	MaxRows           int   `json:"maxRows,omitempty"` // Spreadsheets only: rows read (0 = unlimited)
+}
+
+// FormatCapabilities describes what extraction keeps from files of a format
+type FormatCapabilities struct {
+	// Text in images, such as scanned pages, is read. Without it, files whose pages are
+	// only images extract as empty.
+	OCR bool `json:"ocr"`
+
+	Metadata  bool `json:"metadata"`  // Embedded title, author, page count, ...
+	Links     bool `json:"links"`     // Hyperlinks are listed after the text (ExtractLinks)
+	Footnotes bool `json:"footnotes"` // Footnotes and endnotes are listed after the text (ExtractFootnotes)
 }
@@ -93,3 +93,35 @@ func extractText(n *html.Node, result *strings.Builder, ctx context.Context) {
 		}
 	}
 }
+
+// ExtractLinks extracts the href targets of the anchors in an HTML document
+func (e *HTMLExtractor) ExtractLinks(ctx context.Context, data []byte) ([]string, error) {
+	doc, err := html.Parse(strings.NewReader(string(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	var hrefs []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if ctx.Err() != nil {
+			return
+		}
+		if n.Type == html.ElementNode && n.Data == "a" {
+			for _, attr := range n.Attr {
+				if attr.Key == "href" {
+					hrefs = append(hrefs, attr.Val)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return normalizeLinks(hrefs), nil
+}
@@ -0,0 +1,68 @@
+package extraction
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// maxExtractedLinks caps the links kept from a single document, so a link farm can't
+// flood the knowledge graph
+const maxExtractedLinks = 500
+
+// normalizeLinks keeps the absolute http(s) and mailto links, without duplicates, in the
+// order they first appear. In-document anchors and relative links point nowhere useful
+// outside the document and are dropped.
+func normalizeLinks(candidates []string) []string {
+	seen := make(map[string]bool)
+	var links []string
+
+	for _, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		parsed, err := url.Parse(candidate)
+		if err != nil {
+			continue
+		}
+
+		switch strings.ToLower(parsed.Scheme) {
+		case "http", "https":
+			if parsed.Host == "" {
+				continue
+			}
+		case "mailto":
+		default:
+			continue
+		}
+
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+
+		links = append(links, candidate)
+		if len(links) == maxExtractedLinks {
+			break
+		}
+	}
+
+	return links
+}
+
+// appendLinks adds a "Links:" section listing links to the end of text
+func appendLinks(text string, links []string) string {
+	if len(links) == 0 {
+		return text
+	}
+
+	var result strings.Builder
+	result.WriteString(text)
+	if text != "" {
+		result.WriteString("\n\n")
+	}
+	result.WriteString("Links:\n")
+	for _, link := range links {
+		result.WriteString(fmt.Sprintf("- %s\n", link))
+	}
+
+	return strings.TrimRight(result.String(), "\n")
+}
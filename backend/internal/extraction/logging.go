@@ -2,25 +2,36 @@ package extraction
 
 import (
 	"fmt"
+	"sync/atomic"
 	"time"
 )
 
 // ExtractionLogger handles logging for extraction operations
 // Requirements: 5.2, 7.5 - Log extraction attempts, failures, and performance metrics
 type ExtractionLogger struct {
-	enabled bool
+	enabled atomic.Bool // Atomic so logging can be toggled while extractions are in flight
 }
 
 // NewExtractionLogger creates a new extraction logger
 func NewExtractionLogger(enabled bool) *ExtractionLogger {
-	return &ExtractionLogger{
-		enabled: enabled,
-	}
+	logger := &ExtractionLogger{}
+	logger.enabled.Store(enabled)
+	return logger
+}
+
+// SetEnabled enables or disables logging
+func (l *ExtractionLogger) SetEnabled(enabled bool) {
+	l.enabled.Store(enabled)
+}
+
+// Enabled reports whether logging is enabled
+func (l *ExtractionLogger) Enabled() bool {
+	return l.enabled.Load()
 }
 
 // LogExtractionStart logs the start of an extraction operation
 func (l *ExtractionLogger) LogExtractionStart(contentType string, fileSize int64) {
-	if !l.enabled {
+	if !l.enabled.Load() {
 		return
 	}
 
@@ -30,7 +41,7 @@ func (l *ExtractionLogger) LogExtractionStart(contentType string, fileSize int64
 
 // LogExtractionSuccess logs a successful extraction
 func (l *ExtractionLogger) LogExtractionSuccess(contentType string, fileSize int64, duration time.Duration, textLength int) {
-	if !l.enabled {
+	if !l.enabled.Load() {
 		return
 	}
 
@@ -41,7 +52,7 @@ func (l *ExtractionLogger) LogExtractionSuccess(contentType string, fileSize int
 
 // LogExtractionFailure logs a failed extraction
 func (l *ExtractionLogger) LogExtractionFailure(contentType string, fileSize int64, duration time.Duration, err error) {
-	if !l.enabled {
+	if !l.enabled.Load() {
 		return
 	}
 
@@ -49,9 +60,19 @@ func (l *ExtractionLogger) LogExtractionFailure(contentType string, fileSize int
 		contentType, fileSize, duration, err)
 }
 
+// LogTextTruncated logs extracted text being cut to the configured maximum size
+func (l *ExtractionLogger) LogTextTruncated(contentType string, textLength, maxLength int) {
+	if !l.enabled.Load() {
+		return
+	}
+
+	fmt.Printf("[EXTRACTION] Truncated - Format: %s, Extracted: %d bytes, Limit: %d bytes\n",
+		contentType, textLength, maxLength)
+}
+
 // LogExtractionTimeout logs an extraction timeout
 func (l *ExtractionLogger) LogExtractionTimeout(contentType string, fileSize int64, timeout time.Duration) {
-	if !l.enabled {
+	if !l.enabled.Load() {
 		return
 	}
 
@@ -61,18 +82,18 @@ func (l *ExtractionLogger) LogExtractionTimeout(contentType string, fileSize int
 
 // LogExtractionMetrics logs current extraction metrics
 func (l *ExtractionLogger) LogExtractionMetrics(metrics ExtractionMetrics) {
-	if !l.enabled {
+	if !l.enabled.Load() {
 		return
 	}
 
-	fmt.Printf("[EXTRACTION] Metrics - Total: %d, Active: %d, Queued: %d, Failed: %d, Success Rate: %.2f%%\n",
-		metrics.TotalExtractions, metrics.ActiveExtractions, metrics.QueuedExtractions,
-		metrics.FailedExtractions, l.calculateSuccessRate(metrics))
+	fmt.Printf("[EXTRACTION] Metrics - Total: %d, Active: %d, Queued: %d/%d, Rejected: %d, Failed: %d, Success Rate: %.2f%%\n",
+		metrics.TotalExtractions, metrics.ActiveExtractions, metrics.QueuedExtractions, metrics.MaxQueueDepth,
+		metrics.RejectedExtractions, metrics.FailedExtractions, l.calculateSuccessRate(metrics))
 }
 
 // LogMemoryWarning logs a memory usage warning
 func (l *ExtractionLogger) LogMemoryWarning(used int64, limit int64) {
-	if !l.enabled {
+	if !l.enabled.Load() {
 		return
 	}
 
@@ -83,7 +104,7 @@ func (l *ExtractionLogger) LogMemoryWarning(used int64, limit int64) {
 
 // LogQueueStatus logs the current queue status
 func (l *ExtractionLogger) LogQueueStatus(active int, queued int, maxConcurrent int) {
-	if !l.enabled {
+	if !l.enabled.Load() {
 		return
 	}
 
@@ -2,16 +2,22 @@ package extraction
 
 import (
 	"context"
+	"fmt"
 	"regexp"
 	"strings"
 )
 
 // MarkdownExtractor handles markdown files
-type MarkdownExtractor struct{}
+type MarkdownExtractor struct {
+	// Convert tables to header: value rows and keep blockquote attribution
+	preserveStructure bool
+}
 
-// NewMarkdownExtractor creates a new markdown extractor
-func NewMarkdownExtractor() *MarkdownExtractor {
-	return &MarkdownExtractor{}
+// NewMarkdownExtractor creates a new markdown extractor. With preserveStructure, tables
+// become row-wise header: value lines (as for CSV) and blockquotes keep their attribution;
+// otherwise table and quote lines are passed through as plain text.
+func NewMarkdownExtractor(preserveStructure bool) *MarkdownExtractor {
+	return &MarkdownExtractor{preserveStructure: preserveStructure}
 }
 
 // Extract extracts text from markdown files while preserving structure
@@ -33,7 +39,7 @@ func (e *MarkdownExtractor) Extract(ctx context.Context, data []byte) (string, e
 	text = normalizeLineEndings(text)
 
 	// Process markdown while preserving structure
-	text = processMarkdown(text)
+	text = processMarkdown(text, e.preserveStructure)
 
 	// Normalize whitespace while preserving paragraph breaks
 	text = normalizeWhitespace(text)
@@ -41,15 +47,18 @@ func (e *MarkdownExtractor) Extract(ctx context.Context, data []byte) (string, e
 	return text, nil
 }
 
-// processMarkdown processes markdown syntax while preserving semantic structure
-func processMarkdown(text string) string {
+// processMarkdown processes markdown syntax while preserving semantic structure.
+// With preserveStructure, tables and blockquotes are converted as whole blocks.
+func processMarkdown(text string, preserveStructure bool) string {
 	lines := strings.Split(text, "\n")
 	var result strings.Builder
 
 	inCodeBlock := false
 	codeBlockDelimiter := ""
 
-	for _, line := range lines {
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
 		// Handle code blocks
 		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
 			if !inCodeBlock {
@@ -75,6 +84,19 @@ func processMarkdown(text string) string {
 			continue
 		}
 
+		if preserveStructure {
+			if consumed, block := convertTable(lines[i:]); consumed > 0 {
+				result.WriteString(block)
+				i += consumed - 1
+				continue
+			}
+			if consumed, block := convertBlockquote(lines[i:]); consumed > 0 {
+				result.WriteString(block)
+				i += consumed - 1
+				continue
+			}
+		}
+
 		// Process markdown syntax outside code blocks
 		line = processMarkdownLine(line)
 		result.WriteString(line)
@@ -167,9 +189,176 @@ func convertLinks(line string) string {
 	return re.ReplaceAllString(line, "$1 ($2)")
 }
 
+// markdownLink matches inline links and images, [text](url "title") and ![alt](url), and
+// autolinks, <https://...>. Images are told apart by the leading "!".
+var markdownLink = regexp.MustCompile(`(!?)\[[^\]]*\]\(\s*<?([^)\s>]+)>?[^)]*\)|<((?:https?|mailto):[^>\s]+)>`)
+
+// ExtractLinks extracts the targets of the links in a markdown document; images are skipped
+func (e *MarkdownExtractor) ExtractLinks(ctx context.Context, data []byte) ([]string, error) {
+	text, err := ensureUTF8(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []string
+	for _, match := range markdownLink.FindAllStringSubmatch(text, -1) {
+		switch {
+		case match[3] != "":
+			targets = append(targets, match[3])
+		case match[1] == "":
+			targets = append(targets, match[2])
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return normalizeLinks(targets), nil
+}
+
 // removeImages removes image syntax but keeps alt text
 func removeImages(line string) string {
 	// Convert ![alt](url) to [Image: alt]
 	re := regexp.MustCompile(`!\[([^\]]*)\]\([^)]+\)`)
 	return re.ReplaceAllString(line, "[Image: $1]")
 }
+
+// tableSeparatorCell matches a cell of a table's header separator row, e.g. ---, :--, :-:
+var tableSeparatorCell = regexp.MustCompile(`^:?-+:?$`)
+
+// attributionLine matches a blockquote's closing attribution, e.g. "— Ada Lovelace"
+var attributionLine = regexp.MustCompile(`^(?:—|–|--|-|~)\s*(\S.*)$`)
+
+// convertTable converts a table starting at lines[0] into row-wise header: value lines,
+// matching the CSV extractor's layout. Returns the number of lines consumed, or 0 if
+// lines don't start with a header row followed by a separator row.
+func convertTable(lines []string) (int, string) {
+	if len(lines) < 2 {
+		return 0, ""
+	}
+
+	headers, ok := splitTableRow(lines[0])
+	if !ok {
+		return 0, ""
+	}
+	separator, ok := splitTableRow(lines[1])
+	if !ok || len(separator) != len(headers) {
+		return 0, ""
+	}
+	for _, cell := range separator {
+		if !tableSeparatorCell.MatchString(cell) {
+			return 0, ""
+		}
+	}
+
+	for j, header := range headers {
+		headers[j] = processMarkdownLine(header)
+		if headers[j] == "" {
+			headers[j] = fmt.Sprintf("Column%d", j+1)
+		}
+	}
+
+	var result strings.Builder
+	result.WriteString("\nHeaders: ")
+	result.WriteString(strings.Join(headers, ", "))
+	result.WriteString("\n\n")
+
+	consumed := 2
+	for _, line := range lines[2:] {
+		cells, ok := splitTableRow(line)
+		if !ok {
+			break
+		}
+		consumed++
+
+		result.WriteString(fmt.Sprintf("Row %d:\n", consumed-2))
+		for j, header := range headers {
+			value := ""
+			if j < len(cells) {
+				value = processMarkdownLine(cells[j])
+			}
+			result.WriteString(fmt.Sprintf("  %s: %s\n", header, value))
+		}
+		result.WriteString("\n")
+	}
+
+	return consumed, result.String()
+}
+
+// splitTableRow splits a pipe-delimited table row into trimmed cells.
+// Escaped pipes (\|) stay part of the cell. Returns false for lines that aren't table rows.
+func splitTableRow(line string) ([]string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || !strings.Contains(trimmed, "|") {
+		return nil, false
+	}
+
+	const escapedPipe = "\x00"
+	trimmed = strings.ReplaceAll(trimmed, `\|`, escapedPipe)
+	trimmed = strings.TrimPrefix(trimmed, "|")
+	trimmed = strings.TrimSuffix(trimmed, "|")
+
+	cells := strings.Split(trimmed, "|")
+	for j, cell := range cells {
+		cells[j] = strings.TrimSpace(strings.ReplaceAll(cell, escapedPipe, "|"))
+	}
+
+	return cells, true
+}
+
+// convertBlockquote converts a blockquote starting at lines[0] into a labelled quote,
+// naming its author when the last line is an attribution ("— Author"). Returns the
+// number of lines consumed, or 0 if lines[0] isn't a blockquote.
+func convertBlockquote(lines []string) (int, string) {
+	var quoted []string
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if !strings.HasPrefix(trimmed, ">") {
+			break
+		}
+
+		// Strip every level of nesting
+		for strings.HasPrefix(trimmed, ">") {
+			trimmed = strings.TrimPrefix(trimmed, ">")
+			trimmed = strings.TrimPrefix(trimmed, " ")
+		}
+		quoted = append(quoted, strings.TrimSpace(trimmed))
+	}
+
+	if len(quoted) == 0 {
+		return 0, ""
+	}
+	consumed := len(quoted)
+
+	// Ignore trailing blank quote lines when looking for the attribution
+	for len(quoted) > 0 && quoted[len(quoted)-1] == "" {
+		quoted = quoted[:len(quoted)-1]
+	}
+
+	author := ""
+	if len(quoted) > 1 {
+		if matches := attributionLine.FindStringSubmatch(quoted[len(quoted)-1]); matches != nil {
+			author = processMarkdownLine(matches[1])
+			quoted = quoted[:len(quoted)-1]
+		}
+	}
+
+	var result strings.Builder
+	if author != "" {
+		result.WriteString(fmt.Sprintf("\nQuote from %s:\n", author))
+	} else {
+		result.WriteString("\nQuote:\n")
+	}
+	for _, line := range quoted {
+		if line == "" {
+			result.WriteString("\n")
+			continue
+		}
+		result.WriteString("  ")
+		result.WriteString(processMarkdownLine(line))
+		result.WriteString("\n")
+	}
+	result.WriteString("\n")
+
+	return consumed, result.String()
+}
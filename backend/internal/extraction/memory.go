@@ -1,10 +1,13 @@
 package extraction
 
 import (
+	"archive/zip"
 	"context"
 	"fmt"
+	"io"
 	"runtime"
 	"strings"
+	"sync/atomic"
 )
 
 // MemoryTracker tracks memory usage during extraction
@@ -120,3 +123,117 @@ func extractWithMemoryLimit(ctx context.Context, maxMemory int64, extractFn func
 		return res.text, res.err
 	}
 }
+
+// decompressionBudget caps the bytes decompressed from archive entries during a
+// single extraction, both per entry and in total, as well as the number of entries.
+// Unlike MemoryTracker, which only observes allocation after the fact, the budget
+// stops reading before a limit is crossed, so a small, highly-compressed archive
+// (zip bomb) cannot exhaust memory. Zero limits are treated as unlimited.
+type decompressionBudget struct {
+	maxEntries   int
+	maxEntrySize int64
+	maxTotalSize int64
+	used         atomic.Int64
+}
+
+// decompressionBudgetKey is the context key for the active decompression budget
+type decompressionBudgetKey struct{}
+
+// withDecompressionBudget returns a context carrying a decompression budget built from config
+func withDecompressionBudget(ctx context.Context, config *ExtractionConfig) context.Context {
+	return context.WithValue(ctx, decompressionBudgetKey{}, &decompressionBudget{
+		maxEntries:   config.MaxArchiveEntries,
+		maxEntrySize: config.MaxArchiveEntrySize,
+		maxTotalSize: config.MaxDecompressedSize,
+	})
+}
+
+// decompressionBudgetFrom returns the budget stored in ctx, or nil if there is none
+func decompressionBudgetFrom(ctx context.Context) *decompressionBudget {
+	budget, _ := ctx.Value(decompressionBudgetKey{}).(*decompressionBudget)
+	return budget
+}
+
+// entryAllowance returns the number of bytes the next entry may decompress to
+func (b *decompressionBudget) entryAllowance() int64 {
+	allowance := int64(-1)
+	if b.maxTotalSize > 0 {
+		allowance = max(b.maxTotalSize-b.used.Load(), 0)
+	}
+	if b.maxEntrySize > 0 && (allowance < 0 || b.maxEntrySize < allowance) {
+		allowance = b.maxEntrySize
+	}
+	return allowance
+}
+
+// readZipFile reads an archive entry, charging the decompressed bytes against the
+// budget in ctx. Returns ErrMemoryLimit as soon as a limit would be exceeded.
+func readZipFile(ctx context.Context, file *zip.File) ([]byte, error) {
+	budget := decompressionBudgetFrom(ctx)
+
+	allowance := int64(-1)
+	if budget != nil {
+		allowance = budget.entryAllowance()
+	}
+
+	// Reject up front when the declared size alone is over the allowance
+	if allowance >= 0 && file.UncompressedSize64 > uint64(allowance) {
+		return nil, fmt.Errorf("%w: archive entry %s expands to %d bytes", ErrMemoryLimit, file.Name, file.UncompressedSize64)
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	if allowance < 0 {
+		return io.ReadAll(rc)
+	}
+
+	// Read at most one byte past the allowance so overruns are detectable
+	data, err := io.ReadAll(io.LimitReader(rc, allowance+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > allowance {
+		return nil, fmt.Errorf("%w: archive entry %s exceeds %d bytes when decompressed", ErrMemoryLimit, file.Name, allowance)
+	}
+
+	used := budget.used.Add(int64(len(data)))
+	if budget.maxTotalSize > 0 && used > budget.maxTotalSize {
+		return nil, fmt.Errorf("%w: decompressed archive content exceeds %d bytes", ErrMemoryLimit, budget.maxTotalSize)
+	}
+
+	return data, nil
+}
+
+// checkArchiveLimits rejects archives with too many entries or whose declared sizes
+// exceed the budget in ctx. This is the only guard for formats parsed by third-party
+// libraries that read entries themselves; archive/zip refuses entries that expand
+// past their declared size, so the declared sizes can be trusted.
+func checkArchiveLimits(ctx context.Context, zipReader *zip.Reader) error {
+	budget := decompressionBudgetFrom(ctx)
+	if budget == nil {
+		return nil
+	}
+
+	if budget.maxEntries > 0 && len(zipReader.File) > budget.maxEntries {
+		return fmt.Errorf("%w: archive contains %d entries (limit %d)", ErrMemoryLimit, len(zipReader.File), budget.maxEntries)
+	}
+
+	var total uint64
+	for _, file := range zipReader.File {
+		if budget.maxEntrySize > 0 && file.UncompressedSize64 > uint64(budget.maxEntrySize) {
+			return fmt.Errorf("%w: archive entry %s expands to %d bytes", ErrMemoryLimit, file.Name, file.UncompressedSize64)
+		}
+
+		total += file.UncompressedSize64
+		if budget.maxTotalSize > 0 && total > uint64(budget.maxTotalSize) {
+			return fmt.Errorf("%w: archive expands to more than %d bytes", ErrMemoryLimit, budget.maxTotalSize)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package extraction
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxExtractedNotes caps the footnotes or endnotes kept from a single document
+const maxExtractedNotes = 2000
+
+// numberNotes labels notes [1], [2], ... in order, which matches Word's default numbering
+// of footnotes referenced in document order
+func numberNotes(notes []string) []string {
+	labeled := make([]string, len(notes))
+	for i, note := range notes {
+		labeled[i] = fmt.Sprintf("[%d] %s", i+1, note)
+	}
+	return labeled
+}
+
+// appendNotes adds a section of labeled notes, e.g. "Footnotes:", to the end of text
+func appendNotes(text, heading string, notes []string) string {
+	if len(notes) == 0 {
+		return text
+	}
+	if len(notes) > maxExtractedNotes {
+		notes = notes[:maxExtractedNotes]
+	}
+
+	var result strings.Builder
+	result.WriteString(text)
+	if text != "" {
+		result.WriteString("\n\n")
+	}
+	result.WriteString(heading)
+	result.WriteString(":\n")
+	for _, note := range notes {
+		result.WriteString(note)
+		result.WriteString("\n")
+	}
+
+	return strings.TrimRight(result.String(), "\n")
+}
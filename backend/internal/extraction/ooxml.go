@@ -0,0 +1,103 @@
+package extraction
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"strings"
+)
+
+// oleSignature is the magic number of OLE compound files. Office wraps password-protected
+// .docx, .xlsx and .pptx packages in an OLE container instead of a plain ZIP archive.
+var oleSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// isOLECompoundFile reports whether data starts with the OLE compound file signature
+func isOLECompoundFile(data []byte) bool {
+	return bytes.HasPrefix(data, oleSignature)
+}
+
+// ooxmlCoreProperties mirrors docProps/core.xml in Office Open XML packages
+type ooxmlCoreProperties struct {
+	Title          string `xml:"title"`
+	Subject        string `xml:"subject"`
+	Creator        string `xml:"creator"`
+	Keywords       string `xml:"keywords"`
+	Description    string `xml:"description"`
+	LastModifiedBy string `xml:"lastModifiedBy"`
+	Created        string `xml:"created"`
+	Modified       string `xml:"modified"`
+}
+
+// ooxmlAppProperties mirrors the statistics in docProps/app.xml
+type ooxmlAppProperties struct {
+	Pages  int `xml:"Pages"`
+	Words  int `xml:"Words"`
+	Slides int `xml:"Slides"`
+}
+
+// readOOXMLMetadata reads the core and extended properties of a .docx, .xlsx or .pptx package
+// Missing or unreadable property parts are skipped, so the result may be empty
+func readOOXMLMetadata(ctx context.Context, data []byte) map[string]any {
+	metadata := make(map[string]any)
+
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return metadata
+	}
+
+	if coreData := readZipEntry(ctx, zipReader, "docProps/core.xml"); coreData != nil {
+		var core ooxmlCoreProperties
+		if err := xml.Unmarshal(coreData, &core); err == nil {
+			fields := map[string]string{
+				"title":          core.Title,
+				"subject":        core.Subject,
+				"author":         core.Creator,
+				"keywords":       core.Keywords,
+				"description":    core.Description,
+				"lastModifiedBy": core.LastModifiedBy,
+				"creationDate":   core.Created,
+				"modDate":        core.Modified,
+			}
+			for key, value := range fields {
+				if value = strings.TrimSpace(value); value != "" {
+					metadata[key] = value
+				}
+			}
+		}
+	}
+
+	if appData := readZipEntry(ctx, zipReader, "docProps/app.xml"); appData != nil {
+		var app ooxmlAppProperties
+		if err := xml.Unmarshal(appData, &app); err == nil {
+			if app.Pages > 0 {
+				metadata["pageCount"] = app.Pages
+			}
+			if app.Words > 0 {
+				metadata["wordCount"] = app.Words
+			}
+			if app.Slides > 0 {
+				metadata["slideCount"] = app.Slides
+			}
+		}
+	}
+
+	return metadata
+}
+
+// readZipEntry returns the contents of the named archive entry, or nil if it is missing or unreadable
+func readZipEntry(ctx context.Context, zipReader *zip.Reader, name string) []byte {
+	for _, file := range zipReader.File {
+		if file.Name != name {
+			continue
+		}
+
+		content, err := readZipFile(ctx, file)
+		if err != nil {
+			return nil
+		}
+		return content
+	}
+
+	return nil
+}
@@ -4,17 +4,84 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/ledongthuc/pdf"
 )
 
+// PDFMetadata contains the document information embedded in a PDF
+type PDFMetadata struct {
+	Title        string
+	Author       string
+	Subject      string
+	Keywords     string
+	Creator      string
+	Producer     string
+	CreationDate *time.Time
+	ModDate      *time.Time
+	PageCount    int
+}
+
+// ToMap converts the metadata to a map, omitting empty fields
+func (m *PDFMetadata) ToMap() map[string]any {
+	result := map[string]any{
+		"pageCount": m.PageCount,
+	}
+
+	fields := map[string]string{
+		"title":    m.Title,
+		"author":   m.Author,
+		"subject":  m.Subject,
+		"keywords": m.Keywords,
+		"creator":  m.Creator,
+		"producer": m.Producer,
+	}
+	for key, value := range fields {
+		if value != "" {
+			result[key] = value
+		}
+	}
+
+	if m.CreationDate != nil {
+		result["creationDate"] = m.CreationDate.UTC().Format(time.RFC3339)
+	}
+	if m.ModDate != nil {
+		result["modDate"] = m.ModDate.UTC().Format(time.RFC3339)
+	}
+
+	return result
+}
+
+// pdfFootnoteSizeRatio is how small text must be, relative to a page's body text, to be
+// taken for footnotes
+const pdfFootnoteSizeRatio = 0.9
+
+// Lines starting a PDF footnote: a number, symbol or superscript marker, followed by the
+// note's text or, for a raised marker set apart from it, alone on its line
+var (
+	pdfFootnoteStart  = regexp.MustCompile(`^(\d{1,3}|[*†‡§¶]+|[⁰¹²³⁴⁵⁶⁷⁸⁹]+)[.)]?\s+(\S.*)$`)
+	pdfFootnoteMarker = regexp.MustCompile(`^(\d{1,3}|[*†‡§¶]+|[⁰¹²³⁴⁵⁶⁷⁸⁹]+)$`)
+	pdfPageNumber     = regexp.MustCompile(`(?i)^(page\s+)?\d+(\s+of\s+\d+)?$`)
+)
+
 // PDFExtractor handles PDF document extraction
-type PDFExtractor struct{}
+type PDFExtractor struct {
+	// Move footnotes from the bottom of each page to a section after the text
+	extractFootnotes bool
+}
 
-// NewPDFExtractor creates a new PDF extractor
-func NewPDFExtractor() *PDFExtractor {
-	return &PDFExtractor{}
+// NewPDFExtractor creates a new PDF extractor. PDFs don't mark footnotes, so with
+// extractFootnotes they are recovered heuristically: a block of smaller text at the bottom
+// of a page that starts with a note marker is moved from the page's text to a "Footnotes:"
+// section after the text.
+func NewPDFExtractor(extractFootnotes bool) *PDFExtractor {
+	return &PDFExtractor{extractFootnotes: extractFootnotes}
 }
 
 // Extract extracts text from PDF files
@@ -61,6 +128,7 @@ func (e *PDFExtractor) Extract(ctx context.Context, data []byte) (string, error)
 
 	var result strings.Builder
 	var extractionErrors []string
+	var footnotes []string
 
 	// Extract text from each page
 	for pageNum := 1; pageNum <= numPages; pageNum++ {
@@ -84,6 +152,11 @@ func (e *PDFExtractor) Extract(ctx context.Context, data []byte) (string, error)
 
 		// Extract text from the page with error handling
 		pageText, err := extractPageText(page)
+		if err == nil && e.extractFootnotes {
+			var pageFootnotes []string
+			pageText, pageFootnotes = splitPageFootnotes(page, pageText)
+			footnotes = append(footnotes, pageFootnotes...)
+		}
 		if err != nil {
 			// Record error but continue with other pages
 			extractionErrors = append(extractionErrors, fmt.Sprintf("page %d: %v", pageNum, err))
@@ -112,7 +185,127 @@ func (e *PDFExtractor) Extract(ctx context.Context, data []byte) (string, error)
 	// Normalize whitespace while preserving paragraph breaks
 	text = normalizeWhitespace(text)
 
-	return text, nil
+	return appendNotes(text, "Footnotes", footnotes), nil
+}
+
+// ExtractWithMetadata extracts text and the embedded document information from PDF files
+func (e *PDFExtractor) ExtractWithMetadata(ctx context.Context, data []byte) (ExtractionResult, error) {
+	text, err := e.Extract(ctx, data)
+	if err != nil {
+		return ExtractionResult{Text: text}, err
+	}
+
+	// Metadata is best-effort; the text is what matters for processing
+	metadata, err := e.ExtractMetadata(ctx, data)
+	if err != nil {
+		return ExtractionResult{Text: text}, nil
+	}
+
+	return ExtractionResult{Text: text, Metadata: metadata}, nil
+}
+
+// ExtractMetadata reads the document information dictionary and page count from a PDF
+func (e *PDFExtractor) ExtractMetadata(ctx context.Context, data []byte) (map[string]any, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, fmt.Errorf("%w: invalid PDF header - file may be corrupted or not a PDF", ErrCorruptedFile)
+	}
+
+	pdfReader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse PDF - %v", ErrCorruptedFile, err)
+	}
+
+	metadata := &PDFMetadata{
+		PageCount: pdfReader.NumPage(),
+	}
+
+	// The Info dictionary is optional, so missing keys simply yield empty strings
+	info := pdfReader.Trailer().Key("Info")
+	if !info.IsNull() {
+		metadata.Title = strings.TrimSpace(info.Key("Title").Text())
+		metadata.Author = strings.TrimSpace(info.Key("Author").Text())
+		metadata.Subject = strings.TrimSpace(info.Key("Subject").Text())
+		metadata.Keywords = strings.TrimSpace(info.Key("Keywords").Text())
+		metadata.Creator = strings.TrimSpace(info.Key("Creator").Text())
+		metadata.Producer = strings.TrimSpace(info.Key("Producer").Text())
+		metadata.CreationDate = parsePDFDate(info.Key("CreationDate").RawString())
+		metadata.ModDate = parsePDFDate(info.Key("ModDate").RawString())
+	}
+
+	return metadata.ToMap(), nil
+}
+
+// ExtractLinks extracts the URI targets of the link annotations on each page of a PDF
+func (e *PDFExtractor) ExtractLinks(ctx context.Context, data []byte) ([]string, error) {
+	pdfReader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse PDF - %v", ErrCorruptedFile, err)
+	}
+
+	var uris []string
+	for pageNum := 1; pageNum <= pdfReader.NumPage(); pageNum++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		page := pdfReader.Page(pageNum)
+		if page.V.IsNull() {
+			continue
+		}
+
+		annots := page.V.Key("Annots")
+		for i := 0; i < annots.Len(); i++ {
+			annot := annots.Index(i)
+			if annot.Key("Subtype").Name() != "Link" {
+				continue
+			}
+			if action := annot.Key("A"); action.Key("S").Name() == "URI" {
+				uris = append(uris, action.Key("URI").RawString())
+			}
+		}
+	}
+
+	return normalizeLinks(uris), nil
+}
+
+// parsePDFDate parses a PDF date string (D:YYYYMMDDHHmmSSOHH'mm') into a time
+// Returns nil if the value is empty or cannot be parsed
+func parsePDFDate(value string) *time.Time {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "D:")
+	if value == "" {
+		return nil
+	}
+
+	// Normalize the timezone suffix: Z, +HH'mm' or -HH'mm'
+	value = strings.ReplaceAll(value, "'", "")
+	value = strings.TrimSuffix(value, "Z")
+	if len(value) > 14 && (value[14] == 'Z') {
+		value = value[:14]
+	}
+
+	layouts := []string{
+		"20060102150405-0700",
+		"20060102150405-07",
+		"20060102150405",
+		"200601021504",
+		"2006010215",
+		"20060102",
+		"200601",
+		"2006",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+
+	return nil
 }
 
 // extractPageText extracts text content from a PDF page
@@ -161,3 +354,157 @@ func extractPageTextBasic(page pdf.Page) (string, error) {
 
 	return textBuilder.String(), nil
 }
+
+// pdfLine is a line of text on a PDF page, as laid out by its glyphs
+type pdfLine struct {
+	y        float64
+	text     string
+	fontSize float64 // Size of most of the line's glyphs
+}
+
+// splitPageFootnotes finds the footnotes at the bottom of a page and removes their lines
+// from pageText, returning the remaining text and the footnotes labeled with their markers.
+// Footnotes are the lines below the page's body text set in a smaller font, starting with
+// a line that begins with a marker; page numbers under them are ignored. Pages without
+// such a block are returned unchanged.
+func splitPageFootnotes(page pdf.Page, pageText string) (string, []string) {
+	lines, bodySize := pageLines(page)
+	if bodySize == 0 {
+		return pageText, nil
+	}
+
+	// Lines run bottom to top; skip page numbers, then take the small-print block
+	i := 0
+	for i < len(lines) && pdfPageNumber.MatchString(lines[i].text) {
+		i++
+	}
+	var block []pdfLine
+	for ; i < len(lines) && lines[i].fontSize < bodySize*pdfFootnoteSizeRatio; i++ {
+		block = append(block, lines[i])
+	}
+	if len(block) == 0 {
+		return pageText, nil
+	}
+
+	// Read the block top to bottom, starting a note at each marker
+	sort.Slice(block, func(i, j int) bool { return block[i].y > block[j].y })
+	first := block[0].text
+	if !pdfFootnoteStart.MatchString(first) && !pdfFootnoteMarker.MatchString(first) {
+		return pageText, nil
+	}
+
+	var footnotes []string
+	var label, note string
+	flush := func() {
+		if note = strings.TrimSpace(note); note != "" {
+			footnotes = append(footnotes, fmt.Sprintf("[%s] %s", label, note))
+		}
+	}
+	for _, line := range block {
+		if m := pdfFootnoteMarker.FindStringSubmatch(line.text); m != nil {
+			flush()
+			label, note = m[1], ""
+		} else if m := pdfFootnoteStart.FindStringSubmatch(line.text); m != nil && (label == "" || note != "") {
+			flush()
+			label, note = m[1], m[2]
+		} else {
+			note += " " + line.text
+		}
+	}
+	flush()
+	if len(footnotes) == 0 {
+		return pageText, nil
+	}
+
+	return removeLines(pageText, block), footnotes
+}
+
+// pageLines groups the glyphs of a page into lines, bottom line first, and returns them with
+// the font size of most of the page's text. Pages whose content can't be read have no lines.
+func pageLines(page pdf.Page) (lines []pdfLine, bodySize float64) {
+	defer func() {
+		if recover() != nil {
+			lines, bodySize = nil, 0
+		}
+	}()
+
+	glyphsByY := make(map[float64][]pdf.Text)
+	for _, glyph := range page.Content().Text {
+		y := math.Round(glyph.Y)
+		glyphsByY[y] = append(glyphsByY[y], glyph)
+	}
+
+	pageSizes := make(map[float64]int)
+	for y, glyphs := range glyphsByY {
+		sort.Slice(glyphs, func(i, j int) bool { return glyphs[i].X < glyphs[j].X })
+
+		var text strings.Builder
+		lineSizes := make(map[float64]int)
+		for i, glyph := range glyphs {
+			// Glyphs set apart without a space glyph between them are separate words
+			if i > 0 {
+				prev := glyphs[i-1]
+				if glyph.X > prev.X+prev.W+glyph.FontSize*0.2 && !strings.HasSuffix(prev.S, " ") && !strings.HasPrefix(glyph.S, " ") {
+					text.WriteString(" ")
+				}
+			}
+			text.WriteString(glyph.S)
+
+			size := math.Round(glyph.FontSize*2) / 2
+			lineSizes[size] += len(strings.TrimSpace(glyph.S))
+			pageSizes[size] += len(strings.TrimSpace(glyph.S))
+		}
+
+		lineText := strings.Join(strings.Fields(text.String()), " ")
+		if lineText != "" {
+			lines = append(lines, pdfLine{y: y, text: lineText, fontSize: mostCommonSize(lineSizes)})
+		}
+	}
+
+	sort.Slice(lines, func(i, j int) bool { return lines[i].y < lines[j].y })
+	return lines, mostCommonSize(pageSizes)
+}
+
+// mostCommonSize returns the font size with the most characters, preferring the larger size on ties
+func mostCommonSize(sizes map[float64]int) float64 {
+	var best float64
+	for size, count := range sizes {
+		if count > sizes[best] || (count == sizes[best] && size > best) {
+			best = size
+		}
+	}
+	return best
+}
+
+// removeLines removes the lines of pageText that hold the text of the given PDF lines,
+// searching from the bottom of the page. Lines are compared without whitespace, since page
+// text and glyph lines space words differently.
+func removeLines(pageText string, lines []pdfLine) string {
+	remove := make(map[string]int)
+	for _, line := range lines {
+		remove[withoutSpaces(line.text)]++
+	}
+
+	textLines := strings.Split(pageText, "\n")
+	kept := make([]string, 0, len(textLines))
+	for i := len(textLines) - 1; i >= 0; i-- {
+		key := withoutSpaces(textLines[i])
+		if key != "" && remove[key] > 0 {
+			remove[key]--
+			continue
+		}
+		kept = append(kept, textLines[i])
+	}
+	slices.Reverse(kept)
+	return strings.Join(kept, "\n")
+}
+
+// withoutSpaces removes all whitespace from s
+func withoutSpaces(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
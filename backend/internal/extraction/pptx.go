@@ -5,8 +5,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
-	"io"
 	"strings"
 )
 
@@ -32,6 +32,11 @@ func (e *PptxExtractor) Extract(ctx context.Context, data []byte) (string, error
 		return "", fmt.Errorf("%w: file too small to be a valid .pptx document", ErrCorruptedFile)
 	}
 
+	// Encrypted Office documents are OLE compound files rather than ZIP archives
+	if isOLECompoundFile(data) {
+		return "", fmt.Errorf("%w: .pptx file is encrypted", ErrPasswordProtected)
+	}
+
 	// Check for ZIP magic number (pptx is a ZIP archive)
 	if !bytes.HasPrefix(data, []byte("PK")) {
 		return "", fmt.Errorf("%w: invalid .pptx header - file may be corrupted or not a .pptx", ErrCorruptedFile)
@@ -54,6 +59,11 @@ func (e *PptxExtractor) Extract(ctx context.Context, data []byte) (string, error
 		return "", fmt.Errorf("%w: failed to parse .pptx - %v", ErrCorruptedFile, err)
 	}
 
+	// Reject archives with too many entries or oversized entries before reading any
+	if err := checkArchiveLimits(ctx, zipReader); err != nil {
+		return "", err
+	}
+
 	// Check for context cancellation before processing
 	select {
 	case <-ctx.Done():
@@ -81,17 +91,14 @@ func (e *PptxExtractor) Extract(ctx context.Context, data []byte) (string, error
 		if strings.HasPrefix(file.Name, "ppt/slides/slide") && strings.HasSuffix(file.Name, ".xml") {
 			slideCount++
 
-			// Open the slide file
-			rc, err := file.Open()
-			if err != nil {
-				continue // Skip slides that can't be opened
-			}
-
 			// Read the slide content
-			slideData, err := io.ReadAll(rc)
-			rc.Close()
+			slideData, err := readZipFile(ctx, file)
 			if err != nil {
-				continue // Skip slides that can't be read
+				// Abort on zip bombs, otherwise skip slides that can't be read
+				if errors.Is(err, ErrMemoryLimit) {
+					return "", err
+				}
+				continue
 			}
 
 			// Extract text from the slide XML
@@ -105,17 +112,14 @@ func (e *PptxExtractor) Extract(ctx context.Context, data []byte) (string, error
 
 		// Process notes files (ppt/notesSlides/notesSlideX.xml)
 		if strings.HasPrefix(file.Name, "ppt/notesSlides/notesSlide") && strings.HasSuffix(file.Name, ".xml") {
-			// Open the notes file
-			rc, err := file.Open()
-			if err != nil {
-				continue // Skip notes that can't be opened
-			}
-
 			// Read the notes content
-			notesData, err := io.ReadAll(rc)
-			rc.Close()
+			notesData, err := readZipFile(ctx, file)
 			if err != nil {
-				continue // Skip notes that can't be read
+				// Abort on zip bombs, otherwise skip notes that can't be read
+				if errors.Is(err, ErrMemoryLimit) {
+					return "", err
+				}
+				continue
 			}
 
 			// Extract text from the notes XML
@@ -176,3 +180,13 @@ func extractTextFromPptxXML(data []byte) string {
 
 	return strings.TrimSpace(result.String())
 }
+
+// ExtractWithMetadata extracts text and the document properties from .pptx files
+func (e *PptxExtractor) ExtractWithMetadata(ctx context.Context, data []byte) (ExtractionResult, error) {
+	text, err := e.Extract(ctx, data)
+	if err != nil {
+		return ExtractionResult{Text: text}, err
+	}
+
+	return ExtractionResult{Text: text, Metadata: readOOXMLMetadata(ctx, data)}, nil
+}
@@ -2,8 +2,12 @@ package extraction
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // ExtractionRouter routes extraction requests to format-specific extractors
@@ -11,6 +15,7 @@ type ExtractionRouter struct {
 	extractors map[string]Extractor
 	formats    map[string]FormatInfo
 	config     *ExtractionConfig
+	aliases    map[string]string
 	queue      *ExtractionQueue
 	logger     *ExtractionLogger
 	stats      *ExtractionStats
@@ -26,17 +31,66 @@ func NewExtractionRouter(config *ExtractionConfig) *ExtractionRouter {
 		extractors: make(map[string]Extractor),
 		formats:    make(map[string]FormatInfo),
 		config:     config,
-		queue:      NewExtractionQueue(config.MaxConcurrent),
+		aliases:    contentTypeAliases(config.ContentTypeAliases),
+		queue:      NewExtractionQueue(config.MaxConcurrent, config.MaxQueueDepth),
 		logger:     NewExtractionLogger(true), // Enable logging by default
 		stats:      NewExtractionStats(),
 	}
 
-	// Register all extractors
+	// Register all extractors, then drop those disabled by configuration
 	router.registerExtractors()
+	router.applyFormatPolicy()
 
 	return router
 }
 
+// BuiltinContentTypeAliases maps nonstandard MIME types that clients commonly send for
+// supported formats to the content type the format is registered under
+var BuiltinContentTypeAliases = map[string]string{
+	"application/x-pdf":           "application/pdf",
+	"application/acrobat":         "application/pdf",
+	"applications/vnd.pdf":        "application/pdf",
+	"text/pdf":                    "application/pdf",
+	"text/x-pdf":                  "application/pdf",
+	"application/x-rtf":           "application/rtf",
+	"text/richtext":               "application/rtf",
+	"text/x-markdown":             "text/markdown",
+	"text/x-md":                   "text/markdown",
+	"application/x-markdown":      "text/markdown",
+	"application/xhtml+xml":       "text/html",
+	"text/json":                   "application/json",
+	"application/x-json":          "application/json",
+	"application/csv":             "text/csv",
+	"text/comma-separated-values": "text/csv",
+	"text/x-csv":                  "text/csv",
+	"application/x-msword":        "application/msword",
+	"application/doc":             "application/msword",
+	"application/x-epub+zip":      "application/epub+zip",
+}
+
+// contentTypeAliases merges configured aliases over the built-in ones, normalizing both
+// sides so they match normalized content types
+func contentTypeAliases(configured map[string]string) map[string]string {
+	aliases := make(map[string]string, len(BuiltinContentTypeAliases)+len(configured))
+	for alias, contentType := range BuiltinContentTypeAliases {
+		aliases[alias] = contentType
+	}
+	for alias, contentType := range configured {
+		aliases[normalizeContentType(alias)] = normalizeContentType(contentType)
+	}
+	return aliases
+}
+
+// resolveContentType normalizes a content type and maps known aliases to the content type
+// their extractor is registered under
+func (r *ExtractionRouter) resolveContentType(contentType string) string {
+	contentType = normalizeContentType(contentType)
+	if canonical, ok := r.aliases[contentType]; ok {
+		return canonical
+	}
+	return contentType
+}
+
 // registerExtractors registers all format-specific extractors
 func (r *ExtractionRouter) registerExtractors() {
 	// Text formats
@@ -49,7 +103,7 @@ func (r *ExtractionRouter) registerExtractors() {
 	})
 
 	// Markdown
-	markdownExtractor := NewMarkdownExtractor()
+	markdownExtractor := NewMarkdownExtractor(r.config.MarkdownPreserveStructure)
 	r.Register("text/markdown", markdownExtractor, FormatInfo{
 		Name:       "Markdown",
 		Extensions: []string{".md", ".markdown"},
@@ -76,39 +130,54 @@ func (r *ExtractionRouter) registerExtractors() {
 	})
 
 	// CSV
-	csvExtractor := NewCSVExtractor()
+	csvExtractor := NewCSVExtractor(r.config.MaxSpreadsheetRows, r.config.FailOnSpreadsheetRowLimit)
 	r.Register("text/csv", csvExtractor, FormatInfo{
 		Name:       "CSV",
 		Extensions: []string{".csv"},
 		MimeType:   "text/csv",
 		Extractor:  "CSVExtractor",
+		MaxRows:    r.config.MaxSpreadsheetRows,
 	})
 
 	// PDF
-	pdfExtractor := NewPDFExtractor()
+	pdfExtractor := NewPDFExtractor(r.config.ExtractFootnotes)
 	r.Register("application/pdf", pdfExtractor, FormatInfo{
 		Name:       "PDF Document",
 		Extensions: []string{".pdf"},
 		MimeType:   "application/pdf",
 		Extractor:  "PDFExtractor",
+
+		Capabilities: FormatCapabilities{Footnotes: r.config.ExtractFootnotes},
 	})
 
 	// Microsoft Office - Word
-	docxExtractor := NewDocxExtractor()
+	docxExtractor := NewDocxExtractor(r.config.ExtractFootnotes)
 	r.Register("application/vnd.openxmlformats-officedocument.wordprocessingml.document", docxExtractor, FormatInfo{
 		Name:       "Word Document",
 		Extensions: []string{".docx"},
 		MimeType:   "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
 		Extractor:  "DocxExtractor",
+
+		Capabilities: FormatCapabilities{Footnotes: r.config.ExtractFootnotes},
+	})
+
+	// Microsoft Office - Word 97-2003
+	docExtractor := NewDocExtractor()
+	r.Register("application/msword", docExtractor, FormatInfo{
+		Name:       "Word 97-2003 Document",
+		Extensions: []string{".doc"},
+		MimeType:   "application/msword",
+		Extractor:  "DocExtractor",
 	})
 
 	// Microsoft Office - Excel
-	xlsxExtractor := NewXlsxExtractor()
+	xlsxExtractor := NewXlsxExtractor(r.config.MaxSpreadsheetRows, r.config.FailOnSpreadsheetRowLimit)
 	r.Register("application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", xlsxExtractor, FormatInfo{
 		Name:       "Excel Spreadsheet",
 		Extensions: []string{".xlsx"},
 		MimeType:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
 		Extractor:  "XlsxExtractor",
+		MaxRows:    r.config.MaxSpreadsheetRows,
 	})
 
 	// Microsoft Office - PowerPoint
@@ -146,26 +215,96 @@ func (r *ExtractionRouter) registerExtractors() {
 	})
 }
 
-// Register adds a format-specific extractor
+// applyFormatPolicy unregisters formats excluded by EnabledFormats or DisabledFormats.
+// Entries that match no registered format are logged, since they are likely typos.
+func (r *ExtractionRouter) applyFormatPolicy() {
+	enabled := r.config.EnabledFormats
+	disabled := r.config.DisabledFormats
+	if len(enabled) == 0 && len(disabled) == 0 {
+		return
+	}
+
+	for _, entry := range append(append([]string{}, enabled...), disabled...) {
+		if !r.anyFormatMatches(entry) {
+			fmt.Printf("[EXTRACTION] Warning: format %q in extraction format policy matches no known format\n", entry)
+		}
+	}
+
+	for contentType, info := range r.formats {
+		allowed := len(enabled) == 0 || formatMatchesAny(info, enabled)
+		if !allowed || formatMatchesAny(info, disabled) {
+			delete(r.extractors, contentType)
+			delete(r.formats, contentType)
+		}
+	}
+}
+
+// anyFormatMatches reports whether a policy entry matches a registered format
+func (r *ExtractionRouter) anyFormatMatches(entry string) bool {
+	for _, info := range r.formats {
+		if formatMatchesAny(info, []string{entry}) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatMatchesAny reports whether a format's MIME type or one of its extensions is in entries
+func formatMatchesAny(info FormatInfo, entries []string) bool {
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == info.MimeType {
+			return true
+		}
+		for _, ext := range info.Extensions {
+			if entry == ext || "."+entry == ext {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Register adds a format-specific extractor. The capabilities the extractor implements
+// (metadata, links when enabled) and the configured limits are added to info.
 func (r *ExtractionRouter) Register(contentType string, extractor Extractor, info FormatInfo) {
+	if _, ok := extractor.(MetadataExtractor); ok {
+		info.Capabilities.Metadata = true
+	}
+	if _, ok := extractor.(LinkExtractor); ok && r.config.ExtractLinks {
+		info.Capabilities.Links = true
+	}
+	info.MaxFileSizeBytes = r.config.MaxFileSize
+	info.MaxTimeoutSeconds = int(r.calculateTimeout(r.config.MaxFileSize).Seconds())
+
 	r.extractors[contentType] = extractor
 	r.formats[contentType] = info
 }
 
 // Extract routes the extraction request to the appropriate extractor
 func (r *ExtractionRouter) Extract(ctx context.Context, data []byte, contentType string) (string, error) {
+	result, err := r.ExtractWithMetadata(ctx, data, contentType)
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
+
+// ExtractWithMetadata routes the extraction request to the appropriate extractor and
+// returns structured metadata alongside the text when the extractor provides it
+func (r *ExtractionRouter) ExtractWithMetadata(ctx context.Context, data []byte, contentType string) (ExtractionResult, error) {
 	// Validate file size
 	fileSize := int64(len(data))
 	if fileSize == 0 {
-		return "", WrapEmptyFile(contentType, "")
+		return ExtractionResult{}, WrapEmptyFile(contentType, "")
 	}
 
 	if fileSize > r.config.MaxFileSize {
-		return "", WrapFileTooLarge(contentType, fileSize, "", r.config.MaxFileSize)
+		return ExtractionResult{}, WrapFileTooLarge(contentType, fileSize, "", r.config.MaxFileSize)
 	}
 
-	// Normalize content type (remove parameters like charset)
-	contentType = normalizeContentType(contentType)
+	// Normalize content type (remove parameters like charset) and resolve aliases
+	contentType = r.resolveContentType(contentType)
 
 	// Log extraction start
 	r.logger.LogExtractionStart(contentType, fileSize)
@@ -176,7 +315,7 @@ func (r *ExtractionRouter) Extract(ctx context.Context, data []byte, contentType
 	if !exists {
 		err := WrapUnsupportedFormat(contentType, fileSize, r.SupportedFormats())
 		r.logger.LogExtractionFailure(contentType, fileSize, time.Since(startTime), err)
-		return "", err
+		return ExtractionResult{}, err
 	}
 
 	// Calculate timeout based on file size
@@ -186,11 +325,35 @@ func (r *ExtractionRouter) Extract(ctx context.Context, data []byte, contentType
 	extractCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	// Cap bytes decompressed from archive-based formats (zip bomb protection)
+	extractCtx = withDecompressionBudget(extractCtx, r.config)
+
 	// Execute extraction with queue management for concurrency control
+	var metadata map[string]any
 	text, err := r.queue.Execute(extractCtx, func() (string, error) {
 		// Extract text with memory monitoring
 		return extractWithMemoryLimit(extractCtx, r.config.MaxMemoryPerFile, func() (string, error) {
-			return extractor.Extract(extractCtx, data)
+			var text string
+			var err error
+			if metadataExtractor, ok := extractor.(MetadataExtractor); ok {
+				var result ExtractionResult
+				result, err = metadataExtractor.ExtractWithMetadata(extractCtx, data)
+				text, metadata = result.Text, result.Metadata
+			} else {
+				text, err = extractor.Extract(extractCtx, data)
+			}
+			if err != nil {
+				return text, err
+			}
+
+			text, links := r.appendExtractedLinks(extractCtx, extractor, data, text)
+			if len(links) > 0 {
+				if metadata == nil {
+					metadata = make(map[string]any)
+				}
+				metadata["links"] = links
+			}
+			return text, nil
 		})
 	})
 
@@ -215,17 +378,44 @@ func (r *ExtractionRouter) Extract(ctx context.Context, data []byte, contentType
 		if extractCtx.Err() == context.DeadlineExceeded {
 			r.logger.LogExtractionTimeout(contentType, fileSize, timeout)
 			wrappedErr := WrapExtractionTimeout(contentType, fileSize, "", timeout.String())
-			return "", wrappedErr
+			return ExtractionResult{}, wrappedErr
+		}
+		if errors.Is(err, ErrExtractionBusy) {
+			r.logger.LogExtractionFailure(contentType, fileSize, duration, err)
+			return ExtractionResult{}, WrapExtractionBusy(contentType, fileSize, "", r.queue.GetMetrics().QueuedExtractions)
 		}
 		r.logger.LogExtractionFailure(contentType, fileSize, duration, err)
 
+		if errors.Is(err, ErrMemoryLimit) {
+			return ExtractionResult{}, WrapMemoryLimit(contentType, fileSize, "")
+		}
+		if errors.Is(err, ErrPasswordProtected) {
+			return ExtractionResult{}, WrapPasswordProtected(contentType, fileSize, "")
+		}
+		if errors.Is(err, ErrSpreadsheetTooLarge) {
+			return ExtractionResult{}, WrapSpreadsheetTooLarge(contentType, fileSize, "", r.config.MaxSpreadsheetRows)
+		}
+
 		// Wrap the error with context
 		wrappedErr := WrapGenericExtractionError(contentType, fileSize, "", err)
-		return "", wrappedErr
+		return ExtractionResult{}, wrappedErr
 	}
 
 	r.logger.LogExtractionSuccess(contentType, fileSize, duration, len(text))
-	return text, nil
+
+	if originalLength := len(text); r.config.MaxExtractedTextBytes > 0 && originalLength > r.config.MaxExtractedTextBytes {
+		text = truncateText(text, r.config.MaxExtractedTextBytes)
+		r.logger.LogTextTruncated(contentType, originalLength, r.config.MaxExtractedTextBytes)
+
+		// Flag the truncation so it is stored with the document's metadata
+		if metadata == nil {
+			metadata = make(map[string]any)
+		}
+		metadata["truncated"] = true
+		metadata["extractedTextBytes"] = originalLength
+	}
+
+	return ExtractionResult{Text: text, Metadata: metadata}, nil
 }
 
 // ExtractWithValidation extracts text with format validation
@@ -240,8 +430,8 @@ func (r *ExtractionRouter) ExtractWithValidation(ctx context.Context, data []byt
 		return "", WrapFileTooLarge(contentType, fileSize, filename, r.config.MaxFileSize)
 	}
 
-	// Normalize content type (remove parameters like charset)
-	contentType = normalizeContentType(contentType)
+	// Normalize content type (remove parameters like charset) and resolve aliases
+	contentType = r.resolveContentType(contentType)
 
 	// Log extraction start
 	r.logger.LogExtractionStart(contentType, fileSize)
@@ -272,11 +462,20 @@ func (r *ExtractionRouter) ExtractWithValidation(ctx context.Context, data []byt
 	extractCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	// Cap bytes decompressed from archive-based formats (zip bomb protection)
+	extractCtx = withDecompressionBudget(extractCtx, r.config)
+
 	// Execute extraction with queue management for concurrency control
 	text, err := r.queue.Execute(extractCtx, func() (string, error) {
 		// Extract text with memory monitoring
 		return extractWithMemoryLimit(extractCtx, r.config.MaxMemoryPerFile, func() (string, error) {
-			return extractor.Extract(extractCtx, data)
+			text, err := extractor.Extract(extractCtx, data)
+			if err != nil {
+				return text, err
+			}
+
+			text, _ = r.appendExtractedLinks(extractCtx, extractor, data, text)
+			return text, nil
 		})
 	})
 
@@ -303,24 +502,91 @@ func (r *ExtractionRouter) ExtractWithValidation(ctx context.Context, data []byt
 			wrappedErr := WrapExtractionTimeout(contentType, fileSize, filename, timeout.String())
 			return "", wrappedErr
 		}
+		if errors.Is(err, ErrExtractionBusy) {
+			r.logger.LogExtractionFailure(contentType, fileSize, duration, err)
+			return "", WrapExtractionBusy(contentType, fileSize, filename, r.queue.GetMetrics().QueuedExtractions)
+		}
 		r.logger.LogExtractionFailure(contentType, fileSize, duration, err)
 
+		if errors.Is(err, ErrMemoryLimit) {
+			return "", WrapMemoryLimit(contentType, fileSize, filename)
+		}
+		if errors.Is(err, ErrPasswordProtected) {
+			return "", WrapPasswordProtected(contentType, fileSize, filename)
+		}
+		if errors.Is(err, ErrSpreadsheetTooLarge) {
+			return "", WrapSpreadsheetTooLarge(contentType, fileSize, filename, r.config.MaxSpreadsheetRows)
+		}
+
 		// Wrap the error with context
 		wrappedErr := WrapGenericExtractionError(contentType, fileSize, filename, err)
 		return "", wrappedErr
 	}
 
 	r.logger.LogExtractionSuccess(contentType, fileSize, duration, len(text))
+
+	if originalLength := len(text); r.config.MaxExtractedTextBytes > 0 && originalLength > r.config.MaxExtractedTextBytes {
+		text = truncateText(text, r.config.MaxExtractedTextBytes)
+		r.logger.LogTextTruncated(contentType, originalLength, r.config.MaxExtractedTextBytes)
+	}
+
 	return text, nil
 }
 
+// appendExtractedLinks lists the document's hyperlinks after its text when link extraction
+// is enabled and the format supports it. Links are best-effort: if they can't be read, the
+// text is returned unchanged.
+func (r *ExtractionRouter) appendExtractedLinks(ctx context.Context, extractor Extractor, data []byte, text string) (string, []string) {
+	if !r.config.ExtractLinks {
+		return text, nil
+	}
+
+	linkExtractor, ok := extractor.(LinkExtractor)
+	if !ok {
+		return text, nil
+	}
+
+	links, err := linkExtractor.ExtractLinks(ctx, data)
+	if err != nil || len(links) == 0 {
+		return text, nil
+	}
+
+	return appendLinks(text, links), links
+}
+
 // IsSupported checks if a content type is supported
 func (r *ExtractionRouter) IsSupported(contentType string) bool {
-	contentType = normalizeContentType(contentType)
+	contentType = r.resolveContentType(contentType)
 	_, exists := r.extractors[contentType]
 	return exists
 }
 
+// CanonicalContentType normalizes a content type and maps known aliases, such as
+// application/x-pdf, to the content type their extractor is registered under
+func (r *ExtractionRouter) CanonicalContentType(contentType string) string {
+	return r.resolveContentType(contentType)
+}
+
+// Version combines ExtractorVersion with a bit for each enabled option that changes the
+// extracted text (links, footnotes and Markdown structure), so turning one on or off makes
+// documents extracted before stale. The option bits sit above the logic version, so with
+// every option off the version is ExtractorVersion itself.
+func (r *ExtractionRouter) Version() int {
+	version := ExtractorVersion
+	for i, enabled := range []bool{r.config.ExtractLinks, r.config.ExtractFootnotes, r.config.MarkdownPreserveStructure} {
+		if enabled {
+			version |= 1 << (16 + i)
+		}
+	}
+	return version
+}
+
+// MaxTextBytes returns the size extracted text is truncated to, so callers passing on
+// other forms of a document's content can apply the same cap (0 = unlimited)
+func (r *ExtractionRouter) MaxTextBytes() int {
+	return r.config.MaxExtractedTextBytes
+}
+
 // SupportedFormats returns a list of supported content types
 func (r *ExtractionRouter) SupportedFormats() []string {
 	formats := make([]string, 0, len(r.formats))
@@ -330,9 +596,25 @@ func (r *ExtractionRouter) SupportedFormats() []string {
 	return formats
 }
 
+// Formats returns the supported formats with their capabilities and limits, ordered by
+// name and then MIME type
+func (r *ExtractionRouter) Formats() []FormatInfo {
+	formats := make([]FormatInfo, 0, len(r.formats))
+	for _, info := range r.formats {
+		formats = append(formats, info)
+	}
+	sort.Slice(formats, func(i, j int) bool {
+		if formats[i].Name != formats[j].Name {
+			return formats[i].Name < formats[j].Name
+		}
+		return formats[i].MimeType < formats[j].MimeType
+	})
+	return formats
+}
+
 // GetFormatInfo returns information about a supported format
 func (r *ExtractionRouter) GetFormatInfo(contentType string) (FormatInfo, bool) {
-	contentType = normalizeContentType(contentType)
+	contentType = r.resolveContentType(contentType)
 	info, exists := r.formats[contentType]
 	return info, exists
 }
@@ -351,7 +633,7 @@ func (r *ExtractionRouter) GetStats() ExtractionStats {
 
 // SetLoggingEnabled enables or disables logging
 func (r *ExtractionRouter) SetLoggingEnabled(enabled bool) {
-	r.logger.enabled = enabled
+	r.logger.SetEnabled(enabled)
 }
 
 // calculateTimeout determines the extraction timeout based on file size
@@ -380,6 +662,47 @@ func (r *ExtractionRouter) calculateTimeout(fileSize int64) time.Duration {
 	return scaledTimeout
 }
 
+// truncationMarker is appended to text cut by truncateText
+const truncationMarker = "\n\n[Text truncated: the document exceeds the maximum extracted text size]"
+
+// truncateText cuts text to at most maxBytes including the truncation marker, preferring
+// the end of a sentence, then a word, within the last quarter of the allowed length
+func truncateText(text string, maxBytes int) string {
+	limit := maxBytes - len(truncationMarker)
+	if limit <= 0 {
+		return truncationMarker[:maxBytes]
+	}
+
+	// Back up to a rune boundary so multi-byte characters aren't split
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	cut := text[:limit]
+
+	minCut := limit * 3 / 4
+	if i := lastSentenceEnd(cut); i >= minCut {
+		cut = cut[:i]
+	} else if i := strings.LastIndexAny(cut, " \t\n"); i >= minCut {
+		cut = cut[:i]
+	}
+
+	return strings.TrimRight(cut, " \t\n") + truncationMarker
+}
+
+// lastSentenceEnd returns the index just past the last sentence-ending punctuation
+// followed by whitespace in text, or -1 if there is none
+func lastSentenceEnd(text string) int {
+	for i := len(text) - 2; i >= 0; i-- {
+		switch text[i] {
+		case '.', '!', '?':
+			if next := text[i+1]; next == ' ' || next == '\n' || next == '\t' {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
 // normalizeContentType removes parameters from content type
 func normalizeContentType(contentType string) string {
 	// Remove charset and other parameters
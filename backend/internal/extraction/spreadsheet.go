@@ -0,0 +1,28 @@
+package extraction
+
+import "fmt"
+
+// spreadsheetRowLimit caps the number of rows the CSV and XLSX extractors read. Past the
+// cap they either stop with a truncation notice or fail with ErrSpreadsheetTooLarge.
+type spreadsheetRowLimit struct {
+	maxRows     int // 0 = unlimited
+	failOnLimit bool
+}
+
+// reached reports whether rows is already at the cap, so the next row would exceed it
+func (l spreadsheetRowLimit) reached(rows int) bool {
+	return l.maxRows > 0 && rows >= l.maxRows
+}
+
+// exceededError returns the error for a spreadsheet over the cap, or nil if it should be truncated
+func (l spreadsheetRowLimit) exceededError() error {
+	if !l.failOnLimit {
+		return nil
+	}
+	return fmt.Errorf("%w: more than %d rows", ErrSpreadsheetTooLarge, l.maxRows)
+}
+
+// truncationNotice is appended to the text of a spreadsheet cut off at the cap
+func (l spreadsheetRowLimit) truncationNotice() string {
+	return fmt.Sprintf("[Truncated: the spreadsheet has more than %d rows; only the first %d rows were extracted]", l.maxRows, l.maxRows)
+}
@@ -1,7 +1,9 @@
 package extraction
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"strings"
 	"unicode/utf8"
 
@@ -41,22 +43,75 @@ func (e *PlainTextExtractor) Extract(ctx context.Context, data []byte) (string,
 	return text, nil
 }
 
-// ensureUTF8 converts text to UTF-8 if it's not already
+// ensureUTF8 converts text to UTF-8 if it's not already.
+// A UTF-8 or UTF-16 byte order mark takes precedence and is stripped; BOM-less
+// UTF-16 (common in files exported from Windows) is recognised by its null bytes.
 func ensureUTF8(data []byte) (string, error) {
+	// Decode according to the byte order mark, if any
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return strings.ToValidUTF8(string(data[3:]), "�"), nil
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return decodeUTF16(data, unicode.LittleEndian)
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return decodeUTF16(data, unicode.BigEndian)
+	}
+
+	// ASCII text encoded as UTF-16 is also valid UTF-8, so check for it first
+	if endianness, ok := detectUTF16(data); ok {
+		return decodeUTF16(data, endianness)
+	}
+
 	// Check if already valid UTF-8
 	if utf8.Valid(data) {
 		return string(data), nil
 	}
 
-	// Try to decode as UTF-16
-	decoder := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder()
+	// If all else fails, replace invalid UTF-8 sequences
+	return strings.ToValidUTF8(string(data), "�"), nil
+}
+
+// decodeUTF16 transcodes UTF-16 data to UTF-8, skipping a leading byte order mark
+func decodeUTF16(data []byte, endianness unicode.Endianness) (string, error) {
+	decoder := unicode.UTF16(endianness, unicode.UseBOM).NewDecoder()
 	result, _, err := transform.Bytes(decoder, data)
-	if err == nil && utf8.Valid(result) {
-		return string(result), nil
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid UTF-16 text - %v", ErrCorruptedFile, err)
 	}
 
-	// If all else fails, replace invalid UTF-8 sequences
-	return strings.ToValidUTF8(string(data), "�"), nil
+	return string(result), nil
+}
+
+// detectUTF16 guesses whether BOM-less data is UTF-16 by where its null bytes fall.
+// Mostly-Latin text in UTF-16 has a null in every other byte; genuine UTF-8 text has none.
+func detectUTF16(data []byte) (unicode.Endianness, bool) {
+	sample := data[:min(len(data), 4096)]
+	if len(sample) < 2 {
+		return unicode.LittleEndian, false
+	}
+
+	var evenNulls, oddNulls int
+	for i, b := range sample {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenNulls++
+		} else {
+			oddNulls++
+		}
+	}
+
+	// Require nulls in at least a third of the code units, all on the same side
+	threshold := len(sample) / 2 / 3
+	switch {
+	case oddNulls > threshold && evenNulls == 0:
+		return unicode.LittleEndian, true
+	case evenNulls > threshold && oddNulls == 0:
+		return unicode.BigEndian, true
+	}
+
+	return unicode.LittleEndian, false
 }
 
 // normalizeLineEndings converts all line endings to \n
@@ -1,8 +1,11 @@
 package extraction
 
 import (
+	"archive/zip"
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"path/filepath"
 	"strings"
 )
@@ -19,21 +22,40 @@ var fileSignatures = []FileSignature{
 	// PDF
 	{Offset: 0, Signature: []byte("%PDF-"), MimeType: "application/pdf"},
 
+	// PostScript, which net/http doesn't recognize
+	{Offset: 0, Signature: []byte("%!PS"), MimeType: "application/postscript"},
+
 	// ZIP-based formats (DOCX, XLSX, PPTX, EPUB)
 	{Offset: 0, Signature: []byte("PK\x03\x04"), MimeType: "application/zip"},
 
+	// OLE compound files (legacy Office formats such as DOC, and encrypted DOCX, XLSX, PPTX)
+	{Offset: 0, Signature: oleSignature, MimeType: "application/x-ole-storage"},
+
+	// Other archives, which net/http doesn't recognize
+	{Offset: 0, Signature: []byte("7z\xBC\xAF\x27\x1C"), MimeType: "application/x-7z-compressed"},
+
 	// RTF
 	{Offset: 0, Signature: []byte("{\\rtf"), MimeType: "application/rtf"},
 
 	// HTML
 	{Offset: 0, Signature: []byte("<!DOCTYPE html"), MimeType: "text/html"},
+	{Offset: 0, Signature: []byte("<!DOCTYPE HTML"), MimeType: "text/html"},
 	{Offset: 0, Signature: []byte("<!doctype html"), MimeType: "text/html"},
 	{Offset: 0, Signature: []byte("<html"), MimeType: "text/html"},
 	{Offset: 0, Signature: []byte("<HTML"), MimeType: "text/html"},
 
-	// JSON
-	{Offset: 0, Signature: []byte("{"), MimeType: "application/json"},
-	{Offset: 0, Signature: []byte("["), MimeType: "application/json"},
+	// JSON has no signature of its own; see looksLikeJSON
+}
+
+// utf8BOM is the byte order mark some editors write at the start of UTF-8 text files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// zipBasedFormats maps the extensions of ZIP-based formats to their content types
+var zipBasedFormats = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".epub": "application/epub+zip",
 }
 
 // ValidateFormat validates that the file extension matches the actual file content
@@ -49,8 +71,26 @@ func ValidateFormat(data []byte, filename string, declaredContentType string) er
 	detectedContentType = normalizeContentType(detectedContentType)
 
 	// Special handling for ZIP-based formats
-	if detectedContentType == "application/zip" {
-		return validateZipBasedFormat(data, ext, declaredContentType)
+	if detectedContentType == "application/zip" || isZipBasedContentType(detectedContentType) {
+		return validateZipBasedFormat(data, ext, declaredContentType, detectedContentType)
+	}
+
+	// Encrypted OOXML documents are OLE containers; let the extractor report them as password-protected
+	if detectedContentType == "application/x-ole-storage" && isOOXMLExtension(ext) {
+		return nil
+	}
+
+	// Legacy Word documents are OLE containers
+	if detectedContentType == "application/x-ole-storage" && ext == ".doc" {
+		if declaredContentType != "" && declaredContentType != "application/msword" {
+			return fmt.Errorf("file extension %s does not match declared content type %s", ext, declaredContentType)
+		}
+		return nil
+	}
+	if ext == ".doc" || declaredContentType == "application/msword" {
+		if detectedContentType != "application/x-ole-storage" {
+			return fmt.Errorf("file extension %s suggests a Word 97-2003 document, but file is not an OLE compound file", ext)
+		}
 	}
 
 	// For non-ZIP formats, check if detected type matches declared type
@@ -68,21 +108,45 @@ func ValidateFormat(data []byte, filename string, declaredContentType string) er
 	return nil
 }
 
-// DetectContentType detects the content type from file header (magic number)
+// DetectContentType detects the content type from the file's content. The signals are
+// tried from most to least specific, and the first that recognizes the data wins:
+//
+//  1. The signature table (magic numbers), after skipping a UTF-8 byte order mark. ZIP
+//     archives are narrowed to DOCX, XLSX, PPTX or EPUB by the entries they contain.
+//  2. JSON, which is only detected when the whole file parses as a JSON object or array.
+//  3. net/http's sniffing, for binary formats the table doesn't list (images, audio,
+//     video, fonts, compressed files). Its text results are ignored: it only knows a few
+//     text formats and calls anything else text/plain, which step 4 decides more carefully.
+//  4. The plain text heuristic.
+//
+// It returns "" if none of them recognizes the data.
 func DetectContentType(data []byte) string {
 	if len(data) == 0 {
 		return ""
 	}
 
 	// Check against known signatures
+	text := bytes.TrimPrefix(data, utf8BOM)
 	for _, sig := range fileSignatures {
-		if len(data) >= sig.Offset+len(sig.Signature) {
-			if bytes.Equal(data[sig.Offset:sig.Offset+len(sig.Signature)], sig.Signature) {
+		if len(text) >= sig.Offset+len(sig.Signature) {
+			if bytes.Equal(text[sig.Offset:sig.Offset+len(sig.Signature)], sig.Signature) {
+				if sig.MimeType == "application/zip" {
+					return detectZipBasedType(data)
+				}
 				return sig.MimeType
 			}
 		}
 	}
 
+	if looksLikeJSON(text) {
+		return "application/json"
+	}
+
+	// Fall back to net/http for binary formats
+	if sniffed := normalizeContentType(http.DetectContentType(data)); sniffed != "application/octet-stream" && !strings.HasPrefix(sniffed, "text/") {
+		return sniffed
+	}
+
 	// Check if it's plain text (all printable ASCII or UTF-8)
 	if isPlainText(data) {
 		return "text/plain"
@@ -91,16 +155,63 @@ func DetectContentType(data []byte) string {
 	return ""
 }
 
-// validateZipBasedFormat validates ZIP-based formats (DOCX, XLSX, PPTX, EPUB)
-func validateZipBasedFormat(data []byte, ext string, declaredContentType string) error {
-	// Map extensions to their expected content types
-	zipBasedFormats := map[string]string{
-		".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
-		".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
-		".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
-		".epub": "application/epub+zip",
+// detectZipBasedType identifies the format of a ZIP archive from the entries it contains,
+// returning application/zip if it is not (or can't be read as) DOCX, XLSX, PPTX or EPUB
+func detectZipBasedType(data []byte) string {
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "application/zip"
+	}
+
+	for _, file := range zipReader.File {
+		switch file.Name {
+		case "word/document.xml":
+			return zipBasedFormats[".docx"]
+		case "xl/workbook.xml":
+			return zipBasedFormats[".xlsx"]
+		case "ppt/presentation.xml":
+			return zipBasedFormats[".pptx"]
+		case "mimetype", "META-INF/container.xml":
+			// The mimetype entry is optional in practice, but every EPUB has a container
+			return zipBasedFormats[".epub"]
+		}
 	}
 
+	return "application/zip"
+}
+
+// looksLikeJSON reports whether data is a JSON object or array. A leading brace alone
+// isn't enough: plenty of text files start with one without being JSON.
+func looksLikeJSON(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return false
+	}
+	return json.Valid(trimmed)
+}
+
+// isZipBasedContentType checks if the content type is one of the ZIP-based formats
+func isZipBasedContentType(contentType string) bool {
+	for _, zipContentType := range zipBasedFormats {
+		if contentType == zipContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// isOOXMLExtension checks if the extension belongs to an Office Open XML format
+func isOOXMLExtension(ext string) bool {
+	switch ext {
+	case ".docx", ".xlsx", ".pptx":
+		return true
+	}
+	return false
+}
+
+// validateZipBasedFormat validates ZIP-based formats (DOCX, XLSX, PPTX, EPUB). detectedContentType
+// is application/zip when the archive's entries didn't identify its format.
+func validateZipBasedFormat(data []byte, ext string, declaredContentType string, detectedContentType string) error {
 	expectedContentType, isZipBased := zipBasedFormats[ext]
 
 	if isZipBased {
@@ -114,6 +225,11 @@ func validateZipBasedFormat(data []byte, ext string, declaredContentType string)
 			return fmt.Errorf("file extension %s does not match declared content type %s", ext, declaredContentType)
 		}
 
+		// Catch e.g. a spreadsheet renamed to .docx
+		if detectedContentType != "application/zip" && detectedContentType != expectedContentType {
+			return fmt.Errorf("file extension %s does not match content type (expected %s, detected %s)", ext, expectedContentType, detectedContentType)
+		}
+
 		return nil
 	}
 
@@ -122,6 +238,10 @@ func validateZipBasedFormat(data []byte, ext string, declaredContentType string)
 		return fmt.Errorf("file appears to be a ZIP archive but has unexpected extension %s", ext)
 	}
 
+	if detectedContentType != "application/zip" {
+		return fmt.Errorf("file extension %s does not match content type (detected %s)", ext, detectedContentType)
+	}
+
 	return nil
 }
 
@@ -144,19 +264,30 @@ func isCompatibleContentType(detected, declared string) bool {
 		return true
 	}
 
+	// Plain text is the weakest signal: any text-based format looks like it
+	if detected == "text/plain" && (strings.HasPrefix(declared, "text/") || declared == "application/json") {
+		return true
+	}
+
 	// JSON can be application/json or text/json
 	if (detected == "application/json" || detected == "text/json") &&
 		(declared == "application/json" || declared == "text/json") {
 		return true
 	}
 
+	// JSON is still text, so a .txt file that happens to hold JSON is fine
+	if (detected == "application/json" || detected == "text/json") && declared == "text/plain" {
+		return true
+	}
+
 	return false
 }
 
 // isValidExtensionForContentType checks if an extension is valid for a content type
 func isValidExtensionForContentType(ext, contentType string) bool {
 	validExtensions := map[string][]string{
-		"application/pdf": {".pdf"},
+		"application/pdf":    {".pdf"},
+		"application/msword": {".doc"},
 		"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   {".docx"},
 		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         {".xlsx"},
 		"application/vnd.openxmlformats-officedocument.presentationml.presentation": {".pptx"},
@@ -217,6 +348,7 @@ func GetExpectedContentType(filename string) string {
 
 	extensionToContentType := map[string]string{
 		".pdf":      "application/pdf",
+		".doc":      "application/msword",
 		".docx":     "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
 		".xlsx":     "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
 		".pptx":     "application/vnd.openxmlformats-officedocument.presentationml.presentation",
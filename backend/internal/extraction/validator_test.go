@@ -0,0 +1,74 @@
+package extraction
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+// zipWith returns a ZIP archive holding an empty entry for each name
+func zipWith(t *testing.T, names ...string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range names {
+		if _, err := w.Create(name); err != nil {
+			t.Fatalf("failed to add %s to zip: %v", name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to write zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		// JSON vs text
+		{name: "JSON object", data: []byte(`{"name": "orgmind", "tags": ["a", "b"]}`), want: "application/json"},
+		{name: "JSON array", data: []byte("  [1, 2, 3]\n"), want: "application/json"},
+		{name: "JSON with byte order mark", data: append([]byte{0xEF, 0xBB, 0xBF}, `{"a": 1}`...), want: "application/json"},
+		{name: "text starting with a brace", data: []byte("{draft} notes for the meeting"), want: "text/plain"},
+		{name: "truncated JSON", data: []byte(`{"name": "orgmind"`), want: "text/plain"},
+		{name: "JSON scalar", data: []byte(`"just a string"`), want: "text/plain"},
+		{name: "plain text", data: []byte("Meeting notes\nNothing to report."), want: "text/plain"},
+
+		// HTML vs XML
+		{name: "HTML doctype", data: []byte("<!DOCTYPE html><html><body>Hi</body></html>"), want: "text/html"},
+		{name: "lowercase HTML doctype", data: []byte("<!doctype html><p>Hi</p>"), want: "text/html"},
+		{name: "HTML without doctype", data: []byte("<html><body>Hi</body></html>"), want: "text/html"},
+		{name: "HTML with byte order mark", data: append([]byte{0xEF, 0xBB, 0xBF}, "<html></html>"...), want: "text/html"},
+		{name: "XML", data: []byte(`<?xml version="1.0"?><note><to>Team</to></note>`), want: "text/plain"},
+		{name: "XHTML with XML prolog", data: []byte(`<?xml version="1.0"?><html xmlns="http://www.w3.org/1999/xhtml"></html>`), want: "text/plain"},
+
+		// ZIP containers
+		{name: "DOCX", data: zipWith(t, "[Content_Types].xml", "word/document.xml"), want: zipBasedFormats[".docx"]},
+		{name: "XLSX", data: zipWith(t, "[Content_Types].xml", "xl/workbook.xml"), want: zipBasedFormats[".xlsx"]},
+		{name: "PPTX", data: zipWith(t, "[Content_Types].xml", "ppt/presentation.xml"), want: zipBasedFormats[".pptx"]},
+		{name: "EPUB", data: zipWith(t, "mimetype", "META-INF/container.xml"), want: zipBasedFormats[".epub"]},
+		{name: "EPUB without mimetype entry", data: zipWith(t, "META-INF/container.xml", "OEBPS/content.opf"), want: zipBasedFormats[".epub"]},
+		{name: "plain zip", data: zipWith(t, "readme.txt"), want: "application/zip"},
+		{name: "unreadable zip", data: []byte("PK\x03\x04 not really a zip"), want: "application/zip"},
+
+		// Signatures and fallbacks
+		{name: "PDF", data: []byte("%PDF-1.7\n"), want: "application/pdf"},
+		{name: "RTF", data: []byte(`{\rtf1\ansi Hello}`), want: "application/rtf"},
+		{name: "OLE compound file", data: append(append([]byte{}, oleSignature...), make([]byte, 16)...), want: "application/x-ole-storage"},
+		{name: "PNG", data: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), want: "image/png"},
+		{name: "binary", data: []byte{0x00, 0x01, 0x02, 0xFF, 0xFE, 0x00, 0x10}, want: ""},
+		{name: "empty", data: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectContentType(tt.data); got != tt.want {
+				t.Errorf("DetectContentType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
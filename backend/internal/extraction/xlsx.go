@@ -1,6 +1,7 @@
 package extraction
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"fmt"
@@ -10,11 +11,15 @@ import (
 )
 
 // XlsxExtractor handles .xlsx document extraction
-type XlsxExtractor struct{}
+type XlsxExtractor struct {
+	rowLimit spreadsheetRowLimit
+}
 
-// NewXlsxExtractor creates a new .xlsx extractor
-func NewXlsxExtractor() *XlsxExtractor {
-	return &XlsxExtractor{}
+// NewXlsxExtractor creates a new .xlsx extractor. Workbooks with more than maxRows non-empty
+// rows across all sheets (0 = unlimited) are truncated with a notice, or rejected when
+// failOnRowLimit is set.
+func NewXlsxExtractor(maxRows int, failOnRowLimit bool) *XlsxExtractor {
+	return &XlsxExtractor{rowLimit: spreadsheetRowLimit{maxRows: maxRows, failOnLimit: failOnRowLimit}}
 }
 
 // Extract extracts text from .xlsx files
@@ -31,11 +36,23 @@ func (e *XlsxExtractor) Extract(ctx context.Context, data []byte) (string, error
 		return "", fmt.Errorf("%w: file too small to be a valid .xlsx document", ErrCorruptedFile)
 	}
 
+	// Encrypted Office documents are OLE compound files rather than ZIP archives
+	if isOLECompoundFile(data) {
+		return "", fmt.Errorf("%w: .xlsx file is encrypted", ErrPasswordProtected)
+	}
+
 	// Check for ZIP magic number (xlsx is a ZIP archive)
 	if !bytes.HasPrefix(data, []byte("PK")) {
 		return "", fmt.Errorf("%w: invalid .xlsx header - file may be corrupted or not a .xlsx", ErrCorruptedFile)
 	}
 
+	// Reject archives that exceed the decompression limits before parsing
+	if zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err == nil {
+		if err := checkArchiveLimits(ctx, zipReader); err != nil {
+			return "", err
+		}
+	}
+
 	// Create a reader from the byte slice
 	reader := bytes.NewReader(data)
 
@@ -69,8 +86,15 @@ func (e *XlsxExtractor) Extract(ctx context.Context, data []byte) (string, error
 		return "", nil // Empty workbook is valid
 	}
 
-	// Extract text from all sheets
+	// Extract text from all sheets, counting non-empty rows against the row cap
+	rowCount := 0
+	truncated := false
+
 	for sheetIndex, sheetName := range sheetNames {
+		if truncated {
+			break
+		}
+
 		// Check for context cancellation between sheets
 		select {
 		case <-ctx.Done():
@@ -117,17 +141,32 @@ func (e *XlsxExtractor) Extract(ctx context.Context, data []byte) (string, error
 
 			// Add row text if it has content
 			if hasContent {
+				// Stop at the row cap, or reject the workbook if so configured
+				if e.rowLimit.reached(rowCount) {
+					if err := e.rowLimit.exceededError(); err != nil {
+						return "", err
+					}
+					truncated = true
+					break
+				}
+				rowCount++
+
 				result.WriteString(rowText.String())
 				result.WriteString("\n")
 			}
 		}
 
 		// Add spacing between sheets
-		if sheetIndex < len(sheetNames)-1 {
+		if sheetIndex < len(sheetNames)-1 && !truncated {
 			result.WriteString("\n")
 		}
 	}
 
+	if truncated {
+		result.WriteString("\n")
+		result.WriteString(e.rowLimit.truncationNotice())
+	}
+
 	// Get the extracted text
 	text := result.String()
 
@@ -141,3 +180,23 @@ func (e *XlsxExtractor) Extract(ctx context.Context, data []byte) (string, error
 
 	return text, nil
 }
+
+// ExtractWithMetadata extracts text, the document properties and the sheet names from .xlsx files
+func (e *XlsxExtractor) ExtractWithMetadata(ctx context.Context, data []byte) (ExtractionResult, error) {
+	text, err := e.Extract(ctx, data)
+	if err != nil {
+		return ExtractionResult{Text: text}, err
+	}
+
+	metadata := readOOXMLMetadata(ctx, data)
+
+	// Sheet names are useful context for spreadsheets; skip them if the workbook can't be reopened
+	if file, err := excelize.OpenReader(bytes.NewReader(data)); err == nil {
+		sheetNames := file.GetSheetList()
+		metadata["sheetNames"] = sheetNames
+		metadata["sheetCount"] = len(sheetNames)
+		file.Close()
+	}
+
+	return ExtractionResult{Text: text, Metadata: metadata}, nil
+}
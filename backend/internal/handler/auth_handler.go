@@ -2,21 +2,30 @@ package handler
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"time"
 
+	"github.com/bipulkrdas/orgmind/backend/internal/middleware"
 	"github.com/bipulkrdas/orgmind/backend/internal/service"
+	"github.com/bipulkrdas/orgmind/backend/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
 // AuthHandler handles authentication-related HTTP requests
 type AuthHandler struct {
-	authService service.AuthService
+	authService   service.AuthService
+	exportService service.ExportService
+	jwtKeys       *utils.JWTKeys
 }
 
 // NewAuthHandler creates a new instance of AuthHandler
-func NewAuthHandler(authService service.AuthService) *AuthHandler {
+func NewAuthHandler(authService service.AuthService, exportService service.ExportService, jwtKeys *utils.JWTKeys) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
+		authService:   authService,
+		exportService: exportService,
+		jwtKeys:       jwtKeys,
 	}
 }
 
@@ -46,17 +55,21 @@ type UserResponse struct {
 func (h *AuthHandler) SignUp(c *gin.Context) {
 	var req SignUpRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
 		return
 	}
 
 	user, token, err := h.authService.SignUp(c.Request.Context(), req.Email, req.Password, req.FirstName, req.LastName)
 	if err != nil {
 		if errors.Is(err, service.ErrUserAlreadyExists) {
-			c.JSON(http.StatusConflict, gin.H{"error": "User with this email already exists"})
+			respondError(c, http.StatusConflict, CodeUserAlreadyExists, "User with this email already exists")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		if errors.Is(err, service.ErrEmailDomainNotAllowed) {
+			respondError(c, http.StatusForbidden, CodeEmailDomainNotAllowed, "Signups are restricted to approved email domains")
+			return
+		}
+		respondInternalError(c, "Failed to create user", err)
 		return
 	}
 
@@ -86,17 +99,17 @@ type SignInResponse struct {
 func (h *AuthHandler) SignIn(c *gin.Context) {
 	var req SignInRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
 		return
 	}
 
 	token, err := h.authService.SignIn(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
 		if errors.Is(err, service.ErrInvalidCredentials) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+			respondError(c, http.StatusUnauthorized, CodeInvalidCredentials, "Invalid email or password")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		respondInternalError(c, "Failed to sign in", err)
 		return
 	}
 
@@ -114,13 +127,13 @@ type ResetPasswordRequest struct {
 func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	var req ResetPasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
 		return
 	}
 
 	err := h.authService.ResetPassword(c.Request.Context(), req.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password reset"})
+		respondInternalError(c, "Failed to process password reset", err)
 		return
 	}
 
@@ -138,13 +151,17 @@ type UpdatePasswordRequest struct {
 func (h *AuthHandler) UpdatePassword(c *gin.Context) {
 	var req UpdatePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
 		return
 	}
 
 	err := h.authService.UpdatePassword(c.Request.Context(), req.Token, req.NewPassword)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if errors.Is(err, service.ErrInvalidResetToken) || errors.Is(err, service.ErrResetTokenUsed) || errors.Is(err, service.ErrResetTokenExpired) {
+			respondError(c, http.StatusBadRequest, CodeInvalidResetToken, err.Error())
+			return
+		}
+		respondInternalError(c, "Failed to update password", err)
 		return
 	}
 
@@ -158,10 +175,10 @@ func (h *AuthHandler) InitiateOAuth(c *gin.Context) {
 	authURL, err := h.authService.InitiateOAuth(c.Request.Context(), provider)
 	if err != nil {
 		if errors.Is(err, service.ErrUnsupportedProvider) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported OAuth provider"})
+			respondError(c, http.StatusBadRequest, CodeUnsupportedProvider, "Unsupported OAuth provider")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to initiate OAuth"})
+		respondInternalError(c, "Failed to initiate OAuth", err)
 		return
 	}
 
@@ -179,21 +196,25 @@ func (h *AuthHandler) HandleOAuthCallback(c *gin.Context) {
 
 	var req OAuthCallbackRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing authorization code"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Missing authorization code")
 		return
 	}
 
 	token, err := h.authService.HandleOAuthCallback(c.Request.Context(), provider, req.Code)
 	if err != nil {
 		if errors.Is(err, service.ErrUnsupportedProvider) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported OAuth provider"})
+			respondError(c, http.StatusBadRequest, CodeUnsupportedProvider, "Unsupported OAuth provider")
 			return
 		}
 		if errors.Is(err, service.ErrOAuthFailed) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "OAuth authentication failed"})
+			respondError(c, http.StatusUnauthorized, CodeOAuthFailed, "OAuth authentication failed")
+			return
+		}
+		if errors.Is(err, service.ErrEmailDomainNotAllowed) {
+			respondError(c, http.StatusForbidden, CodeEmailDomainNotAllowed, "Signups are restricted to approved email domains")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete OAuth"})
+		respondInternalError(c, "Failed to complete OAuth", err)
 		return
 	}
 
@@ -201,3 +222,81 @@ func (h *AuthHandler) HandleOAuthCallback(c *gin.Context) {
 		Token: token,
 	})
 }
+
+// DeleteAccountRequest represents the request body for account deletion.
+// Password is required for accounts that sign in with a password; OAuth-only accounts can
+// send no body at all.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// DeleteAccount handles DELETE /api/auth/me
+// Permanently deletes the authenticated user's account and data
+func (h *AuthHandler) DeleteAccount(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// An empty body leaves the password blank, which the service only accepts for accounts without one
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := h.authService.DeleteAccount(c.Request.Context(), userID, req.Password); err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			respondError(c, http.StatusUnauthorized, CodeInvalidCredentials, "Invalid password")
+			return
+		}
+		if errors.Is(err, service.ErrOwnsSharedGraphs) {
+			respondErrorWithDetails(c, http.StatusConflict, CodeOwnsSharedGraphs, "Account owns shared graphs", err.Error())
+			return
+		}
+		respondInternalError(c, "Failed to delete account", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deleted successfully"})
+}
+
+// ExportAccount handles GET /api/auth/me/export
+// Streams a ZIP archive of the authenticated user's data
+func (h *AuthHandler) ExportAccount(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Large exports outlast the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		fmt.Printf("Warning: failed to clear write deadline for export: %v\n", err)
+	}
+
+	filename := fmt.Sprintf("orgmind-export-%s.zip", time.Now().UTC().Format("2006-01-02"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := h.exportService.ExportUserData(c.Request.Context(), userID, c.Writer); err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			respondInternalError(c, "Failed to export account data", err)
+			return
+		}
+
+		// The archive is already partly sent, so the status can't change; the client sees a truncated ZIP
+		fmt.Printf("Error [request %s] exporting data for user %s: %v\n", middleware.GetRequestID(c), userID, err)
+		c.Abort()
+	}
+}
+
+// JWKS handles GET /.well-known/jwks.json
+// Publishes the RS256 public keys so other services can verify our tokens
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, h.jwtKeys.PublicKeys())
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// documentCacheControl lets clients cache documents but makes them revalidate every use,
+// since documents change and are only visible to graph members
+const documentCacheControl = "private, no-cache"
+
+// visualizationSettleTime is how long a graph must go without changes before its
+// visualization is cached, since Zep keeps building the graph from new data in the
+// background after a document is processed
+const visualizationSettleTime = 10 * time.Minute
+
+// checkNotModified sets the ETag and Last-Modified validators of a response and reports
+// whether the client's cached copy is still current, in which case it responds 304 Not
+// Modified. If-None-Match takes precedence over If-Modified-Since, as in RFC 9110. An
+// empty etag is not sent.
+func checkNotModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	c.Header("Cache-Control", documentCacheControl)
+	if etag != "" {
+		c.Header("ETag", etag)
+	}
+	lastModified = lastModified.UTC().Truncate(time.Second)
+	c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+
+	notModified := false
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		notModified = etag != "" && etagMatches(ifNoneMatch, etag)
+	} else if ifModifiedSince := c.GetHeader("If-Modified-Since"); ifModifiedSince != "" {
+		if since, err := http.ParseTime(ifModifiedSince); err == nil {
+			notModified = !lastModified.After(since)
+		}
+	}
+
+	if notModified {
+		c.Status(http.StatusNotModified)
+	}
+	return notModified
+}
+
+// checkETagNotModified is checkNotModified for responses whose latest change time misses
+// some changes, such as deletions. It sends no Last-Modified and ignores If-Modified-Since,
+// so only the ETag decides whether the client's cached copy is still current.
+func checkETagNotModified(c *gin.Context, etag string) bool {
+	c.Header("Cache-Control", documentCacheControl)
+	c.Header("ETag", etag)
+
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, using the weak
+// comparison required for If-None-Match
+func etagMatches(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// responseETag returns a strong ETag for a JSON response body
+func responseETag(body any) (string, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// contentETag returns the ETag of a document's content, from its stored content hash
+func contentETag(hash *string) string {
+	if hash == nil || *hash == "" {
+		return ""
+	}
+	return `"` + *hash + `"`
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bipulkrdas/orgmind/backend/internal/middleware"
@@ -15,26 +16,32 @@ import (
 
 // ChatHandler handles chat-related HTTP requests
 type ChatHandler struct {
-	chatService  service.ChatService
-	graphService service.GraphService
+	chatService       service.ChatService
+	graphService      service.GraphService
+	keepAliveInterval time.Duration // 0 = no keep-alive pings
 }
 
-// NewChatHandler creates a new instance of ChatHandler
-func NewChatHandler(chatService service.ChatService, graphService service.GraphService) *ChatHandler {
+// NewChatHandler creates a new instance of ChatHandler. While a stream waits for its first
+// chunk, a keep-alive comment is sent every keepAliveInterval (0 disables them).
+func NewChatHandler(chatService service.ChatService, graphService service.GraphService, keepAliveInterval time.Duration) *ChatHandler {
 	return &ChatHandler{
-		chatService:  chatService,
-		graphService: graphService,
+		chatService:       chatService,
+		graphService:      graphService,
+		keepAliveInterval: keepAliveInterval,
 	}
 }
 
 // ChatThreadResponse represents a chat thread in API responses
 type ChatThreadResponse struct {
-	ID        string  `json:"id"`
-	GraphID   string  `json:"graphId"`
-	UserID    string  `json:"userId"`
-	Summary   *string `json:"summary,omitempty"`
-	CreatedAt string  `json:"createdAt"`
-	UpdatedAt string  `json:"updatedAt"`
+	ID         string  `json:"id"`
+	GraphID    string  `json:"graphId"`
+	UserID     string  `json:"userId"`
+	Summary    *string `json:"summary,omitempty"`
+	Private    bool    `json:"private"` // Only visible to the thread's author
+	Archived   bool    `json:"archived"`
+	ArchivedAt *string `json:"archivedAt,omitempty"`
+	CreatedAt  string  `json:"createdAt"`
+	UpdatedAt  string  `json:"updatedAt"`
 }
 
 // ChatMessageResponse represents a chat message in API responses
@@ -51,17 +58,44 @@ type SendMessageRequest struct {
 	Content string `json:"content" binding:"required"`
 }
 
+// CreateThreadRequest represents the optional request body for creating a thread
+type CreateThreadRequest struct {
+	Private bool `json:"private"` // Keep the thread visible only to its author
+}
+
+// StartChatRequest represents the request body for starting a conversation
+type StartChatRequest struct {
+	Content string `json:"content" binding:"required"`
+	Private bool   `json:"private"` // Keep the thread visible only to its author
+}
+
 // SendMessageResponse represents the response for sending a message
 type SendMessageResponse struct {
 	MessageID string `json:"messageId"`
 	Status    string `json:"status"`
 }
 
-// MessagesResponse represents the paginated messages response
-type MessagesResponse struct {
-	Messages []ChatMessageResponse `json:"messages"`
-	Total    int                   `json:"total"`
-	HasMore  bool                  `json:"hasMore"`
+// StartChatResponse represents the response for starting a conversation: the new
+// thread and its first user message, whose ID is passed to the stream endpoint
+type StartChatResponse struct {
+	Thread  ChatThreadResponse  `json:"thread"`
+	Message ChatMessageResponse `json:"message"`
+}
+
+// SubmitFeedbackRequest represents the request body for rating an assistant message
+type SubmitFeedbackRequest struct {
+	Rating  string  `json:"rating" binding:"required"` // "up" or "down"
+	Comment *string `json:"comment"`
+}
+
+// MessageFeedbackResponse represents message feedback in API responses
+type MessageFeedbackResponse struct {
+	ID        string  `json:"id"`
+	MessageID string  `json:"messageId"`
+	Rating    string  `json:"rating"`
+	Comment   *string `json:"comment,omitempty"`
+	CreatedAt string  `json:"createdAt"`
+	UpdatedAt string  `json:"updatedAt"`
 }
 
 // ListThreads handles GET /api/graphs/:id/chat/threads
@@ -69,43 +103,46 @@ func (h *ChatHandler) ListThreads(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get graph ID from URL parameter
 	graphID := c.Param("id")
 	if graphID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Graph ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
 		return
 	}
 
-	// List threads for the graph
-	threads, err := h.chatService.ListThreads(c.Request.Context(), graphID, userID)
+	// Archived threads are hidden unless ?includeArchived=true (all threads) or ?archived=true (archived only)
+	var filter models.ChatThreadFilter
+	filter.IncludeArchived, _ = strconv.ParseBool(c.Query("includeArchived"))
+	filter.ArchivedOnly, _ = strconv.ParseBool(c.Query("archived"))
+
+	page, ok := parsePageParamsOrRespond(c)
+	if !ok {
+		return
+	}
+
+	// List a page of the graph's threads
+	filter.Limit, filter.Offset = page.Limit, page.Offset
+	threads, total, err := h.chatService.ListThreads(c.Request.Context(), graphID, userID, filter)
 	if err != nil {
 		if errors.Is(err, service.ErrNotGraphMember) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this graph"})
+			respondError(c, http.StatusForbidden, CodeNotGraphMember, "You don't have access to this graph")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list threads", "details": err.Error()})
+		respondInternalError(c, "Failed to list threads", err)
 		return
 	}
 
 	// Convert to response format
 	response := make([]ChatThreadResponse, len(threads))
 	for i, thread := range threads {
-		response[i] = ChatThreadResponse{
-			ID:        thread.ID,
-			GraphID:   thread.GraphID,
-			UserID:    thread.UserID,
-			Summary:   thread.Summary,
-			CreatedAt: thread.CreatedAt.UTC().Format(time.RFC3339),
-			UpdatedAt: thread.UpdatedAt.UTC().Format(time.RFC3339),
-		}
+		response[i] = convertThreadToResponse(thread)
 	}
 
-	// Return threads array directly (not wrapped)
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, newPaginatedResponse(response, page, total))
 }
 
 // CreateThread handles POST /api/graphs/:id/chat/threads
@@ -113,14 +150,14 @@ func (h *ChatHandler) CreateThread(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get graph ID from URL parameter
 	graphID := c.Param("id")
 	if graphID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Graph ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
 		return
 	}
 
@@ -128,103 +165,271 @@ func (h *ChatHandler) CreateThread(c *gin.Context) {
 	_, err := h.graphService.GetByID(c.Request.Context(), graphID, userID)
 	if err != nil {
 		if errors.Is(err, service.ErrGraphNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Graph not found"})
+			respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
 			return
 		}
 		if errors.Is(err, service.ErrNotGraphMember) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this graph"})
+			respondError(c, http.StatusForbidden, CodeNotGraphMember, "You don't have access to this graph")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify graph access", "details": err.Error()})
+		respondInternalError(c, "Failed to verify graph access", err)
 		return
 	}
 
+	// The body is optional; without one the thread is shared with the graph's members
+	var req CreateThreadRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
+			return
+		}
+	}
+
 	// Create thread
-	thread, err := h.chatService.CreateThread(c.Request.Context(), graphID, userID)
+	thread, err := h.chatService.CreateThread(c.Request.Context(), graphID, userID, req.Private)
 	if err != nil {
 		if errors.Is(err, service.ErrNotGraphMember) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this graph"})
+			respondError(c, http.StatusForbidden, CodeNotGraphMember, "You don't have access to this graph")
+			return
+		}
+		if errors.Is(err, service.ErrChatDisabled) {
+			respondError(c, http.StatusForbidden, CodeChatDisabled, "Chat is disabled for this graph")
+			return
+		}
+		if errors.Is(err, service.ErrBudgetExceeded) {
+			respondError(c, http.StatusTooManyRequests, CodeBudgetExceeded, err.Error())
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create chat thread", "details": err.Error()})
+		respondInternalError(c, "Failed to create chat thread", err)
 		return
 	}
 
 	// Return thread response
-	c.JSON(http.StatusCreated, ChatThreadResponse{
-		ID:        thread.ID,
-		GraphID:   thread.GraphID,
-		UserID:    thread.UserID,
-		Summary:   thread.Summary,
-		CreatedAt: thread.CreatedAt.UTC().Format(time.RFC3339),
-		UpdatedAt: thread.UpdatedAt.UTC().Format(time.RFC3339),
+	c.JSON(http.StatusCreated, convertThreadToResponse(thread))
+}
+
+// StartChat handles POST /api/graphs/:id/chat/start
+// Creates a thread and saves its first user message in one call; the client then opens
+// the SSE stream with the returned thread and message IDs to get the AI response
+func (h *ChatHandler) StartChat(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get graph ID from URL parameter
+	graphID := c.Param("id")
+	if graphID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return
+	}
+
+	// Parse request body
+	var req StartChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	// Verify graph exists and user is a member
+	if _, err := h.graphService.GetByID(c.Request.Context(), graphID, userID); err != nil {
+		handleServiceError(c, err, "verify graph access")
+		return
+	}
+
+	// Create the thread and save the message (rate limited and validated like SendMessage)
+	thread, userMessage, err := h.chatService.StartThread(c.Request.Context(), graphID, userID, req.Content, req.Private)
+	if err != nil {
+		handleServiceError(c, err, "start chat")
+		return
+	}
+
+	c.JSON(http.StatusCreated, StartChatResponse{
+		Thread:  convertThreadToResponse(thread),
+		Message: convertMessageToResponse(userMessage),
 	})
 }
 
+// ArchiveThread handles POST /api/graphs/:id/chat/threads/:threadId/archive
+// Hides the thread from the default thread list; only the thread's creator or the graph's
+// owner can archive it
+func (h *ChatHandler) ArchiveThread(c *gin.Context) {
+	h.setThreadArchived(c, true)
+}
+
+// UnarchiveThread handles DELETE /api/graphs/:id/chat/threads/:threadId/archive
+// Returns the thread to the default thread list; only the thread's creator or the graph's
+// owner can unarchive it
+func (h *ChatHandler) UnarchiveThread(c *gin.Context) {
+	h.setThreadArchived(c, false)
+}
+
+// setThreadArchived archives or unarchives the thread named in the URL and returns it
+func (h *ChatHandler) setThreadArchived(c *gin.Context, archived bool) {
+	userID, threadID, ok := h.threadInGraph(c)
+	if !ok {
+		return
+	}
+
+	var thread *models.ChatThread
+	var err error
+	if archived {
+		thread, err = h.chatService.ArchiveThread(c.Request.Context(), threadID, userID)
+	} else {
+		thread, err = h.chatService.UnarchiveThread(c.Request.Context(), threadID, userID)
+	}
+	if err != nil {
+		handleServiceError(c, err, "update thread")
+		return
+	}
+
+	c.JSON(http.StatusOK, convertThreadToResponse(thread))
+}
+
+// MakeThreadPrivate handles POST /api/graphs/:id/chat/threads/:threadId/private
+// Hides the thread from the graph's other members; only the thread's creator can do this
+func (h *ChatHandler) MakeThreadPrivate(c *gin.Context) {
+	h.setThreadPrivate(c, true)
+}
+
+// ShareThread handles DELETE /api/graphs/:id/chat/threads/:threadId/private
+// Makes a private thread visible to the graph's members again (thread creator only)
+func (h *ChatHandler) ShareThread(c *gin.Context) {
+	h.setThreadPrivate(c, false)
+}
+
+// setThreadPrivate makes the thread named in the URL private or shared and returns it
+func (h *ChatHandler) setThreadPrivate(c *gin.Context, private bool) {
+	userID, threadID, ok := h.threadInGraph(c)
+	if !ok {
+		return
+	}
+
+	thread, err := h.chatService.SetThreadPrivate(c.Request.Context(), threadID, userID, private)
+	if err != nil {
+		handleServiceError(c, err, "update thread")
+		return
+	}
+
+	c.JSON(http.StatusOK, convertThreadToResponse(thread))
+}
+
+// DeleteThread handles DELETE /api/graphs/:id/chat/threads/:threadId
+// Deletes the thread and its messages; only the thread's creator or the graph's owner can delete it
+func (h *ChatHandler) DeleteThread(c *gin.Context) {
+	userID, threadID, ok := h.threadInGraph(c)
+	if !ok {
+		return
+	}
+
+	if err := h.chatService.DeleteThread(c.Request.Context(), threadID, userID); err != nil {
+		handleServiceError(c, err, "delete thread")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// threadInGraph reads the user and the thread named in the URL and verifies the user can
+// see the thread and that it belongs to the graph in the URL, responding otherwise. It
+// reports whether the request may proceed.
+func (h *ChatHandler) threadInGraph(c *gin.Context) (string, string, bool) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return "", "", false
+	}
+
+	// Get graph and thread IDs from URL parameters
+	graphID := c.Param("id")
+	if graphID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return "", "", false
+	}
+
+	threadID := c.Param("threadId")
+	if threadID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Thread ID is required")
+		return "", "", false
+	}
+
+	// Verify user access to thread
+	thread, err := h.chatService.GetThread(c.Request.Context(), threadID, userID)
+	if err != nil {
+		handleServiceError(c, err, "verify thread access")
+		return "", "", false
+	}
+
+	// Verify thread belongs to the graph
+	if thread.GraphID != graphID {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Thread does not belong to this graph")
+		return "", "", false
+	}
+
+	return userID, threadID, true
+}
+
 // GetThreadMessages handles GET /api/graphs/:id/chat/threads/:threadId/messages
 func (h *ChatHandler) GetThreadMessages(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get graph ID from URL parameter
 	graphID := c.Param("id")
 	if graphID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Graph ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
 		return
 	}
 
 	// Get thread ID from URL parameter
 	threadID := c.Param("threadId")
 	if threadID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Thread ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Thread ID is required")
 		return
 	}
 
-	// Parse pagination parameters
-	limit := 50 // default
-	offset := 0 // default
-
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-			limit = parsedLimit
-		}
-	}
-
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
-			offset = parsedOffset
-		}
+	page, ok := parsePageParamsOrRespond(c)
+	if !ok {
+		return
 	}
 
 	// Verify user access to thread
 	thread, err := h.chatService.GetThread(c.Request.Context(), threadID, userID)
 	if err != nil {
 		if errors.Is(err, service.ErrChatThreadNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Chat thread not found"})
+			respondError(c, http.StatusNotFound, CodeThreadNotFound, "Chat thread not found")
 			return
 		}
 		if errors.Is(err, service.ErrChatUnauthorized) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this chat thread"})
+			respondError(c, http.StatusForbidden, CodeThreadAccessDenied, "You don't have access to this chat thread")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify thread access", "details": err.Error()})
+		respondInternalError(c, "Failed to verify thread access", err)
 		return
 	}
 
 	// Verify thread belongs to the graph
 	if thread.GraphID != graphID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Thread does not belong to this graph"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Thread does not belong to this graph")
 		return
 	}
 
-	// Get messages with pagination
-	messages, err := h.chatService.GetMessages(c.Request.Context(), threadID, limit, offset)
+	// Get messages with pagination, by default paging backward from the newest message
+	order := strings.ToLower(c.Query("order"))
+	messages, total, err := h.chatService.GetMessages(c.Request.Context(), threadID, order, page.Limit, page.Offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get messages", "details": err.Error()})
+		if errors.Is(err, service.ErrInvalidSortOrder) {
+			respondError(c, http.StatusBadRequest, CodeInvalidListFilter, err.Error())
+			return
+		}
+		respondInternalError(c, "Failed to get messages", err)
 		return
 	}
 
@@ -240,15 +445,7 @@ func (h *ChatHandler) GetThreadMessages(c *gin.Context) {
 		}
 	}
 
-	// Determine if there are more messages
-	hasMore := len(messages) == limit
-
-	// Return messages with pagination metadata
-	c.JSON(http.StatusOK, MessagesResponse{
-		Messages: response,
-		Total:    len(messages),
-		HasMore:  hasMore,
-	})
+	c.JSON(http.StatusOK, newPaginatedResponse(response, page, total))
 }
 
 // SendMessage handles POST /api/graphs/:id/chat/threads/:threadId/messages
@@ -258,34 +455,34 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get graph ID from URL parameter
 	graphID := c.Param("id")
 	if graphID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Graph ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
 		return
 	}
 
 	// Get thread ID from URL parameter
 	threadID := c.Param("threadId")
 	if threadID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Thread ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Thread ID is required")
 		return
 	}
 
 	// Parse request body
 	var req SendMessageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
 		return
 	}
 
 	// Validate content length
 	if len(req.Content) > 4000 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Message content exceeds 4000 characters"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Message content exceeds 4000 characters")
 		return
 	}
 
@@ -293,20 +490,20 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 	thread, err := h.chatService.GetThread(c.Request.Context(), threadID, userID)
 	if err != nil {
 		if errors.Is(err, service.ErrChatThreadNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Chat thread not found"})
+			respondError(c, http.StatusNotFound, CodeThreadNotFound, "Chat thread not found")
 			return
 		}
 		if errors.Is(err, service.ErrChatUnauthorized) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this chat thread"})
+			respondError(c, http.StatusForbidden, CodeThreadAccessDenied, "You don't have access to this chat thread")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify thread access", "details": err.Error()})
+		respondInternalError(c, "Failed to verify thread access", err)
 		return
 	}
 
 	// Verify thread belongs to the graph
 	if thread.GraphID != graphID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Thread does not belong to this graph"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Thread does not belong to this graph")
 		return
 	}
 
@@ -314,18 +511,26 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 	userMessage, err := h.chatService.SaveUserMessage(c.Request.Context(), threadID, userID, req.Content)
 	if err != nil {
 		if errors.Is(err, service.ErrRateLimitExceeded) {
-			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded: maximum 20 messages per minute"})
+			respondError(c, http.StatusTooManyRequests, CodeRateLimitExceeded, "Rate limit exceeded: maximum 20 messages per minute")
 			return
 		}
 		if errors.Is(err, service.ErrMessageTooLong) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Message content exceeds 4000 characters"})
+			respondError(c, http.StatusBadRequest, CodeMessageTooLong, "Message content exceeds 4000 characters")
 			return
 		}
 		if errors.Is(err, service.ErrInvalidMessageContent) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Message content is required"})
+			respondError(c, http.StatusBadRequest, CodeInvalidMessageContent, "Message content is required")
+			return
+		}
+		if errors.Is(err, service.ErrChatDisabled) {
+			respondError(c, http.StatusForbidden, CodeChatDisabled, "Chat is disabled for this graph")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save message", "details": err.Error()})
+		if errors.Is(err, service.ErrBudgetExceeded) {
+			respondError(c, http.StatusTooManyRequests, CodeBudgetExceeded, err.Error())
+			return
+		}
+		respondInternalError(c, "Failed to save message", err)
 		return
 	}
 
@@ -334,34 +539,125 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 	c.JSON(http.StatusCreated, convertMessageToResponse(userMessage))
 }
 
-// StreamResponse handles GET /api/graphs/:id/chat/stream
+// SubmitFeedback handles POST /api/graphs/:id/chat/threads/:threadId/messages/:messageId/feedback
+// Rating the same message again replaces the user's earlier rating
+func (h *ChatHandler) SubmitFeedback(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get graph, thread and message IDs from URL parameters
+	graphID := c.Param("id")
+	if graphID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return
+	}
+
+	threadID := c.Param("threadId")
+	if threadID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Thread ID is required")
+		return
+	}
+
+	messageID := c.Param("messageId")
+	if messageID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Message ID is required")
+		return
+	}
+
+	// Parse request body
+	var req SubmitFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	// Verify user access to thread
+	thread, err := h.chatService.GetThread(c.Request.Context(), threadID, userID)
+	if err != nil {
+		handleServiceError(c, err, "verify thread access")
+		return
+	}
+
+	// Verify thread belongs to the graph
+	if thread.GraphID != graphID {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Thread does not belong to this graph")
+		return
+	}
+
+	feedback, err := h.chatService.SubmitFeedback(c.Request.Context(), messageID, userID, req.Rating, req.Comment)
+	if err != nil {
+		handleServiceError(c, err, "submit feedback")
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageFeedbackResponse{
+		ID:        feedback.ID,
+		MessageID: feedback.MessageID,
+		Rating:    feedback.Rating,
+		Comment:   feedback.Comment,
+		CreatedAt: feedback.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt: feedback.UpdatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// GetFeedbackSummary handles GET /api/graphs/:id/chat/feedback
+// Returns aggregate ratings of the graph's assistant messages to the graph creator
+func (h *ChatHandler) GetFeedbackSummary(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get graph ID from URL parameter
+	graphID := c.Param("id")
+	if graphID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return
+	}
+
+	summary, err := h.chatService.GetFeedbackSummary(c.Request.Context(), graphID, userID)
+	if err != nil {
+		handleServiceError(c, err, "get feedback summary")
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// StreamResponse handles GET /api/graphs/:id/chat/stream[?graphIds=a,b][&excludeDocumentIds=c,d]
 // This endpoint generates the AI response and streams it back via SSE
 // It expects the user message to already be saved (via SendMessage endpoint)
 func (h *ChatHandler) StreamResponse(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get graph ID from URL parameter
 	graphID := c.Param("id")
 	if graphID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Graph ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
 		return
 	}
 
 	// Get threadId and userMessageId from query params
 	threadID := c.Query("threadId")
 	if threadID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "threadId query parameter is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "threadId query parameter is required")
 		return
 	}
 
 	userMessageID := c.Query("userMessageId")
 	if userMessageID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "userMessageId query parameter is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "userMessageId query parameter is required")
 		return
 	}
 
@@ -369,20 +665,42 @@ func (h *ChatHandler) StreamResponse(c *gin.Context) {
 	thread, err := h.chatService.GetThread(c.Request.Context(), threadID, userID)
 	if err != nil {
 		if errors.Is(err, service.ErrChatThreadNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Chat thread not found"})
+			respondError(c, http.StatusNotFound, CodeThreadNotFound, "Chat thread not found")
 			return
 		}
 		if errors.Is(err, service.ErrChatUnauthorized) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this chat thread"})
+			respondError(c, http.StatusForbidden, CodeThreadAccessDenied, "You don't have access to this chat thread")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify thread access", "details": err.Error()})
+		respondInternalError(c, "Failed to verify thread access", err)
 		return
 	}
 
 	// Verify thread belongs to the graph
 	if thread.GraphID != graphID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Thread does not belong to this graph"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Thread does not belong to this graph")
+		return
+	}
+
+	// Optionally search other graphs too (?graphIds=a,b); the user must be a member of each
+	var additionalGraphIDs []string
+	if graphIDsParam := c.Query("graphIds"); graphIDsParam != "" {
+		additionalGraphIDs = strings.Split(graphIDsParam, ",")
+	}
+	graphIDs, err := h.chatService.ResolveChatGraphs(c.Request.Context(), graphID, userID, additionalGraphIDs)
+	if err != nil {
+		handleServiceError(c, err, "verify graph access")
+		return
+	}
+
+	// Optionally leave documents of the searched graphs out of the answer (?excludeDocumentIds=a,b)
+	var excludeDocumentIDs []string
+	if excludeParam := c.Query("excludeDocumentIds"); excludeParam != "" {
+		excludeDocumentIDs = strings.Split(excludeParam, ",")
+	}
+	excludedDocumentIDs, err := h.chatService.ResolveExcludedDocuments(c.Request.Context(), graphIDs, excludeDocumentIDs)
+	if err != nil {
+		handleServiceError(c, err, "verify excluded documents")
 		return
 	}
 
@@ -409,7 +727,8 @@ func (h *ChatHandler) StreamResponse(c *gin.Context) {
 			c.Request.Context(),
 			threadID,
 			userMessageID,
-			graphID,
+			graphIDs,
+			excludedDocumentIDs,
 			responseChan,
 		)
 
@@ -425,8 +744,23 @@ func (h *ChatHandler) StreamResponse(c *gin.Context) {
 	// Stream chunks to client
 	c.Writer.Flush()
 
+	// Until the first chunk arrives (retrieval and first-token latency), send SSE comments so
+	// proxies don't close the connection as idle; clients ignore comment lines
+	var keepAlive <-chan time.Time
+	if h.keepAliveInterval > 0 {
+		ticker := time.NewTicker(h.keepAliveInterval)
+		defer ticker.Stop()
+		keepAlive = ticker.C
+	}
+
 	for {
 		select {
+		case <-keepAlive:
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+
 		case chunk, ok := <-responseChan:
 			if !ok {
 				// Channel closed, wait for either error or success
@@ -453,11 +787,7 @@ func (h *ChatHandler) StreamResponse(c *gin.Context) {
 					}
 
 					// Channel had an actual error
-					if errors.Is(err, service.ErrRateLimitExceeded) {
-						c.SSEvent("error", map[string]interface{}{"error": "Rate limit exceeded"})
-					} else {
-						c.SSEvent("error", map[string]interface{}{"error": "Failed to generate response"})
-					}
+					c.SSEvent("error", map[string]interface{}{"error": streamErrorMessage(err)})
 					c.Writer.Flush()
 					return
 
@@ -469,11 +799,7 @@ func (h *ChatHandler) StreamResponse(c *gin.Context) {
 						case err, ok := <-errorChan:
 							if ok && err != nil {
 								// There was an error
-								if errors.Is(err, service.ErrRateLimitExceeded) {
-									c.SSEvent("error", map[string]interface{}{"error": "Rate limit exceeded"})
-								} else {
-									c.SSEvent("error", map[string]interface{}{"error": "Failed to generate response"})
-								}
+								c.SSEvent("error", map[string]interface{}{"error": streamErrorMessage(err)})
 							} else {
 								// No error either - unexpected state
 								c.SSEvent("error", map[string]interface{}{"error": "Unexpected streaming completion state"})
@@ -501,6 +827,9 @@ func (h *ChatHandler) StreamResponse(c *gin.Context) {
 				}
 			}
 
+			// The response has started, so no more pings are needed
+			keepAlive = nil
+
 			// Send chunk event
 			c.SSEvent("chunk", map[string]interface{}{
 				"content": chunk,
@@ -516,6 +845,23 @@ func (h *ChatHandler) StreamResponse(c *gin.Context) {
 
 // Helper functions for error handling and validation
 
+// knowledgeBaseNotReadyMessage explains chat failing because the File Search store can't be used yet
+const knowledgeBaseNotReadyMessage = "The knowledge base is not ready yet. Please try again in a few minutes."
+
+// streamErrorMessage returns the message of the SSE error event sent when generating a response fails
+func streamErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, service.ErrRateLimitExceeded):
+		return "Rate limit exceeded"
+	case errors.Is(err, service.ErrBudgetExceeded):
+		return "Monthly chat budget exceeded"
+	case errors.Is(err, service.ErrGeminiStoreNotFound):
+		return knowledgeBaseNotReadyMessage
+	default:
+		return "Failed to generate response"
+	}
+}
+
 // validateGraphID validates that a graph ID is provided
 func validateGraphID(graphID string) error {
 	if graphID == "" {
@@ -547,39 +893,61 @@ func validateMessageContent(content string) error {
 func handleServiceError(c *gin.Context, err error, operation string) {
 	switch {
 	case errors.Is(err, service.ErrGraphNotFound):
-		c.JSON(http.StatusNotFound, gin.H{"error": "Graph not found"})
+		respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
 	case errors.Is(err, service.ErrNotGraphMember):
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this graph"})
+		respondError(c, http.StatusForbidden, CodeNotGraphMember, "You don't have access to this graph")
 	case errors.Is(err, service.ErrChatThreadNotFound):
-		c.JSON(http.StatusNotFound, gin.H{"error": "Chat thread not found"})
+		respondError(c, http.StatusNotFound, CodeThreadNotFound, "Chat thread not found")
 	case errors.Is(err, service.ErrChatUnauthorized):
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this chat thread"})
+		respondError(c, http.StatusForbidden, CodeThreadAccessDenied, "You don't have access to this chat thread")
 	case errors.Is(err, service.ErrRateLimitExceeded):
-		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded: maximum 20 messages per minute"})
+		respondError(c, http.StatusTooManyRequests, CodeRateLimitExceeded, "Rate limit exceeded: maximum 20 messages per minute")
 	case errors.Is(err, service.ErrMessageTooLong):
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Message content exceeds 4000 characters"})
+		respondError(c, http.StatusBadRequest, CodeMessageTooLong, "Message content exceeds 4000 characters")
 	case errors.Is(err, service.ErrInvalidMessageContent):
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Message content is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidMessageContent, "Message content is required")
+	case errors.Is(err, service.ErrNotGraphCreator):
+		respondError(c, http.StatusForbidden, CodeNotGraphCreator, "Only the graph creator can perform this action")
+	case errors.Is(err, service.ErrChatMessageNotFound):
+		respondError(c, http.StatusNotFound, CodeMessageNotFound, "Chat message not found")
+	case errors.Is(err, service.ErrTooManyChatGraphs):
+		respondError(c, http.StatusBadRequest, CodeTooManyChatGraphs, err.Error())
+	case errors.Is(err, service.ErrTooManyExcludedDocs):
+		respondError(c, http.StatusBadRequest, CodeTooManyExcludedDocs, err.Error())
+	case errors.Is(err, service.ErrInvalidExcludedDoc):
+		respondError(c, http.StatusBadRequest, CodeInvalidExcludedDoc, err.Error())
+	case errors.Is(err, service.ErrChatDisabled):
+		respondError(c, http.StatusForbidden, CodeChatDisabled, "Chat is disabled for this graph")
+	case errors.Is(err, service.ErrBudgetExceeded):
+		respondError(c, http.StatusTooManyRequests, CodeBudgetExceeded, err.Error())
+	case errors.Is(err, service.ErrGeminiStoreNotFound):
+		respondError(c, http.StatusServiceUnavailable, CodeKnowledgeBaseNotReady, knowledgeBaseNotReadyMessage)
+	case errors.Is(err, service.ErrMessageNotRatable),
+		errors.Is(err, service.ErrInvalidFeedbackRating),
+		errors.Is(err, service.ErrFeedbackCommentLong):
+		respondError(c, http.StatusBadRequest, CodeInvalidFeedback, err.Error())
 	default:
-		// Log the error with context
-		fmt.Printf("Error in %s: %v\n", operation, err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   fmt.Sprintf("Failed to %s", operation),
-			"details": err.Error(),
-		})
+		respondInternalError(c, fmt.Sprintf("Failed to %s", operation), err)
 	}
 }
 
 // convertThreadToResponse converts a ChatThread model to response format
 func convertThreadToResponse(thread *models.ChatThread) ChatThreadResponse {
-	return ChatThreadResponse{
+	response := ChatThreadResponse{
 		ID:        thread.ID,
 		GraphID:   thread.GraphID,
 		UserID:    thread.UserID,
 		Summary:   thread.Summary,
+		Private:   thread.Private,
+		Archived:  thread.Archived,
 		CreatedAt: thread.CreatedAt.UTC().Format(time.RFC3339),
 		UpdatedAt: thread.UpdatedAt.UTC().Format(time.RFC3339),
 	}
+	if thread.ArchivedAt != nil {
+		archivedAt := thread.ArchivedAt.UTC().Format(time.RFC3339)
+		response.ArchivedAt = &archivedAt
+	}
+	return response
 }
 
 // convertMessageToResponse converts a ChatMessage model to response format
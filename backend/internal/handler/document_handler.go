@@ -1,15 +1,25 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/bipulkrdas/orgmind/backend/internal/extraction"
 	"github.com/bipulkrdas/orgmind/backend/internal/middleware"
+	"github.com/bipulkrdas/orgmind/backend/internal/models"
 	"github.com/bipulkrdas/orgmind/backend/internal/service"
+	"github.com/bipulkrdas/orgmind/backend/internal/storage"
 	"github.com/gin-gonic/gin"
 )
 
+// storageUploadFailedMessage is returned when storage kept an incomplete copy of a document
+const storageUploadFailedMessage = "The document could not be stored completely. Please try again."
+
 // DocumentHandler handles document-related HTTP requests
 type DocumentHandler struct {
 	documentService service.DocumentService
@@ -24,31 +34,134 @@ func NewDocumentHandler(documentService service.DocumentService) *DocumentHandle
 
 // EditorSubmitRequest represents the request body for editor content submission
 type EditorSubmitRequest struct {
-	Content      string `json:"content" binding:"required"`      // Plain text for Zep processing
-	LexicalState string `json:"lexicalState" binding:"required"` // Lexical JSON for editor restoration
-	GraphID      string `json:"graphId" binding:"required"`
+	Content       string `json:"content" binding:"required"` // Plain text for Zep processing, or the document in ContentFormat
+	LexicalState  string `json:"lexicalState"`               // Lexical JSON for editor restoration (required for the Lexical format)
+	ContentFormat string `json:"contentFormat"`              // "lexical" (default), "markdown", "html" or "text"
+	GraphID       string `json:"graphId" binding:"required"`
 }
 
 // UpdateDocumentRequest represents the request body for updating document content
 type UpdateDocumentRequest struct {
-	Content      string `json:"content" binding:"required"`      // Plain text for Zep processing
-	LexicalState string `json:"lexicalState" binding:"required"` // Lexical JSON for editor restoration
+	Content       string `json:"content" binding:"required"` // Plain text for Zep processing, or the document in ContentFormat
+	LexicalState  string `json:"lexicalState"`               // Lexical JSON for editor restoration (required for the Lexical format)
+	ContentFormat string `json:"contentFormat"`              // Omit to keep the document's current format
+}
+
+// BatchStatusRequest represents the request body for polling several documents' statuses
+type BatchStatusRequest struct {
+	DocumentIDs []string `json:"documentIds" binding:"required"`
+}
+
+// BatchStatusResponse maps each requested document ID to its processing status
+type BatchStatusResponse struct {
+	Statuses map[string]string `json:"statuses"`
 }
 
 // DocumentResponse represents a document in API responses
 type DocumentResponse struct {
-	ID           string  `json:"id"`
-	UserID       string  `json:"userId"`
-	GraphID      *string `json:"graphId,omitempty"`
-	Filename     *string `json:"filename,omitempty"`
-	ContentType  *string `json:"contentType,omitempty"`
-	StorageKey   string  `json:"storageKey"`
-	SizeBytes    int64   `json:"sizeBytes"`
-	Source       string  `json:"source"`
-	Status       string  `json:"status"`
-	ErrorMessage *string `json:"errorMessage,omitempty"`
-	CreatedAt    string  `json:"createdAt"`
-	UpdatedAt    string  `json:"updatedAt"`
+	ID            string          `json:"id"`
+	UserID        string          `json:"userId"`
+	GraphID       *string         `json:"graphId,omitempty"`
+	Filename      *string         `json:"filename,omitempty"`
+	ContentType   *string         `json:"contentType,omitempty"`
+	StorageKey    string          `json:"storageKey"`
+	SizeBytes     int64           `json:"sizeBytes"`
+	Source        string          `json:"source"`
+	ContentFormat *string         `json:"contentFormat,omitempty"`
+	Status        string          `json:"status"`
+	ErrorMessage  *string         `json:"errorMessage,omitempty"`
+	Tags          []string        `json:"tags"`
+	Metadata      json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt     string          `json:"createdAt"`
+	UpdatedAt     string          `json:"updatedAt"`
+
+	// Processing attempts so far; a failed document with no retry scheduled stays failed
+	ProcessingAttempts int     `json:"processingAttempts"`
+	LastAttemptAt      *string `json:"lastAttemptAt,omitempty"`
+	NextRetryAt        *string `json:"nextRetryAt,omitempty"`
+	PermanentlyFailed  bool    `json:"permanentlyFailed"`
+
+	// Start of the document's text, only included in lists requested with ?includePreview=true
+	ContentPreview *string `json:"contentPreview,omitempty"`
+
+	// Name of the document's graph, only included in GET /api/documents
+	GraphName *string `json:"graphName,omitempty"`
+}
+
+// listSortFromQuery reads the ?sort= and ?order= list parameters; validation happens in the service
+func listSortFromQuery(c *gin.Context) models.ListSort {
+	return models.ListSort{
+		Field: c.Query("sort"),
+		Order: strings.ToLower(c.Query("order")),
+	}
+}
+
+// isInvalidListFilter reports whether a listing failed because of bad query parameters
+func isInvalidListFilter(err error) bool {
+	return errors.Is(err, service.ErrInvalidTag) ||
+		errors.Is(err, service.ErrTagTooLong) ||
+		errors.Is(err, service.ErrInvalidSortField) ||
+		errors.Is(err, service.ErrInvalidSortOrder) ||
+		errors.Is(err, service.ErrInvalidDocumentScope) ||
+		errors.Is(err, service.ErrInvalidGraphFilter) ||
+		errors.Is(err, service.ErrInvalidGraphRoleFilter)
+}
+
+// toDocumentResponse converts a document model to its API response format
+func toDocumentResponse(doc *models.Document) DocumentResponse {
+	tags := []string(doc.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return DocumentResponse{
+		ID:            doc.ID,
+		UserID:        doc.UserID,
+		GraphID:       doc.GraphID,
+		Filename:      doc.Filename,
+		ContentType:   doc.ContentType,
+		StorageKey:    doc.StorageKey,
+		SizeBytes:     doc.SizeBytes,
+		Source:        doc.Source,
+		ContentFormat: doc.ContentFormat,
+		Status:        doc.Status,
+		ErrorMessage:  doc.ErrorMessage,
+		Tags:          tags,
+		Metadata:      json.RawMessage(doc.Metadata),
+		CreatedAt:     doc.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:     doc.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+
+		ProcessingAttempts: doc.ProcessingAttempts,
+		LastAttemptAt:      formatOptionalTime(doc.LastAttemptAt),
+		NextRetryAt:        formatOptionalTime(doc.NextRetryAt),
+		PermanentlyFailed:  doc.Status == "failed" && doc.NextRetryAt == nil,
+
+		GraphName: doc.GraphName,
+	}
+}
+
+// toDocumentListResponse converts listed documents to their API response format, including
+// content previews when the list was requested with ?includePreview=true
+func toDocumentListResponse(c *gin.Context, docs []*models.Document) []DocumentResponse {
+	includePreview, _ := strconv.ParseBool(c.Query("includePreview"))
+
+	response := make([]DocumentResponse, len(docs))
+	for i, doc := range docs {
+		response[i] = toDocumentResponse(doc)
+		if includePreview {
+			response[i].ContentPreview = doc.ContentPreview
+		}
+	}
+	return response
+}
+
+// formatOptionalTime formats an optional timestamp for API responses
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format("2006-01-02T15:04:05Z07:00")
+	return &formatted
 }
 
 // SubmitEditorContent handles POST /api/documents/editor
@@ -56,37 +169,36 @@ func (h *DocumentHandler) SubmitEditorContent(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	var req EditorSubmitRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
 		return
 	}
 
 	// Create document from editor content (with both plain text and Lexical state)
-	doc, err := h.documentService.CreateFromEditor(c.Request.Context(), userID, req.GraphID, req.Content, req.LexicalState)
+	doc, err := h.documentService.CreateFromEditor(c.Request.Context(), userID, req.GraphID, req.Content, req.LexicalState, req.ContentFormat)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create document", "details": err.Error()})
+		if errors.Is(err, service.ErrInvalidContentFormat) {
+			respondError(c, http.StatusBadRequest, CodeInvalidContentFormat, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrLexicalStateRequired) {
+			respondError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrUploadVerificationFailed) {
+			respondError(c, http.StatusBadGateway, CodeStorageUploadFailed, storageUploadFailedMessage)
+			return
+		}
+		respondInternalError(c, "Failed to create document", err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, DocumentResponse{
-		ID:           doc.ID,
-		UserID:       doc.UserID,
-		GraphID:      doc.GraphID,
-		Filename:     doc.Filename,
-		ContentType:  doc.ContentType,
-		StorageKey:   doc.StorageKey,
-		SizeBytes:    doc.SizeBytes,
-		Source:       doc.Source,
-		Status:       doc.Status,
-		ErrorMessage: doc.ErrorMessage,
-		CreatedAt:    doc.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:    doc.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	})
+	c.JSON(http.StatusCreated, toDocumentResponse(doc))
 }
 
 // UploadFile handles POST /api/documents/upload
@@ -94,96 +206,289 @@ func (h *DocumentHandler) UploadFile(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Parse multipart form
 	file, header, err := c.Request.FormFile("file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file from request", "details": err.Error()})
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Failed to read file from request", err.Error())
 		return
 	}
 	defer file.Close()
 
-	// Read file content
-	fileBytes, err := io.ReadAll(file)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file content", "details": err.Error()})
-		return
-	}
-
-	// Get content type from header or detect it
+	// Get content type from header or detect it from the first bytes of the file
 	contentType := header.Header.Get("Content-Type")
 	if contentType == "" {
-		contentType = http.DetectContentType(fileBytes)
+		sniff := make([]byte, 512)
+		n, err := io.ReadFull(file, sniff)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			respondInternalError(c, "Failed to read file content", err)
+			return
+		}
+		contentType = http.DetectContentType(sniff[:n])
+
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			respondInternalError(c, "Failed to read file content", err)
+			return
+		}
 	}
 
 	// Get graphId from form field
 	graphID := c.PostForm("graphId")
 	if graphID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "graphId is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "graphId is required")
 		return
 	}
 
-	// Create document from file
-	doc, err := h.documentService.CreateFromFile(c.Request.Context(), userID, graphID, fileBytes, header.Filename, contentType)
+	// Create document from file; the content is streamed to storage rather than read up front
+	doc, err := h.documentService.CreateFromFile(c.Request.Context(), userID, graphID, file, header.Size, header.Filename, contentType)
 	if err != nil {
-		// Provide more specific error responses based on error type
-		errMsg := err.Error()
-		if strings.Contains(errMsg, "unsupported") || strings.Contains(errMsg, "not supported") {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Unsupported file format",
-				"message": errMsg,
-			})
-		} else if strings.Contains(errMsg, "file size exceeds") || strings.Contains(errMsg, "maximum allowed size") {
-			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
-				"error":   "File too large",
-				"message": errMsg,
-			})
-		} else if strings.Contains(errMsg, "password-protected") || strings.Contains(errMsg, "password") {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Password-protected documents not supported",
-				"message": errMsg,
-			})
-		} else if strings.Contains(errMsg, "corrupted") || strings.Contains(errMsg, "invalid") {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Invalid or corrupted file",
-				"message": errMsg,
-			})
-		} else if strings.Contains(errMsg, "took too long") || strings.Contains(errMsg, "timeout") {
-			c.JSON(http.StatusRequestTimeout, gin.H{
-				"error":   "Extraction timeout",
-				"message": errMsg,
-			})
-		} else if strings.Contains(errMsg, "empty") {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Empty file",
-				"message": errMsg,
-			})
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Failed to process document",
-				"message": errMsg,
-			})
+		respondUploadError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toDocumentResponse(doc))
+}
+
+// respondUploadError responds to a failed file upload, from validation to text extraction
+func respondUploadError(c *gin.Context, err error) {
+	// Extraction queue is full: ask the client to retry shortly
+	if errors.Is(err, extraction.ErrExtractionBusy) {
+		c.Header("Retry-After", "5")
+		respondError(c, http.StatusServiceUnavailable, CodeExtractionBusy, extraction.GetUserFriendlyMessage(err))
+		return
+	}
+
+	// The graph restricts uploads to other formats
+	if errors.Is(err, service.ErrContentTypeNotAllowed) {
+		respondError(c, http.StatusBadRequest, CodeContentTypeNotAllowed, err.Error())
+		return
+	}
+
+	// Storage kept an incomplete copy, which has been removed
+	if errors.Is(err, storage.ErrUploadVerificationFailed) {
+		respondError(c, http.StatusBadGateway, CodeStorageUploadFailed, storageUploadFailedMessage)
+		return
+	}
+
+	// Extraction failed: the code says why, and the message is safe to show to the user
+	var extractionErr *extraction.ExtractionError
+	if errors.As(err, &extractionErr) {
+		respondExtractionError(c, err)
+		return
+	}
+
+	switch {
+	case errors.Is(err, service.ErrGraphNotFound):
+		respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
+	case errors.Is(err, service.ErrNotGraphMember):
+		respondError(c, http.StatusForbidden, CodeNotGraphMember, "You don't have access to this graph")
+	case errors.Is(err, service.ErrUnsupportedFileType):
+		respondError(c, http.StatusBadRequest, CodeUnsupportedFormat, err.Error())
+	case errors.Is(err, service.ErrFileTooLarge):
+		respondError(c, http.StatusRequestEntityTooLarge, CodeFileTooLarge, err.Error())
+	case errors.Is(err, service.ErrEmptyUpload):
+		respondError(c, http.StatusBadRequest, CodeEmptyFile, err.Error())
+	default:
+		// Storage and database failures are the server's, not the file's
+		respondInternalError(c, "Failed to upload file", err)
+	}
+}
+
+// respondExtractionError responds to a file whose text could not be extracted
+func respondExtractionError(c *gin.Context, err error) {
+	message := extraction.GetUserFriendlyMessage(err)
+
+	switch {
+	case errors.Is(err, extraction.ErrSpreadsheetTooLarge):
+		respondError(c, http.StatusRequestEntityTooLarge, CodeSpreadsheetTooLarge, message)
+	case errors.Is(err, extraction.ErrFileTooLarge):
+		respondError(c, http.StatusRequestEntityTooLarge, CodeFileTooLarge, message)
+	case errors.Is(err, extraction.ErrUnsupportedFormat):
+		respondError(c, http.StatusBadRequest, CodeUnsupportedFormat, message)
+	case errors.Is(err, extraction.ErrPasswordProtected):
+		respondError(c, http.StatusBadRequest, CodePasswordProtected, message)
+	case errors.Is(err, extraction.ErrCorruptedFile), errors.Is(err, extraction.ErrInvalidFormat):
+		respondError(c, http.StatusBadRequest, CodeInvalidFile, message)
+	case errors.Is(err, extraction.ErrExtractionTimeout):
+		respondError(c, http.StatusRequestTimeout, CodeExtractionTimeout, message)
+	case errors.Is(err, extraction.ErrEmptyFile):
+		respondError(c, http.StatusBadRequest, CodeEmptyFile, message)
+	default:
+		respondError(c, http.StatusBadRequest, CodeExtractionFailed, message)
+	}
+}
+
+// UploadSessionResponse represents a resumable upload in API responses
+type UploadSessionResponse struct {
+	UploadID      string `json:"uploadId"`
+	DocumentID    string `json:"documentId"` // ID of the document created when the upload is completed
+	GraphID       string `json:"graphId"`
+	Filename      string `json:"filename"`
+	ContentType   string `json:"contentType"`
+	SizeBytes     int64  `json:"sizeBytes"`
+	PartSize      int64  `json:"partSize"` // Size of every part but the last, which holds the rest
+	PartCount     int    `json:"partCount"`
+	UploadedParts []int  `json:"uploadedParts"` // Part numbers received so far
+	ExpiresAt     string `json:"expiresAt"`
+}
+
+// UploadPartResponse represents a received part of a resumable upload
+type UploadPartResponse struct {
+	PartNumber int   `json:"partNumber"`
+	SizeBytes  int64 `json:"sizeBytes"`
+}
+
+// toUploadSessionResponse converts an upload session and its received parts to their API representation
+func toUploadSessionResponse(session *models.UploadSession, parts []*models.UploadPart) UploadSessionResponse {
+	uploaded := make([]int, len(parts))
+	for i, part := range parts {
+		uploaded[i] = part.PartNumber
+	}
+
+	return UploadSessionResponse{
+		UploadID:      session.ID,
+		DocumentID:    session.DocumentID,
+		GraphID:       session.GraphID,
+		Filename:      session.Filename,
+		ContentType:   session.ContentType,
+		SizeBytes:     session.SizeBytes,
+		PartSize:      session.PartSize,
+		PartCount:     session.PartCount(),
+		UploadedParts: uploaded,
+		ExpiresAt:     session.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// respondUploadSessionError responds to a failed resumable upload operation
+func respondUploadSessionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUploadNotFound):
+		respondError(c, http.StatusNotFound, CodeUploadNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidUploadPart):
+		respondError(c, http.StatusBadRequest, CodeInvalidUploadPart, err.Error())
+	case errors.Is(err, service.ErrUploadIncomplete):
+		respondError(c, http.StatusConflict, CodeUploadIncomplete, err.Error())
+	default:
+		respondUploadError(c, err)
+	}
+}
+
+// InitiateUpload handles POST /api/documents/uploads
+func (h *DocumentHandler) InitiateUpload(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	var req models.InitiateUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	session, err := h.documentService.InitiateUpload(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondUploadSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toUploadSessionResponse(session, nil))
+}
+
+// GetUpload handles GET /api/documents/uploads/:uploadId
+func (h *DocumentHandler) GetUpload(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	session, parts, err := h.documentService.GetUpload(c.Request.Context(), c.Param("uploadId"), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrUploadNotFound) {
+			respondError(c, http.StatusNotFound, CodeUploadNotFound, err.Error())
+			return
+		}
+		respondInternalError(c, "Failed to get upload", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toUploadSessionResponse(session, parts))
+}
+
+// UploadPart handles PUT /api/documents/uploads/:uploadId/parts/:partNumber.
+// The request body is the part's raw bytes.
+func (h *DocumentHandler) UploadPart(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	partNumber, err := strconv.Atoi(c.Param("partNumber"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidUploadPart, "Part number must be an integer")
+		return
+	}
+
+	part, err := h.documentService.UploadPart(c.Request.Context(), c.Param("uploadId"), userID, partNumber, c.Request.Body)
+	if err != nil {
+		if errors.Is(err, service.ErrUploadNotFound) || errors.Is(err, service.ErrInvalidUploadPart) {
+			respondUploadSessionError(c, err)
+			return
 		}
+		respondInternalError(c, "Failed to upload part", err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, DocumentResponse{
-		ID:           doc.ID,
-		UserID:       doc.UserID,
-		GraphID:      doc.GraphID,
-		Filename:     doc.Filename,
-		ContentType:  doc.ContentType,
-		StorageKey:   doc.StorageKey,
-		SizeBytes:    doc.SizeBytes,
-		Source:       doc.Source,
-		Status:       doc.Status,
-		ErrorMessage: doc.ErrorMessage,
-		CreatedAt:    doc.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:    doc.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	})
+	c.JSON(http.StatusOK, UploadPartResponse{PartNumber: part.PartNumber, SizeBytes: part.SizeBytes})
+}
+
+// CompleteUpload handles POST /api/documents/uploads/:uploadId/complete
+func (h *DocumentHandler) CompleteUpload(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// The document is created and processed exactly like a single-request upload
+	doc, err := h.documentService.CompleteUpload(c.Request.Context(), c.Param("uploadId"), userID)
+	if err != nil {
+		respondUploadSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toDocumentResponse(doc))
+}
+
+// AbortUpload handles DELETE /api/documents/uploads/:uploadId
+func (h *DocumentHandler) AbortUpload(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	if err := h.documentService.AbortUpload(c.Request.Context(), c.Param("uploadId"), userID); err != nil {
+		if errors.Is(err, service.ErrUploadNotFound) {
+			respondError(c, http.StatusNotFound, CodeUploadNotFound, err.Error())
+			return
+		}
+		respondInternalError(c, "Failed to abort upload", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Upload aborted"})
 }
 
 // ListDocuments handles GET /api/documents
@@ -191,37 +496,32 @@ func (h *DocumentHandler) ListDocuments(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
-	// Get all documents for the user
-	docs, err := h.documentService.ListUserDocuments(c.Request.Context(), userID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list documents", "details": err.Error()})
+	page, ok := parsePageParamsOrRespond(c)
+	if !ok {
 		return
 	}
 
-	// Convert to response format
-	response := make([]DocumentResponse, len(docs))
-	for i, doc := range docs {
-		response[i] = DocumentResponse{
-			ID:           doc.ID,
-			UserID:       doc.UserID,
-			GraphID:      doc.GraphID,
-			Filename:     doc.Filename,
-			ContentType:  doc.ContentType,
-			StorageKey:   doc.StorageKey,
-			SizeBytes:    doc.SizeBytes,
-			Source:       doc.Source,
-			Status:       doc.Status,
-			ErrorMessage: doc.ErrorMessage,
-			CreatedAt:    doc.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt:    doc.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	// Get the documents the user can access (or authored, with ?scope=authored), optionally filtered by tag
+	// and graph, and sorted
+	filter := models.DocumentFilter{
+		Tag: c.Query("tag"), Scope: c.Query("scope"), GraphID: c.Query("graphId"), Sort: listSortFromQuery(c),
+		Limit: page.Limit, Offset: page.Offset,
+	}
+	docs, total, err := h.documentService.ListUserDocuments(c.Request.Context(), userID, filter)
+	if err != nil {
+		if isInvalidListFilter(err) {
+			respondError(c, http.StatusBadRequest, CodeInvalidListFilter, err.Error())
+			return
 		}
+		respondInternalError(c, "Failed to list documents", err)
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"documents": response})
+	c.JSON(http.StatusOK, newPaginatedResponse(toDocumentListResponse(c, docs), page, total))
 }
 
 // GetDocument handles GET /api/documents/:id
@@ -229,38 +529,78 @@ func (h *DocumentHandler) GetDocument(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get document ID from URL parameter
 	documentID := c.Param("id")
 	if documentID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Document ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Document ID is required")
 		return
 	}
 
 	// Get document
 	doc, err := h.documentService.GetDocument(c.Request.Context(), documentID, userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found", "details": err.Error()})
+		respondDocumentLookupError(c, err, "Failed to get document")
+		return
+	}
+
+	respondDocument(c, doc)
+}
+
+// respondDocument responds with a document's metadata, or 304 Not Modified if the client's
+// cached copy is current
+func respondDocument(c *gin.Context, doc *models.Document) {
+	response := toDocumentResponse(doc)
+	etag, err := responseETag(response)
+	if err != nil {
+		etag = "" // Fall back to Last-Modified alone
+	}
+
+	// Processing attempts are recorded without touching updated_at
+	lastModified := doc.UpdatedAt
+	if doc.LastAttemptAt != nil && doc.LastAttemptAt.After(lastModified) {
+		lastModified = *doc.LastAttemptAt
+	}
+
+	if checkNotModified(c, etag, lastModified) {
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// BatchStatus handles POST /api/documents/status
+// Returns the processing status of up to service.MaxBatchStatusDocuments documents in one call
+func (h *DocumentHandler) BatchStatus(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	var req BatchStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	statuses, err := h.documentService.BatchStatus(c.Request.Context(), userID, req.DocumentIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidBatchStatusRequest), errors.Is(err, service.ErrInvalidDocumentID):
+			respondError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		case errors.Is(err, service.ErrDocumentsNotAccessible):
+			respondError(c, http.StatusNotFound, CodeDocumentNotFound, err.Error())
+		default:
+			respondInternalError(c, "Failed to get document statuses", err)
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, DocumentResponse{
-		ID:           doc.ID,
-		UserID:       doc.UserID,
-		GraphID:      doc.GraphID,
-		Filename:     doc.Filename,
-		ContentType:  doc.ContentType,
-		StorageKey:   doc.StorageKey,
-		SizeBytes:    doc.SizeBytes,
-		Source:       doc.Source,
-		Status:       doc.Status,
-		ErrorMessage: doc.ErrorMessage,
-		CreatedAt:    doc.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:    doc.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	})
+	c.JSON(http.StatusOK, BatchStatusResponse{Statuses: statuses})
 }
 
 // UpdateDocument handles PUT /api/documents/:id
@@ -268,45 +608,44 @@ func (h *DocumentHandler) UpdateDocument(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get document ID from URL parameter
 	documentID := c.Param("id")
 	if documentID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Document ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Document ID is required")
 		return
 	}
 
 	// Parse request body
 	var req UpdateDocumentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
 		return
 	}
 
 	// Update document (with both plain text and Lexical state)
-	doc, err := h.documentService.UpdateDocument(c.Request.Context(), documentID, userID, req.Content, req.LexicalState)
+	doc, err := h.documentService.UpdateDocument(c.Request.Context(), documentID, userID, req.Content, req.LexicalState, req.ContentFormat)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update document", "details": err.Error()})
+		if errors.Is(err, service.ErrInvalidContentFormat) {
+			respondError(c, http.StatusBadRequest, CodeInvalidContentFormat, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrLexicalStateRequired) {
+			respondError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrUploadVerificationFailed) {
+			respondError(c, http.StatusBadGateway, CodeStorageUploadFailed, storageUploadFailedMessage)
+			return
+		}
+		respondInternalError(c, "Failed to update document", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, DocumentResponse{
-		ID:           doc.ID,
-		UserID:       doc.UserID,
-		GraphID:      doc.GraphID,
-		Filename:     doc.Filename,
-		ContentType:  doc.ContentType,
-		StorageKey:   doc.StorageKey,
-		SizeBytes:    doc.SizeBytes,
-		Source:       doc.Source,
-		Status:       doc.Status,
-		ErrorMessage: doc.ErrorMessage,
-		CreatedAt:    doc.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:    doc.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	})
+	c.JSON(http.StatusOK, toDocumentResponse(doc))
 }
 
 // DeleteDocument handles DELETE /api/documents/:id
@@ -314,21 +653,21 @@ func (h *DocumentHandler) DeleteDocument(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get document ID from URL parameter
 	documentID := c.Param("id")
 	if documentID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Document ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Document ID is required")
 		return
 	}
 
 	// Delete document
 	err := h.documentService.DeleteDocument(c.Request.Context(), documentID, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete document", "details": err.Error()})
+		respondInternalError(c, "Failed to delete document", err)
 		return
 	}
 
@@ -340,23 +679,159 @@ func (h *DocumentHandler) GetDocumentContent(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get document ID from URL parameter
 	documentID := c.Param("id")
 	if documentID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Document ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Document ID is required")
+		return
+	}
+
+	// Check the client's cached copy before downloading the content from storage
+	doc, err := h.documentService.GetDocument(c.Request.Context(), documentID, userID)
+	if err != nil {
+		respondDocumentLookupError(c, err, "Failed to get document")
+		return
+	}
+	if checkContentNotModified(c, doc) {
 		return
 	}
 
 	// Get document content
 	content, err := h.documentService.GetDocumentContent(c.Request.Context(), documentID, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get document content", "details": err.Error()})
+		respondDocumentLookupError(c, err, "Failed to get document content")
 		return
 	}
 
 	c.JSON(http.StatusOK, content)
 }
+
+// respondDocumentLookupError responds 404 for documents that don't exist or that the user
+// can't access, and 500 for anything else, such as database or storage failures
+func respondDocumentLookupError(c *gin.Context, err error, message string) {
+	if errors.Is(err, service.ErrDocumentNotFound) {
+		respondError(c, http.StatusNotFound, CodeDocumentNotFound, "Document not found")
+		return
+	}
+	respondInternalError(c, message, err)
+}
+
+// checkContentNotModified sets the cache validators of a document's content and reports
+// whether the response is already complete: 304 Not Modified if the client's cached copy is
+// current, or the headers alone for HEAD requests, which don't need the content downloaded
+func checkContentNotModified(c *gin.Context, doc *models.Document) bool {
+	if checkNotModified(c, contentETag(doc.ContentHash), doc.UpdatedAt) {
+		return true
+	}
+	if c.Request.Method == http.MethodHead {
+		c.Status(http.StatusOK)
+		return true
+	}
+	return false
+}
+
+// SetTags handles PUT /api/documents/:id/tags
+func (h *DocumentHandler) SetTags(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get document ID from URL parameter
+	documentID := c.Param("id")
+	if documentID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Document ID is required")
+		return
+	}
+
+	var req models.SetDocumentTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	doc, err := h.documentService.SetTags(c.Request.Context(), documentID, userID, req.Tags)
+	if err != nil {
+		h.handleTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDocumentResponse(doc))
+}
+
+// AddTag handles POST /api/documents/:id/tags
+func (h *DocumentHandler) AddTag(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get document ID from URL parameter
+	documentID := c.Param("id")
+	if documentID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Document ID is required")
+		return
+	}
+
+	var req models.AddDocumentTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	doc, err := h.documentService.AddTag(c.Request.Context(), documentID, userID, req.Tag)
+	if err != nil {
+		h.handleTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDocumentResponse(doc))
+}
+
+// RemoveTag handles DELETE /api/documents/:id/tags/:tag
+func (h *DocumentHandler) RemoveTag(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get document ID and tag from URL parameters
+	documentID := c.Param("id")
+	if documentID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Document ID is required")
+		return
+	}
+	tag := c.Param("tag")
+
+	doc, err := h.documentService.RemoveTag(c.Request.Context(), documentID, userID, tag)
+	if err != nil {
+		h.handleTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDocumentResponse(doc))
+}
+
+// handleTagError maps tag operation errors to HTTP responses
+func (h *DocumentHandler) handleTagError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTag),
+		errors.Is(err, service.ErrTagTooLong),
+		errors.Is(err, service.ErrTooManyTags):
+		respondError(c, http.StatusBadRequest, CodeInvalidTag, err.Error())
+	case errors.Is(err, service.ErrDocumentNotFound):
+		respondError(c, http.StatusNotFound, CodeDocumentNotFound, "Document not found")
+	default:
+		respondInternalError(c, "Failed to update document tags", err)
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// Error codes returned in ErrorResponse.Code. Codes are stable so clients can branch
+// on them; messages are for display and may change.
+const (
+	// Generic codes, used when no more specific code applies
+	CodeInvalidRequest     = "INVALID_REQUEST"
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodeForbidden          = "FORBIDDEN"
+	CodeNotFound           = "NOT_FOUND"
+	CodeConflict           = "CONFLICT"
+	CodeInternalError      = "INTERNAL_ERROR"
+	CodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+
+	// Authentication
+	CodeInvalidCredentials    = "INVALID_CREDENTIALS"
+	CodeUserAlreadyExists     = "USER_ALREADY_EXISTS"
+	CodeUnsupportedProvider   = "UNSUPPORTED_PROVIDER"
+	CodeOAuthFailed           = "OAUTH_FAILED"
+	CodeOwnsSharedGraphs      = "OWNS_SHARED_GRAPHS"
+	CodeEmailDomainNotAllowed = "EMAIL_DOMAIN_NOT_ALLOWED"
+	CodeInvalidResetToken     = "INVALID_RESET_TOKEN"
+
+	// Graphs
+	CodeGraphNotFound         = "GRAPH_NOT_FOUND"
+	CodeNotGraphMember        = "NOT_GRAPH_MEMBER"
+	CodeNotGraphCreator       = "NOT_GRAPH_CREATOR"
+	CodeMemberAlreadyExists   = "MEMBER_ALREADY_EXISTS"
+	CodeGraphLimitReached     = "GRAPH_LIMIT_REACHED"
+	CodeDuplicateGraphName    = "DUPLICATE_GRAPH_NAME"
+	CodeInvalidIdempotencyKey = "INVALID_IDEMPOTENCY_KEY"
+	CodeInvalidContentType    = "INVALID_CONTENT_TYPE"
+	CodeContentTypeNotAllowed = "CONTENT_TYPE_NOT_ALLOWED"
+	CodeInvalidRole           = "INVALID_ROLE"
+	CodeShareLinkNotFound     = "SHARE_LINK_NOT_FOUND"
+	CodeInvalidShareLink      = "INVALID_SHARE_LINK"
+	CodeInvalidShareExpiry    = "INVALID_SHARE_LINK_EXPIRY"
+	CodeReindexInProgress     = "REINDEX_IN_PROGRESS"
+	CodeReindexJobNotFound    = "REINDEX_JOB_NOT_FOUND"
+
+	// Documents
+	CodeDocumentNotFound     = "DOCUMENT_NOT_FOUND"
+	CodeInvalidTag           = "INVALID_TAG"
+	CodeInvalidListFilter    = "INVALID_LIST_FILTER"
+	CodeInvalidPagination    = "INVALID_PAGINATION"
+	CodeInvalidSearchQuery   = "INVALID_SEARCH_QUERY"
+	CodeInvalidContentFormat = "INVALID_CONTENT_FORMAT"
+	CodeUnsupportedFormat    = "UNSUPPORTED_FORMAT"
+	CodeFileTooLarge         = "FILE_TOO_LARGE"
+	CodePasswordProtected    = "PASSWORD_PROTECTED"
+	CodeInvalidFile          = "INVALID_FILE"
+	CodeExtractionTimeout    = "EXTRACTION_TIMEOUT"
+	CodeEmptyFile            = "EMPTY_FILE"
+	CodeExtractionFailed     = "EXTRACTION_FAILED"
+	CodeExtractionBusy       = "EXTRACTION_BUSY"
+	CodeSpreadsheetTooLarge  = "SPREADSHEET_TOO_LARGE"
+	CodeUploadNotFound       = "UPLOAD_NOT_FOUND"
+	CodeInvalidUploadPart    = "INVALID_UPLOAD_PART"
+	CodeUploadIncomplete     = "UPLOAD_INCOMPLETE"
+	CodeStorageUploadFailed  = "STORAGE_UPLOAD_FAILED"
+
+	// Chat
+	CodeThreadNotFound        = "THREAD_NOT_FOUND"
+	CodeThreadAccessDenied    = "THREAD_ACCESS_DENIED"
+	CodeMessageNotFound       = "MESSAGE_NOT_FOUND"
+	CodeInvalidMessageContent = "INVALID_MESSAGE_CONTENT"
+	CodeMessageTooLong        = "MESSAGE_TOO_LONG"
+	CodeInvalidFeedback       = "INVALID_FEEDBACK"
+	CodeTooManyChatGraphs     = "TOO_MANY_CHAT_GRAPHS"
+	CodeTooManyExcludedDocs   = "TOO_MANY_EXCLUDED_DOCUMENTS"
+	CodeInvalidExcludedDoc    = "INVALID_EXCLUDED_DOCUMENT"
+	CodeRateLimitExceeded     = "RATE_LIMIT_EXCEEDED"
+	CodeChatDisabled          = "CHAT_DISABLED"
+	CodeBudgetExceeded        = "CHAT_BUDGET_EXCEEDED"
+	CodeKnowledgeBaseNotReady = "KNOWLEDGE_BASE_NOT_READY"
+)
+
+// ErrorResponse is the body of every error response
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+// respondError writes an error response with the given status, code and message
+func respondError(c *gin.Context, status int, code, message string) {
+	c.JSON(status, ErrorResponse{Code: code, Message: message})
+}
+
+// respondErrorWithDetails writes an error response that also carries details
+func respondErrorWithDetails(c *gin.Context, status int, code, message string, details any) {
+	c.JSON(status, ErrorResponse{Code: code, Message: message, Details: details})
+}
+
+// respondInternalError logs err with the request ID and writes a 500 response. The
+// error's text can reveal queries, file paths and internal structure, so it is only
+// returned as details when Gin runs in debug mode, which the router enables only in
+// development; elsewhere clients get the message and can quote the X-Request-ID header.
+func respondInternalError(c *gin.Context, message string, err error) {
+	fmt.Printf("Error [request %s] %s %s: %s: %v\n", middleware.GetRequestID(c), c.Request.Method, c.Request.URL.Path, message, err)
+
+	if gin.IsDebugging() {
+		respondErrorWithDetails(c, http.StatusInternalServerError, CodeInternalError, message, err.Error())
+		return
+	}
+	respondError(c, http.StatusInternalServerError, CodeInternalError, message)
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/extraction"
+	"github.com/gin-gonic/gin"
+)
+
+// ExtractionHandler handles requests about document extraction
+type ExtractionHandler struct {
+	extractionService extraction.ExtractionService
+}
+
+// NewExtractionHandler creates a new instance of ExtractionHandler
+func NewExtractionHandler(extractionService extraction.ExtractionService) *ExtractionHandler {
+	return &ExtractionHandler{
+		extractionService: extractionService,
+	}
+}
+
+// ListFormats handles GET /api/extraction/formats
+// Lists the formats that can be uploaded, with what extraction keeps from them and their
+// effective size and time limits, so upload forms can give per-format guidance
+func (h *ExtractionHandler) ListFormats(c *gin.Context) {
+	formats := h.extractionService.Formats()
+
+	c.JSON(http.StatusOK, gin.H{
+		"formats": formats,
+	})
+}
@@ -2,7 +2,10 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/bipulkrdas/orgmind/backend/internal/middleware"
 	"github.com/bipulkrdas/orgmind/backend/internal/models"
@@ -14,14 +17,16 @@ import (
 type GraphHandler struct {
 	graphService    service.GraphService
 	documentService service.DocumentService
+	chatService     service.ChatService
 	zepService      service.ZepService
 }
 
 // NewGraphHandler creates a new instance of GraphHandler
-func NewGraphHandler(graphService service.GraphService, documentService service.DocumentService, zepService service.ZepService) *GraphHandler {
+func NewGraphHandler(graphService service.GraphService, documentService service.DocumentService, chatService service.ChatService, zepService service.ZepService) *GraphHandler {
 	return &GraphHandler{
 		graphService:    graphService,
 		documentService: documentService,
+		chatService:     chatService,
 		zepService:      zepService,
 	}
 }
@@ -34,8 +39,48 @@ type GraphResponse struct {
 	Name          string  `json:"name"`
 	Description   *string `json:"description,omitempty"`
 	DocumentCount int     `json:"documentCount"`
+	MemberCount   int     `json:"memberCount,omitempty"` // Only set when listing graphs
+	IsFavorite    bool    `json:"isFavorite"`
+	IsOwner       bool    `json:"isOwner"`
 	CreatedAt     string  `json:"createdAt"`
 	UpdatedAt     string  `json:"updatedAt"`
+
+	LastActivityAt string `json:"lastActivityAt"`
+
+	AllowedContentTypes []string `json:"allowedContentTypes"` // Empty = all supported formats
+	DefaultMemberRole   string   `json:"defaultMemberRole"`
+	ChatEnabled         bool     `json:"chatEnabled"`
+}
+
+// toGraphResponse converts a graph model to its API representation for the requesting user
+func toGraphResponse(graph *models.Graph, userID string) GraphResponse {
+	return GraphResponse{
+		ID:            graph.ID,
+		CreatorID:     graph.CreatorID,
+		ZepGraphID:    graph.ZepGraphID,
+		Name:          graph.Name,
+		Description:   graph.Description,
+		DocumentCount: graph.DocumentCount,
+		MemberCount:   graph.MemberCount,
+		IsFavorite:    graph.IsFavorite,
+		IsOwner:       graph.CreatorID == userID,
+		CreatedAt:     graph.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:     graph.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+
+		LastActivityAt: graph.LastActivityAt.Format("2006-01-02T15:04:05Z07:00"),
+
+		AllowedContentTypes: allowedContentTypes(graph.AllowedContentTypes),
+		DefaultMemberRole:   graph.DefaultMemberRole,
+		ChatEnabled:         graph.ChatEnabled,
+	}
+}
+
+// allowedContentTypes returns a graph's allowed content types, as an empty list rather than null
+func allowedContentTypes(contentTypes []string) []string {
+	if contentTypes == nil {
+		return []string{}
+	}
+	return contentTypes
 }
 
 // GraphMembershipResponse represents a graph membership in API responses
@@ -52,33 +97,45 @@ func (h *GraphHandler) CreateGraph(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	var req models.CreateGraphRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
 		return
 	}
+	req.IdempotencyKey = c.GetHeader("Idempotency-Key")
 
 	// Create graph
 	graph, err := h.graphService.Create(c.Request.Context(), userID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create graph", "details": err.Error()})
+		if errors.Is(err, service.ErrInvalidIdempotencyKey) {
+			respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidIdempotencyKey, "Invalid Idempotency-Key header", err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrGraphLimitReached) {
+			respondErrorWithDetails(c, http.StatusForbidden, CodeGraphLimitReached, "Graph limit reached", err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrDuplicateGraphName) {
+			respondError(c, http.StatusConflict, CodeDuplicateGraphName, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrInvalidAllowedContentTypes) {
+			respondError(c, http.StatusBadRequest, CodeInvalidContentType, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrInvalidRole) {
+			respondError(c, http.StatusBadRequest, CodeInvalidRole, err.Error())
+			return
+		}
+		respondInternalError(c, "Failed to create graph", err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, GraphResponse{
-		ID:            graph.ID,
-		CreatorID:     graph.CreatorID,
-		ZepGraphID:    graph.ZepGraphID,
-		Name:          graph.Name,
-		Description:   graph.Description,
-		DocumentCount: graph.DocumentCount,
-		CreatedAt:     graph.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:     graph.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	})
+	c.JSON(http.StatusCreated, toGraphResponse(graph, userID))
 }
 
 // ListGraphs handles GET /api/graphs
@@ -86,33 +143,34 @@ func (h *GraphHandler) ListGraphs(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	page, ok := parsePageParamsOrRespond(c)
+	if !ok {
 		return
 	}
 
-	// List all graphs the user is a member of
-	graphs, err := h.graphService.ListByUserID(c.Request.Context(), userID)
+	// List the graphs the user is a member of (only their own or only shared ones with ?role=), in the requested order
+	filter := models.GraphFilter{Role: c.Query("role"), Sort: listSortFromQuery(c), Limit: page.Limit, Offset: page.Offset}
+	graphs, total, err := h.graphService.ListByUserID(c.Request.Context(), userID, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list graphs", "details": err.Error()})
+		if isInvalidListFilter(err) {
+			respondError(c, http.StatusBadRequest, CodeInvalidListFilter, err.Error())
+			return
+		}
+		respondInternalError(c, "Failed to list graphs", err)
 		return
 	}
 
 	// Convert to response format
 	response := make([]GraphResponse, len(graphs))
 	for i, graph := range graphs {
-		response[i] = GraphResponse{
-			ID:            graph.ID,
-			CreatorID:     graph.CreatorID,
-			ZepGraphID:    graph.ZepGraphID,
-			Name:          graph.Name,
-			Description:   graph.Description,
-			DocumentCount: graph.DocumentCount,
-			CreatedAt:     graph.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt:     graph.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		}
+		response[i] = toGraphResponse(graph, userID)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"graphs": response})
+	c.JSON(http.StatusOK, newPaginatedResponse(response, page, total))
 }
 
 // GetGraph handles GET /api/graphs/:id
@@ -120,14 +178,14 @@ func (h *GraphHandler) GetGraph(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get graph ID from URL parameter
 	graphID := c.Param("id")
 	if graphID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Graph ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
 		return
 	}
 
@@ -135,27 +193,26 @@ func (h *GraphHandler) GetGraph(c *gin.Context) {
 	graph, err := h.graphService.GetByID(c.Request.Context(), graphID, userID)
 	if err != nil {
 		if errors.Is(err, service.ErrGraphNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Graph not found"})
+			respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
 			return
 		}
 		if errors.Is(err, service.ErrNotGraphMember) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this graph"})
+			respondError(c, http.StatusForbidden, CodeNotGraphMember, "You don't have access to this graph")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get graph", "details": err.Error()})
+		respondInternalError(c, "Failed to get graph", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, GraphResponse{
-		ID:            graph.ID,
-		CreatorID:     graph.CreatorID,
-		ZepGraphID:    graph.ZepGraphID,
-		Name:          graph.Name,
-		Description:   graph.Description,
-		DocumentCount: graph.DocumentCount,
-		CreatedAt:     graph.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:     graph.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	})
+	// The favorite flag is per-user, so it isn't part of the stored graph
+	isFavorite, err := h.graphService.IsFavorite(c.Request.Context(), graphID, userID)
+	if err != nil {
+		respondInternalError(c, "Failed to get graph", err)
+		return
+	}
+	graph.IsFavorite = isFavorite
+
+	c.JSON(http.StatusOK, toGraphResponse(graph, userID))
 }
 
 // UpdateGraph handles PUT /api/graphs/:id
@@ -163,20 +220,20 @@ func (h *GraphHandler) UpdateGraph(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get graph ID from URL parameter
 	graphID := c.Param("id")
 	if graphID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Graph ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
 		return
 	}
 
 	var req models.UpdateGraphRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
 		return
 	}
 
@@ -184,27 +241,30 @@ func (h *GraphHandler) UpdateGraph(c *gin.Context) {
 	graph, err := h.graphService.Update(c.Request.Context(), graphID, userID, &req)
 	if err != nil {
 		if errors.Is(err, service.ErrGraphNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Graph not found"})
+			respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
 			return
 		}
 		if errors.Is(err, service.ErrNotGraphCreator) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the graph creator can update this graph"})
+			respondError(c, http.StatusForbidden, CodeNotGraphCreator, "Only the graph creator can update this graph")
+			return
+		}
+		if errors.Is(err, service.ErrDuplicateGraphName) {
+			respondError(c, http.StatusConflict, CodeDuplicateGraphName, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrInvalidAllowedContentTypes) {
+			respondError(c, http.StatusBadRequest, CodeInvalidContentType, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrInvalidRole) {
+			respondError(c, http.StatusBadRequest, CodeInvalidRole, err.Error())
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update graph", "details": err.Error()})
+		respondInternalError(c, "Failed to update graph", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, GraphResponse{
-		ID:            graph.ID,
-		CreatorID:     graph.CreatorID,
-		ZepGraphID:    graph.ZepGraphID,
-		Name:          graph.Name,
-		Description:   graph.Description,
-		DocumentCount: graph.DocumentCount,
-		CreatedAt:     graph.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:     graph.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	})
+	c.JSON(http.StatusOK, toGraphResponse(graph, userID))
 }
 
 // DeleteGraph handles DELETE /api/graphs/:id
@@ -212,14 +272,14 @@ func (h *GraphHandler) DeleteGraph(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get graph ID from URL parameter
 	graphID := c.Param("id")
 	if graphID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Graph ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
 		return
 	}
 
@@ -227,14 +287,14 @@ func (h *GraphHandler) DeleteGraph(c *gin.Context) {
 	err := h.graphService.Delete(c.Request.Context(), graphID, userID)
 	if err != nil {
 		if errors.Is(err, service.ErrGraphNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Graph not found"})
+			respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
 			return
 		}
 		if errors.Is(err, service.ErrNotGraphCreator) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the graph creator can delete this graph"})
+			respondError(c, http.StatusForbidden, CodeNotGraphCreator, "Only the graph creator can delete this graph")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete graph", "details": err.Error()})
+		respondInternalError(c, "Failed to delete graph", err)
 		return
 	}
 
@@ -246,20 +306,20 @@ func (h *GraphHandler) AddMember(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get graph ID from URL parameter
 	graphID := c.Param("id")
 	if graphID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Graph ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
 		return
 	}
 
 	var req models.AddMemberRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
 		return
 	}
 
@@ -267,44 +327,100 @@ func (h *GraphHandler) AddMember(c *gin.Context) {
 	err := h.graphService.AddMember(c.Request.Context(), graphID, userID, &req)
 	if err != nil {
 		if errors.Is(err, service.ErrGraphNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Graph not found"})
+			respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
 			return
 		}
 		if errors.Is(err, service.ErrNotGraphCreator) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the graph creator can add members"})
+			respondError(c, http.StatusForbidden, CodeNotGraphCreator, "Only the graph creator can add members")
 			return
 		}
 		if errors.Is(err, service.ErrMemberAlreadyExists) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User is already a member of this graph"})
+			respondError(c, http.StatusBadRequest, CodeMemberAlreadyExists, "User is already a member of this graph")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidRole) {
+			respondError(c, http.StatusBadRequest, CodeInvalidRole, err.Error())
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member", "details": err.Error()})
+		respondInternalError(c, "Failed to add member", err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"message": "Member added successfully"})
 }
 
+// AddMembers handles POST /api/graphs/:id/members/bulk
+// Adds up to service.MaxBulkMembers members, reporting each entry's outcome
+func (h *GraphHandler) AddMembers(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get graph ID from URL parameter
+	graphID := c.Param("id")
+	if graphID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return
+	}
+
+	var req models.AddMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	// Add members (creator verification happens in service)
+	results, err := h.graphService.AddMembers(c.Request.Context(), graphID, userID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBulkMembers) {
+			respondError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrGraphNotFound) {
+			respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
+			return
+		}
+		if errors.Is(err, service.ErrNotGraphCreator) {
+			respondError(c, http.StatusForbidden, CodeNotGraphCreator, "Only the graph creator can add members")
+			return
+		}
+		respondInternalError(c, "Failed to add members", err)
+		return
+	}
+
+	added := 0
+	for _, result := range results {
+		if result.Status == models.AddMemberResultAdded {
+			added++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"added": added, "results": results})
+}
+
 // RemoveMember handles DELETE /api/graphs/:id/members/:userId
 func (h *GraphHandler) RemoveMember(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get graph ID from URL parameter
 	graphID := c.Param("id")
 	if graphID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Graph ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
 		return
 	}
 
 	// Get member user ID from URL parameter
 	memberUserID := c.Param("userId")
 	if memberUserID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "User ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "User ID is required")
 		return
 	}
 
@@ -312,14 +428,14 @@ func (h *GraphHandler) RemoveMember(c *gin.Context) {
 	err := h.graphService.RemoveMember(c.Request.Context(), graphID, userID, memberUserID)
 	if err != nil {
 		if errors.Is(err, service.ErrGraphNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Graph not found"})
+			respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
 			return
 		}
 		if errors.Is(err, service.ErrNotGraphCreator) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the graph creator can remove members"})
+			respondError(c, http.StatusForbidden, CodeNotGraphCreator, "Only the graph creator can remove members")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member", "details": err.Error()})
+		respondInternalError(c, "Failed to remove member", err)
 		return
 	}
 
@@ -331,29 +447,34 @@ func (h *GraphHandler) ListMembers(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get graph ID from URL parameter
 	graphID := c.Param("id")
 	if graphID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Graph ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return
+	}
+
+	page, ok := parsePageParamsOrRespond(c)
+	if !ok {
 		return
 	}
 
 	// List members (membership verification happens in service)
-	members, err := h.graphService.ListMembers(c.Request.Context(), graphID, userID)
+	members, total, err := h.graphService.ListMembers(c.Request.Context(), graphID, userID, page.Limit, page.Offset)
 	if err != nil {
 		if errors.Is(err, service.ErrGraphNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Graph not found"})
+			respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
 			return
 		}
 		if errors.Is(err, service.ErrNotGraphMember) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this graph"})
+			respondError(c, http.StatusForbidden, CodeNotGraphMember, "You don't have access to this graph")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list members", "details": err.Error()})
+		respondInternalError(c, "Failed to list members", err)
 		return
 	}
 
@@ -369,7 +490,7 @@ func (h *GraphHandler) ListMembers(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"members": response})
+	c.JSON(http.StatusOK, newPaginatedResponse(response, page, total))
 }
 
 // ListGraphDocuments handles GET /api/graphs/:id/documents
@@ -377,14 +498,19 @@ func (h *GraphHandler) ListGraphDocuments(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get graph ID from URL parameter
 	graphID := c.Param("id")
 	if graphID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Graph ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return
+	}
+
+	page, ok := parsePageParamsOrRespond(c)
+	if !ok {
 		return
 	}
 
@@ -392,43 +518,263 @@ func (h *GraphHandler) ListGraphDocuments(c *gin.Context) {
 	_, err := h.graphService.GetByID(c.Request.Context(), graphID, userID)
 	if err != nil {
 		if errors.Is(err, service.ErrGraphNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Graph not found"})
+			respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
 			return
 		}
 		if errors.Is(err, service.ErrNotGraphMember) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this graph"})
+			respondError(c, http.StatusForbidden, CodeNotGraphMember, "You don't have access to this graph")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify graph access", "details": err.Error()})
+		respondInternalError(c, "Failed to verify graph access", err)
 		return
 	}
 
-	// List documents for the graph
-	docs, err := h.documentService.ListGraphDocuments(c.Request.Context(), graphID)
+	// List documents for the graph, optionally filtered by tag and sorted
+	filter := models.DocumentFilter{Tag: c.Query("tag"), Sort: listSortFromQuery(c), Limit: page.Limit, Offset: page.Offset}
+	docs, total, err := h.documentService.ListGraphDocuments(c.Request.Context(), graphID, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list documents", "details": err.Error()})
+		if isInvalidListFilter(err) {
+			respondError(c, http.StatusBadRequest, CodeInvalidListFilter, err.Error())
+			return
+		}
+		respondInternalError(c, "Failed to list documents", err)
 		return
 	}
 
-	// Convert to response format
-	response := make([]DocumentResponse, len(docs))
-	for i, doc := range docs {
-		response[i] = DocumentResponse{
-			ID:          doc.ID,
-			UserID:      doc.UserID,
-			GraphID:     doc.GraphID,
-			Filename:    doc.Filename,
-			ContentType: doc.ContentType,
-			StorageKey:  doc.StorageKey,
-			SizeBytes:   doc.SizeBytes,
-			Source:      doc.Source,
-			Status:      doc.Status,
-			CreatedAt:   doc.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt:   doc.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	c.JSON(http.StatusOK, newPaginatedResponse(toDocumentListResponse(c, docs), page, total))
+}
+
+// Sources of graph search results, as reported in GraphSearchResponse.Unavailable
+const (
+	searchSourceDocuments = "documents"
+	searchSourceMemory    = "memory"
+)
+
+// GraphSearchResponse is the result of a graph search: a page of matching documents and
+// relevant knowledge graph facts. Sources that failed are listed in Unavailable and return
+// no results.
+type GraphSearchResponse struct {
+	Query       string                              `json:"query"`
+	Documents   PaginatedResponse[DocumentResponse] `json:"documents"`
+	Memory      []models.MemoryResult               `json:"memory"`
+	Unavailable []string                            `json:"unavailable,omitempty"`
+}
+
+// SearchGraph handles GET /api/graphs/:id/search?q=
+// Searches the graph's documents and its knowledge graph in parallel. Matching documents
+// are paged with limit and offset or cursor, like other listings; memory results aren't.
+func (h *GraphHandler) SearchGraph(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get graph ID from URL parameter
+	graphID := c.Param("id")
+	if graphID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return
+	}
+
+	query, err := service.ValidateSearchQuery(c.Query("q"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidSearchQuery, err.Error())
+		return
+	}
+
+	page, ok := parsePageParamsOrRespond(c)
+	if !ok {
+		return
+	}
+
+	// Verify membership and get the graph's Zep graph ID
+	graph, err := h.graphService.GetByID(c.Request.Context(), graphID, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrGraphNotFound) {
+			respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
+			return
 		}
+		if errors.Is(err, service.ErrNotGraphMember) {
+			respondError(c, http.StatusForbidden, CodeNotGraphMember, "You don't have access to this graph")
+			return
+		}
+		respondInternalError(c, "Failed to verify graph access", err)
+		return
+	}
+
+	var (
+		wg                 sync.WaitGroup
+		docs               []*models.Document
+		total              int
+		memory             []models.MemoryResult
+		docsErr, memoryErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		docs, total, docsErr = h.documentService.SearchGraphDocuments(c.Request.Context(), graphID, query, page.Limit, page.Offset)
+	}()
+	go func() {
+		defer wg.Done()
+		memory, memoryErr = h.zepService.SearchMemory(c.Request.Context(), graph.ZepGraphID, query)
+	}()
+	wg.Wait()
+
+	if docsErr != nil && memoryErr != nil {
+		respondInternalError(c, "Failed to search graph", errors.Join(docsErr, memoryErr))
+		return
+	}
+
+	// One failing source still leaves useful results, so report it instead of failing
+	response := GraphSearchResponse{
+		Query:     query,
+		Documents: newPaginatedResponse(toDocumentListResponse(c, docs), page, total),
+		Memory:    memory,
+	}
+	if docsErr != nil {
+		fmt.Printf("Warning [request %s] document search failed for graph %s: %v\n", middleware.GetRequestID(c), graphID, docsErr)
+		response.Unavailable = append(response.Unavailable, searchSourceDocuments)
+	}
+	if memoryErr != nil {
+		fmt.Printf("Warning [request %s] memory search failed for graph %s: %v\n", middleware.GetRequestID(c), graphID, memoryErr)
+		response.Unavailable = append(response.Unavailable, searchSourceMemory)
+	}
+	if response.Memory == nil {
+		response.Memory = []models.MemoryResult{}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"documents": response})
+	c.JSON(http.StatusOK, response)
+}
+
+// GetGraphStats handles GET /api/graphs/:id/stats
+// Returns the graph's document counts by processing status and its month-to-date Gemini usage
+func (h *GraphHandler) GetGraphStats(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get graph ID from URL parameter
+	graphID := c.Param("id")
+	if graphID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return
+	}
+
+	// Verify membership before counting documents
+	_, err := h.graphService.GetByID(c.Request.Context(), graphID, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrGraphNotFound) {
+			respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
+			return
+		}
+		if errors.Is(err, service.ErrNotGraphMember) {
+			respondError(c, http.StatusForbidden, CodeNotGraphMember, "You don't have access to this graph")
+			return
+		}
+		respondInternalError(c, "Failed to verify graph access", err)
+		return
+	}
+
+	stats, err := h.documentService.GetGraphStats(c.Request.Context(), graphID)
+	if err != nil {
+		respondInternalError(c, "Failed to get graph stats", err)
+		return
+	}
+
+	stats.GeminiUsage, err = h.chatService.GetGeminiUsage(c.Request.Context(), graphID)
+	if err != nil {
+		respondInternalError(c, "Failed to get Gemini usage", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetGraphDocument handles GET /api/graphs/:id/documents/:docId
+// Authorizes by membership of the path's graph and requires the document to belong to it
+func (h *GraphHandler) GetGraphDocument(c *gin.Context) {
+	userID, graphID, documentID, ok := graphDocumentParams(c)
+	if !ok {
+		return
+	}
+
+	doc, err := h.documentService.GetGraphDocument(c.Request.Context(), graphID, documentID, userID)
+	if err != nil {
+		handleGraphDocumentError(c, err, "Failed to get document")
+		return
+	}
+
+	respondDocument(c, doc)
+}
+
+// GetGraphDocumentContent handles GET /api/graphs/:id/documents/:docId/content
+func (h *GraphHandler) GetGraphDocumentContent(c *gin.Context) {
+	userID, graphID, documentID, ok := graphDocumentParams(c)
+	if !ok {
+		return
+	}
+
+	// Check the client's cached copy before downloading the content from storage
+	doc, err := h.documentService.GetGraphDocument(c.Request.Context(), graphID, documentID, userID)
+	if err != nil {
+		handleGraphDocumentError(c, err, "Failed to get document content")
+		return
+	}
+	if checkContentNotModified(c, doc) {
+		return
+	}
+
+	content, err := h.documentService.GetGraphDocumentContent(c.Request.Context(), graphID, documentID, userID)
+	if err != nil {
+		handleGraphDocumentError(c, err, "Failed to get document content")
+		return
+	}
+
+	c.JSON(http.StatusOK, content)
+}
+
+// graphDocumentParams extracts the user, graph and document IDs for graph-scoped document
+// routes, responding with an error and returning false if any is missing
+func graphDocumentParams(c *gin.Context) (userID, graphID, documentID string, ok bool) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok = middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return "", "", "", false
+	}
+
+	graphID = c.Param("id")
+	if graphID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return "", "", "", false
+	}
+
+	documentID = c.Param("docId")
+	if documentID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Document ID is required")
+		return "", "", "", false
+	}
+
+	return userID, graphID, documentID, true
+}
+
+// handleGraphDocumentError maps errors from graph-scoped document operations to responses
+func handleGraphDocumentError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrGraphNotFound):
+		respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
+	case errors.Is(err, service.ErrNotGraphMember):
+		respondError(c, http.StatusForbidden, CodeNotGraphMember, "You don't have access to this graph")
+	case errors.Is(err, service.ErrDocumentNotInGraph):
+		respondError(c, http.StatusNotFound, CodeDocumentNotFound, "Document not found in this graph")
+	default:
+		respondInternalError(c, message, err)
+	}
 }
 
 // GetGraphVisualization handles GET /api/graphs/:id/visualization
@@ -436,14 +782,14 @@ func (h *GraphHandler) GetGraphVisualization(c *gin.Context) {
 	// Extract userID from JWT token (set by auth middleware)
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
 		return
 	}
 
 	// Get graph ID from URL parameter
 	graphID := c.Param("id")
 	if graphID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Graph ID is required"})
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
 		return
 	}
 
@@ -454,23 +800,524 @@ func (h *GraphHandler) GetGraphVisualization(c *gin.Context) {
 	graph, err := h.graphService.GetByID(c.Request.Context(), graphID, userID)
 	if err != nil {
 		if errors.Is(err, service.ErrGraphNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Graph not found"})
+			respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
 			return
 		}
 		if errors.Is(err, service.ErrNotGraphMember) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this graph"})
+			respondError(c, http.StatusForbidden, CodeNotGraphMember, "You don't have access to this graph")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify graph access", "details": err.Error()})
+		respondInternalError(c, "Failed to verify graph access", err)
 		return
 	}
 
+	// Let clients revalidate a settled graph's visualization instead of querying Zep again
+	lastChange, err := h.documentService.LastGraphChange(c.Request.Context(), graph)
+	if err != nil {
+		respondInternalError(c, "Failed to get graph visualization", err)
+		return
+	}
+	if time.Since(lastChange) >= visualizationSettleTime {
+		etag, err := responseETag(visualizationVersion{
+			ZepGraphID:    graph.ZepGraphID,
+			Query:         query,
+			DocumentCount: graph.DocumentCount,
+			LastChange:    lastChange,
+		})
+		if err != nil {
+			respondInternalError(c, "Failed to get graph visualization", err)
+			return
+		}
+		// Deleted documents don't move lastChange, so only the ETag, which also covers the
+		// document count, can tell whether the visualization is current
+		if checkETagNotModified(c, etag) {
+			return
+		}
+	}
+
 	// Get graph visualization data from Zep with query filter
 	graphData, err := h.zepService.GetGraph(c.Request.Context(), graph.ZepGraphID, query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get graph visualization", "details": err.Error()})
+		respondInternalError(c, "Failed to get graph visualization", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, graphData)
+}
+
+// visualizationVersion identifies the state of a graph a visualization was built from.
+// The document count catches deleted documents, which leave no later update behind.
+type visualizationVersion struct {
+	ZepGraphID    string    `json:"zepGraphId"`
+	Query         string    `json:"query"`
+	DocumentCount int       `json:"documentCount"`
+	LastChange    time.Time `json:"lastChange"`
+}
+
+// VerifyDocumentIntegrity handles GET /api/graphs/:id/integrity
+// Checks that every document in the graph still has its storage object (creator only)
+func (h *GraphHandler) VerifyDocumentIntegrity(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get graph ID from URL parameter
+	graphID := c.Param("id")
+	if graphID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return
+	}
+
+	if !h.requireGraphCreator(c, graphID, userID, "Only the graph creator can verify document integrity") {
+		return
+	}
+
+	report, err := h.documentService.VerifyIntegrity(c.Request.Context(), graphID)
+	if err != nil {
+		respondInternalError(c, "Failed to verify document integrity", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ReindexGraph handles POST /api/graphs/:id/reindex
+// Starts rebuilding the graph's Zep data from its documents' stored content (creator only).
+// The rebuild runs in the background; its progress is reported by GetReindexStatus.
+func (h *GraphHandler) ReindexGraph(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get graph ID from URL parameter
+	graphID := c.Param("id")
+	if graphID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return
+	}
+
+	// The body is optional; without one the existing Zep data is kept
+	var req models.ReindexGraphRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
+			return
+		}
+	}
+
+	// Creator verification happens in service
+	job, err := h.documentService.ReindexGraph(c.Request.Context(), graphID, userID, req.ClearGraph)
+	if err != nil {
+		if errors.Is(err, service.ErrGraphNotFound) {
+			respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
+			return
+		}
+		if errors.Is(err, service.ErrNotGraphCreator) {
+			respondError(c, http.StatusForbidden, CodeNotGraphCreator, "Only the graph creator can reindex the graph")
+			return
+		}
+		if errors.Is(err, service.ErrReindexInProgress) {
+			respondError(c, http.StatusConflict, CodeReindexInProgress, "A reindex of this graph is already running")
+			return
+		}
+		respondInternalError(c, "Failed to start reindex", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// ReextractGraph handles POST /api/graphs/:id/reextract
+// Starts re-extracting the graph's uploaded documents that an older extractor read, so
+// extraction improvements reach existing documents (creator only). It runs as a reindex
+// job, whose progress is reported by GetReindexStatus.
+func (h *GraphHandler) ReextractGraph(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get graph ID from URL parameter
+	graphID := c.Param("id")
+	if graphID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return
+	}
+
+	// The body is optional; without one documents of every format are re-extracted
+	var req models.ReextractGraphRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
+			return
+		}
+	}
+
+	// Creator verification happens in service
+	job, err := h.documentService.ReextractAll(c.Request.Context(), graphID, userID, req.ContentType)
+	if err != nil {
+		if errors.Is(err, service.ErrGraphNotFound) {
+			respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
+			return
+		}
+		if errors.Is(err, service.ErrNotGraphCreator) {
+			respondError(c, http.StatusForbidden, CodeNotGraphCreator, "Only the graph creator can re-extract the graph's documents")
+			return
+		}
+		if errors.Is(err, service.ErrReextractFormat) {
+			respondError(c, http.StatusBadRequest, CodeUnsupportedFormat, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrReindexInProgress) {
+			respondError(c, http.StatusConflict, CodeReindexInProgress, "A reindex of this graph is already running")
+			return
+		}
+		respondInternalError(c, "Failed to start re-extraction", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetReindexStatus handles GET /api/graphs/:id/reindex
+// Returns the progress of the graph's latest reindex (creator only)
+func (h *GraphHandler) GetReindexStatus(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get graph ID from URL parameter
+	graphID := c.Param("id")
+	if graphID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return
+	}
+
+	if !h.requireGraphCreator(c, graphID, userID, "Only the graph creator can view reindex status") {
+		return
+	}
+
+	job, err := h.documentService.GetReindexJob(c.Request.Context(), graphID)
+	if err != nil {
+		if errors.Is(err, service.ErrReindexJobNotFound) {
+			respondError(c, http.StatusNotFound, CodeReindexJobNotFound, "This graph has not been reindexed")
+			return
+		}
+		respondInternalError(c, "Failed to get reindex status", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// requireGraphCreator verifies membership, then restricts access to the graph creator,
+// responding with forbiddenMessage otherwise. It reports whether the request may proceed.
+func (h *GraphHandler) requireGraphCreator(c *gin.Context, graphID, userID, forbiddenMessage string) bool {
+	graph, err := h.graphService.GetByID(c.Request.Context(), graphID, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrGraphNotFound) {
+			respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
+			return false
+		}
+		if errors.Is(err, service.ErrNotGraphMember) {
+			respondError(c, http.StatusForbidden, CodeNotGraphMember, "You don't have access to this graph")
+			return false
+		}
+		respondInternalError(c, "Failed to verify graph access", err)
+		return false
+	}
+	if graph.CreatorID != userID {
+		respondError(c, http.StatusForbidden, CodeNotGraphCreator, forbiddenMessage)
+		return false
+	}
+	return true
+}
+
+// AddFavorite handles POST /api/graphs/:id/favorite
+// Pins the graph to the top of the user's graph list
+func (h *GraphHandler) AddFavorite(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get graph ID from URL parameter
+	graphID := c.Param("id")
+	if graphID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return
+	}
+
+	// Add favorite (membership verification happens in service)
+	err := h.graphService.AddFavorite(c.Request.Context(), graphID, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrGraphNotFound) {
+			respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
+			return
+		}
+		if errors.Is(err, service.ErrNotGraphMember) {
+			respondError(c, http.StatusForbidden, CodeNotGraphMember, "You don't have access to this graph")
+			return
+		}
+		respondInternalError(c, "Failed to add favorite", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Graph added to favorites"})
+}
+
+// RemoveFavorite handles DELETE /api/graphs/:id/favorite
+// Unpins the graph from the user's graph list
+func (h *GraphHandler) RemoveFavorite(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get graph ID from URL parameter
+	graphID := c.Param("id")
+	if graphID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return
+	}
+
+	if err := h.graphService.RemoveFavorite(c.Request.Context(), graphID, userID); err != nil {
+		respondInternalError(c, "Failed to remove favorite", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Graph removed from favorites"})
+}
+
+// ShareLinkResponse represents a graph share link in API responses. Token is only
+// included when the link is created.
+type ShareLinkResponse struct {
+	ID        string  `json:"id"`
+	GraphID   string  `json:"graphId"`
+	CreatedBy string  `json:"createdBy"`
+	Token     string  `json:"token,omitempty"`
+	Active    bool    `json:"active"`
+	ExpiresAt *string `json:"expiresAt,omitempty"`
+	RevokedAt *string `json:"revokedAt,omitempty"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+// toShareLinkResponse converts a share link model to its API response
+func toShareLinkResponse(link *models.GraphShareLink) ShareLinkResponse {
+	return ShareLinkResponse{
+		ID:        link.ID,
+		GraphID:   link.GraphID,
+		CreatedBy: link.CreatedBy,
+		Active:    link.IsActive(time.Now()),
+		ExpiresAt: formatOptionalTime(link.ExpiresAt),
+		RevokedAt: formatOptionalTime(link.RevokedAt),
+		CreatedAt: link.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// respondShareLinkError responds to errors from share link management
+func respondShareLinkError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrGraphNotFound):
+		respondError(c, http.StatusNotFound, CodeGraphNotFound, "Graph not found")
+	case errors.Is(err, service.ErrNotGraphCreator):
+		respondError(c, http.StatusForbidden, CodeNotGraphCreator, "Only the graph creator can manage share links")
+	case errors.Is(err, service.ErrShareLinkNotFound):
+		respondError(c, http.StatusNotFound, CodeShareLinkNotFound, "Share link not found")
+	case errors.Is(err, service.ErrInvalidShareLinkExpiry):
+		respondError(c, http.StatusBadRequest, CodeInvalidShareExpiry, err.Error())
+	default:
+		respondInternalError(c, message, err)
+	}
+}
+
+// CreateShareLink handles POST /api/graphs/:id/share-links
+// Creates a read-only public link to the graph's visualization and document list (creator only)
+func (h *GraphHandler) CreateShareLink(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get graph ID from URL parameter
+	graphID := c.Param("id")
+	if graphID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return
+	}
+
+	// The body is optional; without one the link lasts until revoked
+	var req models.CreateShareLinkRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondErrorWithDetails(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request body", err.Error())
+			return
+		}
+	}
+
+	link, token, err := h.graphService.CreateShareLink(c.Request.Context(), graphID, userID, req.ExpiresAt)
+	if err != nil {
+		respondShareLinkError(c, err, "Failed to create share link")
+		return
+	}
+
+	response := toShareLinkResponse(link)
+	response.Token = token
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListShareLinks handles GET /api/graphs/:id/share-links (creator only)
+func (h *GraphHandler) ListShareLinks(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get graph ID from URL parameter
+	graphID := c.Param("id")
+	if graphID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID is required")
+		return
+	}
+
+	page, ok := parsePageParamsOrRespond(c)
+	if !ok {
+		return
+	}
+
+	links, total, err := h.graphService.ListShareLinks(c.Request.Context(), graphID, userID, page.Limit, page.Offset)
+	if err != nil {
+		respondShareLinkError(c, err, "Failed to list share links")
+		return
+	}
+
+	response := make([]ShareLinkResponse, len(links))
+	for i, link := range links {
+		response[i] = toShareLinkResponse(link)
+	}
+
+	c.JSON(http.StatusOK, newPaginatedResponse(response, page, total))
+}
+
+// RevokeShareLink handles DELETE /api/graphs/:id/share-links/:linkId (creator only)
+func (h *GraphHandler) RevokeShareLink(c *gin.Context) {
+	// Extract userID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeUnauthorized, "User ID not found in token")
+		return
+	}
+
+	// Get graph and link IDs from URL parameters
+	graphID := c.Param("id")
+	linkID := c.Param("linkId")
+	if graphID == "" || linkID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, "Graph ID and share link ID are required")
+		return
+	}
+
+	if err := h.graphService.RevokeShareLink(c.Request.Context(), graphID, userID, linkID); err != nil {
+		respondShareLinkError(c, err, "Failed to revoke share link")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Share link revoked"})
+}
+
+// SharedDocumentResponse represents a document listed through a share link. It leaves out
+// owner, storage and error details that anonymous viewers shouldn't see.
+type SharedDocumentResponse struct {
+	ID          string   `json:"id"`
+	Filename    *string  `json:"filename,omitempty"`
+	ContentType *string  `json:"contentType,omitempty"`
+	SizeBytes   int64    `json:"sizeBytes"`
+	Source      string   `json:"source"`
+	Status      string   `json:"status"`
+	Tags        []string `json:"tags"`
+	CreatedAt   string   `json:"createdAt"`
+	UpdatedAt   string   `json:"updatedAt"`
+}
+
+// GetSharedGraphVisualization handles GET /api/shared/:token/visualization
+// Read-only access through a share link (set by share link middleware)
+func (h *GraphHandler) GetSharedGraphVisualization(c *gin.Context) {
+	graph, ok := middleware.GetSharedGraph(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeInvalidShareLink, "Share link not found in request")
+		return
+	}
+
+	// Get query parameter (default to "all" if not provided)
+	query := c.DefaultQuery("query", "all")
+
+	graphData, err := h.zepService.GetGraph(c.Request.Context(), graph.ZepGraphID, query)
+	if err != nil {
+		respondInternalError(c, "Failed to get graph visualization", err)
 		return
 	}
 
 	c.JSON(http.StatusOK, graphData)
 }
+
+// ListSharedGraphDocuments handles GET /api/shared/:token/documents
+// Read-only access through a share link (set by share link middleware)
+func (h *GraphHandler) ListSharedGraphDocuments(c *gin.Context) {
+	graph, ok := middleware.GetSharedGraph(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, CodeInvalidShareLink, "Share link not found in request")
+		return
+	}
+
+	page, ok := parsePageParamsOrRespond(c)
+	if !ok {
+		return
+	}
+
+	filter := models.DocumentFilter{Tag: c.Query("tag"), Sort: listSortFromQuery(c), Limit: page.Limit, Offset: page.Offset}
+	docs, total, err := h.documentService.ListGraphDocuments(c.Request.Context(), graph.ID, filter)
+	if err != nil {
+		if isInvalidListFilter(err) {
+			respondError(c, http.StatusBadRequest, CodeInvalidListFilter, err.Error())
+			return
+		}
+		respondInternalError(c, "Failed to list documents", err)
+		return
+	}
+
+	response := make([]SharedDocumentResponse, len(docs))
+	for i, doc := range docs {
+		full := toDocumentResponse(doc)
+		response[i] = SharedDocumentResponse{
+			ID:          full.ID,
+			Filename:    full.Filename,
+			ContentType: full.ContentType,
+			SizeBytes:   full.SizeBytes,
+			Source:      full.Source,
+			Status:      full.Status,
+			Tags:        full.Tags,
+			CreatedAt:   full.CreatedAt,
+			UpdatedAt:   full.UpdatedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, newPaginatedResponse(response, page, total))
+}
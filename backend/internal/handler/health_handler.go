@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// readinessCheckTimeout bounds each dependency check so a hung dependency can't stall the probe
+const readinessCheckTimeout = 5 * time.Second
+
+// HealthCheck reports whether a dependency is usable
+type HealthCheck func(ctx context.Context) error
+
+// HealthHandler handles readiness probes
+type HealthHandler struct {
+	checks map[string]HealthCheck
+}
+
+// NewHealthHandler creates a new instance of HealthHandler that runs the given named checks
+func NewHealthHandler(checks map[string]HealthCheck) *HealthHandler {
+	return &HealthHandler{
+		checks: checks,
+	}
+}
+
+// Ready handles GET /ready
+// Runs every dependency check and returns 503 if any of them fails. The probe is
+// unauthenticated, so failures are only logged; the response carries each check's status.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	names := make([]string, 0, len(h.checks))
+	for name := range h.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ready := true
+	results := make(gin.H, len(names))
+	for _, name := range names {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessCheckTimeout)
+		err := h.checks[name](ctx)
+		cancel()
+
+		if err != nil {
+			fmt.Printf("Warning [request %s] readiness check %s failed: %v\n", middleware.GetRequestID(c), name, err)
+			ready = false
+			results[name] = gin.H{"status": "unhealthy"}
+			continue
+		}
+		results[name] = gin.H{"status": "healthy"}
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "checks": results})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": results})
+}
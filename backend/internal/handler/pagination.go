@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page sizes of listings; larger limits are clamped to MaxPageLimit
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// cursorPrefix versions the opaque cursor format, so it can change without misreading old cursors
+const cursorPrefix = "o1:"
+
+// errInvalidPagination is returned for malformed limit, offset or cursor parameters
+var errInvalidPagination = errors.New("invalid pagination parameters")
+
+// PageParams is the page of a listing a request asks for
+type PageParams struct {
+	Limit  int
+	Offset int
+}
+
+// Pagination describes the page of a listing a response holds. NextCursor is set when
+// there are more items, and can be passed as ?cursor= to get the next page.
+type Pagination struct {
+	Limit      int     `json:"limit"`
+	Offset     int     `json:"offset"`
+	Total      int     `json:"total"`
+	HasMore    bool    `json:"hasMore"`
+	NextCursor *string `json:"nextCursor,omitempty"`
+}
+
+// PaginatedResponse is the envelope of every listing response
+type PaginatedResponse[T any] struct {
+	Items      []T        `json:"items"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// parsePageParams reads ?limit= and either ?offset= or ?cursor= (from a previous response's
+// nextCursor). The limit defaults to DefaultPageLimit and is clamped to MaxPageLimit.
+func parsePageParams(c *gin.Context) (PageParams, error) {
+	page := PageParams{Limit: DefaultPageLimit}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return page, fmt.Errorf("%w: limit must be a positive integer", errInvalidPagination)
+		}
+		page.Limit = min(limit, MaxPageLimit)
+	}
+
+	offsetStr, cursor := c.Query("offset"), c.Query("cursor")
+	switch {
+	case offsetStr != "" && cursor != "":
+		return page, fmt.Errorf("%w: use either offset or cursor, not both", errInvalidPagination)
+	case offsetStr != "":
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			return page, fmt.Errorf("%w: offset must be a non-negative integer", errInvalidPagination)
+		}
+		page.Offset = offset
+	case cursor != "":
+		offset, err := decodeCursor(cursor)
+		if err != nil {
+			return page, fmt.Errorf("%w: cursor is not valid", errInvalidPagination)
+		}
+		page.Offset = offset
+	}
+
+	return page, nil
+}
+
+// parsePageParamsOrRespond parses the page parameters, responding 400 if they are invalid
+func parsePageParamsOrRespond(c *gin.Context) (PageParams, bool) {
+	page, err := parsePageParams(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidPagination, err.Error())
+		return page, false
+	}
+	return page, true
+}
+
+// newPaginatedResponse wraps one page of items, out of total, in the listing envelope
+func newPaginatedResponse[T any](items []T, page PageParams, total int) PaginatedResponse[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	pagination := Pagination{
+		Limit:   page.Limit,
+		Offset:  page.Offset,
+		Total:   total,
+		HasMore: page.Offset+len(items) < total,
+	}
+	if pagination.HasMore {
+		cursor := encodeCursor(page.Offset + len(items))
+		pagination.NextCursor = &cursor
+	}
+
+	return PaginatedResponse[T]{Items: items, Pagination: pagination}
+}
+
+// encodeCursor returns the opaque cursor for the page starting at offset
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the offset a cursor points to
+func decodeCursor(cursor string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	offsetStr, ok := strings.CutPrefix(string(data), cursorPrefix)
+	if !ok {
+		return 0, errInvalidPagination
+	}
+
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < 0 {
+		return 0, errInvalidPagination
+	}
+	return offset, nil
+}
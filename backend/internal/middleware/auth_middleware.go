@@ -9,7 +9,7 @@ import (
 )
 
 // AuthMiddleware validates JWT tokens and adds user information to context
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+func AuthMiddleware(jwtKeys *utils.JWTKeys) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Extract Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -36,7 +36,7 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		tokenString := parts[1]
 
 		// Validate token
-		claims, err := utils.ValidateToken(tokenString, jwtSecret)
+		claims, err := utils.ValidateToken(tokenString, jwtKeys)
 		if err != nil {
 			var message string
 			switch err {
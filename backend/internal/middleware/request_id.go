@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied request IDs so they can't bloat the logs
+const maxRequestIDLength = 128
+
+// RequestID assigns every request an ID, reusing the caller's X-Request-ID when it is
+// usable, and echoes it in the response so clients can quote it when reporting errors
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		c.Set("requestID", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// GetRequestID retrieves the request ID from the gin context
+func GetRequestID(c *gin.Context) string {
+	requestID, _ := c.Get("requestID")
+	requestIDStr, _ := requestID.(string)
+	return requestIDStr
+}
+
+// isValidRequestID accepts non-empty IDs of printable ASCII, so a client can't inject
+// line breaks or control characters into the logs
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
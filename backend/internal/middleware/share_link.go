@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/models"
+	"github.com/bipulkrdas/orgmind/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ShareLinkMiddleware grants read-only access to a graph from the share link token in the
+// :token path parameter, without a JWT. Only register it on read-only routes: it identifies
+// a graph, not a user.
+func ShareLinkMiddleware(graphService service.GraphService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		graph, err := graphService.ResolveShareLink(c.Request.Context(), c.Param("token"))
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidShareLink) {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"code":    "INVALID_SHARE_LINK",
+					"message": "Share link is invalid, expired or revoked",
+				})
+			} else {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"code":    "INTERNAL_ERROR",
+					"message": "Failed to verify share link",
+				})
+			}
+			c.Abort()
+			return
+		}
+
+		// Add the shared graph to context
+		c.Set("sharedGraph", graph)
+
+		// Continue to next handler
+		c.Next()
+	}
+}
+
+// GetSharedGraph retrieves the graph a share link grants access to from the gin context
+func GetSharedGraph(c *gin.Context) (*models.Graph, bool) {
+	graph, exists := c.Get("sharedGraph")
+	if !exists {
+		return nil, false
+	}
+
+	sharedGraph, ok := graph.(*models.Graph)
+	return sharedGraph, ok
+}
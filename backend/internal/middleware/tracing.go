@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// Tracing starts a span for every request, continuing the caller's trace when the request
+// carries a traceparent header. Handlers pass the request context on, so the spans of the
+// Zep, Gemini and storage calls a request makes become children of its span.
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched route"
+		}
+
+		ctx := tracing.Extract(c.Request.Context(), c.Request.Header)
+		ctx, span := tracing.Start(ctx, c.Request.Method+" "+route,
+			"http.request.method", c.Request.Method,
+			"http.route", route,
+			"request.id", GetRequestID(c),
+		)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttribute("http.response.status_code", status)
+		if status >= http.StatusInternalServerError {
+			span.Fail(fmt.Sprintf("request failed with status %d", status))
+		}
+		span.Finish(nil)
+	}
+}
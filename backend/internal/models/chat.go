@@ -4,16 +4,42 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // ChatThread represents a conversation session containing multiple messages
 type ChatThread struct {
-	ID        string    `json:"id" db:"id"`
-	GraphID   string    `json:"graphId" db:"graph_id"`
-	UserID    string    `json:"userId" db:"user_id"`
-	Summary   *string   `json:"summary" db:"summary"`
-	CreatedAt time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+	ID         string     `json:"id" db:"id"`
+	GraphID    string     `json:"graphId" db:"graph_id"`
+	UserID     string     `json:"userId" db:"user_id"`
+	Summary    *string    `json:"summary" db:"summary"`
+	Private    bool       `json:"private" db:"private"` // Only visible to the thread's author
+	Archived   bool       `json:"archived" db:"archived"`
+	ArchivedAt *time.Time `json:"archivedAt" db:"archived_at"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// Thread summary lengths, in characters
+const (
+	DefaultSummaryLength = 100 // Default maximum length of a summary generated from the first message
+	MinSummaryLength     = 10  // Shortest allowed maximum, leaving room for text besides the ellipsis
+	MaxSummaryLength     = 200 // Longest summary the database stores
+)
+
+// summaryEllipsis marks a summary cut short of the full message
+const summaryEllipsis = "..."
+
+// ChatThreadFilter narrows thread listings. By default archived threads are excluded.
+type ChatThreadFilter struct {
+	IncludeArchived bool // Include archived threads alongside active ones
+	ArchivedOnly    bool // Only list archived threads (takes precedence over IncludeArchived)
+
+	// User the list is for: their own private threads are included, other members' are not
+	ViewerID string
+
+	Limit  int // Maximum number of threads returned; zero returns every thread
+	Offset int // Number of threads skipped before the first one returned
 }
 
 // Validate validates the ChatThread fields
@@ -27,25 +53,50 @@ func (ct *ChatThread) Validate() error {
 	if ct.UserID == "" {
 		return fmt.Errorf("user ID is required")
 	}
-	if ct.Summary != nil && len(*ct.Summary) > 200 {
-		return fmt.Errorf("summary must not exceed 200 characters")
+	if ct.Summary != nil && utf8.RuneCountInString(*ct.Summary) > MaxSummaryLength {
+		return fmt.Errorf("summary must not exceed %d characters", MaxSummaryLength)
 	}
 	return nil
 }
 
-// GenerateSummary creates a summary from the first message (max 100 characters)
-func (ct *ChatThread) GenerateSummary(firstMessage string) {
-	// Trim whitespace
-	message := strings.TrimSpace(firstMessage)
+// GenerateSummary creates a summary from the first message. Whitespace, including line breaks,
+// is collapsed to single spaces, and longer messages are cut at a word boundary so the summary
+// with its trailing ellipsis fits in maxLength characters. A maxLength outside
+// MinSummaryLength..MaxSummaryLength falls back to DefaultSummaryLength.
+func (ct *ChatThread) GenerateSummary(firstMessage string, maxLength int) {
+	if maxLength < MinSummaryLength || maxLength > MaxSummaryLength {
+		maxLength = DefaultSummaryLength
+	}
+
+	// Collapse whitespace
+	message := strings.Join(strings.Fields(firstMessage), " ")
 
-	// Limit to 100 characters
-	if len(message) > 100 {
-		message = message[:97] + "..."
+	runes := []rune(message)
+	if len(runes) > maxLength {
+		// Leave room for the ellipsis, then back up to the last word boundary unless
+		// that would drop more than half the summary (a single very long word)
+		cut := maxLength - len(summaryEllipsis)
+		if runes[cut] != ' ' {
+			if space := lastSpace(runes[:cut]); space > cut/2 {
+				cut = space
+			}
+		}
+		message = strings.TrimRight(string(runes[:cut]), " ,;:-") + summaryEllipsis
 	}
 
 	ct.Summary = &message
 }
 
+// lastSpace returns the index of the last space in runes, or -1
+func lastSpace(runes []rune) int {
+	for i := len(runes) - 1; i >= 0; i-- {
+		if runes[i] == ' ' {
+			return i
+		}
+	}
+	return -1
+}
+
 // ChatMessage represents a single message in a chat thread
 type ChatMessage struct {
 	ID        string    `json:"id" db:"id"`
@@ -90,3 +141,54 @@ func (cm *ChatMessage) ValidateContent() error {
 	}
 	return nil
 }
+
+// Feedback ratings for assistant messages
+const (
+	FeedbackRatingUp   = "up"
+	FeedbackRatingDown = "down"
+)
+
+// MessageFeedback represents a user's rating of an assistant message
+type MessageFeedback struct {
+	ID        string    `json:"id" db:"id"`
+	MessageID string    `json:"messageId" db:"message_id"`
+	UserID    string    `json:"userId" db:"user_id"`
+	Rating    string    `json:"rating" db:"rating"` // "up" or "down"
+	Comment   *string   `json:"comment,omitempty" db:"comment"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// GeminiUsage summarizes a graph's Gemini usage for the current month and what is left
+// of its monthly chat budget
+type GeminiUsage struct {
+	Month           string           `json:"month"` // "2006-01", in UTC
+	RequestCount    int64            `json:"requestCount"`
+	InputTokens     int64            `json:"inputTokens"`
+	OutputTokens    int64            `json:"outputTokens"`
+	MonthlyTokenCap int64            `json:"monthlyTokenCap"` // 0 = unlimited
+	Days            []GeminiUsageDay `json:"days"`
+
+	// Budget; the remaining counts are nil when the corresponding cap is unlimited
+	MonthlyRequestCap int64     `json:"monthlyRequestCap"` // 0 = unlimited
+	RemainingTokens   *int64    `json:"remainingTokens"`
+	RemainingRequests *int64    `json:"remainingRequests"`
+	BudgetExceeded    bool      `json:"budgetExceeded"`
+	ResetsAt          time.Time `json:"resetsAt"` // Start of the next month
+}
+
+// GeminiUsageDay is a graph's Gemini usage on one day (UTC)
+type GeminiUsageDay struct {
+	Day          string `json:"day" db:"day"` // "2006-01-02"
+	RequestCount int64  `json:"requestCount" db:"request_count"`
+	InputTokens  int64  `json:"inputTokens" db:"input_tokens"`
+	OutputTokens int64  `json:"outputTokens" db:"output_tokens"`
+}
+
+// FeedbackSummary aggregates the feedback on a graph's assistant messages
+type FeedbackSummary struct {
+	GraphID      string `json:"graphId" db:"-"`
+	UpCount      int    `json:"upCount" db:"up_count"`
+	DownCount    int    `json:"downCount" db:"down_count"`
+	CommentCount int    `json:"commentCount" db:"comment_count"`
+}
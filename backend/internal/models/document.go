@@ -1,20 +1,109 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
+)
 
 // Document represents a document in the system
 type Document struct {
-	ID           string    `json:"id" db:"id"`
-	UserID       string    `json:"userId" db:"user_id"`
-	GraphID      *string   `json:"graphId" db:"graph_id"`
-	Filename     *string   `json:"filename" db:"filename"`
-	ContentType  *string   `json:"contentType" db:"content_type"`
-	StorageKey   string    `json:"storageKey" db:"storage_key"`
-	SizeBytes    int64     `json:"sizeBytes" db:"size_bytes"`
-	Source       string    `json:"source" db:"source"` // "editor" or "upload"
-	Status       string    `json:"status" db:"status"`
-	ErrorMessage *string   `json:"errorMessage,omitempty" db:"error_message"`
-	GeminiFileID *string   `json:"geminiFileId,omitempty" db:"gemini_file_id"`
-	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at"`
+	ID            string         `json:"id" db:"id"`
+	UserID        string         `json:"userId" db:"user_id"`
+	GraphID       *string        `json:"graphId" db:"graph_id"`
+	Filename      *string        `json:"filename" db:"filename"`
+	ContentType   *string        `json:"contentType" db:"content_type"`
+	StorageKey    string         `json:"storageKey" db:"storage_key"`
+	SizeBytes     int64          `json:"sizeBytes" db:"size_bytes"`
+	Source        string         `json:"source" db:"source"`                          // "editor" or "upload"
+	ContentFormat *string        `json:"contentFormat,omitempty" db:"content_format"` // Editor documents only: one of the ContentFormat constants
+	Status        string         `json:"status" db:"status"`                          // "processing", "completed", "failed" or "empty" (no extractable text)
+	ErrorMessage  *string        `json:"errorMessage,omitempty" db:"error_message"`
+	GeminiFileID  *string        `json:"geminiFileId,omitempty" db:"gemini_file_id"`
+	Tags          pq.StringArray `json:"tags" db:"tags"`
+	Metadata      types.JSONText `json:"metadata" db:"metadata"` // Embedded document metadata (title, author, page count, ...)
+	CreatedAt     time.Time      `json:"createdAt" db:"created_at"`
+	UpdatedAt     time.Time      `json:"updatedAt" db:"updated_at"`
+
+	// Times processing has been attempted and when it last was, and when a failed document is
+	// next retried automatically
+	ProcessingAttempts int        `json:"processingAttempts" db:"processing_attempts"`
+	LastAttemptAt      *time.Time `json:"lastAttemptAt,omitempty" db:"last_attempt_at"`
+	NextRetryAt        *time.Time `json:"nextRetryAt,omitempty" db:"next_retry_at"`
+
+	// Start of the document's text, shown in document lists, and the SHA-256 hash of its
+	// stored content, used to revalidate cached content
+	ContentPreview *string `json:"contentPreview,omitempty" db:"content_preview"`
+	ContentHash    *string `json:"contentHash,omitempty" db:"content_hash"`
+
+	// Version of the extraction logic that produced an uploaded document's text (0 = before
+	// versions were recorded); older documents can be re-extracted with the current logic
+	ExtractorVersion int `json:"extractorVersion" db:"extractor_version"`
+
+	// Name of the document's graph, only set in lists of a user's documents across graphs
+	GraphName *string `json:"graphName,omitempty" db:"graph_name"`
+}
+
+// DocumentStatus is a document's processing status, as polled after uploads
+type DocumentStatus struct {
+	ID     string `json:"id" db:"id"`
+	Status string `json:"status" db:"status"`
+}
+
+// Formats of editor content
+const (
+	ContentFormatLexical  = "lexical"  // Plain text plus Lexical editor state (default)
+	ContentFormatMarkdown = "markdown" // Markdown source
+	ContentFormatHTML     = "html"     // HTML fragment
+	ContentFormatText     = "text"     // Plain text
+)
+
+// Scopes for listing a user's documents via ?scope=
+const (
+	DocumentScopeAccessible = "accessible" // Documents in graphs the user is a member of (default)
+	DocumentScopeAuthored   = "authored"   // Accessible documents the user created, and theirs without a graph
+)
+
+// DocumentFilter holds optional filters for listing documents
+type DocumentFilter struct {
+	Tag     string   // Only include documents carrying this tag
+	Scope   string   // User document lists only: one of the DocumentScope constants
+	GraphID string   // User document lists only: only include documents in this graph
+	Sort    ListSort // Result ordering
+	Limit   int      // Maximum number of documents returned; zero returns every document
+	Offset  int      // Number of documents skipped before the first one returned
+}
+
+// SetDocumentTagsRequest represents the request body for replacing a document's tags
+type SetDocumentTagsRequest struct {
+	Tags []string `json:"tags" binding:"required"`
+}
+
+// AddDocumentTagRequest represents the request body for adding a single tag to a document
+type AddDocumentTagRequest struct {
+	Tag string `json:"tag" binding:"required"`
+}
+
+// Integrity issue codes reported in DocumentIntegrityIssue.Error
+const (
+	IntegrityMissingInStorage   = "missing_in_storage"  // The storage object does not exist
+	IntegrityStorageUnreachable = "storage_unreachable" // Storage could not be asked about the object
+)
+
+// DocumentIntegrityIssue identifies a document whose stored content could not be confirmed
+type DocumentIntegrityIssue struct {
+	DocumentID string  `json:"documentId"`
+	Filename   *string `json:"filename"`
+	StorageKey string  `json:"storageKey"`
+	Error      string  `json:"error"` // One of the Integrity* codes
+}
+
+// DocumentIntegrityReport summarizes a storage integrity check of a graph's documents
+type DocumentIntegrityReport struct {
+	GraphID      string                   `json:"graphId"`
+	CheckedCount int                      `json:"checkedCount"`
+	Orphaned     []DocumentIntegrityIssue `json:"orphaned"`   // Documents whose storage object is missing
+	Unverified   []DocumentIntegrityIssue `json:"unverified"` // Documents whose storage could not be checked
+	CheckedAt    time.Time                `json:"checkedAt"`
 }
@@ -1,6 +1,13 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
 
 // Graph represents a knowledge graph entity stored in Zep Cloud
 type Graph struct {
@@ -13,6 +20,33 @@ type Graph struct {
 	GeminiStoreID *string   `json:"geminiStoreId,omitempty" db:"gemini_store_id"`
 	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt     time.Time `json:"updatedAt" db:"updated_at"`
+
+	// Time of the latest document upload or chat message in the graph
+	LastActivityAt time.Time `json:"lastActivityAt" db:"last_activity_at"`
+
+	// MIME types that may be uploaded to the graph, on top of the globally supported formats (empty = all)
+	AllowedContentTypes pq.StringArray `json:"allowedContentTypes" db:"allowed_content_types"`
+
+	// Role given to members added without an explicit role
+	DefaultMemberRole string `json:"defaultMemberRole" db:"default_member_role"`
+
+	// Whether members can chat with the graph's documents (AI chat uses Gemini quota)
+	ChatEnabled bool `json:"chatEnabled" db:"chat_enabled"`
+
+	// Aggregated by list queries only; zero when the graph is loaded individually
+	MemberCount int `json:"memberCount,omitempty" db:"member_count"`
+
+	// Whether the requesting user has pinned the graph (per-user, not stored on graphs)
+	IsFavorite bool `json:"isFavorite" db:"is_favorite"`
+}
+
+// GraphStats summarizes a graph's ingestion health and AI usage
+type GraphStats struct {
+	DocumentCount     int            `json:"documentCount"`
+	DocumentsByStatus map[string]int `json:"documentsByStatus"` // Always includes processing, completed, failed and empty
+
+	// Month-to-date AI chat usage
+	GeminiUsage *GeminiUsage `json:"geminiUsage,omitempty"`
 }
 
 // GraphMembership represents a many-to-many relationship between users and graphs
@@ -20,26 +54,173 @@ type GraphMembership struct {
 	ID        string    `json:"id" db:"id"`
 	GraphID   string    `json:"graphId" db:"graph_id"`
 	UserID    string    `json:"userId" db:"user_id"`
-	Role      string    `json:"role" db:"role"`
+	Role      string    `json:"role" db:"role"` // One of MembershipRoles
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 }
 
+// Roles of graph members
+const (
+	RoleOwner  = "owner" // The graph's creator
+	RoleEditor = "editor"
+	RoleMember = "member"
+	RoleViewer = "viewer"
+)
+
+// MembershipRoles lists every valid membership role
+var MembershipRoles = []string{RoleOwner, RoleEditor, RoleMember, RoleViewer}
+
+// Validate validates the GraphMembership fields
+func (gm *GraphMembership) Validate() error {
+	if gm.ID == "" {
+		return fmt.Errorf("membership ID is required")
+	}
+	if gm.GraphID == "" {
+		return fmt.Errorf("graph ID is required")
+	}
+	if gm.UserID == "" {
+		return fmt.Errorf("user ID is required")
+	}
+	return gm.ValidateRole()
+}
+
+// ValidateRole validates that the role is one of MembershipRoles
+func (gm *GraphMembership) ValidateRole() error {
+	if !slices.Contains(MembershipRoles, gm.Role) {
+		return fmt.Errorf("role must be one of %s, got '%s'", strings.Join(MembershipRoles, ", "), gm.Role)
+	}
+	return nil
+}
+
+// GraphShareLink grants read-only access to a graph's visualization and document list to
+// anyone holding its token. Links without ExpiresAt never expire; revoked links stop working.
+type GraphShareLink struct {
+	ID        string     `json:"id" db:"id"`
+	GraphID   string     `json:"graphId" db:"graph_id"`
+	CreatedBy string     `json:"createdBy" db:"created_by"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" db:"expires_at"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty" db:"revoked_at"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// IsActive reports whether the link is neither revoked nor expired at now
+func (l *GraphShareLink) IsActive(now time.Time) bool {
+	if l.RevokedAt != nil {
+		return false
+	}
+	return l.ExpiresAt == nil || now.Before(*l.ExpiresAt)
+}
+
+// CreateShareLinkRequest represents the request body for creating a share link
+type CreateShareLinkRequest struct {
+	// When the link stops working; omit for a link that lasts until revoked
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// Statuses of a graph reindex job
+const (
+	ReindexStatusRunning   = "running"
+	ReindexStatusCompleted = "completed"
+	ReindexStatusFailed    = "failed"
+)
+
+// GraphReindexJob is a background rebuild of a graph's Zep data from the stored content of
+// its documents, or a re-extraction of its documents extracted by an older extractor. Only
+// one job per graph runs at a time.
+type GraphReindexJob struct {
+	ID                 string     `json:"id" db:"id"`
+	GraphID            string     `json:"graphId" db:"graph_id"`
+	RequestedBy        string     `json:"requestedBy" db:"requested_by"`
+	ClearGraph         bool       `json:"clearGraph" db:"clear_graph"`             // Whether the Zep graph was emptied first
+	Reextract          bool       `json:"reextract" db:"reextract"`                // Whether only documents extracted by an older extractor were reprocessed
+	ContentType        *string    `json:"contentType,omitempty" db:"content_type"` // Content type a re-extraction was limited to
+	Status             string     `json:"status" db:"status"`
+	TotalDocuments     int        `json:"totalDocuments" db:"total_documents"`
+	ProcessedDocuments int        `json:"processedDocuments" db:"processed_documents"` // Including failed ones
+	FailedDocuments    int        `json:"failedDocuments" db:"failed_documents"`
+	ErrorMessage       *string    `json:"errorMessage,omitempty" db:"error_message"`
+	CreatedAt          time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updatedAt" db:"updated_at"`
+	FinishedAt         *time.Time `json:"finishedAt,omitempty" db:"finished_at"`
+}
+
+// ReindexGraphRequest represents the request body for rebuilding a graph's Zep data
+type ReindexGraphRequest struct {
+	// Delete the Zep graph and start from an empty one, instead of adding to the existing data
+	ClearGraph bool `json:"clearGraph"`
+}
+
+// ReextractGraphRequest represents the request body for re-extracting a graph's documents
+type ReextractGraphRequest struct {
+	// Only re-extract documents of this MIME type (empty = all formats)
+	ContentType string `json:"contentType"`
+}
+
 // CreateGraphRequest represents the request body for creating a new graph
 type CreateGraphRequest struct {
 	Name        string  `json:"name" binding:"required,min=1,max=255"`
 	Description *string `json:"description" binding:"omitempty,max=1000"`
+
+	// MIME types that may be uploaded to the graph (empty = all supported formats)
+	AllowedContentTypes []string `json:"allowedContentTypes"`
+
+	// Role given to members added without an explicit role (default "member")
+	DefaultMemberRole string `json:"defaultMemberRole"`
+
+	// Whether members can chat with the graph's documents (default true)
+	ChatEnabled *bool `json:"chatEnabled"`
+
+	// Client-chosen key from the Idempotency-Key header; retries with the same key create at most one graph
+	IdempotencyKey string `json:"-"`
 }
 
 // UpdateGraphRequest represents the request body for updating a graph
 type UpdateGraphRequest struct {
 	Name        *string `json:"name" binding:"omitempty,min=1,max=255"`
 	Description *string `json:"description" binding:"omitempty,max=1000"`
+
+	// Replaces the allowed content types when set; an empty list removes the restriction
+	AllowedContentTypes *[]string `json:"allowedContentTypes"`
+
+	// Replaces the default member role when set; an empty value resets it to "member"
+	DefaultMemberRole *string `json:"defaultMemberRole"`
+
+	// Turns AI chat on or off for the graph when set
+	ChatEnabled *bool `json:"chatEnabled"`
 }
 
 // AddMemberRequest represents the request body for adding a member to a graph
 type AddMemberRequest struct {
 	UserID string `json:"userId" binding:"required"`
-	Role   string `json:"role" binding:"omitempty,oneof=member editor viewer"`
+	Role   string `json:"role"` // Defaults to the graph's default member role
+}
+
+// AddMembersRequest represents the request body for adding several members to a graph at once
+type AddMembersRequest struct {
+	Members []AddMembersEntry `json:"members" binding:"required,dive"`
+}
+
+// AddMembersEntry identifies a user to add by ID or by email; exactly one must be given
+type AddMembersEntry struct {
+	UserID string `json:"userId,omitempty"`
+	Email  string `json:"email,omitempty"`
+	Role   string `json:"role"` // Defaults to the graph's default member role
+}
+
+// Outcomes of an entry in a bulk member addition
+const (
+	AddMemberResultAdded         = "added"
+	AddMemberResultAlreadyMember = "already_member"
+	AddMemberResultNotFound      = "not_found"
+	AddMemberResultInvalid       = "invalid" // Neither or both of userId and email given, or an unknown role
+	AddMemberResultFailed        = "failed"
+)
+
+// AddMemberResult reports the outcome of one entry of a bulk member addition
+type AddMemberResult struct {
+	UserID string `json:"userId,omitempty"` // The resolved user, when found
+	Email  string `json:"email,omitempty"`
+	Role   string `json:"role,omitempty"`
+	Status string `json:"status"` // One of the AddMemberResult constants
 }
 
 // GraphData represents the knowledge graph visualization data
@@ -0,0 +1,38 @@
+package models
+
+// Sort fields accepted by list endpoints via ?sort=
+const (
+	SortByName    = "name"
+	SortByCreated = "created"
+	SortByUpdated = "updated"
+
+	// Graphs only: most recent document upload or chat message first
+	SortByActivity = "activity"
+)
+
+// Sort directions accepted by list endpoints via ?order=
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// ListSort holds the requested ordering for a list query.
+// Empty fields fall back to the default ordering (newest first).
+type ListSort struct {
+	Field string // One of the SortBy constants
+	Order string // SortOrderAsc or SortOrderDesc
+}
+
+// Roles for listing a user's graphs via ?role=
+const (
+	GraphRoleOwner  = "owner"  // Graphs the user created
+	GraphRoleMember = "member" // Graphs shared with the user by someone else
+)
+
+// GraphFilter holds optional filters for listing graphs
+type GraphFilter struct {
+	Role   string   // One of the GraphRole constants; empty lists every graph the user is a member of
+	Sort   ListSort // Result ordering
+	Limit  int      // Maximum number of graphs returned; zero returns every graph
+	Offset int      // Number of graphs skipped before the first one returned
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// UploadSession is a resumable upload of a large file, uploaded in parts to a storage
+// multipart upload and turned into a document once it is completed
+type UploadSession struct {
+	ID              string    `json:"id" db:"id"`
+	UserID          string    `json:"userId" db:"user_id"`
+	GraphID         string    `json:"graphId" db:"graph_id"`
+	DocumentID      string    `json:"documentId" db:"document_id"` // ID the document gets on completion
+	Filename        string    `json:"filename" db:"filename"`
+	ContentType     string    `json:"contentType" db:"content_type"`
+	SizeBytes       int64     `json:"sizeBytes" db:"size_bytes"`
+	PartSize        int64     `json:"partSize" db:"part_size"` // Size of every part but the last
+	StorageKey      string    `json:"-" db:"storage_key"`
+	StorageUploadID string    `json:"-" db:"storage_upload_id"`
+	Assembled       bool      `json:"assembled" db:"assembled"` // Parts already joined into the stored file
+	ExpiresAt       time.Time `json:"expiresAt" db:"expires_at"`
+	CreatedAt       time.Time `json:"createdAt" db:"created_at"`
+}
+
+// PartCount returns the number of parts the file is uploaded in
+func (s *UploadSession) PartCount() int {
+	return int((s.SizeBytes + s.PartSize - 1) / s.PartSize)
+}
+
+// ExpectedPartSize returns the size of a part: the part size, except for a shorter last part
+func (s *UploadSession) ExpectedPartSize(partNumber int) int64 {
+	if partNumber == s.PartCount() {
+		return s.SizeBytes - int64(partNumber-1)*s.PartSize
+	}
+	return s.PartSize
+}
+
+// UploadPart is a part of an upload session that has been received
+type UploadPart struct {
+	SessionID  string    `json:"-" db:"session_id"`
+	PartNumber int       `json:"partNumber" db:"part_number"`
+	ETag       string    `json:"etag" db:"etag"`
+	SizeBytes  int64     `json:"sizeBytes" db:"size_bytes"`
+	UploadedAt time.Time `json:"uploadedAt" db:"uploaded_at"`
+}
+
+// InitiateUploadRequest represents the request body for starting a resumable upload
+type InitiateUploadRequest struct {
+	GraphID     string `json:"graphId" binding:"required"`
+	Filename    string `json:"filename" binding:"required"`
+	ContentType string `json:"contentType" binding:"required"`
+	SizeBytes   int64  `json:"sizeBytes" binding:"required"`
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/bipulkrdas/orgmind/backend/internal/models"
 
@@ -31,12 +32,12 @@ func (r *chatRepository) CreateThread(ctx context.Context, thread *models.ChatTh
 	query, args, err := r.qb.
 		Insert("chat_threads").
 		Columns(
-			"id", "graph_id", "user_id", "summary",
-			"created_at", "updated_at",
+			"id", "graph_id", "user_id", "summary", "private",
+			"archived", "archived_at", "created_at", "updated_at",
 		).
 		Values(
-			thread.ID, thread.GraphID, thread.UserID, thread.Summary,
-			thread.CreatedAt, thread.UpdatedAt,
+			thread.ID, thread.GraphID, thread.UserID, thread.Summary, thread.Private,
+			thread.Archived, thread.ArchivedAt, thread.CreatedAt, thread.UpdatedAt,
 		).
 		ToSql()
 
@@ -44,7 +45,7 @@ func (r *chatRepository) CreateThread(ctx context.Context, thread *models.ChatTh
 		return fmt.Errorf("failed to build insert query: %w", err)
 	}
 
-	_, err = r.db.ExecContext(ctx, query, args...)
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to create chat thread: %w", err)
 	}
@@ -56,8 +57,8 @@ func (r *chatRepository) CreateThread(ctx context.Context, thread *models.ChatTh
 func (r *chatRepository) GetThreadByID(ctx context.Context, threadID string) (*models.ChatThread, error) {
 	query, args, err := r.qb.
 		Select(
-			"id", "graph_id", "user_id", "summary",
-			"created_at", "updated_at",
+			"id", "graph_id", "user_id", "summary", "private",
+			"archived", "archived_at", "created_at", "updated_at",
 		).
 		From("chat_threads").
 		Where(sq.Eq{"id": threadID}).
@@ -68,7 +69,7 @@ func (r *chatRepository) GetThreadByID(ctx context.Context, threadID string) (*m
 	}
 
 	var thread models.ChatThread
-	err = r.db.GetContext(ctx, &thread, query, args...)
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &thread, query, args...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("chat thread not found")
@@ -79,16 +80,77 @@ func (r *chatRepository) GetThreadByID(ctx context.Context, threadID string) (*m
 	return &thread, nil
 }
 
-// ListThreadsByGraphID retrieves all chat threads for a specific graph, ordered by most recent activity
-func (r *chatRepository) ListThreadsByGraphID(ctx context.Context, graphID string) ([]*models.ChatThread, error) {
+// ListThreadsByGraphID retrieves the chat threads for a specific graph matching filter, ordered by most recent activity
+func (r *chatRepository) ListThreadsByGraphID(ctx context.Context, graphID string, filter models.ChatThreadFilter) ([]*models.ChatThread, error) {
+	builder := r.qb.
+		Select(
+			"id", "graph_id", "user_id", "summary", "private",
+			"archived", "archived_at", "created_at", "updated_at",
+		).
+		From("chat_threads")
+
+	query, args, err := withPage(whereGraphThreads(builder, graphID, filter), filter.Limit, filter.Offset).
+		OrderBy("updated_at DESC", "id").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var threads []*models.ChatThread
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &threads, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chat threads by graph ID: %w", err)
+	}
+
+	return threads, nil
+}
+
+// CountThreadsByGraphID counts the threads ListThreadsByGraphID returns for filter, ignoring its limit and offset
+func (r *chatRepository) CountThreadsByGraphID(ctx context.Context, graphID string, filter models.ChatThreadFilter) (int, error) {
+	query, args, err := whereGraphThreads(r.qb.Select("COUNT(*)").From("chat_threads"), graphID, filter).
+		ToSql()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to build count query: %w", err)
+	}
+
+	var count int
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &count, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count chat threads by graph ID: %w", err)
+	}
+
+	return count, nil
+}
+
+// whereGraphThreads restricts a query on chat threads to those ListThreadsByGraphID returns for filter
+func whereGraphThreads(builder sq.SelectBuilder, graphID string, filter models.ChatThreadFilter) sq.SelectBuilder {
+	builder = builder.Where(sq.Eq{"graph_id": graphID})
+
+	// Other members' private threads are never listed
+	builder = builder.Where(sq.Or{sq.Eq{"private": false}, sq.Eq{"user_id": filter.ViewerID}})
+
+	switch {
+	case filter.ArchivedOnly:
+		builder = builder.Where(sq.Eq{"archived": true})
+	case !filter.IncludeArchived:
+		builder = builder.Where(sq.Eq{"archived": false})
+	}
+
+	return builder
+}
+
+// ListThreadsByUserID retrieves all chat threads a user started, across all graphs
+func (r *chatRepository) ListThreadsByUserID(ctx context.Context, userID string) ([]*models.ChatThread, error) {
 	query, args, err := r.qb.
 		Select(
-			"id", "graph_id", "user_id", "summary",
-			"created_at", "updated_at",
+			"id", "graph_id", "user_id", "summary", "private",
+			"archived", "archived_at", "created_at", "updated_at",
 		).
 		From("chat_threads").
-		Where(sq.Eq{"graph_id": graphID}).
-		OrderBy("updated_at DESC").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at ASC").
 		ToSql()
 
 	if err != nil {
@@ -96,9 +158,9 @@ func (r *chatRepository) ListThreadsByGraphID(ctx context.Context, graphID strin
 	}
 
 	var threads []*models.ChatThread
-	err = r.db.SelectContext(ctx, &threads, query, args...)
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &threads, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list chat threads by graph ID: %w", err)
+		return nil, fmt.Errorf("failed to list chat threads by user ID: %w", err)
 	}
 
 	return threads, nil
@@ -117,7 +179,7 @@ func (r *chatRepository) UpdateThread(ctx context.Context, thread *models.ChatTh
 		return fmt.Errorf("failed to build update query: %w", err)
 	}
 
-	result, err := r.db.ExecContext(ctx, query, args...)
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update chat thread: %w", err)
 	}
@@ -134,6 +196,68 @@ func (r *chatRepository) UpdateThread(ctx context.Context, thread *models.ChatTh
 	return nil
 }
 
+// SetThreadArchived archives or unarchives a chat thread. archivedAt is stored as the
+// archive time and should be nil when unarchiving; updated_at is left alone so
+// archiving doesn't count as activity.
+func (r *chatRepository) SetThreadArchived(ctx context.Context, threadID string, archived bool, archivedAt *time.Time) error {
+	query, args, err := r.qb.
+		Update("chat_threads").
+		Set("archived", archived).
+		Set("archived_at", archivedAt).
+		Where(sq.Eq{"id": threadID}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %w", err)
+	}
+
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update chat thread archive state: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("chat thread not found")
+	}
+
+	return nil
+}
+
+// SetThreadPrivate makes a chat thread private to its author or visible to the graph's
+// members; like archiving, it doesn't count as activity
+func (r *chatRepository) SetThreadPrivate(ctx context.Context, threadID string, private bool) error {
+	query, args, err := r.qb.
+		Update("chat_threads").
+		Set("private", private).
+		Where(sq.Eq{"id": threadID}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %w", err)
+	}
+
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update chat thread privacy: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("chat thread not found")
+	}
+
+	return nil
+}
+
 // DeleteThread removes a chat thread from the database (cascade deletes messages)
 func (r *chatRepository) DeleteThread(ctx context.Context, threadID string) error {
 	query, args, err := r.qb.
@@ -145,7 +269,7 @@ func (r *chatRepository) DeleteThread(ctx context.Context, threadID string) erro
 		return fmt.Errorf("failed to build delete query: %w", err)
 	}
 
-	result, err := r.db.ExecContext(ctx, query, args...)
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to delete chat thread: %w", err)
 	}
@@ -178,11 +302,23 @@ func (r *chatRepository) CreateMessage(ctx context.Context, message *models.Chat
 		return fmt.Errorf("failed to build insert query: %w", err)
 	}
 
-	_, err = r.db.ExecContext(ctx, query, args...)
+	db := dbFromContext(ctx, r.db)
+	_, err = db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to create chat message: %w", err)
 	}
 
+	// Record the message as activity on the thread's graph (used for ?sort=activity)
+	activityQuery := `
+		UPDATE graphs
+		SET last_activity_at = GREATEST(last_activity_at, $1)
+		WHERE id = (SELECT graph_id FROM chat_threads WHERE id = $2)
+	`
+	_, err = db.ExecContext(ctx, activityQuery, message.CreatedAt, message.ThreadID)
+	if err != nil {
+		return fmt.Errorf("failed to update graph activity: %w", err)
+	}
+
 	return nil
 }
 
@@ -201,7 +337,7 @@ func (r *chatRepository) GetMessageByID(ctx context.Context, messageID string) (
 	}
 
 	var message models.ChatMessage
-	err = r.db.GetContext(ctx, &message, query, args...)
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &message, query, args...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("chat message not found")
@@ -212,15 +348,21 @@ func (r *chatRepository) GetMessageByID(ctx context.Context, messageID string) (
 	return &message, nil
 }
 
-// GetMessagesByThreadID retrieves all messages for a specific thread with pagination
-func (r *chatRepository) GetMessagesByThreadID(ctx context.Context, threadID string, limit, offset int) ([]*models.ChatMessage, error) {
+// GetMessagesByThreadID retrieves all messages for a specific thread with pagination,
+// oldest first, or newest first when order is models.SortOrderDesc
+func (r *chatRepository) GetMessagesByThreadID(ctx context.Context, threadID string, order string, limit, offset int) ([]*models.ChatMessage, error) {
+	orderBy := "created_at ASC, id ASC"
+	if order == models.SortOrderDesc {
+		orderBy = "created_at DESC, id DESC"
+	}
+
 	query, args, err := r.qb.
 		Select(
 			"id", "thread_id", "role", "content", "created_at",
 		).
 		From("chat_messages").
 		Where(sq.Eq{"thread_id": threadID}).
-		OrderBy("created_at ASC").
+		OrderBy(orderBy).
 		Limit(uint64(limit)).
 		Offset(uint64(offset)).
 		ToSql()
@@ -230,7 +372,7 @@ func (r *chatRepository) GetMessagesByThreadID(ctx context.Context, threadID str
 	}
 
 	var messages []*models.ChatMessage
-	err = r.db.SelectContext(ctx, &messages, query, args...)
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &messages, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get messages by thread ID: %w", err)
 	}
@@ -238,6 +380,27 @@ func (r *chatRepository) GetMessagesByThreadID(ctx context.Context, threadID str
 	return messages, nil
 }
 
+// CountMessagesByThreadID counts the messages in a thread
+func (r *chatRepository) CountMessagesByThreadID(ctx context.Context, threadID string) (int, error) {
+	query, args, err := r.qb.
+		Select("COUNT(*)").
+		From("chat_messages").
+		Where(sq.Eq{"thread_id": threadID}).
+		ToSql()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to build count query: %w", err)
+	}
+
+	var count int
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &count, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count messages by thread ID: %w", err)
+	}
+
+	return count, nil
+}
+
 // DeleteMessagesByThreadID removes all messages for a specific thread
 func (r *chatRepository) DeleteMessagesByThreadID(ctx context.Context, threadID string) error {
 	query, args, err := r.qb.
@@ -249,10 +412,114 @@ func (r *chatRepository) DeleteMessagesByThreadID(ctx context.Context, threadID
 		return fmt.Errorf("failed to build delete query: %w", err)
 	}
 
-	_, err = r.db.ExecContext(ctx, query, args...)
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to delete messages by thread ID: %w", err)
 	}
 
 	return nil
 }
+
+// UpsertFeedback stores a user's rating of a message, replacing any earlier rating by the same user.
+// The feedback's ID and CreatedAt are updated to those of the stored row.
+func (r *chatRepository) UpsertFeedback(ctx context.Context, feedback *models.MessageFeedback) error {
+	query, args, err := r.qb.
+		Insert("message_feedback").
+		Columns(
+			"id", "message_id", "user_id", "rating", "comment",
+			"created_at", "updated_at",
+		).
+		Values(
+			feedback.ID, feedback.MessageID, feedback.UserID, feedback.Rating, feedback.Comment,
+			feedback.CreatedAt, feedback.UpdatedAt,
+		).
+		Suffix(`ON CONFLICT (message_id, user_id) DO UPDATE
+			SET rating = EXCLUDED.rating, comment = EXCLUDED.comment, updated_at = EXCLUDED.updated_at
+			RETURNING id, created_at`).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build upsert query: %w", err)
+	}
+
+	err = dbFromContext(ctx, r.db).GetContext(ctx, feedback, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to save message feedback: %w", err)
+	}
+
+	return nil
+}
+
+// GetFeedbackSummaryByGraphID counts the feedback on assistant messages across all threads of a graph
+func (r *chatRepository) GetFeedbackSummaryByGraphID(ctx context.Context, graphID string) (*models.FeedbackSummary, error) {
+	query, args, err := r.qb.
+		Select(
+			"COUNT(*) FILTER (WHERE f.rating = 'up') AS up_count",
+			"COUNT(*) FILTER (WHERE f.rating = 'down') AS down_count",
+			"COUNT(f.comment) AS comment_count",
+		).
+		From("message_feedback f").
+		Join("chat_messages m ON m.id = f.message_id").
+		Join("chat_threads t ON t.id = m.thread_id").
+		Where(sq.Eq{"t.graph_id": graphID}).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	summary := models.FeedbackSummary{GraphID: graphID}
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &summary, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback summary: %w", err)
+	}
+
+	return &summary, nil
+}
+
+// RecordGeminiUsage adds a Gemini request and its tokens to a graph's usage on the given day
+func (r *chatRepository) RecordGeminiUsage(ctx context.Context, graphID string, day time.Time, inputTokens, outputTokens int) error {
+	query, args, err := r.qb.
+		Insert("gemini_usage").
+		Columns("graph_id", "day", "request_count", "input_tokens", "output_tokens").
+		Values(graphID, day.UTC().Format("2006-01-02"), 1, inputTokens, outputTokens).
+		Suffix(`ON CONFLICT (graph_id, day) DO UPDATE
+			SET request_count = gemini_usage.request_count + 1,
+				input_tokens = gemini_usage.input_tokens + EXCLUDED.input_tokens,
+				output_tokens = gemini_usage.output_tokens + EXCLUDED.output_tokens`).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %w", err)
+	}
+
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to record Gemini usage: %w", err)
+	}
+
+	return nil
+}
+
+// ListGeminiUsage retrieves a graph's daily Gemini usage from the given day onwards, oldest first
+func (r *chatRepository) ListGeminiUsage(ctx context.Context, graphID string, since time.Time) ([]models.GeminiUsageDay, error) {
+	query, args, err := r.qb.
+		Select("to_char(day, 'YYYY-MM-DD') AS day", "request_count", "input_tokens", "output_tokens").
+		From("gemini_usage").
+		Where(sq.Eq{"graph_id": graphID}).
+		Where(sq.GtOrEq{"day": since.UTC().Format("2006-01-02")}).
+		OrderBy("day ASC").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	days := []models.GeminiUsageDay{}
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &days, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Gemini usage: %w", err)
+	}
+
+	return days, nil
+}
@@ -5,13 +5,20 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/bipulkrdas/orgmind/backend/internal/models"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// ErrDocumentNotFound is returned when no document has the requested ID
+var ErrDocumentNotFound = errors.New("document not found")
+
 // documentRepository implements DocumentRepository interface
 type documentRepository struct {
 	db *sqlx.DB
@@ -32,13 +39,13 @@ func (r *documentRepository) Create(ctx context.Context, doc *models.Document) e
 		Insert("documents").
 		Columns(
 			"id", "user_id", "graph_id", "filename", "content_type", "storage_key",
-			"size_bytes", "source", "status",
-			"created_at", "updated_at",
+			"size_bytes", "source", "content_format", "status", "tags", "metadata",
+			"content_preview", "content_hash", "extractor_version", "created_at", "updated_at",
 		).
 		Values(
 			doc.ID, doc.UserID, doc.GraphID, doc.Filename, doc.ContentType, doc.StorageKey,
-			doc.SizeBytes, doc.Source, doc.Status,
-			doc.CreatedAt, doc.UpdatedAt,
+			doc.SizeBytes, doc.Source, doc.ContentFormat, doc.Status, tagsOrEmpty(doc.Tags), doc.Metadata,
+			doc.ContentPreview, doc.ContentHash, doc.ExtractorVersion, doc.CreatedAt, doc.UpdatedAt,
 		).
 		ToSql()
 
@@ -46,7 +53,7 @@ func (r *documentRepository) Create(ctx context.Context, doc *models.Document) e
 		return fmt.Errorf("failed to build insert query: %w", err)
 	}
 
-	_, err = r.db.ExecContext(ctx, query, args...)
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to create document: %w", err)
 	}
@@ -56,11 +63,16 @@ func (r *documentRepository) Create(ctx context.Context, doc *models.Document) e
 
 // GetByID retrieves a document by its ID
 func (r *documentRepository) GetByID(ctx context.Context, docID string) (*models.Document, error) {
+	// A malformed ID can't match any document, and Postgres would reject it as a UUID
+	if _, err := uuid.Parse(docID); err != nil {
+		return nil, ErrDocumentNotFound
+	}
+
 	query, args, err := r.qb.
 		Select(
 			"id", "user_id", "graph_id", "filename", "content_type", "storage_key",
-			"size_bytes", "source", "status",
-			"created_at", "updated_at",
+			"size_bytes", "source", "content_format", "status", "error_message", "tags",
+			"metadata", "content_preview", "content_hash", "extractor_version", "processing_attempts", "last_attempt_at", "next_retry_at", "created_at", "updated_at",
 		).
 		From("documents").
 		Where(sq.Eq{"id": docID}).
@@ -71,10 +83,10 @@ func (r *documentRepository) GetByID(ctx context.Context, docID string) (*models
 	}
 
 	var doc models.Document
-	err = r.db.GetContext(ctx, &doc, query, args...)
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &doc, query, args...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("document not found")
+			return nil, ErrDocumentNotFound
 		}
 		return nil, fmt.Errorf("failed to get document by ID: %w", err)
 	}
@@ -82,17 +94,96 @@ func (r *documentRepository) GetByID(ctx context.Context, docID string) (*models
 	return &doc, nil
 }
 
-// ListByUserID retrieves all documents for a specific user
-func (r *documentRepository) ListByUserID(ctx context.Context, userID string) ([]*models.Document, error) {
+// ListByUserID retrieves the documents in graphs the user is currently a member of, matching the filter,
+// along with the name of each document's graph. With the authored scope, only documents the user
+// created are included, together with those of theirs that aren't in any graph.
+func (r *documentRepository) ListByUserID(ctx context.Context, userID string, filter models.DocumentFilter) ([]*models.Document, error) {
+	orderBy, err := orderByClause(filter.Sort, documentSortColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := r.qb.
+		Select(
+			"id", "user_id", "graph_id", "filename", "content_type", "storage_key",
+			"size_bytes", "source", "content_format", "status", "error_message", "tags",
+			"metadata", "content_preview", "content_hash", "extractor_version", "processing_attempts", "last_attempt_at", "next_retry_at", "created_at", "updated_at",
+			"(SELECT name FROM graphs WHERE graphs.id = documents.graph_id) AS graph_name",
+		).
+		From("documents")
+
+	query, args, err := withPage(whereUserDocuments(builder, userID, filter), filter.Limit, filter.Offset).
+		OrderBy(orderBy, "id").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var docs []*models.Document
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &docs, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents by user ID: %w", err)
+	}
+
+	return docs, nil
+}
+
+// CountByUserID counts the documents ListByUserID returns for the filter, ignoring its limit and offset
+func (r *documentRepository) CountByUserID(ctx context.Context, userID string, filter models.DocumentFilter) (int, error) {
+	query, args, err := whereUserDocuments(r.qb.Select("COUNT(*)").From("documents"), userID, filter).
+		ToSql()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to build count query: %w", err)
+	}
+
+	var count int
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &count, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count documents by user ID: %w", err)
+	}
+
+	return count, nil
+}
+
+// whereUserDocuments restricts a query on documents to those ListByUserID returns for the filter
+func whereUserDocuments(builder sq.SelectBuilder, userID string, filter models.DocumentFilter) sq.SelectBuilder {
+	inMemberGraph := sq.Expr("graph_id IN (SELECT graph_id FROM graph_memberships WHERE user_id = ?)", userID)
+
+	if filter.Scope == models.DocumentScopeAuthored {
+		// graph_id IN (...) is never true for NULL, so documents without a graph need their own case
+		builder = builder.
+			Where(sq.Eq{"user_id": userID}).
+			Where(sq.Or{sq.Eq{"graph_id": nil}, inMemberGraph})
+	} else {
+		builder = builder.Where(inMemberGraph)
+	}
+
+	if filter.GraphID != "" {
+		builder = builder.Where(sq.Eq{"graph_id": filter.GraphID})
+	}
+
+	if filter.Tag != "" {
+		// Containment, unlike = ANY, can use the GIN index on tags
+		builder = builder.Where(sq.Expr("tags @> ARRAY[?]::text[]", filter.Tag))
+	}
+
+	return builder
+}
+
+// ListByAuthorID retrieves every document created by a user, including documents in
+// graphs the user is no longer a member of
+func (r *documentRepository) ListByAuthorID(ctx context.Context, userID string) ([]*models.Document, error) {
 	query, args, err := r.qb.
 		Select(
 			"id", "user_id", "graph_id", "filename", "content_type", "storage_key",
-			"size_bytes", "source", "status",
-			"created_at", "updated_at",
+			"size_bytes", "source", "content_format", "status", "error_message", "tags",
+			"metadata", "content_preview", "content_hash", "extractor_version", "processing_attempts", "last_attempt_at", "next_retry_at", "created_at", "updated_at",
 		).
 		From("documents").
 		Where(sq.Eq{"user_id": userID}).
-		OrderBy("created_at DESC").
+		OrderBy("created_at ASC").
 		ToSql()
 
 	if err != nil {
@@ -100,25 +191,173 @@ func (r *documentRepository) ListByUserID(ctx context.Context, userID string) ([
 	}
 
 	var docs []*models.Document
-	err = r.db.SelectContext(ctx, &docs, query, args...)
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &docs, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list documents by user ID: %w", err)
+		return nil, fmt.Errorf("failed to list documents by author ID: %w", err)
 	}
 
 	return docs, nil
 }
 
-// ListByGraphID retrieves all documents for a specific graph
-func (r *documentRepository) ListByGraphID(ctx context.Context, graphID string) ([]*models.Document, error) {
+// ListStatusesForMember retrieves the statuses of the given documents that are in graphs the
+// user is a member of. Documents that don't exist or aren't accessible are left out.
+func (r *documentRepository) ListStatusesForMember(ctx context.Context, docIDs []string, userID string) ([]*models.DocumentStatus, error) {
+	if len(docIDs) == 0 {
+		return nil, nil
+	}
+
 	query, args, err := r.qb.
+		Select("id", "status").
+		From("documents").
+		Where(sq.Eq{"id": docIDs}).
+		Where(sq.Expr("graph_id IN (SELECT graph_id FROM graph_memberships WHERE user_id = ?)", userID)).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var statuses []*models.DocumentStatus
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &statuses, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list document statuses: %w", err)
+	}
+
+	return statuses, nil
+}
+
+// SearchByGraphID finds a graph's documents whose filename, title, content preview or tags
+// contain query (case-insensitively), most recently updated first. At most limit documents
+// are returned, skipping the first offset.
+func (r *documentRepository) SearchByGraphID(ctx context.Context, graphID, query string, limit, offset int) ([]*models.Document, error) {
+	builder := r.qb.
 		Select(
 			"id", "user_id", "graph_id", "filename", "content_type", "storage_key",
-			"size_bytes", "source", "status",
-			"created_at", "updated_at",
+			"size_bytes", "source", "content_format", "status", "error_message", "tags",
+			"metadata", "content_preview", "content_hash", "extractor_version", "processing_attempts", "last_attempt_at", "next_retry_at", "created_at", "updated_at",
 		).
+		From("documents")
+
+	sqlQuery, args, err := withPage(whereSearchMatches(builder, graphID, query), limit, offset).
+		OrderBy("updated_at DESC", "id").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var docs []*models.Document
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &docs, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+
+	return docs, nil
+}
+
+// CountSearchByGraphID counts the graph's documents matching query, as searched by SearchByGraphID
+func (r *documentRepository) CountSearchByGraphID(ctx context.Context, graphID, query string) (int, error) {
+	sqlQuery, args, err := whereSearchMatches(r.qb.Select("COUNT(*)").From("documents"), graphID, query).
+		ToSql()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to build count query: %w", err)
+	}
+
+	var count int
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &count, sqlQuery, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count matching documents: %w", err)
+	}
+
+	return count, nil
+}
+
+// whereSearchMatches restricts a query on documents to the graph's documents matching query
+func whereSearchMatches(builder sq.SelectBuilder, graphID, query string) sq.SelectBuilder {
+	// Match query literally, not as a LIKE pattern
+	pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(query) + "%"
+
+	return builder.
+		Where(sq.Eq{"graph_id": graphID}).
+		Where(sq.Or{
+			sq.ILike{"filename": pattern},
+			sq.ILike{"metadata->>'title'": pattern},
+			sq.ILike{"content_preview": pattern},
+			sq.Expr("array_to_string(tags, ' ') ILIKE ?", pattern),
+		})
+}
+
+// CountByStatus counts a graph's documents by processing status. Statuses without
+// documents are absent from the result.
+func (r *documentRepository) CountByStatus(ctx context.Context, graphID string) (map[string]int, error) {
+	query, args, err := r.qb.
+		Select("status", "COUNT(*) AS count").
 		From("documents").
 		Where(sq.Eq{"graph_id": graphID}).
-		OrderBy("created_at DESC").
+		GroupBy("status").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var rows []struct {
+		Status string `db:"status"`
+		Count  int    `db:"count"`
+	}
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents by status: %w", err)
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
+
+// LatestUpdateByGraphID returns when a graph's documents were last updated, or nil if the
+// graph has no documents
+func (r *documentRepository) LatestUpdateByGraphID(ctx context.Context, graphID string) (*time.Time, error) {
+	query, args, err := r.qb.
+		Select("MAX(updated_at)").
+		From("documents").
+		Where(sq.Eq{"graph_id": graphID}).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var latest *time.Time
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &latest, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest document update: %w", err)
+	}
+
+	return latest, nil
+}
+
+// ListByGraphID retrieves the documents of a specific graph matching the filter
+func (r *documentRepository) ListByGraphID(ctx context.Context, graphID string, filter models.DocumentFilter) ([]*models.Document, error) {
+	orderBy, err := orderByClause(filter.Sort, documentSortColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := r.qb.
+		Select(
+			"id", "user_id", "graph_id", "filename", "content_type", "storage_key",
+			"size_bytes", "source", "content_format", "status", "error_message", "tags",
+			"metadata", "content_preview", "content_hash", "extractor_version", "processing_attempts", "last_attempt_at", "next_retry_at", "created_at", "updated_at",
+		).
+		From("documents")
+
+	query, args, err := withPage(whereGraphDocuments(builder, graphID, filter), filter.Limit, filter.Offset).
+		OrderBy(orderBy, "id").
 		ToSql()
 
 	if err != nil {
@@ -126,7 +365,7 @@ func (r *documentRepository) ListByGraphID(ctx context.Context, graphID string)
 	}
 
 	var docs []*models.Document
-	err = r.db.SelectContext(ctx, &docs, query, args...)
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &docs, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list documents by graph ID: %w", err)
 	}
@@ -134,6 +373,36 @@ func (r *documentRepository) ListByGraphID(ctx context.Context, graphID string)
 	return docs, nil
 }
 
+// CountByGraphID counts the documents ListByGraphID returns for the filter, ignoring its limit and offset
+func (r *documentRepository) CountByGraphID(ctx context.Context, graphID string, filter models.DocumentFilter) (int, error) {
+	query, args, err := whereGraphDocuments(r.qb.Select("COUNT(*)").From("documents"), graphID, filter).
+		ToSql()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to build count query: %w", err)
+	}
+
+	var count int
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &count, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count documents by graph ID: %w", err)
+	}
+
+	return count, nil
+}
+
+// whereGraphDocuments restricts a query on documents to those ListByGraphID returns for the filter
+func whereGraphDocuments(builder sq.SelectBuilder, graphID string, filter models.DocumentFilter) sq.SelectBuilder {
+	builder = builder.Where(sq.Eq{"graph_id": graphID})
+
+	if filter.Tag != "" {
+		// Containment, unlike = ANY, can use the GIN index on tags
+		builder = builder.Where(sq.Expr("tags @> ARRAY[?]::text[]", filter.Tag))
+	}
+
+	return builder
+}
+
 // Update updates an existing document in the database
 func (r *documentRepository) Update(ctx context.Context, doc *models.Document) error {
 	query, args, err := r.qb.
@@ -144,7 +413,13 @@ func (r *documentRepository) Update(ctx context.Context, doc *models.Document) e
 		Set("storage_key", doc.StorageKey).
 		Set("size_bytes", doc.SizeBytes).
 		Set("source", doc.Source).
+		Set("content_format", doc.ContentFormat).
 		Set("status", doc.Status).
+		Set("error_message", doc.ErrorMessage).
+		Set("metadata", doc.Metadata).
+		Set("content_preview", doc.ContentPreview).
+		Set("content_hash", doc.ContentHash).
+		Set("extractor_version", doc.ExtractorVersion).
 		Set("updated_at", doc.UpdatedAt).
 		Where(sq.Eq{"id": doc.ID}).
 		ToSql()
@@ -153,7 +428,7 @@ func (r *documentRepository) Update(ctx context.Context, doc *models.Document) e
 		return fmt.Errorf("failed to build update query: %w", err)
 	}
 
-	result, err := r.db.ExecContext(ctx, query, args...)
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update document: %w", err)
 	}
@@ -164,7 +439,7 @@ func (r *documentRepository) Update(ctx context.Context, doc *models.Document) e
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("document not found")
+		return ErrDocumentNotFound
 	}
 
 	return nil
@@ -181,7 +456,7 @@ func (r *documentRepository) Delete(ctx context.Context, docID string) error {
 		return fmt.Errorf("failed to build delete query: %w", err)
 	}
 
-	result, err := r.db.ExecContext(ctx, query, args...)
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to delete document: %w", err)
 	}
@@ -192,7 +467,7 @@ func (r *documentRepository) Delete(ctx context.Context, docID string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("document not found")
+		return ErrDocumentNotFound
 	}
 
 	return nil
@@ -211,7 +486,7 @@ func (r *documentRepository) UpdateGeminiFileID(ctx context.Context, docID, gemi
 		return fmt.Errorf("failed to build update query: %w", err)
 	}
 
-	result, err := r.db.ExecContext(ctx, query, args...)
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update gemini file ID: %w", err)
 	}
@@ -222,8 +497,329 @@ func (r *documentRepository) UpdateGeminiFileID(ctx context.Context, docID, gemi
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("document not found")
+		return ErrDocumentNotFound
+	}
+
+	return nil
+}
+
+// IncrementProcessingAttempts counts a processing attempt for a document, recording when it
+// was made and clearing any scheduled retry, and returns the new number of attempts
+func (r *documentRepository) IncrementProcessingAttempts(ctx context.Context, docID string) (int, error) {
+	query, args, err := r.qb.
+		Update("documents").
+		Set("processing_attempts", sq.Expr("processing_attempts + 1")).
+		Set("last_attempt_at", sq.Expr("NOW()")).
+		Set("next_retry_at", nil).
+		Where(sq.Eq{"id": docID}).
+		Suffix("RETURNING processing_attempts").
+		ToSql()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to build update query: %w", err)
+	}
+
+	var attempts int
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &attempts, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrDocumentNotFound
+		}
+		return 0, fmt.Errorf("failed to increment processing attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// ScheduleRetry sets when a failed document's processing is next retried
+func (r *documentRepository) ScheduleRetry(ctx context.Context, docID string, retryAt time.Time) error {
+	query, args, err := r.qb.
+		Update("documents").
+		Set("next_retry_at", retryAt).
+		Where(sq.Eq{"id": docID}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %w", err)
+	}
+
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to schedule retry: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrDocumentNotFound
+	}
+
+	return nil
+}
+
+// ListDueForRetry retrieves up to limit failed documents whose scheduled retry is due,
+// oldest first
+func (r *documentRepository) ListDueForRetry(ctx context.Context, now time.Time, limit int) ([]*models.Document, error) {
+	query, args, err := r.qb.
+		Select(
+			"id", "user_id", "graph_id", "filename", "content_type", "storage_key",
+			"size_bytes", "source", "content_format", "status", "error_message", "tags",
+			"metadata", "content_preview", "content_hash", "extractor_version", "processing_attempts", "last_attempt_at", "next_retry_at", "created_at", "updated_at",
+			"gemini_file_id",
+		).
+		From("documents").
+		Where(sq.Eq{"status": "failed"}).
+		Where(sq.LtOrEq{"next_retry_at": now}).
+		OrderBy("next_retry_at ASC").
+		Limit(uint64(limit)).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var docs []*models.Document
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &docs, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents due for retry: %w", err)
+	}
+
+	return docs, nil
+}
+
+// ClaimForRetry marks a failed document whose retry is due as processing again. It reports
+// false if the document was claimed by another server or is no longer due, so each
+// retry runs once even when several servers retry documents.
+func (r *documentRepository) ClaimForRetry(ctx context.Context, docID string, now time.Time) (bool, error) {
+	query, args, err := r.qb.
+		Update("documents").
+		Set("status", "processing").
+		Set("error_message", nil).
+		Set("next_retry_at", nil).
+		Set("updated_at", now).
+		Where(sq.Eq{"id": docID, "status": "failed"}).
+		Where(sq.LtOrEq{"next_retry_at": now}).
+		ToSql()
+
+	if err != nil {
+		return false, fmt.Errorf("failed to build update query: %w", err)
+	}
+
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim document for retry: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// UpdateTags replaces the tags of a document
+func (r *documentRepository) UpdateTags(ctx context.Context, docID string, tags []string) error {
+	query, args, err := r.qb.
+		Update("documents").
+		Set("tags", tagsOrEmpty(tags)).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": docID}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %w", err)
+	}
+
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update document tags: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrDocumentNotFound
+	}
+
+	return nil
+}
+
+// AddTag appends a tag to a document unless the document already has it or already has
+// maxTags tags. The check and the change are one statement, so concurrent tag changes
+// can't overwrite each other. It returns the document's tags and whether the tag was added.
+func (r *documentRepository) AddTag(ctx context.Context, docID, tag string, maxTags int) ([]string, bool, error) {
+	query, args, err := r.qb.
+		Update("documents").
+		Set("tags", sq.Expr("array_append(tags, ?::text)", tag)).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": docID}).
+		Where(sq.Expr("NOT (tags @> ARRAY[?]::text[])", tag)).
+		Where(sq.Expr("cardinality(tags) < ?", maxTags)).
+		Suffix("RETURNING tags").
+		ToSql()
+
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to build update query: %w", err)
+	}
+
+	var tags pq.StringArray
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &tags, query, args...)
+	if err == nil {
+		return tags, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, fmt.Errorf("failed to add document tag: %w", err)
+	}
+
+	// Nothing was updated: the document is missing, already has the tag, or is full
+	tags, err = r.getTags(ctx, docID)
+	if err != nil {
+		return nil, false, err
+	}
+	return tags, false, nil
+}
+
+// RemoveTag removes a tag from a document in one statement, so concurrent tag changes
+// can't overwrite each other. It returns the document's remaining tags.
+func (r *documentRepository) RemoveTag(ctx context.Context, docID, tag string) ([]string, error) {
+	query, args, err := r.qb.
+		Update("documents").
+		Set("tags", sq.Expr("array_remove(tags, ?::text)", tag)).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": docID}).
+		Where(sq.Expr("tags @> ARRAY[?]::text[]", tag)).
+		Suffix("RETURNING tags").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update query: %w", err)
+	}
+
+	var tags pq.StringArray
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &tags, query, args...)
+	if err == nil {
+		return tags, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to remove document tag: %w", err)
+	}
+
+	// Nothing was updated: the document is missing or doesn't have the tag
+	return r.getTags(ctx, docID)
+}
+
+// getTags returns the tags of a document
+func (r *documentRepository) getTags(ctx context.Context, docID string) ([]string, error) {
+	query, args, err := r.qb.
+		Select("tags").
+		From("documents").
+		Where(sq.Eq{"id": docID}).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var tags pq.StringArray
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &tags, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDocumentNotFound
+		}
+		return nil, fmt.Errorf("failed to get document tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// AddZepEpisodes records the Zep episodes created from a document's chunks.
+// Recording an episode that is already tracked is a no-op.
+func (r *documentRepository) AddZepEpisodes(ctx context.Context, docID string, episodeIDs []string) error {
+	if len(episodeIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	builder := r.qb.
+		Insert("document_zep_episodes").
+		Columns("episode_uuid", "document_id", "created_at")
+	for _, episodeID := range episodeIDs {
+		builder = builder.Values(episodeID, docID, now)
+	}
+
+	query, args, err := builder.
+		Suffix("ON CONFLICT (episode_uuid) DO NOTHING").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %w", err)
+	}
+
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to add zep episodes: %w", err)
+	}
+
+	return nil
+}
+
+// ListZepEpisodes returns the IDs of the Zep episodes created from a document
+func (r *documentRepository) ListZepEpisodes(ctx context.Context, docID string) ([]string, error) {
+	query, args, err := r.qb.
+		Select("episode_uuid").
+		From("document_zep_episodes").
+		Where(sq.Eq{"document_id": docID}).
+		OrderBy("created_at ASC").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var episodeIDs []string
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &episodeIDs, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list zep episodes: %w", err)
+	}
+
+	return episodeIDs, nil
+}
+
+// DeleteZepEpisodes stops tracking the given episodes of a document, after they were removed from Zep
+func (r *documentRepository) DeleteZepEpisodes(ctx context.Context, docID string, episodeIDs []string) error {
+	if len(episodeIDs) == 0 {
+		return nil
+	}
+
+	query, args, err := r.qb.
+		Delete("document_zep_episodes").
+		Where(sq.Eq{"document_id": docID, "episode_uuid": episodeIDs}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build delete query: %w", err)
+	}
+
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete zep episodes: %w", err)
 	}
 
 	return nil
 }
+
+// tagsOrEmpty converts tags to a Postgres array, using an empty array for nil
+// so the NOT NULL constraint on the tags column is satisfied
+func tagsOrEmpty(tags []string) pq.StringArray {
+	if tags == nil {
+		return pq.StringArray{}
+	}
+	return pq.StringArray(tags)
+}
@@ -36,7 +36,7 @@ func (r *geminiStoreRepository) Create(ctx context.Context, store *models.Gemini
 		return fmt.Errorf("failed to build insert query: %w", err)
 	}
 
-	_, err = r.db.ExecContext(ctx, query, args...)
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to create gemini store: %w", err)
 	}
@@ -57,7 +57,7 @@ func (r *geminiStoreRepository) GetByStoreName(ctx context.Context, storeName st
 	}
 
 	var store models.GeminiFileSearchStore
-	err = r.db.GetContext(ctx, &store, query, args...)
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &store, query, args...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Not found
@@ -81,7 +81,7 @@ func (r *geminiStoreRepository) Update(ctx context.Context, store *models.Gemini
 		return fmt.Errorf("failed to build update query: %w", err)
 	}
 
-	_, err = r.db.ExecContext(ctx, query, args...)
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update gemini store: %w", err)
 	}
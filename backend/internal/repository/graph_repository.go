@@ -5,11 +5,28 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/bipulkrdas/orgmind/backend/internal/models"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ErrGraphNotFound is returned when no graph has the requested ID
+var ErrGraphNotFound = errors.New("graph not found")
+
+// ErrShareLinkNotFound is returned when a graph has no share link with the requested ID
+var ErrShareLinkNotFound = errors.New("share link not found")
+
+// Reindex job errors
+var (
+	// ErrReindexJobNotFound is returned when a graph has never been reindexed
+	ErrReindexJobNotFound = errors.New("reindex job not found")
+	// ErrReindexJobRunning is returned when creating a job for a graph that already has one running
+	ErrReindexJobRunning = errors.New("a reindex job is already running for this graph")
 )
 
 // graphRepository implements GraphRepository interface
@@ -32,11 +49,13 @@ func (r *graphRepository) Create(ctx context.Context, graph *models.Graph) error
 		Insert("graphs").
 		Columns(
 			"id", "creator_id", "zep_graph_id", "name", "description",
-			"document_count", "created_at", "updated_at",
+			"document_count", "created_at", "updated_at", "last_activity_at",
+			"allowed_content_types", "default_member_role", "chat_enabled",
 		).
 		Values(
 			graph.ID, graph.CreatorID, graph.ZepGraphID, graph.Name, graph.Description,
-			graph.DocumentCount, graph.CreatedAt, graph.UpdatedAt,
+			graph.DocumentCount, graph.CreatedAt, graph.UpdatedAt, graph.LastActivityAt,
+			graph.AllowedContentTypes, graph.DefaultMemberRole, graph.ChatEnabled,
 		).
 		ToSql()
 
@@ -44,7 +63,7 @@ func (r *graphRepository) Create(ctx context.Context, graph *models.Graph) error
 		return fmt.Errorf("failed to build insert query: %w", err)
 	}
 
-	_, err = r.db.ExecContext(ctx, query, args...)
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to create graph: %w", err)
 	}
@@ -54,10 +73,16 @@ func (r *graphRepository) Create(ctx context.Context, graph *models.Graph) error
 
 // GetByID retrieves a graph by its ID
 func (r *graphRepository) GetByID(ctx context.Context, graphID string) (*models.Graph, error) {
+	// A malformed ID can't match any graph, and Postgres would reject it as a UUID
+	if _, err := uuid.Parse(graphID); err != nil {
+		return nil, ErrGraphNotFound
+	}
+
 	query, args, err := r.qb.
 		Select(
 			"id", "creator_id", "zep_graph_id", "name", "description",
 			"document_count", "gemini_store_id", "created_at", "updated_at",
+			"last_activity_at", "allowed_content_types", "default_member_role", "chat_enabled",
 		).
 		From("graphs").
 		Where(sq.Eq{"id": graphID}).
@@ -68,10 +93,10 @@ func (r *graphRepository) GetByID(ctx context.Context, graphID string) (*models.
 	}
 
 	var graph models.Graph
-	err = r.db.GetContext(ctx, &graph, query, args...)
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &graph, query, args...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("graph not found")
+			return nil, ErrGraphNotFound
 		}
 		return nil, fmt.Errorf("failed to get graph by ID: %w", err)
 	}
@@ -85,6 +110,7 @@ func (r *graphRepository) GetByZepGraphID(ctx context.Context, zepGraphID string
 		Select(
 			"id", "creator_id", "zep_graph_id", "name", "description",
 			"document_count", "gemini_store_id", "created_at", "updated_at",
+			"last_activity_at", "allowed_content_types", "default_member_role", "chat_enabled",
 		).
 		From("graphs").
 		Where(sq.Eq{"zep_graph_id": zepGraphID}).
@@ -95,10 +121,10 @@ func (r *graphRepository) GetByZepGraphID(ctx context.Context, zepGraphID string
 	}
 
 	var graph models.Graph
-	err = r.db.GetContext(ctx, &graph, query, args...)
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &graph, query, args...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("graph not found")
+			return nil, ErrGraphNotFound
 		}
 		return nil, fmt.Errorf("failed to get graph by Zep graph ID: %w", err)
 	}
@@ -106,12 +132,15 @@ func (r *graphRepository) GetByZepGraphID(ctx context.Context, zepGraphID string
 	return &graph, nil
 }
 
-// Update updates an existing graph's name and description
+// Update updates an existing graph's name, description and settings
 func (r *graphRepository) Update(ctx context.Context, graph *models.Graph) error {
 	query, args, err := r.qb.
 		Update("graphs").
 		Set("name", graph.Name).
 		Set("description", graph.Description).
+		Set("allowed_content_types", graph.AllowedContentTypes).
+		Set("default_member_role", graph.DefaultMemberRole).
+		Set("chat_enabled", graph.ChatEnabled).
 		Set("updated_at", graph.UpdatedAt).
 		Where(sq.Eq{"id": graph.ID}).
 		ToSql()
@@ -120,7 +149,7 @@ func (r *graphRepository) Update(ctx context.Context, graph *models.Graph) error
 		return fmt.Errorf("failed to build update query: %w", err)
 	}
 
-	result, err := r.db.ExecContext(ctx, query, args...)
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update graph: %w", err)
 	}
@@ -131,7 +160,7 @@ func (r *graphRepository) Update(ctx context.Context, graph *models.Graph) error
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("graph not found")
+		return ErrGraphNotFound
 	}
 
 	return nil
@@ -148,7 +177,7 @@ func (r *graphRepository) Delete(ctx context.Context, graphID string) error {
 		return fmt.Errorf("failed to build delete query: %w", err)
 	}
 
-	result, err := r.db.ExecContext(ctx, query, args...)
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to delete graph: %w", err)
 	}
@@ -159,7 +188,7 @@ func (r *graphRepository) Delete(ctx context.Context, graphID string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("graph not found")
+		return ErrGraphNotFound
 	}
 
 	return nil
@@ -181,7 +210,7 @@ func (r *graphRepository) CreateMembership(ctx context.Context, membership *mode
 		return fmt.Errorf("failed to build insert query: %w", err)
 	}
 
-	_, err = r.db.ExecContext(ctx, query, args...)
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to create membership: %w", err)
 	}
@@ -200,7 +229,7 @@ func (r *graphRepository) DeleteMembership(ctx context.Context, graphID, userID
 		return fmt.Errorf("failed to build delete query: %w", err)
 	}
 
-	result, err := r.db.ExecContext(ctx, query, args...)
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to delete membership: %w", err)
 	}
@@ -232,7 +261,7 @@ func (r *graphRepository) GetMembership(ctx context.Context, graphID, userID str
 	}
 
 	var membership models.GraphMembership
-	err = r.db.GetContext(ctx, &membership, query, args...)
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &membership, query, args...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("membership not found")
@@ -243,15 +272,18 @@ func (r *graphRepository) GetMembership(ctx context.Context, graphID, userID str
 	return &membership, nil
 }
 
-// ListMembersByGraphID gets all members of a graph
-func (r *graphRepository) ListMembersByGraphID(ctx context.Context, graphID string) ([]*models.GraphMembership, error) {
-	query, args, err := r.qb.
+// ListMembersByGraphID gets up to limit members of a graph, skipping the first offset, in the
+// order they joined. A zero limit returns every member.
+func (r *graphRepository) ListMembersByGraphID(ctx context.Context, graphID string, limit, offset int) ([]*models.GraphMembership, error) {
+	builder := r.qb.
 		Select(
 			"id", "graph_id", "user_id", "role", "created_at",
 		).
 		From("graph_memberships").
 		Where(sq.Eq{"graph_id": graphID}).
-		OrderBy("created_at ASC").
+		OrderBy("created_at ASC", "id ASC")
+
+	query, args, err := withPage(builder, limit, offset).
 		ToSql()
 
 	if err != nil {
@@ -259,7 +291,7 @@ func (r *graphRepository) ListMembersByGraphID(ctx context.Context, graphID stri
 	}
 
 	var memberships []*models.GraphMembership
-	err = r.db.SelectContext(ctx, &memberships, query, args...)
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &memberships, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list members by graph ID: %w", err)
 	}
@@ -267,6 +299,100 @@ func (r *graphRepository) ListMembersByGraphID(ctx context.Context, graphID stri
 	return memberships, nil
 }
 
+// CountMembersByGraphID counts the members of a graph
+func (r *graphRepository) CountMembersByGraphID(ctx context.Context, graphID string) (int, error) {
+	query, args, err := r.qb.
+		Select("COUNT(*)").
+		From("graph_memberships").
+		Where(sq.Eq{"graph_id": graphID}).
+		ToSql()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to build count query: %w", err)
+	}
+
+	var count int
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &count, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count members by graph ID: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountByCreatorID returns the number of graphs a user has created
+func (r *graphRepository) CountByCreatorID(ctx context.Context, creatorID string) (int, error) {
+	query, args, err := r.qb.
+		Select("COUNT(*)").
+		From("graphs").
+		Where(sq.Eq{"creator_id": creatorID}).
+		ToSql()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to build count query: %w", err)
+	}
+
+	var count int
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &count, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count graphs by creator ID: %w", err)
+	}
+
+	return count, nil
+}
+
+// ExistsByCreatorAndName reports whether a user has created a graph with the given name,
+// ignoring case and surrounding spaces. excludeGraphID, if set, is left out, so a graph
+// being renamed doesn't conflict with itself.
+func (r *graphRepository) ExistsByCreatorAndName(ctx context.Context, creatorID, name, excludeGraphID string) (bool, error) {
+	builder := r.qb.
+		Select("COUNT(*)").
+		From("graphs").
+		Where(sq.Eq{"creator_id": creatorID}).
+		Where(sq.Expr("LOWER(TRIM(name)) = LOWER(TRIM(?))", name))
+
+	if excludeGraphID != "" {
+		builder = builder.Where(sq.NotEq{"id": excludeGraphID})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build count query: %w", err)
+	}
+
+	var count int
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &count, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to check graph name: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// ListMembershipsByUserID retrieves all graph memberships of a user
+func (r *graphRepository) ListMembershipsByUserID(ctx context.Context, userID string) ([]*models.GraphMembership, error) {
+	query, args, err := r.qb.
+		Select(
+			"id", "graph_id", "user_id", "role", "created_at",
+		).
+		From("graph_memberships").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at ASC").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var memberships []*models.GraphMembership
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &memberships, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memberships by user ID: %w", err)
+	}
+
+	return memberships, nil
+}
+
 // IsMember checks if a user is a member of a graph
 func (r *graphRepository) IsMember(ctx context.Context, graphID, userID string) (bool, error) {
 	query, args, err := r.qb.
@@ -280,7 +406,7 @@ func (r *graphRepository) IsMember(ctx context.Context, graphID, userID string)
 	}
 
 	var count int
-	err = r.db.GetContext(ctx, &count, query, args...)
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &count, query, args...)
 	if err != nil {
 		return false, fmt.Errorf("failed to check membership: %w", err)
 	}
@@ -288,17 +414,31 @@ func (r *graphRepository) IsMember(ctx context.Context, graphID, userID string)
 	return count > 0, nil
 }
 
-// ListByUserID returns all graphs where the user is a member (via graph_memberships join)
-func (r *graphRepository) ListByUserID(ctx context.Context, userID string) ([]*models.Graph, error) {
-	query, args, err := r.qb.
+// ListByUserID returns all graphs where the user is a member (via graph_memberships join),
+// the user's favorites first and then in the requested order, with each graph's member
+// count aggregated in the same query
+func (r *graphRepository) ListByUserID(ctx context.Context, userID string, filter models.GraphFilter) ([]*models.Graph, error) {
+	orderBy, err := orderByClause(filter.Sort, graphSortColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := r.qb.
 		Select(
 			"g.id", "g.creator_id", "g.zep_graph_id", "g.name", "g.description",
 			"g.document_count", "g.gemini_store_id", "g.created_at", "g.updated_at",
+			"g.last_activity_at", "g.allowed_content_types", "g.default_member_role", "g.chat_enabled",
+			"COUNT(m.id) AS member_count",
+			"f.user_id IS NOT NULL AS is_favorite",
 		).
 		From("graphs g").
 		Join("graph_memberships gm ON g.id = gm.graph_id").
-		Where(sq.Eq{"gm.user_id": userID}).
-		OrderBy("g.created_at DESC").
+		LeftJoin("graph_memberships m ON g.id = m.graph_id").
+		LeftJoin("graph_favorites f ON g.id = f.graph_id AND f.user_id = ?", userID)
+
+	query, args, err := withPage(whereUserGraphs(builder, userID, filter), filter.Limit, filter.Offset).
+		GroupBy("g.id", "f.user_id").
+		OrderBy("is_favorite DESC", orderBy, "g.id").
 		ToSql()
 
 	if err != nil {
@@ -306,7 +446,7 @@ func (r *graphRepository) ListByUserID(ctx context.Context, userID string) ([]*m
 	}
 
 	var graphs []*models.Graph
-	err = r.db.SelectContext(ctx, &graphs, query, args...)
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &graphs, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list graphs by user ID: %w", err)
 	}
@@ -314,17 +454,82 @@ func (r *graphRepository) ListByUserID(ctx context.Context, userID string) ([]*m
 	return graphs, nil
 }
 
+// CountByUserID counts the graphs ListByUserID returns for the filter, ignoring its limit and offset
+func (r *graphRepository) CountByUserID(ctx context.Context, userID string, filter models.GraphFilter) (int, error) {
+	builder := r.qb.
+		Select("COUNT(*)").
+		From("graphs g").
+		Join("graph_memberships gm ON g.id = gm.graph_id")
+
+	query, args, err := whereUserGraphs(builder, userID, filter).
+		ToSql()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to build count query: %w", err)
+	}
+
+	var count int
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &count, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count graphs by user ID: %w", err)
+	}
+
+	return count, nil
+}
+
+// whereUserGraphs restricts a query joining graphs g to the user's memberships gm to the
+// graphs ListByUserID returns for the filter
+func whereUserGraphs(builder sq.SelectBuilder, userID string, filter models.GraphFilter) sq.SelectBuilder {
+	builder = builder.Where(sq.Eq{"gm.user_id": userID})
+
+	switch filter.Role {
+	case models.GraphRoleOwner:
+		builder = builder.Where(sq.Eq{"g.creator_id": userID})
+	case models.GraphRoleMember:
+		builder = builder.Where(sq.NotEq{"g.creator_id": userID})
+	}
+
+	return builder
+}
+
+// ListByCreatorID retrieves all graphs created by a user, whether or not they are still a member
+func (r *graphRepository) ListByCreatorID(ctx context.Context, creatorID string) ([]*models.Graph, error) {
+	query, args, err := r.qb.
+		Select(
+			"id", "creator_id", "zep_graph_id", "name", "description",
+			"document_count", "gemini_store_id", "created_at", "updated_at",
+			"last_activity_at", "allowed_content_types", "default_member_role", "chat_enabled",
+		).
+		From("graphs").
+		Where(sq.Eq{"creator_id": creatorID}).
+		OrderBy("created_at ASC").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var graphs []*models.Graph
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &graphs, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list graphs by creator ID: %w", err)
+	}
+
+	return graphs, nil
+}
+
 // UpdateDocumentCount atomically increments or decrements the document count
 func (r *graphRepository) UpdateDocumentCount(ctx context.Context, graphID string, delta int) error {
 	// Use raw SQL for atomic UPDATE with delta
 	query := `
 		UPDATE graphs
 		SET document_count = document_count + $1,
-		    updated_at = NOW()
+		    updated_at = NOW(),
+		    last_activity_at = CASE WHEN $1 > 0 THEN NOW() ELSE last_activity_at END
 		WHERE id = $2
 	`
 
-	result, err := r.db.ExecContext(ctx, query, delta, graphID)
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, delta, graphID)
 	if err != nil {
 		return fmt.Errorf("failed to update document count: %w", err)
 	}
@@ -335,7 +540,7 @@ func (r *graphRepository) UpdateDocumentCount(ctx context.Context, graphID strin
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("graph not found")
+		return ErrGraphNotFound
 	}
 
 	return nil
@@ -354,7 +559,7 @@ func (r *graphRepository) UpdateGeminiStoreID(ctx context.Context, graphID, gemi
 		return fmt.Errorf("failed to build update query: %w", err)
 	}
 
-	result, err := r.db.ExecContext(ctx, query, args...)
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update gemini store ID: %w", err)
 	}
@@ -365,7 +570,312 @@ func (r *graphRepository) UpdateGeminiStoreID(ctx context.Context, graphID, gemi
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("graph not found")
+		return ErrGraphNotFound
+	}
+
+	return nil
+}
+
+// AddFavorite marks a graph as a favorite of the user. Adding an existing favorite is a no-op.
+func (r *graphRepository) AddFavorite(ctx context.Context, graphID, userID string) error {
+	query, args, err := r.qb.
+		Insert("graph_favorites").
+		Columns("user_id", "graph_id", "created_at").
+		Values(userID, graphID, time.Now()).
+		Suffix("ON CONFLICT (user_id, graph_id) DO NOTHING").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %w", err)
+	}
+
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to add favorite: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveFavorite unmarks a graph as a favorite of the user. Removing a missing favorite is a no-op.
+func (r *graphRepository) RemoveFavorite(ctx context.Context, graphID, userID string) error {
+	query, args, err := r.qb.
+		Delete("graph_favorites").
+		Where(sq.Eq{"graph_id": graphID, "user_id": userID}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build delete query: %w", err)
+	}
+
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to remove favorite: %w", err)
+	}
+
+	return nil
+}
+
+// IsFavorite checks if the user has marked a graph as a favorite
+func (r *graphRepository) IsFavorite(ctx context.Context, graphID, userID string) (bool, error) {
+	query, args, err := r.qb.
+		Select("COUNT(*)").
+		From("graph_favorites").
+		Where(sq.Eq{"graph_id": graphID, "user_id": userID}).
+		ToSql()
+
+	if err != nil {
+		return false, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var count int
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &count, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to check favorite: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// shareLinkColumns are the columns selected for a graph share link
+var shareLinkColumns = []string{"id", "graph_id", "created_by", "expires_at", "revoked_at", "created_at"}
+
+// CreateShareLink inserts a new share link for a graph
+func (r *graphRepository) CreateShareLink(ctx context.Context, link *models.GraphShareLink) error {
+	query, args, err := r.qb.
+		Insert("graph_share_links").
+		Columns(shareLinkColumns...).
+		Values(link.ID, link.GraphID, link.CreatedBy, link.ExpiresAt, link.RevokedAt, link.CreatedAt).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %w", err)
+	}
+
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to create share link: %w", err)
+	}
+
+	return nil
+}
+
+// GetShareLink retrieves a share link by ID, including revoked and expired links
+func (r *graphRepository) GetShareLink(ctx context.Context, linkID string) (*models.GraphShareLink, error) {
+	query, args, err := r.qb.
+		Select(shareLinkColumns...).
+		From("graph_share_links").
+		Where(sq.Eq{"id": linkID}).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var link models.GraphShareLink
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &link, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrShareLinkNotFound
+		}
+		return nil, fmt.Errorf("failed to get share link: %w", err)
+	}
+
+	return &link, nil
+}
+
+// ListShareLinksByGraphID lists up to limit of a graph's share links, newest first, skipping
+// the first offset. A zero limit returns every link.
+func (r *graphRepository) ListShareLinksByGraphID(ctx context.Context, graphID string, limit, offset int) ([]*models.GraphShareLink, error) {
+	builder := r.qb.
+		Select(shareLinkColumns...).
+		From("graph_share_links").
+		Where(sq.Eq{"graph_id": graphID}).
+		OrderBy("created_at DESC", "id DESC")
+
+	query, args, err := withPage(builder, limit, offset).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var links []*models.GraphShareLink
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &links, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+
+	return links, nil
+}
+
+// CountShareLinksByGraphID counts a graph's share links
+func (r *graphRepository) CountShareLinksByGraphID(ctx context.Context, graphID string) (int, error) {
+	query, args, err := r.qb.
+		Select("COUNT(*)").
+		From("graph_share_links").
+		Where(sq.Eq{"graph_id": graphID}).
+		ToSql()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to build count query: %w", err)
+	}
+
+	var count int
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &count, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count share links: %w", err)
+	}
+
+	return count, nil
+}
+
+// RevokeShareLink marks a graph's share link as revoked. Revoking an already revoked link
+// keeps its original revocation time.
+func (r *graphRepository) RevokeShareLink(ctx context.Context, graphID, linkID string, revokedAt time.Time) error {
+	query, args, err := r.qb.
+		Update("graph_share_links").
+		Set("revoked_at", sq.Expr("COALESCE(revoked_at, ?)", revokedAt)).
+		Where(sq.Eq{"id": linkID, "graph_id": graphID}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %w", err)
+	}
+
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrShareLinkNotFound
+	}
+
+	return nil
+}
+
+// reindexJobColumns are the columns selected for a graph reindex job
+var reindexJobColumns = []string{
+	"id", "graph_id", "requested_by", "clear_graph", "reextract", "content_type", "status",
+	"total_documents", "processed_documents", "failed_documents", "error_message", "created_at",
+	"updated_at", "finished_at",
+}
+
+// CreateReindexJob inserts a new reindex job. Only one job per graph may be running, which
+// the database enforces so concurrent requests can't both start one.
+func (r *graphRepository) CreateReindexJob(ctx context.Context, job *models.GraphReindexJob) error {
+	query, args, err := r.qb.
+		Insert("graph_reindex_jobs").
+		Columns(reindexJobColumns...).
+		Values(job.ID, job.GraphID, job.RequestedBy, job.ClearGraph, job.Reextract, job.ContentType, job.Status,
+			job.TotalDocuments, job.ProcessedDocuments, job.FailedDocuments, job.ErrorMessage, job.CreatedAt,
+			job.UpdatedAt, job.FinishedAt).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %w", err)
+	}
+
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrReindexJobRunning
+		}
+		return fmt.Errorf("failed to create reindex job: %w", err)
+	}
+
+	return nil
+}
+
+// GetLatestReindexJob retrieves the most recently started reindex job of a graph
+func (r *graphRepository) GetLatestReindexJob(ctx context.Context, graphID string) (*models.GraphReindexJob, error) {
+	query, args, err := r.qb.
+		Select(reindexJobColumns...).
+		From("graph_reindex_jobs").
+		Where(sq.Eq{"graph_id": graphID}).
+		OrderBy("created_at DESC").
+		Limit(1).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var job models.GraphReindexJob
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &job, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReindexJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get reindex job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// UpdateReindexJob saves a reindex job's status and progress
+func (r *graphRepository) UpdateReindexJob(ctx context.Context, job *models.GraphReindexJob) error {
+	query, args, err := r.qb.
+		Update("graph_reindex_jobs").
+		Set("status", job.Status).
+		Set("total_documents", job.TotalDocuments).
+		Set("processed_documents", job.ProcessedDocuments).
+		Set("failed_documents", job.FailedDocuments).
+		Set("error_message", job.ErrorMessage).
+		Set("updated_at", job.UpdatedAt).
+		Set("finished_at", job.FinishedAt).
+		Where(sq.Eq{"id": job.ID}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %w", err)
+	}
+
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update reindex job: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrReindexJobNotFound
+	}
+
+	return nil
+}
+
+// FailStaleReindexJobs marks a graph's running jobs that made no progress since
+// updatedBefore as failed, such as jobs interrupted by a server restart
+func (r *graphRepository) FailStaleReindexJobs(ctx context.Context, graphID string, updatedBefore time.Time, message string) error {
+	now := time.Now().UTC()
+	query, args, err := r.qb.
+		Update("graph_reindex_jobs").
+		Set("status", models.ReindexStatusFailed).
+		Set("error_message", message).
+		Set("updated_at", now).
+		Set("finished_at", now).
+		Where(sq.Eq{"graph_id": graphID, "status": models.ReindexStatusRunning}).
+		Where(sq.Lt{"updated_at": updatedBefore}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %w", err)
+	}
+
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to fail stale reindex jobs: %w", err)
 	}
 
 	return nil
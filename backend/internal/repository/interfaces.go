@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/bipulkrdas/orgmind/backend/internal/models"
 )
@@ -12,17 +13,38 @@ type UserRepository interface {
 	GetByID(ctx context.Context, userID string) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	Update(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, userID string) error
 }
 
 // DocumentRepository defines the interface for document data access operations
 type DocumentRepository interface {
 	Create(ctx context.Context, doc *models.Document) error
 	GetByID(ctx context.Context, docID string) (*models.Document, error)
-	ListByUserID(ctx context.Context, userID string) ([]*models.Document, error)
-	ListByGraphID(ctx context.Context, graphID string) ([]*models.Document, error)
+	ListByUserID(ctx context.Context, userID string, filter models.DocumentFilter) ([]*models.Document, error)
+	CountByUserID(ctx context.Context, userID string, filter models.DocumentFilter) (int, error)
+	ListByGraphID(ctx context.Context, graphID string, filter models.DocumentFilter) ([]*models.Document, error)
+	CountByGraphID(ctx context.Context, graphID string, filter models.DocumentFilter) (int, error)
+	ListByAuthorID(ctx context.Context, userID string) ([]*models.Document, error)
+	ListStatusesForMember(ctx context.Context, docIDs []string, userID string) ([]*models.DocumentStatus, error)
+	CountByStatus(ctx context.Context, graphID string) (map[string]int, error)
+	LatestUpdateByGraphID(ctx context.Context, graphID string) (*time.Time, error)
+	SearchByGraphID(ctx context.Context, graphID, query string, limit, offset int) ([]*models.Document, error)
+	CountSearchByGraphID(ctx context.Context, graphID, query string) (int, error)
 	Update(ctx context.Context, doc *models.Document) error
 	Delete(ctx context.Context, docID string) error
 	UpdateGeminiFileID(ctx context.Context, docID, geminiFileID string) error
+	UpdateTags(ctx context.Context, docID string, tags []string) error
+	AddTag(ctx context.Context, docID, tag string, maxTags int) ([]string, bool, error)
+	RemoveTag(ctx context.Context, docID, tag string) ([]string, error)
+	AddZepEpisodes(ctx context.Context, docID string, episodeIDs []string) error
+	ListZepEpisodes(ctx context.Context, docID string) ([]string, error)
+	DeleteZepEpisodes(ctx context.Context, docID string, episodeIDs []string) error
+
+	// Automatic retries of failed processing
+	IncrementProcessingAttempts(ctx context.Context, docID string) (int, error)
+	ScheduleRetry(ctx context.Context, docID string, retryAt time.Time) error
+	ListDueForRetry(ctx context.Context, now time.Time, limit int) ([]*models.Document, error)
+	ClaimForRetry(ctx context.Context, docID string, now time.Time) (bool, error)
 }
 
 // PasswordResetTokenRepository defines the interface for password reset token operations
@@ -42,7 +64,11 @@ type GraphRepository interface {
 	Delete(ctx context.Context, graphID string) error
 
 	// Graph listing with membership join
-	ListByUserID(ctx context.Context, userID string) ([]*models.Graph, error)
+	ListByUserID(ctx context.Context, userID string, filter models.GraphFilter) ([]*models.Graph, error)
+	CountByUserID(ctx context.Context, userID string, filter models.GraphFilter) (int, error)
+	ListByCreatorID(ctx context.Context, creatorID string) ([]*models.Graph, error)
+	CountByCreatorID(ctx context.Context, creatorID string) (int, error)
+	ExistsByCreatorAndName(ctx context.Context, creatorID, name, excludeGraphID string) (bool, error)
 
 	// Document count management
 	UpdateDocumentCount(ctx context.Context, graphID string, delta int) error
@@ -57,8 +83,28 @@ type GraphRepository interface {
 	CreateMembership(ctx context.Context, membership *models.GraphMembership) error
 	DeleteMembership(ctx context.Context, graphID, userID string) error
 	GetMembership(ctx context.Context, graphID, userID string) (*models.GraphMembership, error)
-	ListMembersByGraphID(ctx context.Context, graphID string) ([]*models.GraphMembership, error)
+	ListMembersByGraphID(ctx context.Context, graphID string, limit, offset int) ([]*models.GraphMembership, error)
+	CountMembersByGraphID(ctx context.Context, graphID string) (int, error)
+	ListMembershipsByUserID(ctx context.Context, userID string) ([]*models.GraphMembership, error)
 	IsMember(ctx context.Context, graphID, userID string) (bool, error)
+
+	// Per-user favorites
+	AddFavorite(ctx context.Context, graphID, userID string) error
+	RemoveFavorite(ctx context.Context, graphID, userID string) error
+	IsFavorite(ctx context.Context, graphID, userID string) (bool, error)
+
+	// Public share links
+	CreateShareLink(ctx context.Context, link *models.GraphShareLink) error
+	GetShareLink(ctx context.Context, linkID string) (*models.GraphShareLink, error)
+	ListShareLinksByGraphID(ctx context.Context, graphID string, limit, offset int) ([]*models.GraphShareLink, error)
+	CountShareLinksByGraphID(ctx context.Context, graphID string) (int, error)
+	RevokeShareLink(ctx context.Context, graphID, linkID string, revokedAt time.Time) error
+
+	// Reindex jobs
+	CreateReindexJob(ctx context.Context, job *models.GraphReindexJob) error
+	GetLatestReindexJob(ctx context.Context, graphID string) (*models.GraphReindexJob, error)
+	UpdateReindexJob(ctx context.Context, job *models.GraphReindexJob) error
+	FailStaleReindexJobs(ctx context.Context, graphID string, updatedBefore time.Time, message string) error
 }
 
 // ChatRepository defines the interface for chat data access operations
@@ -66,15 +112,28 @@ type ChatRepository interface {
 	// Thread operations
 	CreateThread(ctx context.Context, thread *models.ChatThread) error
 	GetThreadByID(ctx context.Context, threadID string) (*models.ChatThread, error)
-	ListThreadsByGraphID(ctx context.Context, graphID string) ([]*models.ChatThread, error)
+	ListThreadsByGraphID(ctx context.Context, graphID string, filter models.ChatThreadFilter) ([]*models.ChatThread, error)
+	CountThreadsByGraphID(ctx context.Context, graphID string, filter models.ChatThreadFilter) (int, error)
+	ListThreadsByUserID(ctx context.Context, userID string) ([]*models.ChatThread, error)
 	UpdateThread(ctx context.Context, thread *models.ChatThread) error
+	SetThreadArchived(ctx context.Context, threadID string, archived bool, archivedAt *time.Time) error
+	SetThreadPrivate(ctx context.Context, threadID string, private bool) error
 	DeleteThread(ctx context.Context, threadID string) error
 
 	// Message operations
 	CreateMessage(ctx context.Context, message *models.ChatMessage) error
 	GetMessageByID(ctx context.Context, messageID string) (*models.ChatMessage, error)
-	GetMessagesByThreadID(ctx context.Context, threadID string, limit, offset int) ([]*models.ChatMessage, error)
+	GetMessagesByThreadID(ctx context.Context, threadID string, order string, limit, offset int) ([]*models.ChatMessage, error)
+	CountMessagesByThreadID(ctx context.Context, threadID string) (int, error)
 	DeleteMessagesByThreadID(ctx context.Context, threadID string) error
+
+	// Feedback operations
+	UpsertFeedback(ctx context.Context, feedback *models.MessageFeedback) error
+	GetFeedbackSummaryByGraphID(ctx context.Context, graphID string) (*models.FeedbackSummary, error)
+
+	// Gemini usage operations
+	RecordGeminiUsage(ctx context.Context, graphID string, day time.Time, inputTokens, outputTokens int) error
+	ListGeminiUsage(ctx context.Context, graphID string, since time.Time) ([]models.GeminiUsageDay, error)
 }
 
 // GeminiStoreRepository defines the interface for Gemini File Search store operations
@@ -83,3 +142,16 @@ type GeminiStoreRepository interface {
 	GetByStoreName(ctx context.Context, storeName string) (*models.GeminiFileSearchStore, error)
 	Update(ctx context.Context, store *models.GeminiFileSearchStore) error
 }
+
+// UploadSessionRepository defines the interface for resumable upload session operations
+type UploadSessionRepository interface {
+	Create(ctx context.Context, session *models.UploadSession) error
+	GetByID(ctx context.Context, sessionID string) (*models.UploadSession, error)
+	Delete(ctx context.Context, sessionID string) error
+	MarkAssembled(ctx context.Context, sessionID string) error
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]*models.UploadSession, error)
+
+	// Parts received so far, in part number order; saving a part again replaces it
+	SavePart(ctx context.Context, part *models.UploadPart) error
+	ListParts(ctx context.Context, sessionID string) ([]*models.UploadPart, error)
+}
@@ -0,0 +1,12 @@
+package repository
+
+import sq "github.com/Masterminds/squirrel"
+
+// withPage limits a list query to limit rows, skipping the first offset. A zero limit
+// returns every row, for callers that need the complete list.
+func withPage(builder sq.SelectBuilder, limit, offset int) sq.SelectBuilder {
+	if limit <= 0 {
+		return builder
+	}
+	return builder.Limit(uint64(limit)).Offset(uint64(offset))
+}
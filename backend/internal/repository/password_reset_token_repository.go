@@ -10,6 +10,9 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// ErrResetTokenNotFound is returned when no password reset token matches the requested token
+var ErrResetTokenNotFound = errors.New("reset token not found")
+
 // passwordResetTokenRepository implements PasswordResetTokenRepository interface
 type passwordResetTokenRepository struct {
 	db *sqlx.DB
@@ -30,7 +33,7 @@ func (r *passwordResetTokenRepository) Create(ctx context.Context, token *models
 		)
 	`
 
-	_, err := r.db.ExecContext(
+	_, err := dbFromContext(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		token.ID,
@@ -58,10 +61,10 @@ func (r *passwordResetTokenRepository) GetByToken(ctx context.Context, tokenStr
 	`
 
 	var token models.PasswordResetToken
-	err := r.db.GetContext(ctx, &token, query, tokenStr)
+	err := dbFromContext(ctx, r.db).GetContext(ctx, &token, query, tokenStr)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("token not found")
+			return nil, ErrResetTokenNotFound
 		}
 		return nil, fmt.Errorf("failed to get token: %w", err)
 	}
@@ -77,7 +80,7 @@ func (r *passwordResetTokenRepository) MarkAsUsed(ctx context.Context, tokenID s
 		WHERE id = $1
 	`
 
-	result, err := r.db.ExecContext(ctx, query, tokenID)
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, tokenID)
 	if err != nil {
 		return fmt.Errorf("failed to mark token as used: %w", err)
 	}
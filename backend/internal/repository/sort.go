@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/models"
+)
+
+// Sortable columns per list query, keyed by the API sort field.
+// Only these mapped expressions ever reach the ORDER BY clause.
+var (
+	documentSortColumns = map[string]string{
+		models.SortByName:    "LOWER(filename)",
+		models.SortByCreated: "created_at",
+		models.SortByUpdated: "updated_at",
+	}
+
+	graphSortColumns = map[string]string{
+		models.SortByName:     "LOWER(g.name)",
+		models.SortByCreated:  "g.created_at",
+		models.SortByUpdated:  "g.updated_at",
+		models.SortByActivity: "g.last_activity_at",
+	}
+)
+
+// orderByClause builds an ORDER BY expression for sort from a column whitelist.
+// An empty field sorts by the "created" column; an empty order sorts descending.
+func orderByClause(sort models.ListSort, columns map[string]string) (string, error) {
+	field := sort.Field
+	if field == "" {
+		field = models.SortByCreated
+	}
+
+	column, ok := columns[field]
+	if !ok {
+		return "", fmt.Errorf("unsupported sort field %q", sort.Field)
+	}
+
+	switch sort.Order {
+	case "", models.SortOrderDesc:
+		return column + " DESC", nil
+	case models.SortOrderAsc:
+		return column + " ASC", nil
+	default:
+		return "", fmt.Errorf("unsupported sort order %q", sort.Order)
+	}
+}
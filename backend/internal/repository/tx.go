@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DBTX is the set of query methods shared by *sqlx.DB and *sqlx.Tx.
+// Repository methods run their queries through a DBTX so the same code
+// works both inside and outside of a transaction.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// txContextKey is the context key under which the active transaction is stored
+type txContextKey struct{}
+
+// TxManager runs functions inside a database transaction.
+// Repository calls made with the context passed to fn participate in the transaction.
+type TxManager interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// txManager implements TxManager interface
+type txManager struct {
+	db *sqlx.DB
+}
+
+// NewTxManager creates a new instance of TxManager
+func NewTxManager(db *sqlx.DB) TxManager {
+	return &txManager{db: db}
+}
+
+// WithTx begins a transaction, calls fn with a context carrying it, and commits
+// if fn returns nil. The transaction is rolled back if fn returns an error or panics.
+// Nested calls reuse the outer transaction, so only the outermost call commits.
+func (m *txManager) WithTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txContextKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txContextKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// dbFromContext returns the transaction stored in ctx, or db if there is none
+func dbFromContext(ctx context.Context, db *sqlx.DB) DBTX {
+	if tx, ok := ctx.Value(txContextKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	return db
+}
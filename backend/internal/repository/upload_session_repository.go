@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/models"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrUploadSessionNotFound is returned when there is no upload session with the requested ID
+var ErrUploadSessionNotFound = errors.New("upload session not found")
+
+// uploadSessionColumns lists the columns of upload_sessions in the order they are inserted
+var uploadSessionColumns = []string{
+	"id", "user_id", "graph_id", "document_id", "filename", "content_type", "size_bytes",
+	"part_size", "storage_key", "storage_upload_id", "assembled", "expires_at", "created_at",
+}
+
+// uploadSessionRepository implements UploadSessionRepository interface
+type uploadSessionRepository struct {
+	db *sqlx.DB
+	qb sq.StatementBuilderType
+}
+
+// NewUploadSessionRepository creates a new instance of UploadSessionRepository
+func NewUploadSessionRepository(db *sqlx.DB) UploadSessionRepository {
+	return &uploadSessionRepository{
+		db: db,
+		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// Create inserts a new upload session
+func (r *uploadSessionRepository) Create(ctx context.Context, session *models.UploadSession) error {
+	query, args, err := r.qb.
+		Insert("upload_sessions").
+		Columns(uploadSessionColumns...).
+		Values(
+			session.ID, session.UserID, session.GraphID, session.DocumentID, session.Filename, session.ContentType, session.SizeBytes,
+			session.PartSize, session.StorageKey, session.StorageUploadID, session.Assembled, session.ExpiresAt, session.CreatedAt,
+		).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %w", err)
+	}
+
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to create upload session: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an upload session by ID, including expired sessions
+func (r *uploadSessionRepository) GetByID(ctx context.Context, sessionID string) (*models.UploadSession, error) {
+	query, args, err := r.qb.
+		Select(uploadSessionColumns...).
+		From("upload_sessions").
+		Where(sq.Eq{"id": sessionID}).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var session models.UploadSession
+	err = dbFromContext(ctx, r.db).GetContext(ctx, &session, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUploadSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get upload session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// Delete removes an upload session and its parts. It returns ErrUploadSessionNotFound when
+// the session is already gone, so concurrent callers can tell which of them removed it.
+func (r *uploadSessionRepository) Delete(ctx context.Context, sessionID string) error {
+	query, args, err := r.qb.
+		Delete("upload_sessions").
+		Where(sq.Eq{"id": sessionID}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build delete query: %w", err)
+	}
+
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete upload session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUploadSessionNotFound
+	}
+
+	return nil
+}
+
+// MarkAssembled records that an upload session's parts were joined into the stored file
+func (r *uploadSessionRepository) MarkAssembled(ctx context.Context, sessionID string) error {
+	query, args, err := r.qb.
+		Update("upload_sessions").
+		Set("assembled", true).
+		Where(sq.Eq{"id": sessionID}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %w", err)
+	}
+
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to mark upload session assembled: %w", err)
+	}
+
+	return nil
+}
+
+// ListExpired lists up to limit upload sessions that expired before now, oldest first
+func (r *uploadSessionRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*models.UploadSession, error) {
+	query, args, err := r.qb.
+		Select(uploadSessionColumns...).
+		From("upload_sessions").
+		Where(sq.Lt{"expires_at": now}).
+		OrderBy("expires_at ASC").
+		Limit(uint64(limit)).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var sessions []*models.UploadSession
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &sessions, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired upload sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// SavePart records a received part, replacing an earlier upload of the same part number
+func (r *uploadSessionRepository) SavePart(ctx context.Context, part *models.UploadPart) error {
+	query, args, err := r.qb.
+		Insert("upload_session_parts").
+		Columns("session_id", "part_number", "etag", "size_bytes", "uploaded_at").
+		Values(part.SessionID, part.PartNumber, part.ETag, part.SizeBytes, part.UploadedAt).
+		Suffix(`ON CONFLICT (session_id, part_number) DO UPDATE
+			SET etag = EXCLUDED.etag, size_bytes = EXCLUDED.size_bytes, uploaded_at = EXCLUDED.uploaded_at`).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %w", err)
+	}
+
+	_, err = dbFromContext(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to save upload part: %w", err)
+	}
+
+	return nil
+}
+
+// ListParts lists the parts received for an upload session, in part number order
+func (r *uploadSessionRepository) ListParts(ctx context.Context, sessionID string) ([]*models.UploadPart, error) {
+	query, args, err := r.qb.
+		Select("session_id", "part_number", "etag", "size_bytes", "uploaded_at").
+		From("upload_session_parts").
+		Where(sq.Eq{"session_id": sessionID}).
+		OrderBy("part_number ASC").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var parts []*models.UploadPart
+	err = dbFromContext(ctx, r.db).SelectContext(ctx, &parts, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upload parts: %w", err)
+	}
+
+	return parts, nil
+}
@@ -12,6 +12,9 @@ import (
 	"github.com/lib/pq"
 )
 
+// ErrUserNotFound is returned when no user has the requested ID or email
+var ErrUserNotFound = errors.New("user not found")
+
 // userRepository implements UserRepository interface
 type userRepository struct {
 	db *sqlx.DB
@@ -33,7 +36,7 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 		)
 	`
 
-	_, err := r.db.ExecContext(
+	_, err := dbFromContext(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		user.ID,
@@ -71,10 +74,10 @@ func (r *userRepository) GetByID(ctx context.Context, userID string) (*models.Us
 	`
 
 	var user models.User
-	err := r.db.GetContext(ctx, &user, query, userID)
+	err := dbFromContext(ctx, r.db).GetContext(ctx, &user, query, userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user by ID: %w", err)
 	}
@@ -93,10 +96,10 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.
 	`
 
 	var user models.User
-	err := r.db.GetContext(ctx, &user, query, email)
+	err := dbFromContext(ctx, r.db).GetContext(ctx, &user, query, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
@@ -119,7 +122,7 @@ func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 		WHERE id = $1
 	`
 
-	result, err := r.db.ExecContext(
+	result, err := dbFromContext(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		user.ID,
@@ -148,7 +151,29 @@ func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a user from the database (cascade deletes memberships, documents,
+// chat threads, favorites, feedback and password reset tokens)
+func (r *userRepository) Delete(ctx context.Context, userID string) error {
+	query := `DELETE FROM users WHERE id = $1`
+
+	result, err := dbFromContext(ctx, r.db).ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
 	}
 
 	return nil
@@ -9,18 +9,41 @@ import (
 func (r *Router) setupAuthenticatedRoutes(router *gin.Engine) {
 	// Create authenticated API group with JWT middleware
 	authenticated := router.Group("/api")
-	authenticated.Use(middleware.AuthMiddleware(r.config.JWTSecret))
+	authenticated.Use(middleware.AuthMiddleware(r.jwtKeys))
+
+	// Account endpoints (sign-in endpoints under /api/auth are public)
+	authenticated.DELETE("/auth/me", r.authHandler.DeleteAccount)
+	authenticated.GET("/auth/me/export", r.authHandler.ExportAccount)
+
+	// Formats that can be uploaded, with their capabilities and limits
+	authenticated.GET("/extraction/formats", r.extractionHandler.ListFormats)
 
 	// Document endpoints
 	documents := authenticated.Group("/documents")
 	{
 		documents.POST("/editor", r.documentHandler.SubmitEditorContent)
 		documents.POST("/upload", r.documentHandler.UploadFile)
+
+		// Resumable uploads of large files, sent in parts
+		documents.POST("/uploads", r.documentHandler.InitiateUpload)
+		documents.GET("/uploads/:uploadId", r.documentHandler.GetUpload)
+		documents.PUT("/uploads/:uploadId/parts/:partNumber", r.documentHandler.UploadPart)
+		documents.POST("/uploads/:uploadId/complete", r.documentHandler.CompleteUpload)
+		documents.DELETE("/uploads/:uploadId", r.documentHandler.AbortUpload)
+
 		documents.GET("", r.documentHandler.ListDocuments)
+		documents.POST("/status", r.documentHandler.BatchStatus)
 		documents.GET("/:id", r.documentHandler.GetDocument)
+		documents.HEAD("/:id", r.documentHandler.GetDocument)
 		documents.GET("/:id/content", r.documentHandler.GetDocumentContent)
+		documents.HEAD("/:id/content", r.documentHandler.GetDocumentContent)
 		documents.PUT("/:id", r.documentHandler.UpdateDocument)
 		documents.DELETE("/:id", r.documentHandler.DeleteDocument)
+
+		// Tag management
+		documents.PUT("/:id/tags", r.documentHandler.SetTags)
+		documents.POST("/:id/tags", r.documentHandler.AddTag)
+		documents.DELETE("/:id/tags/:tag", r.documentHandler.RemoveTag)
 	}
 
 	// Graph management endpoints
@@ -35,12 +58,32 @@ func (r *Router) setupAuthenticatedRoutes(router *gin.Engine) {
 
 		// Membership management
 		graphs.POST("/:id/members", r.graphHandler.AddMember)
+		graphs.POST("/:id/members/bulk", r.graphHandler.AddMembers)
 		graphs.DELETE("/:id/members/:userId", r.graphHandler.RemoveMember)
 		graphs.GET("/:id/members", r.graphHandler.ListMembers)
 
+		// Per-user favorites
+		graphs.POST("/:id/favorite", r.graphHandler.AddFavorite)
+		graphs.DELETE("/:id/favorite", r.graphHandler.RemoveFavorite)
+
+		// Public share links (creator only)
+		graphs.POST("/:id/share-links", r.graphHandler.CreateShareLink)
+		graphs.GET("/:id/share-links", r.graphHandler.ListShareLinks)
+		graphs.DELETE("/:id/share-links/:linkId", r.graphHandler.RevokeShareLink)
+
 		// Graph-specific data endpoints
 		graphs.GET("/:id/documents", r.graphHandler.ListGraphDocuments)
+		graphs.GET("/:id/documents/:docId", r.graphHandler.GetGraphDocument)
+		graphs.HEAD("/:id/documents/:docId", r.graphHandler.GetGraphDocument)
+		graphs.GET("/:id/documents/:docId/content", r.graphHandler.GetGraphDocumentContent)
+		graphs.HEAD("/:id/documents/:docId/content", r.graphHandler.GetGraphDocumentContent)
+		graphs.GET("/:id/stats", r.graphHandler.GetGraphStats)
+		graphs.GET("/:id/search", r.graphHandler.SearchGraph)
 		graphs.GET("/:id/visualization", r.graphHandler.GetGraphVisualization)
+		graphs.GET("/:id/integrity", r.graphHandler.VerifyDocumentIntegrity)
+		graphs.POST("/:id/reindex", r.graphHandler.ReindexGraph)
+		graphs.GET("/:id/reindex", r.graphHandler.GetReindexStatus)
+		graphs.POST("/:id/reextract", r.graphHandler.ReextractGraph)
 
 		// Chat endpoints - using :id to match parent graph routes
 		chat := graphs.Group("/:id/chat")
@@ -48,8 +91,18 @@ func (r *Router) setupAuthenticatedRoutes(router *gin.Engine) {
 			// Thread management
 			chat.GET("/threads", r.chatHandler.ListThreads)
 			chat.POST("/threads", r.chatHandler.CreateThread)
+			chat.POST("/start", r.chatHandler.StartChat)
+			chat.POST("/threads/:threadId/archive", r.chatHandler.ArchiveThread)
+			chat.DELETE("/threads/:threadId/archive", r.chatHandler.UnarchiveThread)
+			chat.POST("/threads/:threadId/private", r.chatHandler.MakeThreadPrivate)
+			chat.DELETE("/threads/:threadId/private", r.chatHandler.ShareThread)
+			chat.DELETE("/threads/:threadId", r.chatHandler.DeleteThread)
 			chat.GET("/threads/:threadId/messages", r.chatHandler.GetThreadMessages)
 			chat.POST("/threads/:threadId/messages", r.chatHandler.SendMessage)
+			chat.POST("/threads/:threadId/messages/:messageId/feedback", r.chatHandler.SubmitFeedback)
+
+			// Aggregate feedback (graph creator only)
+			chat.GET("/feedback", r.chatHandler.GetFeedbackSummary)
 
 			// SSE streaming endpoint
 			chat.GET("/stream", r.chatHandler.StreamResponse)
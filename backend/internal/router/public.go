@@ -1,11 +1,15 @@
 package router
 
 import (
+	"github.com/bipulkrdas/orgmind/backend/internal/middleware"
 	"github.com/gin-gonic/gin"
 )
 
 // setupPublicRoutes configures all public routes that don't require authentication
 func (r *Router) setupPublicRoutes(router *gin.Engine) {
+	// Public keys for verifying our tokens in other services (empty for HS256)
+	router.GET("/.well-known/jwks.json", r.authHandler.JWKS)
+
 	// Create public API group
 	public := router.Group("/api/auth")
 
@@ -18,4 +22,10 @@ func (r *Router) setupPublicRoutes(router *gin.Engine) {
 	// OAuth endpoints
 	public.GET("/oauth/:provider", r.authHandler.InitiateOAuth)
 	public.GET("/oauth/:provider/callback", r.authHandler.HandleOAuthCallback)
+
+	// Read-only graph access through public share links; never add write, member or chat routes here
+	shared := router.Group("/api/shared/:token")
+	shared.Use(middleware.ShareLinkMiddleware(r.graphService))
+	shared.GET("/visualization", r.graphHandler.GetSharedGraphVisualization)
+	shared.GET("/documents", r.graphHandler.ListSharedGraphDocuments)
 }
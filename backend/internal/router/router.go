@@ -1,21 +1,30 @@
 package router
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/bipulkrdas/orgmind/backend/internal/config"
 	"github.com/bipulkrdas/orgmind/backend/internal/handler"
+	"github.com/bipulkrdas/orgmind/backend/internal/middleware"
+	"github.com/bipulkrdas/orgmind/backend/internal/service"
+	"github.com/bipulkrdas/orgmind/backend/internal/tracing"
+	"github.com/bipulkrdas/orgmind/backend/pkg/utils"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
 // Router holds all handlers and configuration
 type Router struct {
-	authHandler     *handler.AuthHandler
-	documentHandler *handler.DocumentHandler
-	graphHandler    *handler.GraphHandler
-	chatHandler     *handler.ChatHandler
-	config          *config.Config
+	authHandler       *handler.AuthHandler
+	documentHandler   *handler.DocumentHandler
+	graphHandler      *handler.GraphHandler
+	chatHandler       *handler.ChatHandler
+	extractionHandler *handler.ExtractionHandler
+	healthHandler     *handler.HealthHandler
+	graphService      service.GraphService
+	config            *config.Config
+	jwtKeys           *utils.JWTKeys
 }
 
 // NewRouter creates a new router instance with all handlers
@@ -24,47 +33,72 @@ func NewRouter(
 	documentHandler *handler.DocumentHandler,
 	graphHandler *handler.GraphHandler,
 	chatHandler *handler.ChatHandler,
+	extractionHandler *handler.ExtractionHandler,
+	healthHandler *handler.HealthHandler,
+	graphService service.GraphService,
 	config *config.Config,
+	jwtKeys *utils.JWTKeys,
 ) *Router {
 	return &Router{
-		authHandler:     authHandler,
-		documentHandler: documentHandler,
-		graphHandler:    graphHandler,
-		chatHandler:     chatHandler,
-		config:          config,
+		authHandler:       authHandler,
+		documentHandler:   documentHandler,
+		graphHandler:      graphHandler,
+		chatHandler:       chatHandler,
+		extractionHandler: extractionHandler,
+		healthHandler:     healthHandler,
+		graphService:      graphService,
+		config:            config,
+		jwtKeys:           jwtKeys,
 	}
 }
 
 // Setup initializes the Gin router with all middleware and routes
 func (r *Router) Setup() *gin.Engine {
+	// Gin's debug output (route table, warnings) is only useful in development
+	if !r.config.IsDevelopment() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
 	// Create Gin router
 	router := gin.New()
 
 	// Add recovery middleware to handle panics
 	router.Use(gin.Recovery())
 
-	// Add custom logging middleware
-	router.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		return ""
-	}))
+	// Tag every request with an ID so client-reported errors can be matched to the logs
+	router.Use(middleware.RequestID())
+
+	// Time every request and the Zep, Gemini and storage calls it makes (a no-op unless TRACING_EXPORTER is set)
+	router.Use(middleware.Tracing())
 
-	// Configure CORS middleware
+	// Log every request at debug level; otherwise stay quiet
+	if r.config.LogLevel == "debug" {
+		router.Use(gin.Logger())
+	} else {
+		router.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+			return ""
+		}))
+	}
+
+	// Configure CORS middleware (origins come from CORS_ALLOWED_ORIGINS, "*" by default in development)
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"}, // Configure based on environment in production
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
+		AllowOrigins:     r.config.CORSAllowedOrigins,
+		AllowMethods:     []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "Idempotency-Key", middleware.RequestIDHeader, tracing.TraceparentHeader},
+		ExposeHeaders:    []string{"Content-Length", "ETag", middleware.RequestIDHeader},
+		AllowCredentials: r.config.CORSAllowCredentials,
 		MaxAge:           12 * time.Hour,
 	}))
 
-	// Configure request size limits for file uploads (50MB max)
-	router.MaxMultipartMemory = 50 << 20 // 50 MB
+	// Keep at most 8MB of each multipart upload in memory; larger files spill to
+	// temporary files on disk and are streamed from there to storage
+	router.MaxMultipartMemory = 8 << 20 // 8 MB
 
 	// Add error handling middleware
 	router.Use(errorHandler())
 
 	// Health check endpoint (for Cloud Run and monitoring)
+	// Registered before the TLS check so plain-HTTP health probes keep working
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"status":  "healthy",
@@ -72,6 +106,14 @@ func (r *Router) Setup() *gin.Engine {
 		})
 	})
 
+	// Readiness endpoint: checks the database and external services the API depends on
+	router.GET("/ready", r.healthHandler.Ready)
+
+	// Reject plain-HTTP API requests when TLS is required
+	if r.config.RequireTLS {
+		router.Use(requireTLS())
+	}
+
 	// Setup route groups
 	r.setupPublicRoutes(router)
 	r.setupAuthenticatedRoutes(router)
@@ -90,7 +132,7 @@ func errorHandler() gin.HandlerFunc {
 			err := c.Errors.Last()
 
 			// Log the error
-			gin.DefaultErrorWriter.Write([]byte(err.Error() + "\n"))
+			gin.DefaultErrorWriter.Write([]byte("[request " + middleware.GetRequestID(c) + "] " + err.Error() + "\n"))
 
 			// If response hasn't been written yet, send error response
 			if !c.Writer.Written() {
@@ -102,3 +144,22 @@ func errorHandler() gin.HandlerFunc {
 		}
 	}
 }
+
+// requireTLS is a middleware that rejects requests not made over HTTPS.
+// TLS is usually terminated by a load balancer, so X-Forwarded-Proto is trusted as well.
+func requireTLS() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil && c.GetHeader("X-Forwarded-Proto") != "https" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    "TLS_REQUIRED",
+				"message": "HTTPS is required",
+			})
+			c.Abort()
+			return
+		}
+
+		// Tell browsers to keep using HTTPS
+		c.Header("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+		c.Next()
+	}
+}
@@ -6,11 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/bipulkrdas/orgmind/backend/internal/config"
 	"github.com/bipulkrdas/orgmind/backend/internal/models"
 	"github.com/bipulkrdas/orgmind/backend/internal/repository"
+	"github.com/bipulkrdas/orgmind/backend/internal/storage"
 	"github.com/bipulkrdas/orgmind/backend/pkg/utils"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -27,26 +29,63 @@ var (
 	ErrUnsupportedProvider = errors.New("unsupported OAuth provider")
 	// ErrOAuthFailed is returned when OAuth authentication fails
 	ErrOAuthFailed = errors.New("OAuth authentication failed")
+	// ErrOwnsSharedGraphs is returned when deleting an account would remove graphs other members still use
+	ErrOwnsSharedGraphs = errors.New("account owns graphs shared with other members; remove the other members or delete those graphs first")
+	// ErrEmailDomainNotAllowed is returned when signup is restricted and the email's domain is not allowed
+	ErrEmailDomainNotAllowed = errors.New("email domain is not allowed to create accounts")
+	// ErrInvalidResetToken is returned when a password reset token doesn't exist
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+	// ErrResetTokenUsed is returned when a password reset token was already used
+	ErrResetTokenUsed = errors.New("reset token has already been used")
+	// ErrResetTokenExpired is returned when a password reset token has expired
+	ErrResetTokenExpired = errors.New("reset token has expired")
 )
 
 // authService implements AuthService interface
 type authService struct {
 	userRepo       repository.UserRepository
 	resetTokenRepo repository.PasswordResetTokenRepository
+	graphRepo      repository.GraphRepository
+	documentRepo   repository.DocumentRepository
+	txManager      repository.TxManager
+	storageService storage.StorageService
+	zepService     ZepService
 	cfg            *config.Config
+	jwtKeys        *utils.JWTKeys
 }
 
 // NewAuthService creates a new instance of AuthService
-func NewAuthService(userRepo repository.UserRepository, resetTokenRepo repository.PasswordResetTokenRepository, cfg *config.Config) AuthService {
+func NewAuthService(
+	userRepo repository.UserRepository,
+	resetTokenRepo repository.PasswordResetTokenRepository,
+	graphRepo repository.GraphRepository,
+	documentRepo repository.DocumentRepository,
+	txManager repository.TxManager,
+	storageService storage.StorageService,
+	zepService ZepService,
+	cfg *config.Config,
+	jwtKeys *utils.JWTKeys,
+) AuthService {
 	return &authService{
 		userRepo:       userRepo,
 		resetTokenRepo: resetTokenRepo,
+		graphRepo:      graphRepo,
+		documentRepo:   documentRepo,
+		txManager:      txManager,
+		storageService: storageService,
+		zepService:     zepService,
 		cfg:            cfg,
+		jwtKeys:        jwtKeys,
 	}
 }
 
 // SignUp creates a new user account with email and password
 func (s *authService) SignUp(ctx context.Context, email, password, firstName, lastName string) (*models.User, string, error) {
+	// Reject domains outside the allow list before revealing whether the account exists
+	if !s.isEmailDomainAllowed(email) {
+		return nil, "", ErrEmailDomainNotAllowed
+	}
+
 	// Check if user already exists
 	existingUser, err := s.userRepo.GetByEmail(ctx, email)
 	if err == nil && existingUser != nil {
@@ -82,7 +121,7 @@ func (s *authService) SignUp(ctx context.Context, email, password, firstName, la
 	}
 
 	// Generate JWT token
-	token, err := utils.GenerateToken(user.ID, user.Email, s.cfg.JWTSecret, s.cfg.JWTExpirationHours)
+	token, err := utils.GenerateToken(user.ID, user.Email, s.jwtKeys, s.cfg.JWTExpirationHours)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -110,7 +149,7 @@ func (s *authService) SignIn(ctx context.Context, email, password string) (strin
 	}
 
 	// Generate JWT token
-	token, err := utils.GenerateToken(user.ID, user.Email, s.cfg.JWTSecret, s.cfg.JWTExpirationHours)
+	token, err := utils.GenerateToken(user.ID, user.Email, s.jwtKeys, s.cfg.JWTExpirationHours)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -156,7 +195,11 @@ func (s *authService) HandleOAuthCallback(ctx context.Context, provider, code st
 	// Check if user already exists
 	user, err := s.userRepo.GetByEmail(ctx, userInfo.Email)
 	if err != nil {
-		// User doesn't exist, create new user
+		// User doesn't exist, create new user if the domain may sign up
+		if !s.isEmailDomainAllowed(userInfo.Email) {
+			return "", ErrEmailDomainNotAllowed
+		}
+
 		providerName := provider
 		user = &models.User{
 			ID:            uuid.New().String(),
@@ -189,7 +232,7 @@ func (s *authService) HandleOAuthCallback(ctx context.Context, provider, code st
 	}
 
 	// Generate JWT token
-	jwtToken, err := utils.GenerateToken(user.ID, user.Email, s.cfg.JWTSecret, s.cfg.JWTExpirationHours)
+	jwtToken, err := utils.GenerateToken(user.ID, user.Email, s.jwtKeys, s.cfg.JWTExpirationHours)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -197,6 +240,27 @@ func (s *authService) HandleOAuthCallback(ctx context.Context, provider, code st
 	return jwtToken, nil
 }
 
+// isEmailDomainAllowed checks the email's domain against the configured allow list.
+// An empty list allows every domain.
+func (s *authService) isEmailDomainAllowed(email string) bool {
+	if len(s.cfg.AllowedEmailDomains) == 0 {
+		return true
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+
+	for _, allowed := range s.cfg.AllowedEmailDomains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
+
 // getOAuthConfig returns OAuth2 config for the specified provider
 func (s *authService) getOAuthConfig(provider string) (*oauth2.Config, error) {
 	switch provider {
@@ -405,17 +469,20 @@ func (s *authService) UpdatePassword(ctx context.Context, token, newPassword str
 	// Get reset token from database
 	resetToken, err := s.resetTokenRepo.GetByToken(ctx, token)
 	if err != nil {
-		return errors.New("invalid or expired reset token")
+		if errors.Is(err, repository.ErrResetTokenNotFound) {
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("failed to get reset token: %w", err)
 	}
 
 	// Check if token is already used
 	if resetToken.Used {
-		return errors.New("reset token has already been used")
+		return ErrResetTokenUsed
 	}
 
 	// Check if token is expired
 	if time.Now().After(resetToken.ExpiresAt) {
-		return errors.New("reset token has expired")
+		return ErrResetTokenExpired
 	}
 
 	// Hash new password with bcrypt cost 12
@@ -448,3 +515,129 @@ func (s *authService) UpdatePassword(ctx context.Context, token, newPassword str
 
 	return nil
 }
+
+// DeleteAccount permanently deletes a user and their data after verifying their password.
+// Graphs the user created are deleted with their documents; if any of them still has other
+// members, nothing is deleted and ErrOwnsSharedGraphs is returned. The user's documents in
+// other graphs, their memberships, chat threads, favorites and feedback are removed too.
+// Database rows are deleted in one transaction; stored files and Zep data are removed afterwards.
+func (s *authService) DeleteAccount(ctx context.Context, userID, password string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// OAuth-only accounts have no password; their authenticated session is the confirmation
+	if user.PasswordHash != nil && *user.PasswordHash != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(*user.PasswordHash), []byte(password)); err != nil {
+			return ErrInvalidCredentials
+		}
+	}
+
+	ownedGraphs, err := s.graphRepo.ListByCreatorID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list owned graphs: %w", err)
+	}
+
+	// Refuse to orphan graphs other members still use
+	ownedGraphIDs := make(map[string]bool, len(ownedGraphs))
+	for _, graph := range ownedGraphs {
+		members, err := s.graphRepo.ListMembersByGraphID(ctx, graph.ID, 0, 0)
+		if err != nil {
+			return fmt.Errorf("failed to list members of graph %s: %w", graph.ID, err)
+		}
+		for _, member := range members {
+			if member.UserID != userID {
+				return fmt.Errorf("%w: %s", ErrOwnsSharedGraphs, graph.Name)
+			}
+		}
+		ownedGraphIDs[graph.ID] = true
+	}
+
+	// Collect everything stored outside the database before the rows that reference it are gone
+	var storageKeys []string
+	for _, graph := range ownedGraphs {
+		docs, err := s.documentRepo.ListByGraphID(ctx, graph.ID, models.DocumentFilter{})
+		if err != nil {
+			return fmt.Errorf("failed to list documents of graph %s: %w", graph.ID, err)
+		}
+		for _, doc := range docs {
+			if doc.StorageKey != "" {
+				storageKeys = append(storageKeys, doc.StorageKey)
+			}
+		}
+	}
+
+	authoredDocs, err := s.documentRepo.ListByAuthorID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	// Documents in other users' graphs: their Zep data has to be removed episode by episode
+	var sharedDocs []*models.Document
+	var episodeIDs []string
+	for _, doc := range authoredDocs {
+		if doc.GraphID != nil && ownedGraphIDs[*doc.GraphID] {
+			continue // Already collected, and removed with the graph
+		}
+		sharedDocs = append(sharedDocs, doc)
+		if doc.StorageKey != "" {
+			storageKeys = append(storageKeys, doc.StorageKey)
+		}
+
+		ids, err := s.documentRepo.ListZepEpisodes(ctx, doc.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get episodes of document %s: %w", doc.ID, err)
+		}
+		episodeIDs = append(episodeIDs, ids...)
+	}
+
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		for _, graph := range ownedGraphs {
+			if err := s.graphRepo.Delete(ctx, graph.ID); err != nil {
+				return fmt.Errorf("failed to delete graph %s: %w", graph.ID, err)
+			}
+		}
+
+		// The user's documents cascade with the user row, so keep the other graphs' counts in step
+		for _, doc := range sharedDocs {
+			if doc.GraphID == nil {
+				continue
+			}
+			if err := s.graphRepo.UpdateDocumentCount(ctx, *doc.GraphID, -1); err != nil {
+				return fmt.Errorf("failed to update document count for graph %s: %w", *doc.GraphID, err)
+			}
+		}
+
+		// Cascades to memberships, documents, chat threads, favorites, feedback and reset tokens
+		if err := s.userRepo.Delete(ctx, userID); err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The account is gone; external cleanup failures are logged rather than returned
+	for _, graph := range ownedGraphs {
+		if err := s.zepService.DeleteGraph(ctx, graph.ZepGraphID); err != nil {
+			fmt.Printf("Warning: failed to delete graph %s from Zep: %v\n", graph.ID, err)
+		}
+	}
+
+	if len(episodeIDs) > 0 {
+		if err := s.zepService.RemoveDocumentData(ctx, episodeIDs); err != nil {
+			fmt.Printf("Warning: failed to remove documents of user %s from Zep: %v\n", userID, err)
+		}
+	}
+
+	for _, storageKey := range storageKeys {
+		if err := s.storageService.Delete(ctx, storageKey); err != nil {
+			fmt.Printf("Warning: failed to delete storage file %s: %v\n", storageKey, err)
+		}
+	}
+
+	return nil
+}
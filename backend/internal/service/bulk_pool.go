@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// BulkPool bounds how many documents bulk operations, such as graph reindexes and retry
+// passes, reprocess at once. The pool is shared by every bulk operation on the server, so
+// several running together can't saturate Zep and Gemini between them, while each still
+// runs faster than one document at a time.
+type BulkPool struct {
+	slots chan struct{}
+}
+
+// NewBulkPool creates a pool running at most size items at once
+func NewBulkPool(size int) *BulkPool {
+	if size < 1 {
+		size = 1
+	}
+	return &BulkPool{slots: make(chan struct{}, size)}
+}
+
+// Run calls fn for each index in [0, count), sharing the pool's slots with the other
+// operations using it, and returns once every call has finished. Items not yet started
+// when ctx is cancelled are skipped.
+func (p *BulkPool) Run(ctx context.Context, count int, fn func(i int)) {
+	var wg sync.WaitGroup
+
+dispatch:
+	for i := 0; i < count; i++ {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case p.slots <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-p.slots }()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
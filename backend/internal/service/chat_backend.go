@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/models"
+)
+
+// Limits for answers from the Zep chat backend
+const (
+	zepChatMaxResults      = 10  // Passages included in an answer
+	zepChatMaxResultLength = 600 // Characters of each passage
+)
+
+// geminiChatBackend answers with Gemini, grounded in the graphs' documents through File Search
+type geminiChatBackend struct {
+	gemini GeminiService
+}
+
+// NewGeminiChatBackend creates a chat backend answering with Gemini File Search. Without a
+// Gemini service, such as when GEMINI_API_KEY is not set, every message fails.
+func NewGeminiChatBackend(gemini GeminiService) ChatBackend {
+	return &geminiChatBackend{gemini: gemini}
+}
+
+// GenerateStreamingResponse streams Gemini's answer, searching the documents of every graph
+func (b *geminiChatBackend) GenerateStreamingResponse(ctx context.Context, graphs []*models.Graph, excludedDocumentIDs []string, query string, responseChan chan<- string) (TokenUsage, error) {
+	if b.gemini == nil {
+		return TokenUsage{}, ErrGeminiAPIKey
+	}
+
+	graphIDs := make([]string, len(graphs))
+	for i, graph := range graphs {
+		graphIDs[i] = graph.ID
+	}
+
+	// Use empty storeID to let the service use the shared store
+	return b.gemini.GenerateStreamingResponse(ctx, "", graphIDs, excludedDocumentIDs, "topeic.com", "1.1", query, responseChan)
+}
+
+// MetersUsage reports that Gemini answers count against graphs' monthly chat budgets
+func (b *geminiChatBackend) MetersUsage() bool {
+	return true
+}
+
+// zepChatBackend answers with the passages of the graphs' documents that Zep's memory search
+// finds for a message. No language model is involved, so deployments using only Zep can
+// offer chat, and retrieval quality can be compared with Gemini's.
+type zepChatBackend struct {
+	zep ZepService
+}
+
+// NewZepChatBackend creates a chat backend answering with Zep memory search
+func NewZepChatBackend(zep ZepService) ChatBackend {
+	return &zepChatBackend{zep: zep}
+}
+
+// GenerateStreamingResponse searches every graph and streams the best matching passages,
+// each attributed to its document
+func (b *zepChatBackend) GenerateStreamingResponse(ctx context.Context, graphs []*models.Graph, excludedDocumentIDs []string, query string, responseChan chan<- string) (TokenUsage, error) {
+	var results []models.MemoryResult
+	for _, graph := range graphs {
+		graphResults, err := b.zep.SearchMemory(ctx, graph.ZepGraphID, query)
+		if err != nil {
+			return TokenUsage{}, fmt.Errorf("failed to search graph %s: %w", graph.ID, err)
+		}
+
+		for _, result := range graphResults {
+			if !fromExcludedDocument(result, excludedDocumentIDs) {
+				results = append(results, result)
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > zepChatMaxResults {
+		results = results[:zepChatMaxResults]
+	}
+
+	chunks := []string{"I couldn't find anything in the knowledge graph about that."}
+	if len(results) > 0 {
+		chunks = []string{"Here is what the knowledge graph holds on this:\n"}
+		for _, result := range results {
+			chunks = append(chunks, formatZepChatResult(result))
+		}
+	}
+
+	for _, chunk := range chunks {
+		select {
+		case responseChan <- chunk:
+		case <-ctx.Done():
+			return TokenUsage{}, ctx.Err()
+		}
+	}
+
+	return TokenUsage{}, nil
+}
+
+// MetersUsage reports that Zep answers don't use Gemini, so they aren't charged to budgets
+func (b *zepChatBackend) MetersUsage() bool {
+	return false
+}
+
+// fromExcludedDocument reports whether a memory result came from one of documentIDs. Each
+// episode's source description names its document (see documentSourceDescription).
+func fromExcludedDocument(result models.MemoryResult, documentIDs []string) bool {
+	source, _ := result.Metadata["source_description"].(string)
+	for _, documentID := range documentIDs {
+		if strings.Contains(source, documentID) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatZepChatResult formats a memory result as a Markdown list item, shortened to
+// zepChatMaxResultLength characters and followed by its source
+func formatZepChatResult(result models.MemoryResult) string {
+	content := strings.Join(strings.Fields(result.Content), " ")
+	if runes := []rune(content); len(runes) > zepChatMaxResultLength {
+		content = strings.TrimSpace(string(runes[:zepChatMaxResultLength])) + "…"
+	}
+
+	item := "\n- " + content
+	if source, ok := result.Metadata["source_description"].(string); ok && source != "" {
+		item += fmt.Sprintf(" _(%s)_", source)
+	}
+	return item
+}
@@ -2,12 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"html"
+	"slices"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/bipulkrdas/orgmind/backend/internal/config"
 	"github.com/bipulkrdas/orgmind/backend/internal/models"
 	"github.com/bipulkrdas/orgmind/backend/internal/repository"
 	"github.com/google/uuid"
@@ -20,32 +24,75 @@ var (
 	ErrMessageTooLong        = fmt.Errorf("message content exceeds 4000 characters")
 	ErrRateLimitExceeded     = fmt.Errorf("rate limit exceeded: maximum 20 messages per minute")
 	ErrInvalidMessageContent = fmt.Errorf("message content is required")
+	ErrChatMessageNotFound   = fmt.Errorf("chat message not found")
+	ErrMessageNotRatable     = fmt.Errorf("only assistant messages can be rated")
+	ErrInvalidFeedbackRating = fmt.Errorf("rating must be either 'up' or 'down'")
+	ErrFeedbackCommentLong   = fmt.Errorf("feedback comment exceeds %d characters", MaxFeedbackCommentLength)
+	ErrTooManyChatGraphs     = fmt.Errorf("a chat message can search at most %d graphs", MaxChatGraphs)
+	ErrChatDisabled          = fmt.Errorf("chat is disabled for this graph")
+	ErrBudgetExceeded        = fmt.Errorf("this graph has used its monthly chat budget")
+	ErrTooManyExcludedDocs   = fmt.Errorf("a chat message can exclude at most %d documents", MaxExcludedChatDocuments)
+	ErrInvalidExcludedDoc    = fmt.Errorf("excluded documents must belong to a graph the message searches")
 )
 
+// MaxFeedbackCommentLength is the maximum length of a feedback comment in characters
+const MaxFeedbackCommentLength = 1000
+
+// MaxChatGraphs is the maximum number of graphs a single chat message can search
+const MaxChatGraphs = 10
+
+// MaxExcludedChatDocuments is the maximum number of documents a single chat message can
+// exclude, which keeps the File Search metadata filter short
+const MaxExcludedChatDocuments = 50
+
 // chatService implements the ChatService interface
 type chatService struct {
-	chatRepo    repository.ChatRepository
-	graphRepo   repository.GraphRepository
-	geminiSvc   GeminiService
-	rateLimiter *rateLimiter
+	chatRepo     repository.ChatRepository
+	graphRepo    repository.GraphRepository
+	documentRepo repository.DocumentRepository
+	txManager    repository.TxManager
+	chatBackend  ChatBackend
+	rateLimiter  *rateLimiter
+
+	// escapeContent HTML-escapes message content before it is stored (the "escape" sanitization policy)
+	escapeContent bool
+	// summaryLength is the maximum length of thread summaries, in characters
+	summaryLength int
+	// monthlyTokenCap and monthlyRequestCap are each graph's monthly chat budget, in Gemini
+	// tokens and generated responses (0 = unlimited)
+	monthlyTokenCap   int64
+	monthlyRequestCap int64
 }
 
 // NewChatService creates a new chat service instance
 func NewChatService(
 	chatRepo repository.ChatRepository,
 	graphRepo repository.GraphRepository,
-	geminiSvc GeminiService,
+	documentRepo repository.DocumentRepository,
+	txManager repository.TxManager,
+	chatBackend ChatBackend,
+	contentSanitization string,
+	summaryLength int,
+	monthlyTokenCap int64,
+	monthlyRequestCap int64,
 ) ChatService {
 	return &chatService{
-		chatRepo:    chatRepo,
-		graphRepo:   graphRepo,
-		geminiSvc:   geminiSvc,
-		rateLimiter: newRateLimiter(20, time.Minute), // 20 messages per minute
+		chatRepo:      chatRepo,
+		graphRepo:     graphRepo,
+		documentRepo:  documentRepo,
+		txManager:     txManager,
+		chatBackend:   chatBackend,
+		rateLimiter:   newRateLimiter(20, time.Minute), // 20 messages per minute
+		escapeContent: contentSanitization == config.ChatSanitizationEscape,
+		summaryLength: summaryLength,
+
+		monthlyTokenCap:   monthlyTokenCap,
+		monthlyRequestCap: monthlyRequestCap,
 	}
 }
 
-// CreateThread creates a new chat thread for a graph
-func (s *chatService) CreateThread(ctx context.Context, graphID, userID string) (*models.ChatThread, error) {
+// CreateThread creates a new chat thread for a graph, visible to every member unless private
+func (s *chatService) CreateThread(ctx context.Context, graphID, userID string, private bool) (*models.ChatThread, error) {
 	// Verify user is a member of the graph
 	isMember, err := s.graphRepo.IsMember(ctx, graphID, userID)
 	if err != nil {
@@ -55,6 +102,10 @@ func (s *chatService) CreateThread(ctx context.Context, graphID, userID string)
 		return nil, ErrNotGraphMember
 	}
 
+	if err := s.checkChatEnabled(ctx, graphID); err != nil {
+		return nil, err
+	}
+
 	// Create thread
 	now := time.Now()
 	thread := &models.ChatThread{
@@ -62,6 +113,7 @@ func (s *chatService) CreateThread(ctx context.Context, graphID, userID string)
 		GraphID:   graphID,
 		UserID:    userID,
 		Summary:   nil,
+		Private:   private,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -79,7 +131,67 @@ func (s *chatService) CreateThread(ctx context.Context, graphID, userID string)
 	return thread, nil
 }
 
-// GetThread retrieves a chat thread with access control
+// StartThread creates a thread and saves its first user message in one transaction, so a
+// failed start leaves neither behind. The message is ready for GenerateResponseForMessage.
+func (s *chatService) StartThread(ctx context.Context, graphID, userID, content string, private bool) (*models.ChatThread, *models.ChatMessage, error) {
+	if err := s.checkUserMessage(userID, content); err != nil {
+		return nil, nil, err
+	}
+
+	// Verify user is a member of the graph
+	isMember, err := s.graphRepo.IsMember(ctx, graphID, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to verify graph membership: %w", err)
+	}
+	if !isMember {
+		return nil, nil, ErrNotGraphMember
+	}
+
+	if err := s.checkChatEnabled(ctx, graphID); err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	thread := &models.ChatThread{
+		ID:        uuid.New().String(),
+		GraphID:   graphID,
+		UserID:    userID,
+		Private:   private,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	thread.GenerateSummary(content, s.summaryLength)
+	if err := thread.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid thread: %w", err)
+	}
+
+	userMsg := &models.ChatMessage{
+		ID:        uuid.New().String(),
+		ThreadID:  thread.ID,
+		Role:      "user",
+		Content:   content,
+		CreatedAt: now,
+	}
+
+	err = s.txManager.WithTx(ctx, func(txCtx context.Context) error {
+		if err := s.chatRepo.CreateThread(txCtx, thread); err != nil {
+			return fmt.Errorf("failed to create thread: %w", err)
+		}
+		if err := s.SaveMessage(txCtx, userMsg); err != nil {
+			return fmt.Errorf("failed to save user message: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return thread, userMsg, nil
+}
+
+// GetThread retrieves a chat thread with access control. Threads are shared: any member of
+// the graph can read and continue them, except private threads, which only their author
+// can see. Other users are told a private thread doesn't exist.
 func (s *chatService) GetThread(ctx context.Context, threadID, userID string) (*models.ChatThread, error) {
 	// Get thread from database
 	thread, err := s.chatRepo.GetThreadByID(ctx, threadID)
@@ -96,43 +208,171 @@ func (s *chatService) GetThread(ctx context.Context, threadID, userID string) (*
 		return nil, ErrChatUnauthorized
 	}
 
+	if thread.Private && thread.UserID != userID {
+		return nil, ErrChatThreadNotFound
+	}
+
 	return thread, nil
 }
 
-// ListThreads lists all threads for a graph with filtering
-func (s *chatService) ListThreads(ctx context.Context, graphID, userID string) ([]*models.ChatThread, error) {
+// ListThreads lists the threads for a graph matching filter (archived threads are excluded by
+// default), including the user's own private threads but not other members'.
+func (s *chatService) ListThreads(ctx context.Context, graphID, userID string, filter models.ChatThreadFilter) ([]*models.ChatThread, int, error) {
 	// Verify user is a member of the graph
 	isMember, err := s.graphRepo.IsMember(ctx, graphID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to verify graph membership: %w", err)
+		return nil, 0, fmt.Errorf("failed to verify graph membership: %w", err)
 	}
 	if !isMember {
-		return nil, ErrNotGraphMember
+		return nil, 0, ErrNotGraphMember
+	}
+
+	// Get the matching threads for the graph, leaving out other members' private threads
+	filter.ViewerID = userID
+	threads, err := s.chatRepo.ListThreadsByGraphID(ctx, graphID, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list threads: %w", err)
+	}
+
+	total, err := s.chatRepo.CountThreadsByGraphID(ctx, graphID, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count threads: %w", err)
+	}
+
+	return threads, total, nil
+}
+
+// ArchiveThread hides a thread from the default thread list without deleting it.
+// Only the user who started the thread or the graph's owner can archive it.
+func (s *chatService) ArchiveThread(ctx context.Context, threadID, userID string) (*models.ChatThread, error) {
+	return s.setThreadArchived(ctx, threadID, userID, true)
+}
+
+// UnarchiveThread returns an archived thread to the default thread list.
+// Only the user who started the thread or the graph's owner can unarchive it.
+func (s *chatService) UnarchiveThread(ctx context.Context, threadID, userID string) (*models.ChatThread, error) {
+	return s.setThreadArchived(ctx, threadID, userID, false)
+}
+
+// setThreadArchived updates a thread's archive state after checking the user may modify it.
+// Archiving an already archived thread keeps its original archive time.
+func (s *chatService) setThreadArchived(ctx context.Context, threadID, userID string, archived bool) (*models.ChatThread, error) {
+	thread, err := s.getModifiableThread(ctx, threadID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if thread.Archived == archived {
+		return thread, nil
 	}
 
-	// Get all threads for the graph
-	threads, err := s.chatRepo.ListThreadsByGraphID(ctx, graphID)
+	var archivedAt *time.Time
+	if archived {
+		now := time.Now()
+		archivedAt = &now
+	}
+
+	if err := s.chatRepo.SetThreadArchived(ctx, threadID, archived, archivedAt); err != nil {
+		return nil, fmt.Errorf("failed to update thread: %w", err)
+	}
+
+	thread.Archived = archived
+	thread.ArchivedAt = archivedAt
+	return thread, nil
+}
+
+// SetThreadPrivate makes a thread visible only to its author, or shares it with the graph's
+// members again. Only the author can change it, since a private thread is hidden from
+// everyone else, the graph's owner included.
+func (s *chatService) SetThreadPrivate(ctx context.Context, threadID, userID string, private bool) (*models.ChatThread, error) {
+	thread, err := s.GetThread(ctx, threadID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list threads: %w", err)
+		return nil, err
+	}
+	if thread.UserID != userID {
+		return nil, ErrChatUnauthorized
+	}
+
+	if thread.Private == private {
+		return thread, nil
+	}
+
+	if err := s.chatRepo.SetThreadPrivate(ctx, threadID, private); err != nil {
+		return nil, fmt.Errorf("failed to update thread: %w", err)
 	}
 
-	return threads, nil
+	thread.Private = private
+	return thread, nil
 }
 
-// GetMessages retrieves messages for a thread with pagination
-func (s *chatService) GetMessages(ctx context.Context, threadID string, limit, offset int) ([]*models.ChatMessage, error) {
+// DeleteThread deletes a thread with its messages and their feedback. Only the user who
+// started the thread or the graph's owner can delete it.
+func (s *chatService) DeleteThread(ctx context.Context, threadID, userID string) error {
+	if _, err := s.getModifiableThread(ctx, threadID, userID); err != nil {
+		return err
+	}
+
+	if err := s.chatRepo.DeleteThread(ctx, threadID); err != nil {
+		return fmt.Errorf("failed to delete thread: %w", err)
+	}
+
+	return nil
+}
+
+// getModifiableThread gets a thread the user can see and checks they may modify it: they
+// started it or own its graph
+func (s *chatService) getModifiableThread(ctx context.Context, threadID, userID string) (*models.ChatThread, error) {
+	thread, err := s.GetThread(ctx, threadID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if thread.UserID == userID {
+		return thread, nil
+	}
+
+	graph, err := s.graphRepo.GetByID(ctx, thread.GraphID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph: %w", err)
+	}
+	if graph.CreatorID != userID {
+		return nil, ErrChatUnauthorized
+	}
+
+	return thread, nil
+}
+
+// GetMessages retrieves a page of a thread's messages. By default, or with order
+// models.SortOrderDesc, pages are counted from the newest message, so offset 0 is the latest
+// messages and later offsets page backward; with models.SortOrderAsc they are counted from the
+// oldest. Either way the messages of a page are returned oldest first, in reading order.
+func (s *chatService) GetMessages(ctx context.Context, threadID, order string, limit, offset int) ([]*models.ChatMessage, int, error) {
+	if err := validateListSort(models.ListSort{Order: order}); err != nil {
+		return nil, 0, err
+	}
+	if order == "" {
+		order = models.SortOrderDesc
+	}
+
 	// Set default limit if not provided
 	if limit <= 0 {
 		limit = 50
 	}
 
 	// Get messages from database
-	messages, err := s.chatRepo.GetMessagesByThreadID(ctx, threadID, limit, offset)
+	messages, err := s.chatRepo.GetMessagesByThreadID(ctx, threadID, order, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get messages: %w", err)
+	}
+	if order == models.SortOrderDesc {
+		slices.Reverse(messages)
+	}
+
+	total, err := s.chatRepo.CountMessagesByThreadID(ctx, threadID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get messages: %w", err)
+		return nil, 0, fmt.Errorf("failed to count messages: %w", err)
 	}
 
-	return messages, nil
+	return messages, total, nil
 }
 
 // SaveMessage saves a message with validation and sanitization
@@ -142,8 +382,8 @@ func (s *chatService) SaveMessage(ctx context.Context, message *models.ChatMessa
 		return err
 	}
 
-	// Sanitize content (escape HTML)
-	message.Content = sanitizeContent(message.Content)
+	// Sanitize content
+	message.Content = s.sanitizeContent(message.Content)
 
 	// Save to database
 	if err := s.chatRepo.CreateMessage(ctx, message); err != nil {
@@ -189,6 +429,10 @@ func (s *chatService) GenerateResponse(ctx context.Context, threadID, userID, us
 		return fmt.Errorf("failed to get graph: %w", err)
 	}
 
+	if err := s.checkBudget(ctx, graph.ID); err != nil {
+		return err
+	}
+
 	// Save user message
 	userMsg := &models.ChatMessage{
 		ID:        uuid.New().String(),
@@ -203,7 +447,7 @@ func (s *chatService) GenerateResponse(ctx context.Context, threadID, userID, us
 
 	// Update thread summary if this is the first message
 	if thread.Summary == nil {
-		thread.GenerateSummary(userMessage)
+		thread.GenerateSummary(userMessage, s.summaryLength)
 		thread.UpdatedAt = time.Now()
 		if err := s.chatRepo.UpdateThread(ctx, thread); err != nil {
 			// Log error but continue
@@ -246,9 +490,10 @@ func (s *chatService) GenerateResponse(ctx context.Context, threadID, userID, us
 		close(responseChan)
 	}()
 
-	// Call Gemini service for streaming response with metadata filtering
-	// Use empty storeID to let service use the shared store
-	if err := s.geminiSvc.GenerateStreamingResponse(ctx, "", graph.ID, "topeic.com", "1.1", userMessage, fullResponseChan); err != nil {
+	// Generate the response from the graph's documents
+	usage, err := s.chatBackend.GenerateStreamingResponse(ctx, []*models.Graph{graph}, nil, userMessage, fullResponseChan)
+	s.recordUsage(graph.ID, usage)
+	if err != nil {
 		close(fullResponseChan)
 		return fmt.Errorf("failed to generate AI response: %w", err)
 	}
@@ -258,17 +503,8 @@ func (s *chatService) GenerateResponse(ctx context.Context, threadID, userID, us
 
 // SaveUserMessage saves a user message with validation and rate limiting
 func (s *chatService) SaveUserMessage(ctx context.Context, threadID, userID, content string) (*models.ChatMessage, error) {
-	// Check rate limit
-	if !s.rateLimiter.Allow(userID) {
-		return nil, ErrRateLimitExceeded
-	}
-
-	// Validate message content
-	if strings.TrimSpace(content) == "" {
-		return nil, ErrInvalidMessageContent
-	}
-	if len(content) > 4000 {
-		return nil, ErrMessageTooLong
+	if err := s.checkUserMessage(userID, content); err != nil {
+		return nil, err
 	}
 
 	// Get thread and verify access
@@ -277,6 +513,10 @@ func (s *chatService) SaveUserMessage(ctx context.Context, threadID, userID, con
 		return nil, err
 	}
 
+	if err := s.checkChatEnabled(ctx, thread.GraphID); err != nil {
+		return nil, err
+	}
+
 	// Create user message
 	userMsg := &models.ChatMessage{
 		ID:        uuid.New().String(),
@@ -293,7 +533,7 @@ func (s *chatService) SaveUserMessage(ctx context.Context, threadID, userID, con
 
 	// Update thread summary if this is the first message
 	if thread.Summary == nil {
-		thread.GenerateSummary(content)
+		thread.GenerateSummary(content, s.summaryLength)
 		thread.UpdatedAt = time.Now()
 		if err := s.chatRepo.UpdateThread(ctx, thread); err != nil {
 			// Log error but continue
@@ -304,14 +544,184 @@ func (s *chatService) SaveUserMessage(ctx context.Context, threadID, userID, con
 	return userMsg, nil
 }
 
-// GenerateResponseForMessage generates an AI response for a specific user message
+// checkUserMessage applies the rate limit and content validation shared by every way of sending a user message
+func (s *chatService) checkUserMessage(userID, content string) error {
+	// Check rate limit
+	if !s.rateLimiter.Allow(userID) {
+		return ErrRateLimitExceeded
+	}
+
+	// Validate message content
+	if strings.TrimSpace(content) == "" {
+		return ErrInvalidMessageContent
+	}
+	if len(content) > 4000 {
+		return ErrMessageTooLong
+	}
+
+	return nil
+}
+
+// ResolveChatGraphs returns the graphs a chat message should search: the thread's graph
+// followed by the additional graphs, without duplicates. The user must be a member of
+// every additional graph; membership of the thread's graph is checked with the thread.
+// Every graph must have chat enabled.
+func (s *chatService) ResolveChatGraphs(ctx context.Context, threadGraphID, userID string, additionalGraphIDs []string) ([]string, error) {
+	graphIDs := []string{threadGraphID}
+	seen := map[string]bool{threadGraphID: true}
+
+	for _, graphID := range additionalGraphIDs {
+		graphID = strings.TrimSpace(graphID)
+		if graphID == "" || seen[graphID] {
+			continue
+		}
+		seen[graphID] = true
+		graphIDs = append(graphIDs, graphID)
+	}
+
+	if len(graphIDs) > MaxChatGraphs {
+		return nil, ErrTooManyChatGraphs
+	}
+
+	for _, graphID := range graphIDs[1:] {
+		if _, err := s.getGraph(ctx, graphID); err != nil {
+			return nil, err
+		}
+
+		isMember, err := s.graphRepo.IsMember(ctx, graphID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify graph membership: %w", err)
+		}
+		if !isMember {
+			return nil, ErrNotGraphMember
+		}
+	}
+
+	// Owners who turned chat off don't want their graph's documents answering questions
+	for _, graphID := range graphIDs {
+		if err := s.checkChatEnabled(ctx, graphID); err != nil {
+			return nil, err
+		}
+	}
+
+	return graphIDs, nil
+}
+
+// ResolveExcludedDocuments validates the documents a chat message should leave out of its
+// answer and returns their IDs without duplicates. Every document must belong to one of
+// graphIDs, the graphs the message searches (see ResolveChatGraphs).
+func (s *chatService) ResolveExcludedDocuments(ctx context.Context, graphIDs, documentIDs []string) ([]string, error) {
+	searched := make(map[string]bool, len(graphIDs))
+	for _, graphID := range graphIDs {
+		searched[graphID] = true
+	}
+
+	var excluded []string
+	seen := make(map[string]bool)
+	for _, documentID := range documentIDs {
+		documentID = strings.TrimSpace(documentID)
+		if documentID == "" || seen[documentID] {
+			continue
+		}
+		seen[documentID] = true
+		excluded = append(excluded, documentID)
+	}
+
+	if len(excluded) > MaxExcludedChatDocuments {
+		return nil, ErrTooManyExcludedDocs
+	}
+
+	for _, documentID := range excluded {
+		if _, err := uuid.Parse(documentID); err != nil {
+			return nil, ErrInvalidExcludedDoc
+		}
+
+		doc, err := s.documentRepo.GetByID(ctx, documentID)
+		if err != nil {
+			if errors.Is(err, repository.ErrDocumentNotFound) {
+				return nil, ErrInvalidExcludedDoc
+			}
+			return nil, fmt.Errorf("failed to get excluded document: %w", err)
+		}
+		if doc.GraphID == nil || !searched[*doc.GraphID] {
+			return nil, ErrInvalidExcludedDoc
+		}
+	}
+
+	return excluded, nil
+}
+
+// checkChatEnabled returns ErrChatDisabled if the graph's owner has turned chat off, and
+// ErrBudgetExceeded if the graph has used its monthly chat budget
+func (s *chatService) checkChatEnabled(ctx context.Context, graphID string) error {
+	graph, err := s.getGraph(ctx, graphID)
+	if err != nil {
+		return err
+	}
+	if !graph.ChatEnabled {
+		return ErrChatDisabled
+	}
+	return s.checkBudget(ctx, graphID)
+}
+
+// getGraph retrieves a graph, translating the repository's not-found error to ErrGraphNotFound
+func (s *chatService) getGraph(ctx context.Context, graphID string) (*models.Graph, error) {
+	graph, err := s.graphRepo.GetByID(ctx, graphID)
+	if err != nil {
+		if errors.Is(err, repository.ErrGraphNotFound) {
+			return nil, ErrGraphNotFound
+		}
+		return nil, fmt.Errorf("failed to get graph: %w", err)
+	}
+	return graph, nil
+}
+
+// checkBudget returns ErrBudgetExceeded if the graph has used its monthly chat budget.
+// Budgets only apply to chat backends that meter usage.
+func (s *chatService) checkBudget(ctx context.Context, graphID string) error {
+	if !s.chatBackend.MetersUsage() || (s.monthlyTokenCap <= 0 && s.monthlyRequestCap <= 0) {
+		return nil
+	}
+
+	usage, err := s.GetGeminiUsage(ctx, graphID)
+	if err != nil {
+		return err
+	}
+	if usage.BudgetExceeded {
+		return ErrBudgetExceeded
+	}
+	return nil
+}
+
+// recordUsage adds a Gemini request's tokens to the graph's usage for today. It runs after
+// the response has streamed, so a failure is only logged.
+func (s *chatService) recordUsage(graphID string, usage TokenUsage) {
+	if !s.chatBackend.MetersUsage() {
+		return
+	}
+	if err := s.chatRepo.RecordGeminiUsage(context.Background(), graphID, time.Now(), usage.InputTokens, usage.OutputTokens); err != nil {
+		fmt.Printf("Warning: failed to record Gemini usage for graph %s: %v\n", graphID, err)
+	}
+}
+
+// GenerateResponseForMessage generates an AI response for a specific user message,
+// searching the documents of every graph in graphIDs (see ResolveChatGraphs) except
+// excludedDocumentIDs (see ResolveExcludedDocuments)
 func (s *chatService) GenerateResponseForMessage(
 	ctx context.Context,
 	threadID string,
 	userMessageID string,
-	graphID string,
+	graphIDs []string,
+	excludedDocumentIDs []string,
 	responseChan chan<- string,
 ) (string, error) {
+	if len(graphIDs) == 0 {
+		return "", fmt.Errorf("at least one graph ID is required")
+	}
+	if len(graphIDs) > MaxChatGraphs {
+		return "", ErrTooManyChatGraphs
+	}
+
 	// Get the user message
 	userMsg, err := s.chatRepo.GetMessageByID(ctx, userMessageID)
 	if err != nil {
@@ -328,10 +738,19 @@ func (s *chatService) GenerateResponseForMessage(
 		return "", fmt.Errorf("message is not from user")
 	}
 
-	// Get graph for metadata filtering
-	graph, err := s.graphRepo.GetByID(ctx, graphID)
-	if err != nil {
-		return "", fmt.Errorf("failed to get graph: %w", err)
+	graphs := make([]*models.Graph, len(graphIDs))
+	for i, graphID := range graphIDs {
+		graph, err := s.graphRepo.GetByID(ctx, graphID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get graph: %w", err)
+		}
+		graphs[i] = graph
+	}
+
+	// Usage is charged to the thread's graph, which ResolveChatGraphs puts first. Check its
+	// budget before the collector goroutine starts, since nothing would close its channel.
+	if err := s.checkBudget(ctx, graphIDs[0]); err != nil {
+		return "", err
 	}
 
 	// Create assistant message
@@ -364,9 +783,9 @@ func (s *chatService) GenerateResponseForMessage(
 		}
 	}()
 
-	// Call Gemini service for streaming response with metadata filtering
-	// Use empty storeID to let service use the shared store
-	geminiErr := s.geminiSvc.GenerateStreamingResponse(ctx, "", graph.ID, "topeic.com", "1.1", userMsg.Content, fullResponseChan)
+	// Generate the response from the documents of every graph searched
+	usage, genErr := s.chatBackend.GenerateStreamingResponse(ctx, graphs, excludedDocumentIDs, userMsg.Content, fullResponseChan)
+	s.recordUsage(graphIDs[0], usage)
 
 	// Close the channel to signal completion to the goroutine
 	close(fullResponseChan)
@@ -374,9 +793,9 @@ func (s *chatService) GenerateResponseForMessage(
 	// Wait for goroutine to finish forwarding all chunks
 	<-done
 
-	// Check for Gemini errors first
-	if geminiErr != nil {
-		return "", fmt.Errorf("failed to generate AI response: %w", geminiErr)
+	// Check for generation errors first
+	if genErr != nil {
+		return "", fmt.Errorf("failed to generate AI response: %w", genErr)
 	}
 
 	// Check for streaming errors
@@ -397,10 +816,127 @@ func (s *chatService) GenerateResponseForMessage(
 	return assistantMsg.ID, nil
 }
 
-// sanitizeContent sanitizes message content by escaping HTML
-func sanitizeContent(content string) string {
-	// Escape HTML to prevent XSS
-	return html.EscapeString(content)
+// SubmitFeedback records a user's rating of an assistant message.
+// Only members of the message's graph may rate it; rating the same message again replaces the earlier rating.
+func (s *chatService) SubmitFeedback(ctx context.Context, messageID, userID, rating string, comment *string) (*models.MessageFeedback, error) {
+	if rating != models.FeedbackRatingUp && rating != models.FeedbackRatingDown {
+		return nil, ErrInvalidFeedbackRating
+	}
+
+	// Treat a blank comment as no comment
+	if comment != nil {
+		trimmed := strings.TrimSpace(*comment)
+		if trimmed == "" {
+			comment = nil
+		} else if utf8.RuneCountInString(trimmed) > MaxFeedbackCommentLength {
+			return nil, ErrFeedbackCommentLong
+		} else {
+			comment = &trimmed
+		}
+	}
+
+	message, err := s.chatRepo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, ErrChatMessageNotFound
+	}
+
+	// Verify user has access to the message's graph
+	if _, err := s.GetThread(ctx, message.ThreadID, userID); err != nil {
+		return nil, err
+	}
+
+	if message.Role != "assistant" {
+		return nil, ErrMessageNotRatable
+	}
+
+	now := time.Now()
+	feedback := &models.MessageFeedback{
+		ID:        uuid.New().String(),
+		MessageID: messageID,
+		UserID:    userID,
+		Rating:    rating,
+		Comment:   comment,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.chatRepo.UpsertFeedback(ctx, feedback); err != nil {
+		return nil, fmt.Errorf("failed to save feedback: %w", err)
+	}
+
+	return feedback, nil
+}
+
+// GetFeedbackSummary aggregates the feedback on a graph's assistant messages (creator only)
+func (s *chatService) GetFeedbackSummary(ctx context.Context, graphID, userID string) (*models.FeedbackSummary, error) {
+	graph, err := s.getGraph(ctx, graphID)
+	if err != nil {
+		return nil, err
+	}
+
+	if graph.CreatorID != userID {
+		return nil, ErrNotGraphCreator
+	}
+
+	summary, err := s.chatRepo.GetFeedbackSummaryByGraphID(ctx, graphID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback summary: %w", err)
+	}
+
+	return summary, nil
+}
+
+// GetGeminiUsage returns a graph's Gemini usage for the current calendar month (UTC) and
+// the rest of its monthly budget.
+// Callers are responsible for checking that the user may see the graph.
+func (s *chatService) GetGeminiUsage(ctx context.Context, graphID string) (*models.GeminiUsage, error) {
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	days, err := s.chatRepo.ListGeminiUsage(ctx, graphID, monthStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Gemini usage: %w", err)
+	}
+
+	usage := &models.GeminiUsage{
+		Month:             monthStart.Format("2006-01"),
+		MonthlyTokenCap:   s.monthlyTokenCap,
+		MonthlyRequestCap: s.monthlyRequestCap,
+		ResetsAt:          monthStart.AddDate(0, 1, 0),
+		Days:              days,
+	}
+	for _, day := range days {
+		usage.RequestCount += day.RequestCount
+		usage.InputTokens += day.InputTokens
+		usage.OutputTokens += day.OutputTokens
+	}
+
+	if s.monthlyTokenCap > 0 {
+		remaining := max(s.monthlyTokenCap-usage.InputTokens-usage.OutputTokens, 0)
+		usage.RemainingTokens = &remaining
+		usage.BudgetExceeded = remaining == 0
+	}
+	if s.monthlyRequestCap > 0 {
+		remaining := max(s.monthlyRequestCap-usage.RequestCount, 0)
+		usage.RemainingRequests = &remaining
+		usage.BudgetExceeded = usage.BudgetExceeded || remaining == 0
+	}
+
+	return usage, nil
+}
+
+// sanitizeContent prepares message content for storage. By default the content is kept as
+// written, so code and angle brackets round-trip unchanged and escaping happens when it is
+// rendered; only what Postgres can't store in a text column (invalid UTF-8 and NUL) is replaced.
+// With the escape policy the content is also HTML-escaped, for clients that render it as HTML.
+func (s *chatService) sanitizeContent(content string) string {
+	content = strings.ToValidUTF8(content, "\uFFFD")
+	content = strings.ReplaceAll(content, "\x00", "")
+
+	if s.escapeContent {
+		return html.EscapeString(content)
+	}
+	return content
 }
 
 // rateLimiter implements a simple in-memory rate limiter
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"html"
+	"testing"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/config"
+)
+
+func TestSanitizeContentRoundTrip(t *testing.T) {
+	inputs := []string{
+		"if a < b && b > c { return }",
+		"<script>alert('hi')</script>",
+		"Tom & Jerry",
+		"5 > 3 & 2 < 4",
+		"&lt; is already an entity",
+		"```go\nfunc f() []int { return nil }\n```",
+		"plain text",
+	}
+
+	tests := []struct {
+		policy string
+		// read turns stored content back into what the user wrote, as a client using the policy would
+		read func(stored string) string
+	}{
+		{policy: config.ChatSanitizationNone, read: func(stored string) string { return stored }},
+		{policy: config.ChatSanitizationEscape, read: html.UnescapeString},
+	}
+
+	for _, tt := range tests {
+		s := &chatService{escapeContent: tt.policy == config.ChatSanitizationEscape}
+		for _, input := range inputs {
+			t.Run(tt.policy+"/"+input, func(t *testing.T) {
+				stored := s.sanitizeContent(input)
+				if got := tt.read(stored); got != input {
+					t.Errorf("content did not round-trip: stored %q, read back %q, want %q", stored, got, input)
+				}
+			})
+		}
+	}
+}
+
+func TestSanitizeContentNoneStoresInputUnchanged(t *testing.T) {
+	s := &chatService{}
+	for _, input := range []string{"<", ">", "&", "a < b > c & d"} {
+		if got := s.sanitizeContent(input); got != input {
+			t.Errorf("sanitizeContent(%q) = %q, want the input unchanged", input, got)
+		}
+	}
+}
+
+func TestSanitizeContentEscapeEscapesHTML(t *testing.T) {
+	s := &chatService{escapeContent: true}
+	if got, want := s.sanitizeContent("<b>&</b>"), "&lt;b&gt;&amp;&lt;/b&gt;"; got != want {
+		t.Errorf("sanitizeContent() = %q, want %q", got, want)
+	}
+}
+
+func TestSanitizeContentRemovesWhatPostgresCannotStore(t *testing.T) {
+	s := &chatService{}
+	if got, want := s.sanitizeContent("a\x00b\xffc"), "ab\uFFFDc"; got != want {
+		t.Errorf("sanitizeContent() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/extraction"
+	"github.com/bipulkrdas/orgmind/backend/internal/models"
+)
+
+// retryBatchSize is the maximum number of failed documents retried per pass, so a backlog
+// of failures is worked through gradually instead of saturating extraction and Zep
+const retryBatchSize = 10
+
+// RunDocumentRetries retries failed documents whose retry is due every interval, until ctx
+// is cancelled
+func RunDocumentRetries(ctx context.Context, documentService DocumentService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			retried, err := documentService.RetryFailedDocuments(ctx)
+			if err != nil {
+				fmt.Printf("Warning: failed to retry failed documents: %v\n", err)
+			}
+			if retried > 0 {
+				fmt.Printf("Retried processing of %d failed document(s)\n", retried)
+			}
+		}
+	}
+}
+
+// RetryFailedDocuments reprocesses failed documents whose scheduled retry is due and
+// returns how many were retried. Documents are only scheduled for retry after transient
+// failures (extraction timeouts, Zep errors), so unsupported or corrupted files are never
+// retried. Each document is claimed first, so concurrent servers don't retry it twice, and
+// documents are reprocessed through the bulk pool shared with graph reindexes.
+func (s *documentService) RetryFailedDocuments(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	docs, err := s.documentRepo.ListDueForRetry(ctx, now, retryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var retried atomic.Int64
+	s.bulkPool.Run(ctx, len(docs), func(i int) {
+		doc := docs[i]
+		claimed, err := s.documentRepo.ClaimForRetry(ctx, doc.ID, now)
+		if err != nil {
+			fmt.Printf("Warning: failed to claim document %s for retry: %v\n", doc.ID, err)
+			return
+		}
+		if !claimed {
+			return
+		}
+
+		doc.Status = "processing"
+		doc.ErrorMessage = nil
+		if err := s.reprocessFromStorage(ctx, doc); err != nil {
+			fmt.Printf("Error retrying document %s: %v\n", doc.ID, err)
+		}
+		retried.Add(1)
+	})
+
+	return int(retried.Load()), nil
+}
+
+// reprocessFromStorage reprocesses a document marked as processing from its stored content,
+// for a retry or a graph reindex. Any data an earlier attempt added to Zep is replaced, and
+// a new failure is scheduled for retry again while attempts remain.
+func (s *documentService) reprocessFromStorage(ctx context.Context, doc *models.Document) error {
+	if doc.GraphID == nil {
+		return fmt.Errorf("document is not associated with a graph")
+	}
+
+	gr, err := s.graphRepo.GetByID(ctx, *doc.GraphID)
+	if err != nil {
+		s.failRetry(ctx, doc, "The document could not be processed.", true)
+		return fmt.Errorf("failed to get graph: %w", err)
+	}
+
+	reader, err := s.storageService.Download(ctx, doc.StorageKey)
+	if err != nil {
+		// Storage may be briefly unavailable too, so count the attempt and retry later
+		s.failRetry(ctx, doc, "The document could not be read from storage.", true)
+		return fmt.Errorf("failed to download content from storage: %w", err)
+	}
+	defer reader.Close()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(reader); err != nil {
+		s.failRetry(ctx, doc, "The document could not be read from storage.", true)
+		return fmt.Errorf("failed to read content: %w", err)
+	}
+
+	filename := ""
+	if doc.Filename != nil {
+		filename = *doc.Filename
+	}
+
+	var textContent, zepContent, zepContentType string
+	if doc.Source == "editor" {
+		textContent = editorStoredPlainText(buf.Bytes())
+		zepContent, zepContentType = textContent, "text/plain"
+	} else {
+		contentType := ""
+		if doc.ContentType != nil {
+			contentType = *doc.ContentType
+		}
+
+		extracted, err := s.extractionService.ExtractWithMetadata(ctx, buf.Bytes(), contentType)
+		if err != nil {
+			s.failRetry(ctx, doc, extraction.GetUserFriendlyMessage(err), extraction.IsRetryable(err))
+			return fmt.Errorf("failed to extract text: %w", err)
+		}
+
+		textContent = extracted.Text
+		zepContent, zepContentType = s.prepareUploadContent(ctx, doc, buf.Bytes(), extracted)
+
+		if strings.TrimSpace(textContent) == "" {
+			return s.markEmpty(ctx, doc)
+		}
+	}
+
+	// Documents whose first attempt failed before reaching File Search were never uploaded there
+	if doc.GeminiFileID == nil {
+		s.uploadToFileSearch(ctx, doc, textContent, "text/plain")
+	}
+
+	return s.processingService.ReprocessDocument(ctx, doc.UserID, gr.ZepGraphID, doc.ID, filename, zepContent, zepContentType)
+}
+
+// failRetry marks a retried document as failed again with a message, counting the attempt
+// and scheduling another retry if the failure was transient
+func (s *documentService) failRetry(ctx context.Context, doc *models.Document, message string, retryable bool) {
+	doc.Status = "failed"
+	doc.ErrorMessage = &message
+	doc.UpdatedAt = time.Now().UTC()
+	if err := s.documentRepo.Update(ctx, doc); err != nil {
+		fmt.Printf("Warning: failed to update status of document %s: %v\n", doc.ID, err)
+	}
+
+	if err := s.processingService.RecordFailedAttempt(ctx, doc.ID, retryable); err != nil {
+		fmt.Printf("Warning: failed to record failed attempt for document %s: %v\n", doc.ID, err)
+	}
+}
+
+// editorStoredPlainText returns the plain text of stored editor content: the plainText field
+// of the JSON written by the editor, or the whole content for legacy plain-text documents
+func editorStoredPlainText(data []byte) string {
+	var stored struct {
+		PlainText string `json:"plainText"`
+	}
+	if err := json.Unmarshal(data, &stored); err == nil && stored.PlainText != "" {
+		return stored.PlainText
+	}
+	return string(data)
+}
@@ -3,9 +3,19 @@ package service
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/bipulkrdas/orgmind/backend/internal/extraction"
 	"github.com/bipulkrdas/orgmind/backend/internal/models"
@@ -15,9 +25,52 @@ import (
 )
 
 const (
-	MaxFileSize = 50 * 1024 * 1024 // 50MB in bytes
+	MaxFilenameLength = 255 // Maximum length of a stored filename in bytes
+
+	MaxTagsPerDocument = 20 // Maximum number of tags on a single document
+	MaxTagLength       = 50 // Maximum length of a single tag in characters
+
+	MaxBatchStatusDocuments = 100 // Maximum number of documents in a single status request
+
+	ContentPreviewLength = 200 // Maximum length of a document's content preview in characters
+
+	MaxSearchQueryLength = 200 // Maximum length of a search query in characters
+)
+
+// Custom errors for document tag, listing, editor content and status operations
+var (
+	ErrInvalidTag  = fmt.Errorf("tags may only contain letters, numbers, spaces, hyphens and underscores")
+	ErrTagTooLong  = fmt.Errorf("tag exceeds maximum length of %d characters", MaxTagLength)
+	ErrTooManyTags = fmt.Errorf("document cannot have more than %d tags", MaxTagsPerDocument)
+
+	ErrInvalidDocumentScope = fmt.Errorf("scope must be one of: %s, %s", models.DocumentScopeAccessible, models.DocumentScopeAuthored)
+	ErrInvalidGraphFilter   = fmt.Errorf("graphId must be a valid graph ID")
+
+	ErrInvalidContentFormat = fmt.Errorf("content format must be one of: %s, %s, %s, %s",
+		models.ContentFormatLexical, models.ContentFormatMarkdown, models.ContentFormatHTML, models.ContentFormatText)
+	ErrLexicalStateRequired = fmt.Errorf("lexical state is required for the %s content format", models.ContentFormatLexical)
+
+	ErrInvalidBatchStatusRequest = fmt.Errorf("between 1 and %d document IDs are required", MaxBatchStatusDocuments)
+	ErrInvalidDocumentID         = fmt.Errorf("invalid document ID")
+	ErrDocumentsNotAccessible    = fmt.Errorf("documents not found")
+
+	ErrDocumentNotFound   = fmt.Errorf("document not found")
+	ErrDocumentNotInGraph = fmt.Errorf("document not found in this graph")
+
+	ErrContentTypeNotAllowed = fmt.Errorf("file type is not allowed in this graph")
+	ErrFileTooLarge          = fmt.Errorf("file size exceeds maximum allowed size")
+	ErrEmptyUpload           = fmt.Errorf("file cannot be empty")
+	ErrUnsupportedFileType   = fmt.Errorf("unsupported file type")
+
+	ErrInvalidSearchQuery = fmt.Errorf("search query must be between 1 and %d characters", MaxSearchQueryLength)
 )
 
+// emptyExtractionMessage explains the "empty" status of an uploaded file with no extractable text
+const emptyExtractionMessage = "No text could be extracted from this file, so nothing was added to the knowledge graph. Scanned or image-only files are not supported."
+
+// tagPattern matches valid tags: alphanumeric start followed by letters, numbers, spaces, hyphens or underscores
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N} _-]*$`)
+
 // documentService implements DocumentService interface
 type documentService struct {
 	documentRepo      repository.DocumentRepository
@@ -27,6 +80,16 @@ type documentService struct {
 	graphService      GraphService
 	extractionService extraction.ExtractionService
 	geminiService     GeminiService
+
+	// Resumable uploads, which expire uploadSessionTTL after they are started
+	uploadSessionRepo repository.UploadSessionRepository
+	uploadSessionTTL  time.Duration
+
+	// Shared limit on documents reprocessed at once by reindexes and retry passes
+	bulkPool *BulkPool
+
+	// Largest file accepted, in bytes, from MAX_UPLOAD_SIZE_MB
+	maxFileSize int64
 }
 
 // NewDocumentService creates a new instance of DocumentService
@@ -38,6 +101,10 @@ func NewDocumentService(
 	graphService GraphService,
 	extractionService extraction.ExtractionService,
 	geminiService GeminiService,
+	uploadSessionRepo repository.UploadSessionRepository,
+	uploadSessionTTL time.Duration,
+	bulkPool *BulkPool,
+	maxFileSize int64,
 ) DocumentService {
 	return &documentService{
 		documentRepo:      documentRepo,
@@ -47,17 +114,31 @@ func NewDocumentService(
 		graphService:      graphService,
 		extractionService: extractionService,
 		geminiService:     geminiService,
+
+		uploadSessionRepo: uploadSessionRepo,
+		uploadSessionTTL:  uploadSessionTTL,
+
+		bulkPool: bulkPool,
+
+		maxFileSize: maxFileSize,
 	}
 }
 
-// CreateFromEditor handles text content from the editor with Lexical state
-func (s *documentService) CreateFromEditor(ctx context.Context, userID, graphID, plainText, lexicalState string) (*models.Document, error) {
+// CreateFromEditor handles content from the editor. With the Lexical format (the default) the
+// content is plain text accompanied by the Lexical state; otherwise it is the document itself
+// in the given format.
+func (s *documentService) CreateFromEditor(ctx context.Context, userID, graphID, content, lexicalState, contentFormat string) (*models.Document, error) {
+	contentFormat, err := resolveContentFormat(contentFormat, models.ContentFormatLexical)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate content
-	if plainText == "" {
+	if content == "" {
 		return nil, fmt.Errorf("content cannot be empty")
 	}
-	if lexicalState == "" {
-		return nil, fmt.Errorf("lexical state cannot be empty")
+	if contentFormat == models.ContentFormatLexical && lexicalState == "" {
+		return nil, ErrLexicalStateRequired
 	}
 
 	// Verify graph membership before creating document
@@ -69,15 +150,24 @@ func (s *documentService) CreateFromEditor(ctx context.Context, userID, graphID,
 	// Generate unique document ID
 	documentID := uuid.New().String()
 
-	// Create combined JSON structure for storage
+	// Markdown and HTML are reduced to plain text for Zep and File Search
+	plainText := s.editorPlainText(ctx, content, contentFormat)
+
+	// Create combined JSON structure for storage; Markdown and HTML are also kept as written
 	combinedContent := map[string]interface{}{
-		"plainText":    plainText,
-		"lexicalState": lexicalState,
+		"plainText":     plainText,
+		"contentFormat": contentFormat,
 		"metadata": map[string]interface{}{
 			"version":   "1.0",
 			"createdAt": time.Now().UTC().Format(time.RFC3339),
 		},
 	}
+	if lexicalState != "" {
+		combinedContent["lexicalState"] = lexicalState
+	}
+	if plainText != content {
+		combinedContent["content"] = content
+	}
 
 	// Marshal to JSON
 	jsonBytes, err := json.Marshal(combinedContent)
@@ -91,23 +181,28 @@ func (s *documentService) CreateFromEditor(ctx context.Context, userID, graphID,
 	sizeBytes := int64(len(jsonBytes))
 
 	doc := &models.Document{
-		ID:          documentID,
-		UserID:      userID,
-		GraphID:     &graphID,
-		Filename:    nil, // No filename for editor content
-		ContentType: &contentType,
-		StorageKey:  "", // Will be set after upload
-		SizeBytes:   sizeBytes,
-		Source:      "editor",
-		Status:      "processing",
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:            documentID,
+		UserID:        userID,
+		GraphID:       &graphID,
+		Filename:      nil, // No filename for editor content
+		ContentType:   &contentType,
+		StorageKey:    "", // Will be set after upload
+		SizeBytes:     sizeBytes,
+		Source:        "editor",
+		ContentFormat: &contentFormat,
+		Status:        "processing",
+		CreatedAt:     now,
+		UpdatedAt:     now,
+
+		ContentPreview: contentPreview(plainText),
+		ContentHash:    contentHash(jsonBytes),
 	}
 
 	// Upload combined JSON to S3
 	storageKey, err := s.storageService.Upload(
 		ctx,
 		userID,
+		graphID,
 		documentID,
 		"editor-content.json",
 		bytes.NewReader(jsonBytes),
@@ -137,7 +232,7 @@ func (s *documentService) CreateFromEditor(ctx context.Context, userID, graphID,
 	go func() {
 		// Use a new context for background processing
 		bgCtx := context.Background()
-		if err := s.processingService.ProcessDocument(bgCtx, userID, gr.ZepGraphID, documentID, plainText); err != nil {
+		if err := s.processingService.ProcessDocument(bgCtx, userID, gr.ZepGraphID, documentID, "", plainText, "text/plain"); err != nil {
 			// Log error (in production, use proper logging)
 			fmt.Printf("Error processing document %s: %v\n", documentID, err)
 		}
@@ -147,40 +242,152 @@ func (s *documentService) CreateFromEditor(ctx context.Context, userID, graphID,
 	go func() {
 		bgCtx := context.Background()
 		// Use plain text content with text/plain MIME type for File Search
-		s.uploadToFileSearch(bgCtx, graphID, documentID, plainText, "text/plain")
+		s.uploadToFileSearch(bgCtx, doc, plainText, "text/plain")
 	}()
 
 	return doc, nil
 }
 
-// CreateFromFile handles multipart file uploads
-func (s *documentService) CreateFromFile(ctx context.Context, userID, graphID string, file []byte, filename, contentType string) (*models.Document, error) {
+// resolveContentFormat validates an editor content format, using fallback when none is given
+func resolveContentFormat(contentFormat, fallback string) (string, error) {
+	switch contentFormat {
+	case "":
+		return fallback, nil
+	case models.ContentFormatLexical, models.ContentFormatMarkdown, models.ContentFormatHTML, models.ContentFormatText:
+		return contentFormat, nil
+	default:
+		return "", ErrInvalidContentFormat
+	}
+}
+
+// editorPlainText returns the text to ingest for editor content. Lexical and plain text
+// content is already plain text; Markdown and HTML go through the matching extractor so
+// markup doesn't end up in the knowledge graph. If extraction fails the content is used as is.
+func (s *documentService) editorPlainText(ctx context.Context, content, contentFormat string) string {
+	var mimeType string
+	switch contentFormat {
+	case models.ContentFormatMarkdown:
+		mimeType = "text/markdown"
+	case models.ContentFormatHTML:
+		mimeType = "text/html"
+	default:
+		return content
+	}
+
+	text, err := s.extractionService.Extract(ctx, []byte(content), mimeType)
+	if err != nil || strings.TrimSpace(text) == "" {
+		fmt.Printf("Warning: failed to extract text from %s editor content, ingesting it as is: %v\n", contentFormat, err)
+		return content
+	}
+	return text
+}
+
+// readUpload rewinds an uploaded file and reads it into a buffer sized for it, reading at most
+// maxSize bytes
+func readUpload(file io.ReadSeeker, size, maxSize int64) ([]byte, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	if _, err := buf.ReadFrom(io.LimitReader(file, maxSize)); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// CreateFromFile handles multipart file uploads.
+// The file is streamed to storage and only read into memory afterwards for text
+// extraction, so uploads waiting on storage don't each hold a full in-memory copy.
+func (s *documentService) CreateFromFile(ctx context.Context, userID, graphID string, file io.ReadSeeker, size int64, filename, contentType string) (*models.Document, error) {
+	filename, contentType, gr, err := s.validateUpload(ctx, userID, graphID, size, filename, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate unique document ID
+	documentID := uuid.New().String()
+
+	// Upload file to S3
+	storageKey, err := s.storageService.Upload(
+		ctx,
+		userID,
+		graphID,
+		documentID,
+		filename,
+		file,
+		contentType,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file to storage: %w", err)
+	}
+
+	// Read the content back for extraction, which needs the whole file
+	fileBytes, err := readUpload(file, size, s.maxFileSize)
+	if err != nil {
+		_ = s.storageService.Delete(ctx, storageKey)
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+
+	doc, err := s.createUploadedDocument(ctx, userID, gr, documentID, filename, contentType, storageKey, fileBytes)
+	if err != nil {
+		// The client resends the whole file on retry, so the stored copy isn't needed
+		if errors.Is(err, extraction.ErrExtractionBusy) {
+			_ = s.storageService.Delete(ctx, storageKey)
+		}
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// validateUpload checks an upload's size and type and that the user may upload it to the
+// graph, returning the sanitized filename, the canonical content type and the graph
+func (s *documentService) validateUpload(ctx context.Context, userID, graphID string, size int64, filename, contentType string) (string, string, *models.Graph, error) {
 	// Validate file size
-	if len(file) > MaxFileSize {
-		return nil, fmt.Errorf("file size exceeds maximum allowed size of 50MB")
+	if size > s.maxFileSize {
+		return "", "", nil, fmt.Errorf("%w of %dMB", ErrFileTooLarge, s.maxFileSize/(1024*1024))
 	}
 
-	if len(file) == 0 {
-		return nil, fmt.Errorf("file cannot be empty")
+	if size <= 0 {
+		return "", "", nil, ErrEmptyUpload
 	}
 
+	// Strip path components and control characters from the client-supplied name
+	filename = sanitizeFilename(filename)
+
+	// Clients label some formats with nonstandard types (application/x-pdf); use the standard one
+	contentType = s.extractionService.CanonicalContentType(contentType)
+
 	// Validate file type (whitelist of supported types)
 	if !s.isValidFileType(contentType) {
-		return nil, fmt.Errorf("unsupported file type: %s. Supported formats: %v", contentType, s.extractionService.SupportedFormats())
+		return "", "", nil, fmt.Errorf("%w: %s. Supported formats: %v", ErrUnsupportedFileType, contentType, s.extractionService.SupportedFormats())
 	}
 
 	// Verify graph membership before creating document
 	gr, err := s.graphService.GetByID(ctx, graphID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to verify graph membership: %w", err)
+		return "", "", nil, fmt.Errorf("failed to verify graph membership: %w", err)
 	}
 
-	// Generate unique document ID
-	documentID := uuid.New().String()
+	// The graph's owner may restrict uploads to some of the supported formats
+	if len(gr.AllowedContentTypes) > 0 && !slices.Contains(gr.AllowedContentTypes, contentType) {
+		return "", "", nil, fmt.Errorf("%w: %s. Allowed types: %s", ErrContentTypeNotAllowed, contentType, strings.Join(gr.AllowedContentTypes, ", "))
+	}
+
+	return filename, contentType, gr, nil
+}
+
+// createUploadedDocument creates the document for a file already stored under storageKey,
+// extracts its text and starts processing it. The stored file is removed if the document
+// can't be created, except when extraction is busy: the document is then undone but the
+// file kept, and the caller decides whether to keep it for a retry.
+func (s *documentService) createUploadedDocument(ctx context.Context, userID string, gr *models.Graph, documentID, filename, contentType, storageKey string, fileBytes []byte) (*models.Document, error) {
+	graphID := gr.ID
 
 	// Create document metadata
 	now := time.Now().UTC()
-	sizeBytes := int64(len(file))
 
 	doc := &models.Document{
 		ID:          documentID,
@@ -188,31 +395,17 @@ func (s *documentService) CreateFromFile(ctx context.Context, userID, graphID st
 		GraphID:     &graphID,
 		Filename:    &filename,
 		ContentType: &contentType,
-		StorageKey:  "", // Will be set after upload
-		SizeBytes:   sizeBytes,
+		StorageKey:  storageKey,
+		SizeBytes:   int64(len(fileBytes)),
 		Source:      "upload",
 		Status:      "processing",
+		ContentHash: contentHash(fileBytes),
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
 
-	// Upload file to S3
-	storageKey, err := s.storageService.Upload(
-		ctx,
-		userID,
-		documentID,
-		filename,
-		bytes.NewReader(file),
-		contentType,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to upload file to storage: %w", err)
-	}
-
-	doc.StorageKey = storageKey
-
 	// Store document metadata in database
-	err = s.documentRepo.Create(ctx, doc)
+	err := s.documentRepo.Create(ctx, doc)
 	if err != nil {
 		// Attempt to clean up uploaded file
 		_ = s.storageService.Delete(ctx, storageKey)
@@ -225,9 +418,18 @@ func (s *documentService) CreateFromFile(ctx context.Context, userID, graphID st
 		fmt.Printf("Warning: failed to increment document count for graph %s: %v\n", graphID, err)
 	}
 
-	// Extract text content from file using extraction service
-	textContent, err := s.extractionService.Extract(ctx, file, contentType)
+	// Extract text content and structured metadata from file using extraction service
+	extracted, err := s.extractionService.ExtractWithMetadata(ctx, fileBytes, contentType)
 	if err != nil {
+		// The extraction queue is saturated: undo the document so the client can simply retry
+		if errors.Is(err, extraction.ErrExtractionBusy) {
+			_ = s.documentRepo.Delete(ctx, documentID)
+			if decErr := s.graphService.DecrementDocumentCount(ctx, graphID); decErr != nil {
+				fmt.Printf("Warning: failed to decrement document count for graph %s: %v\n", graphID, decErr)
+			}
+			return nil, err
+		}
+
 		// Get user-friendly error message
 		userMessage := extraction.GetUserFriendlyMessage(err)
 
@@ -237,15 +439,32 @@ func (s *documentService) CreateFromFile(ctx context.Context, userID, graphID st
 		doc.UpdatedAt = time.Now().UTC()
 		_ = s.documentRepo.Update(ctx, doc)
 
-		// Return user-friendly error
-		return nil, fmt.Errorf("%s", userMessage)
+		// Transient failures such as timeouts are retried automatically
+		if attemptErr := s.processingService.RecordFailedAttempt(ctx, documentID, extraction.IsRetryable(err)); attemptErr != nil {
+			fmt.Printf("Warning: failed to record failed extraction for document %s: %v\n", documentID, attemptErr)
+		}
+
+		// Keep the extraction error in the chain so callers can tell why extraction failed
+		return nil, fmt.Errorf("failed to extract text: %w", err)
+	}
+
+	textContent := extracted.Text
+	zepContent, zepContentType := s.prepareUploadContent(ctx, doc, fileBytes, extracted)
+
+	// A file with no extractable text (a blank PDF, slides with only images) would be marked
+	// completed without adding anything to the graph, so flag it as empty and skip processing
+	if strings.TrimSpace(textContent) == "" {
+		if err := s.markEmpty(ctx, doc); err != nil {
+			return nil, err
+		}
+		return doc, nil
 	}
 
 	// Process document asynchronously (in production, this would be a background job)
 	go func() {
 		// Use a new context for background processing
 		bgCtx := context.Background()
-		if err := s.processingService.ProcessDocument(bgCtx, userID, gr.ZepGraphID, documentID, textContent); err != nil {
+		if err := s.processingService.ProcessDocument(bgCtx, userID, gr.ZepGraphID, documentID, filename, zepContent, zepContentType); err != nil {
 			// Log error (in production, use proper logging)
 			fmt.Printf("Error processing document %s: %v\n", documentID, err)
 		}
@@ -255,56 +474,226 @@ func (s *documentService) CreateFromFile(ctx context.Context, userID, graphID st
 	go func() {
 		bgCtx := context.Background()
 		// Use extracted text content with text/plain MIME type for File Search
-		s.uploadToFileSearch(bgCtx, graphID, documentID, textContent, "text/plain")
+		s.uploadToFileSearch(bgCtx, doc, textContent, "text/plain")
 	}()
 
 	return doc, nil
 }
 
-// GetDocument retrieves a document by ID, ensuring the user owns it
+// prepareUploadContent builds the content sent to Zep for an uploaded file and stores the
+// file's content preview, embedded metadata (title, author, page count) and the version of
+// the extractor that read it on the document
+func (s *documentService) prepareUploadContent(ctx context.Context, doc *models.Document, fileBytes []byte, extracted extraction.ExtractionResult) (string, string) {
+	zepContent := extracted.Text
+	zepContentType := "text/plain"
+
+	doc.ContentPreview = contentPreview(extracted.Text)
+	doc.ExtractorVersion = s.extractionService.Version()
+
+	// Store embedded document metadata for formats that carry it
+	if len(extracted.Metadata) > 0 {
+		if metadataJSON, err := json.Marshal(extracted.Metadata); err == nil {
+			doc.Metadata = metadataJSON
+		}
+
+		// Prepend the metadata so Zep can link the document to its title and author entities
+		zepContent = metadataPreamble(extracted.Metadata) + extracted.Text
+	}
+
+	// Send JSON documents to Zep as-is so it can extract entities from their structure. JSON
+	// can't be cut short, so files over the extracted text cap are sent as their truncated
+	// text instead, like other formats.
+	maxBytes := s.extractionService.MaxTextBytes()
+	if doc.ContentType != nil && isJSONContentType(*doc.ContentType) && (maxBytes == 0 || len(fileBytes) <= maxBytes) {
+		zepContent = string(fileBytes)
+		zepContentType = *doc.ContentType
+	}
+
+	doc.UpdatedAt = time.Now().UTC()
+	if err := s.documentRepo.Update(ctx, doc); err != nil {
+		// The preview and metadata are informational only, so log and continue
+		fmt.Printf("Warning: failed to save preview and metadata for document %s: %v\n", doc.ID, err)
+	}
+
+	return zepContent, zepContentType
+}
+
+// markEmpty flags a document whose file has no extractable text
+func (s *documentService) markEmpty(ctx context.Context, doc *models.Document) error {
+	message := emptyExtractionMessage
+	doc.Status = "empty"
+	doc.ErrorMessage = &message
+	doc.UpdatedAt = time.Now().UTC()
+	if err := s.documentRepo.Update(ctx, doc); err != nil {
+		return fmt.Errorf("failed to update document status: %w", err)
+	}
+	return nil
+}
+
+// GetDocument retrieves a document by ID, ensuring the user is a member of its graph.
+// Any member may read a document, not just its author.
 func (s *documentService) GetDocument(ctx context.Context, documentID, userID string) (*models.Document, error) {
-	doc, err := s.documentRepo.GetByID(ctx, documentID)
+	return s.getDocumentForMember(ctx, documentID, userID)
+}
+
+// ListUserDocuments retrieves the documents a user can access through graph membership,
+// or only those they created with the authored scope, matching the filter. Each document
+// carries its graph's name; filtering by a graph the user isn't a member of lists nothing.
+func (s *documentService) ListUserDocuments(ctx context.Context, userID string, filter models.DocumentFilter) ([]*models.Document, int, error) {
+	filter, err := validateDocumentFilter(filter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get document: %w", err)
+		return nil, 0, err
 	}
 
-	// Verify ownership
-	if doc.UserID != userID {
-		return nil, fmt.Errorf("access denied: document does not belong to user")
+	if filter.GraphID != "" {
+		if _, err := uuid.Parse(filter.GraphID); err != nil {
+			return nil, 0, ErrInvalidGraphFilter
+		}
 	}
 
-	return doc, nil
+	switch filter.Scope {
+	case "":
+		filter.Scope = models.DocumentScopeAccessible
+	case models.DocumentScopeAccessible, models.DocumentScopeAuthored:
+	default:
+		return nil, 0, ErrInvalidDocumentScope
+	}
+
+	docs, err := s.documentRepo.ListByUserID(ctx, userID, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list user documents: %w", err)
+	}
+
+	total, err := s.documentRepo.CountByUserID(ctx, userID, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count user documents: %w", err)
+	}
+
+	return docs, total, nil
+}
+
+// ListGraphDocuments retrieves the filter's page of a graph's documents, and the total matching it
+func (s *documentService) ListGraphDocuments(ctx context.Context, graphID string, filter models.DocumentFilter) ([]*models.Document, int, error) {
+	filter, err := validateDocumentFilter(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	docs, err := s.documentRepo.ListByGraphID(ctx, graphID, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list graph documents: %w", err)
+	}
+
+	total, err := s.documentRepo.CountByGraphID(ctx, graphID, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count graph documents: %w", err)
+	}
+
+	return docs, total, nil
+}
+
+// validateDocumentFilter normalizes the tag and validates the ordering of a document listing
+func validateDocumentFilter(filter models.DocumentFilter) (models.DocumentFilter, error) {
+	if filter.Tag != "" {
+		tag, err := normalizeTag(filter.Tag)
+		if err != nil {
+			return filter, err
+		}
+		filter.Tag = tag
+	}
+
+	if err := validateListSort(filter.Sort, models.SortByName, models.SortByCreated, models.SortByUpdated); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// ValidateSearchQuery trims a search query and checks its length
+func ValidateSearchQuery(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || utf8.RuneCountInString(query) > MaxSearchQueryLength {
+		return "", ErrInvalidSearchQuery
+	}
+	return query, nil
+}
+
+// SearchGraphDocuments finds a page of a graph's documents matching query by filename, title,
+// content preview or tag, and counts every match
+func (s *documentService) SearchGraphDocuments(ctx context.Context, graphID, query string, limit, offset int) ([]*models.Document, int, error) {
+	query, err := ValidateSearchQuery(query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	docs, err := s.documentRepo.SearchByGraphID(ctx, graphID, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search documents: %w", err)
+	}
+
+	total, err := s.documentRepo.CountSearchByGraphID(ctx, graphID, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count matching documents: %w", err)
+	}
+
+	return docs, total, nil
 }
 
-// ListUserDocuments retrieves all documents for a specific user
-func (s *documentService) ListUserDocuments(ctx context.Context, userID string) ([]*models.Document, error) {
-	docs, err := s.documentRepo.ListByUserID(ctx, userID)
+// GetGraphStats counts a graph's documents by processing status. The statuses a document
+// can have are always present, with zero counts, so dashboards don't need to fill gaps.
+func (s *documentService) GetGraphStats(ctx context.Context, graphID string) (*models.GraphStats, error) {
+	counts, err := s.documentRepo.CountByStatus(ctx, graphID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list user documents: %w", err)
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+
+	stats := &models.GraphStats{DocumentsByStatus: map[string]int{
+		"processing": 0,
+		"completed":  0,
+		"failed":     0,
+		"empty":      0,
+	}}
+	for status, count := range counts {
+		stats.DocumentsByStatus[status] = count
+		stats.DocumentCount += count
 	}
 
-	return docs, nil
+	return stats, nil
 }
 
-// ListGraphDocuments retrieves all documents for a specific graph
-func (s *documentService) ListGraphDocuments(ctx context.Context, graphID string) ([]*models.Document, error) {
-	docs, err := s.documentRepo.ListByGraphID(ctx, graphID)
+// LastGraphChange returns the latest of the graph's own update, its documents' updates
+// (which include every processing status change) and its latest reindex. Deleted documents
+// leave no trace here, so callers comparing versions should include the document count too.
+func (s *documentService) LastGraphChange(ctx context.Context, graph *models.Graph) (time.Time, error) {
+	latest := graph.UpdatedAt
+
+	documentUpdate, err := s.documentRepo.LatestUpdateByGraphID(ctx, graph.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list graph documents: %w", err)
+		return time.Time{}, err
+	}
+	if documentUpdate != nil && documentUpdate.After(latest) {
+		latest = *documentUpdate
 	}
 
-	return docs, nil
+	// Clearing the Zep graph for a reindex changes its data before any document is updated
+	job, err := s.graphRepo.GetLatestReindexJob(ctx, graph.ID)
+	if err != nil && !errors.Is(err, repository.ErrReindexJobNotFound) {
+		return time.Time{}, err
+	}
+	if job != nil && job.UpdatedAt.After(latest) {
+		latest = job.UpdatedAt
+	}
+
+	return latest, nil
 }
 
-// UpdateDocument updates document content and re-processes it
-func (s *documentService) UpdateDocument(ctx context.Context, documentID, userID, plainText, lexicalState string) (*models.Document, error) {
+// UpdateDocument updates document content and re-processes it. An empty content format
+// keeps the document's current format.
+func (s *documentService) UpdateDocument(ctx context.Context, documentID, userID, content, lexicalState, contentFormat string) (*models.Document, error) {
 	// Validate content
-	if plainText == "" {
+	if content == "" {
 		return nil, fmt.Errorf("content cannot be empty")
 	}
-	if lexicalState == "" {
-		return nil, fmt.Errorf("lexical state cannot be empty")
-	}
 
 	// Get the document
 	doc, err := s.documentRepo.GetByID(ctx, documentID)
@@ -312,6 +701,18 @@ func (s *documentService) UpdateDocument(ctx context.Context, documentID, userID
 		return nil, fmt.Errorf("failed to get document: %w", err)
 	}
 
+	currentFormat := models.ContentFormatLexical
+	if doc.ContentFormat != nil {
+		currentFormat = *doc.ContentFormat
+	}
+	contentFormat, err = resolveContentFormat(contentFormat, currentFormat)
+	if err != nil {
+		return nil, err
+	}
+	if contentFormat == models.ContentFormatLexical && lexicalState == "" {
+		return nil, ErrLexicalStateRequired
+	}
+
 	// Verify user is member of document's graph
 	if doc.GraphID == nil {
 		return nil, fmt.Errorf("document is not associated with a graph")
@@ -322,15 +723,24 @@ func (s *documentService) UpdateDocument(ctx context.Context, documentID, userID
 		return nil, fmt.Errorf("failed to verify graph membership: %w", err)
 	}
 
-	// Create combined JSON structure for storage
+	// Markdown and HTML are reduced to plain text for Zep and File Search
+	plainText := s.editorPlainText(ctx, content, contentFormat)
+
+	// Create combined JSON structure for storage; Markdown and HTML are also kept as written
 	combinedContent := map[string]interface{}{
-		"plainText":    plainText,
-		"lexicalState": lexicalState,
+		"plainText":     plainText,
+		"contentFormat": contentFormat,
 		"metadata": map[string]interface{}{
 			"version":   "1.0",
 			"updatedAt": time.Now().UTC().Format(time.RFC3339),
 		},
 	}
+	if lexicalState != "" {
+		combinedContent["lexicalState"] = lexicalState
+	}
+	if plainText != content {
+		combinedContent["content"] = content
+	}
 
 	// Marshal to JSON
 	jsonBytes, err := json.Marshal(combinedContent)
@@ -344,14 +754,20 @@ func (s *documentService) UpdateDocument(ctx context.Context, documentID, userID
 	sizeBytes := int64(len(jsonBytes))
 
 	doc.ContentType = &contentType
+	doc.ContentFormat = &contentFormat
 	doc.SizeBytes = sizeBytes
 	doc.Status = "processing"
+	doc.ContentPreview = contentPreview(plainText)
+	doc.ContentHash = contentHash(jsonBytes)
 	doc.UpdatedAt = now
 
 	// Upload new content to storage
+	// Keys are built from the document's uploader and graph, whoever edits it. Documents
+	// stored under an earlier key layout move to the current one here.
 	storageKey, err := s.storageService.Upload(
 		ctx,
-		userID,
+		doc.UserID,
+		*doc.GraphID,
 		documentID,
 		"editor-content.json",
 		bytes.NewReader(jsonBytes),
@@ -361,23 +777,33 @@ func (s *documentService) UpdateDocument(ctx context.Context, documentID, userID
 		return nil, fmt.Errorf("failed to upload content to storage: %w", err)
 	}
 
-	// Delete old storage file if different
-	if doc.StorageKey != storageKey && doc.StorageKey != "" {
-		_ = s.storageService.Delete(ctx, doc.StorageKey)
-	}
-
+	oldStorageKey := doc.StorageKey
 	doc.StorageKey = storageKey
 
 	// Update document in database
 	err = s.documentRepo.Update(ctx, doc)
 	if err != nil {
+		// Keep the stored content the database still points to
+		if oldStorageKey != storageKey {
+			_ = s.storageService.Delete(ctx, storageKey)
+		}
 		return nil, fmt.Errorf("failed to update document in database: %w", err)
 	}
 
-	// Re-process document asynchronously using plain text for Zep
+	// Delete old storage file if different, now that nothing refers to it
+	if oldStorageKey != storageKey && oldStorageKey != "" {
+		_ = s.storageService.Delete(ctx, oldStorageKey)
+	}
+
+	filename := ""
+	if doc.Filename != nil {
+		filename = *doc.Filename
+	}
+
+	// Re-process document asynchronously using plain text for Zep, replacing the data from the previous content
 	go func() {
 		bgCtx := context.Background()
-		if err := s.processingService.ProcessDocument(bgCtx, userID, gr.ZepGraphID, documentID, plainText); err != nil {
+		if err := s.processingService.ReprocessDocument(bgCtx, userID, gr.ZepGraphID, documentID, filename, plainText, "text/plain"); err != nil {
 			fmt.Printf("Error processing document %s: %v\n", documentID, err)
 		}
 	}()
@@ -386,7 +812,7 @@ func (s *documentService) UpdateDocument(ctx context.Context, documentID, userID
 	go func() {
 		bgCtx := context.Background()
 		// Use plain text content with text/plain MIME type for File Search
-		s.uploadToFileSearch(bgCtx, *doc.GraphID, documentID, plainText, "text/plain")
+		s.uploadToFileSearch(bgCtx, doc, plainText, "text/plain")
 	}()
 
 	return doc, nil
@@ -394,22 +820,51 @@ func (s *documentService) UpdateDocument(ctx context.Context, documentID, userID
 
 // GetDocumentContent retrieves the actual content of a document from storage
 func (s *documentService) GetDocumentContent(ctx context.Context, documentID, userID string) (map[string]interface{}, error) {
-	// Get the document
+	doc, err := s.getDocumentForMember(ctx, documentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.readDocumentContent(ctx, doc)
+}
+
+// GetGraphDocument retrieves a document through the graph it belongs to. Access is always
+// authorized by membership of graphID, and documents in other graphs are reported as
+// ErrDocumentNotInGraph, the same as missing ones, so their existence isn't revealed.
+func (s *documentService) GetGraphDocument(ctx context.Context, graphID, documentID, userID string) (*models.Document, error) {
+	// Graph errors (ErrGraphNotFound, ErrNotGraphMember) are returned as-is for the handler to map
+	if _, err := s.graphService.GetByID(ctx, graphID, userID); err != nil {
+		return nil, err
+	}
+
 	doc, err := s.documentRepo.GetByID(ctx, documentID)
 	if err != nil {
+		if errors.Is(err, repository.ErrDocumentNotFound) {
+			return nil, ErrDocumentNotInGraph
+		}
 		return nil, fmt.Errorf("failed to get document: %w", err)
 	}
 
-	// Verify user is member of document's graph
-	if doc.GraphID == nil {
-		return nil, fmt.Errorf("document is not associated with a graph")
+	if doc.GraphID == nil || *doc.GraphID != graphID {
+		return nil, ErrDocumentNotInGraph
 	}
 
-	_, err = s.graphService.GetByID(ctx, *doc.GraphID, userID)
+	return doc, nil
+}
+
+// GetGraphDocumentContent retrieves a document's content through the graph it belongs to,
+// authorized the same way as GetGraphDocument
+func (s *documentService) GetGraphDocumentContent(ctx context.Context, graphID, documentID, userID string) (map[string]interface{}, error) {
+	doc, err := s.GetGraphDocument(ctx, graphID, documentID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to verify graph membership: %w", err)
+		return nil, err
 	}
 
+	return s.readDocumentContent(ctx, doc)
+}
+
+// readDocumentContent downloads a document's stored content, parsing editor documents' JSON
+func (s *documentService) readDocumentContent(ctx context.Context, doc *models.Document) (map[string]interface{}, error) {
 	// Download content from storage
 	reader, err := s.storageService.Download(ctx, doc.StorageKey)
 	if err != nil {
@@ -443,6 +898,52 @@ func (s *documentService) GetDocumentContent(ctx context.Context, documentID, us
 	}
 }
 
+// BatchStatus returns the processing status of several documents in one call, so clients can
+// poll a batch of uploads together. Every document must be in a graph the user is a member of;
+// otherwise the request fails with ErrDocumentsNotAccessible naming the documents that aren't.
+func (s *documentService) BatchStatus(ctx context.Context, userID string, documentIDs []string) (map[string]string, error) {
+	// Validate IDs so malformed ones are rejected before reaching the query, and deduplicate
+	// them in canonical form, which is how they are returned
+	seen := make(map[string]bool, len(documentIDs))
+	ids := make([]string, 0, len(documentIDs))
+	for _, rawID := range documentIDs {
+		parsed, err := uuid.Parse(rawID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidDocumentID, rawID)
+		}
+		if id := parsed.String(); !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 || len(ids) > MaxBatchStatusDocuments {
+		return nil, ErrInvalidBatchStatusRequest
+	}
+
+	statuses, err := s.documentRepo.ListStatusesForMember(ctx, ids, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document statuses: %w", err)
+	}
+
+	result := make(map[string]string, len(statuses))
+	for _, status := range statuses {
+		result[status.ID] = status.Status
+	}
+
+	// Missing and inaccessible documents are reported alike, as for single documents
+	var missing []string
+	for _, id := range ids {
+		if _, ok := result[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrDocumentsNotAccessible, strings.Join(missing, ", "))
+	}
+
+	return result, nil
+}
+
 // DeleteDocument deletes a document and its associated data
 func (s *documentService) DeleteDocument(ctx context.Context, documentID, userID string) error {
 	// Get the document
@@ -469,6 +970,12 @@ func (s *documentService) DeleteDocument(ctx context.Context, documentID, userID
 		}
 	}
 
+	// Remove the document's facts from the knowledge graph before its episode records are deleted with it
+	if err := s.processingService.RemoveDocument(ctx, documentID); err != nil {
+		// Log error but continue with database deletion
+		fmt.Printf("Warning: failed to remove document %s from knowledge graph: %v\n", documentID, err)
+	}
+
 	// Delete from database
 	if err := s.documentRepo.Delete(ctx, documentID); err != nil {
 		return fmt.Errorf("failed to delete document from database: %w", err)
@@ -483,6 +990,179 @@ func (s *documentService) DeleteDocument(ctx context.Context, documentID, userID
 	return nil
 }
 
+// SetTags replaces all tags on a document
+func (s *documentService) SetTags(ctx context.Context, documentID, userID string, tags []string) (*models.Document, error) {
+	doc, err := s.getDocumentForMember(ctx, documentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.saveTags(ctx, doc, tags)
+}
+
+// AddTag adds a single tag to a document (no-op if already present)
+func (s *documentService) AddTag(ctx context.Context, documentID, userID, tag string) (*models.Document, error) {
+	doc, err := s.getDocumentForMember(ctx, documentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err = normalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, added, err := s.documentRepo.AddTag(ctx, doc.ID, tag, MaxTagsPerDocument)
+	if err != nil {
+		if errors.Is(err, repository.ErrDocumentNotFound) {
+			return nil, ErrDocumentNotFound
+		}
+		return nil, fmt.Errorf("failed to add document tag: %w", err)
+	}
+	if !added && !slices.Contains(tags, tag) {
+		return nil, ErrTooManyTags
+	}
+
+	doc.Tags = tags
+	if added {
+		doc.UpdatedAt = time.Now().UTC()
+	}
+	return doc, nil
+}
+
+// RemoveTag removes a single tag from a document (no-op if not present)
+func (s *documentService) RemoveTag(ctx context.Context, documentID, userID, tag string) (*models.Document, error) {
+	doc, err := s.getDocumentForMember(ctx, documentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err = normalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	hadTag := slices.Contains(doc.Tags, tag)
+	tags, err := s.documentRepo.RemoveTag(ctx, doc.ID, tag)
+	if err != nil {
+		if errors.Is(err, repository.ErrDocumentNotFound) {
+			return nil, ErrDocumentNotFound
+		}
+		return nil, fmt.Errorf("failed to remove document tag: %w", err)
+	}
+
+	doc.Tags = tags
+	if hadTag {
+		doc.UpdatedAt = time.Now().UTC()
+	}
+	return doc, nil
+}
+
+// saveTags validates tags and persists them as the document's complete tag set
+func (s *documentService) saveTags(ctx context.Context, doc *models.Document, tags []string) (*models.Document, error) {
+	normalized, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.documentRepo.UpdateTags(ctx, doc.ID, normalized); err != nil {
+		return nil, fmt.Errorf("failed to update document tags: %w", err)
+	}
+
+	doc.Tags = normalized
+	doc.UpdatedAt = time.Now().UTC()
+	return doc, nil
+}
+
+// getDocumentForMember retrieves a document, ensuring the user is a member of its graph.
+// Documents the user can't access are reported as ErrDocumentNotFound, the same as missing
+// ones, so their existence isn't revealed.
+func (s *documentService) getDocumentForMember(ctx context.Context, documentID, userID string) (*models.Document, error) {
+	doc, err := s.documentRepo.GetByID(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDocumentNotFound) {
+			return nil, ErrDocumentNotFound
+		}
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	if doc.GraphID == nil {
+		return nil, ErrDocumentNotFound
+	}
+
+	if _, err := s.graphService.GetByID(ctx, *doc.GraphID, userID); err != nil {
+		if errors.Is(err, ErrGraphNotFound) || errors.Is(err, ErrNotGraphMember) {
+			return nil, ErrDocumentNotFound
+		}
+		return nil, fmt.Errorf("failed to verify graph membership: %w", err)
+	}
+
+	return doc, nil
+}
+
+// normalizeTags validates and normalizes a list of tags, dropping duplicates
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+
+	for _, tag := range tags {
+		tag, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > MaxTagsPerDocument {
+		return nil, ErrTooManyTags
+	}
+
+	return normalized, nil
+}
+
+// normalizeTag trims and lowercases a tag and validates its format and length
+func normalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+
+	if len([]rune(tag)) > MaxTagLength {
+		return "", ErrTagTooLong
+	}
+	if !tagPattern.MatchString(tag) {
+		return "", ErrInvalidTag
+	}
+
+	return tag, nil
+}
+
+// metadataPreamble renders the descriptive metadata fields as a short text header
+func metadataPreamble(metadata map[string]any) string {
+	fields := []struct {
+		key   string
+		label string
+	}{
+		{"title", "Title"},
+		{"author", "Author"},
+		{"subject", "Subject"},
+		{"keywords", "Keywords"},
+	}
+
+	var preamble strings.Builder
+	for _, field := range fields {
+		if value, ok := metadata[field.key].(string); ok && value != "" {
+			preamble.WriteString(fmt.Sprintf("%s: %s\n", field.label, value))
+		}
+	}
+
+	if preamble.Len() == 0 {
+		return ""
+	}
+	return preamble.String() + "\n"
+}
+
 // isValidFileType checks if the content type is supported by the extraction service
 func (s *documentService) isValidFileType(contentType string) bool {
 	return s.extractionService.IsSupported(contentType)
@@ -491,7 +1171,14 @@ func (s *documentService) isValidFileType(contentType string) bool {
 // uploadToFileSearch uploads a document to Gemini File Search asynchronously
 // This method uploads documents to the shared File Search store with metadata for graph isolation
 // Failures are logged but do not affect the main document processing flow (Zep continues)
-func (s *documentService) uploadToFileSearch(ctx context.Context, graphID, documentID, content, mimeType string) {
+func (s *documentService) uploadToFileSearch(ctx context.Context, doc *models.Document, content, mimeType string) {
+	documentID := doc.ID
+	if doc.GraphID == nil {
+		fmt.Printf("[FileSearch] Document %s is not associated with a graph, skipping upload\n", documentID)
+		return
+	}
+	graphID := *doc.GraphID
+
 	// Check if Gemini service is available
 	if s.geminiService == nil {
 		fmt.Printf("[FileSearch] Gemini service not available, skipping upload for document %s\n", documentID)
@@ -510,7 +1197,7 @@ func (s *documentService) uploadToFileSearch(ctx context.Context, graphID, docum
 
 	// Upload document to shared File Search store with metadata (with built-in retry logic in GeminiService)
 	// The shared store ID is managed by the GeminiService (set during initialization)
-	fileID, err := s.geminiService.UploadDocument(ctx, "", graphID, graph.Name, documentID, []byte(content), mimeType)
+	fileID, err := s.geminiService.UploadDocument(ctx, "", graphID, graph.Name, doc, []byte(content), mimeType)
 	if err != nil {
 		// Log detailed error but continue with Zep processing
 		fmt.Printf("[FileSearch] FAILED to upload document %s to File Search after retries: %v\n", documentID, err)
@@ -522,3 +1209,119 @@ func (s *documentService) uploadToFileSearch(ctx context.Context, graphID, docum
 	fmt.Printf("[FileSearch] Successfully uploaded document %s to shared File Search store (file ID: %s) with graph metadata [graph_id=%s, name=%s]\n",
 		documentID, fileID, graphID, graph.Name)
 }
+
+// VerifyIntegrity checks that the storage object of every document in a graph exists.
+// Documents whose object is missing (e.g. deleted out-of-band) are reported as orphaned;
+// documents that could not be checked are reported separately so they can be retried.
+func (s *documentService) VerifyIntegrity(ctx context.Context, graphID string) (*models.DocumentIntegrityReport, error) {
+	docs, err := s.documentRepo.ListByGraphID(ctx, graphID, models.DocumentFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	report := &models.DocumentIntegrityReport{
+		GraphID:    graphID,
+		Orphaned:   []models.DocumentIntegrityIssue{},
+		Unverified: []models.DocumentIntegrityIssue{},
+	}
+
+	for _, doc := range docs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if doc.StorageKey == "" {
+			continue
+		}
+
+		issue := models.DocumentIntegrityIssue{
+			DocumentID: doc.ID,
+			Filename:   doc.Filename,
+			StorageKey: doc.StorageKey,
+		}
+
+		exists, err := s.storageService.Exists(ctx, doc.StorageKey)
+		report.CheckedCount++
+		if err != nil {
+			// The storage error may name buckets and keys, so the report only gets a code
+			fmt.Printf("Warning: failed to check storage for document %s: %v\n", doc.ID, err)
+			issue.Error = models.IntegrityStorageUnreachable
+			report.Unverified = append(report.Unverified, issue)
+			continue
+		}
+		if !exists {
+			issue.Error = models.IntegrityMissingInStorage
+			report.Orphaned = append(report.Orphaned, issue)
+		}
+	}
+
+	report.CheckedAt = time.Now()
+	return report, nil
+}
+
+// sanitizeFilename reduces a client-supplied filename to a safe display name: directory
+// components, control characters and surrounding whitespace/dots are removed, and long
+// names are truncated to MaxFilenameLength bytes while keeping the extension intact.
+func sanitizeFilename(filename string) string {
+	// Browsers on Windows may send a full path; keep only the final element
+	filename = strings.ReplaceAll(filename, "\\", "/")
+	if i := strings.LastIndex(filename, "/"); i >= 0 {
+		filename = filename[i+1:]
+	}
+
+	filename = strings.ToValidUTF8(filename, "")
+	filename = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, filename)
+	filename = strings.Trim(filename, " .")
+
+	if filename == "" {
+		return "untitled"
+	}
+
+	if len(filename) > MaxFilenameLength {
+		ext := path.Ext(filename)
+		if len(ext) > 16 {
+			ext = "" // Not a real extension; truncate it with the rest of the name
+		}
+		base := truncateUTF8(strings.TrimSuffix(filename, ext), MaxFilenameLength-len(ext))
+		filename = strings.TrimRight(base, " .") + ext
+	}
+
+	return filename
+}
+
+// truncateUTF8 shortens s to at most n bytes without splitting a multi-byte character
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// contentPreview returns the start of a document's text for document lists, with runs of
+// whitespace collapsed, or nil if there is no text
+func contentPreview(text string) *string {
+	preview := strings.Join(strings.Fields(text), " ")
+	if preview == "" {
+		return nil
+	}
+
+	if runes := []rune(preview); len(runes) > ContentPreviewLength {
+		preview = strings.TrimRight(string(runes[:ContentPreviewLength]), " ") + "…"
+	}
+	return &preview
+}
+
+// contentHash returns the hex-encoded SHA-256 hash of a document's stored content
+func contentHash(data []byte) *string {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	return &hash
+}
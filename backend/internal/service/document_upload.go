@@ -0,0 +1,352 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/extraction"
+	"github.com/bipulkrdas/orgmind/backend/internal/models"
+	"github.com/bipulkrdas/orgmind/backend/internal/repository"
+	"github.com/bipulkrdas/orgmind/backend/internal/storage"
+	"github.com/google/uuid"
+)
+
+const (
+	// UploadPartSize is the size of every part of a resumable upload but the last: the
+	// smallest part S3 accepts, so a failed part costs as little as possible to resend
+	UploadPartSize = 5 * 1024 * 1024 // 5MB
+
+	// uploadCleanupBatchSize is the maximum number of expired upload sessions removed per pass
+	uploadCleanupBatchSize = 50
+)
+
+// Custom errors for resumable uploads
+var (
+	ErrUploadNotFound    = fmt.Errorf("upload not found or expired")
+	ErrInvalidUploadPart = fmt.Errorf("invalid upload part")
+	ErrUploadIncomplete  = fmt.Errorf("not every part of the upload has been received")
+)
+
+// RunUploadCleanup aborts expired upload sessions every interval, until ctx is cancelled
+func RunUploadCleanup(ctx context.Context, documentService DocumentService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := documentService.CleanupExpiredUploads(ctx)
+			if err != nil {
+				fmt.Printf("Warning: failed to clean up expired uploads: %v\n", err)
+			}
+			if removed > 0 {
+				fmt.Printf("Removed %d expired upload(s)\n", removed)
+			}
+		}
+	}
+}
+
+// InitiateUpload starts a resumable upload of a file to a graph. The file is checked like a
+// CreateFromFile upload, and is then sent in parts of UploadPartSize bytes (the last part
+// holds the rest) before being completed with CompleteUpload.
+func (s *documentService) InitiateUpload(ctx context.Context, userID string, req *models.InitiateUploadRequest) (*models.UploadSession, error) {
+	filename, contentType, gr, err := s.validateUpload(ctx, userID, req.GraphID, req.SizeBytes, req.Filename, req.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	// The document ID is chosen now so the client can find the document even if the
+	// response to CompleteUpload is lost
+	documentID := uuid.New().String()
+
+	storageKey, storageUploadID, err := s.storageService.CreateMultipartUpload(ctx, userID, gr.ID, documentID, filename, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start upload to storage: %w", err)
+	}
+
+	now := time.Now().UTC()
+	session := &models.UploadSession{
+		ID:              uuid.New().String(),
+		UserID:          userID,
+		GraphID:         gr.ID,
+		DocumentID:      documentID,
+		Filename:        filename,
+		ContentType:     contentType,
+		SizeBytes:       req.SizeBytes,
+		PartSize:        UploadPartSize,
+		StorageKey:      storageKey,
+		StorageUploadID: storageUploadID,
+		ExpiresAt:       now.Add(s.uploadSessionTTL),
+		CreatedAt:       now,
+	}
+
+	if err := s.uploadSessionRepo.Create(ctx, session); err != nil {
+		_ = s.storageService.AbortMultipartUpload(ctx, storageKey, storageUploadID)
+		return nil, fmt.Errorf("failed to create upload session: %w", err)
+	}
+
+	return session, nil
+}
+
+// GetUpload returns an upload session and the parts received so far, so an interrupted
+// client can resume by sending only the missing parts
+func (s *documentService) GetUpload(ctx context.Context, uploadID, userID string) (*models.UploadSession, []*models.UploadPart, error) {
+	session, err := s.getActiveUpload(ctx, uploadID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parts, err := s.uploadSessionRepo.ListParts(ctx, session.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return session, parts, nil
+}
+
+// UploadPart stores one part of an upload. Parts may arrive in any order, and sending a part
+// again replaces it, so a part whose request failed can simply be retried.
+func (s *documentService) UploadPart(ctx context.Context, uploadID, userID string, partNumber int, content io.Reader) (*models.UploadPart, error) {
+	session, err := s.getActiveUpload(ctx, uploadID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Assembled {
+		return nil, fmt.Errorf("%w: the upload's parts were already assembled", ErrInvalidUploadPart)
+	}
+
+	if partNumber < 1 || partNumber > session.PartCount() {
+		return nil, fmt.Errorf("%w: part number must be between 1 and %d", ErrInvalidUploadPart, session.PartCount())
+	}
+
+	// Read one byte more than expected to detect parts that are too long
+	expected := session.ExpectedPartSize(partNumber)
+	data, err := io.ReadAll(io.LimitReader(content, expected+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload part: %w", err)
+	}
+	if int64(len(data)) != expected {
+		return nil, fmt.Errorf("%w: part %d must be exactly %d bytes", ErrInvalidUploadPart, partNumber, expected)
+	}
+
+	etag, err := s.storageService.UploadPart(ctx, session.StorageKey, session.StorageUploadID, int32(partNumber), bytes.NewReader(data), expected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload part to storage: %w", err)
+	}
+
+	part := &models.UploadPart{
+		SessionID:  session.ID,
+		PartNumber: partNumber,
+		ETag:       etag,
+		SizeBytes:  expected,
+		UploadedAt: time.Now().UTC(),
+	}
+	if err := s.uploadSessionRepo.SavePart(ctx, part); err != nil {
+		return nil, err
+	}
+
+	return part, nil
+}
+
+// CompleteUpload assembles a fully uploaded file and creates its document, which is then
+// extracted and processed exactly like a CreateFromFile upload. If extraction is too busy
+// to take the file, the upload is kept so the completion can be retried.
+func (s *documentService) CompleteUpload(ctx context.Context, uploadID, userID string) (*models.Document, error) {
+	session, err := s.getActiveUpload(ctx, uploadID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The user's membership and the graph's allowed types may have changed since the upload started
+	filename, contentType, gr, err := s.validateUpload(ctx, userID, session.GraphID, session.SizeBytes, session.Filename, session.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	// A retried completion finds the parts already assembled
+	if !session.Assembled {
+		if err := s.assembleUpload(ctx, session); err != nil {
+			return nil, err
+		}
+	}
+
+	// Removing the session claims it, so concurrent completions create only one document
+	if err := s.uploadSessionRepo.Delete(ctx, session.ID); err != nil {
+		if errors.Is(err, repository.ErrUploadSessionNotFound) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, err
+	}
+
+	fileBytes, err := s.readStoredUpload(ctx, session)
+	if err != nil {
+		_ = s.storageService.Delete(ctx, session.StorageKey)
+		return nil, err
+	}
+
+	doc, err := s.createUploadedDocument(ctx, userID, gr, session.DocumentID, filename, contentType, session.StorageKey, fileBytes)
+	if err != nil {
+		if errors.Is(err, extraction.ErrExtractionBusy) {
+			// Put the session back so the client's retry finds the assembled file
+			if restoreErr := s.uploadSessionRepo.Create(ctx, session); restoreErr != nil {
+				fmt.Printf("Warning: failed to restore upload %s after busy extraction: %v\n", session.ID, restoreErr)
+				_ = s.storageService.Delete(ctx, session.StorageKey)
+			}
+		}
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// assembleUpload checks that every part of an upload has arrived, joins them into the
+// stored file and records that the session is assembled
+func (s *documentService) assembleUpload(ctx context.Context, session *models.UploadSession) error {
+	parts, err := s.uploadSessionRepo.ListParts(ctx, session.ID)
+	if err != nil {
+		return err
+	}
+
+	completed, err := completedParts(session, parts)
+	if err != nil {
+		return err
+	}
+
+	if err := s.storageService.CompleteMultipartUpload(ctx, session.StorageKey, session.StorageUploadID, completed); err != nil {
+		return fmt.Errorf("failed to complete upload in storage: %w", err)
+	}
+
+	if err := s.uploadSessionRepo.MarkAssembled(ctx, session.ID); err != nil {
+		return err
+	}
+	session.Assembled = true
+
+	return nil
+}
+
+// AbortUpload cancels an upload and discards the parts received so far
+func (s *documentService) AbortUpload(ctx context.Context, uploadID, userID string) error {
+	session, err := s.getActiveUpload(ctx, uploadID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.removeUpload(ctx, session)
+}
+
+// CleanupExpiredUploads aborts upload sessions that expired before being completed and
+// returns how many were removed
+func (s *documentService) CleanupExpiredUploads(ctx context.Context) (int, error) {
+	sessions, err := s.uploadSessionRepo.ListExpired(ctx, time.Now().UTC(), uploadCleanupBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, session := range sessions {
+		if err := s.removeUpload(ctx, session); err != nil {
+			fmt.Printf("Warning: failed to remove expired upload %s: %v\n", session.ID, err)
+			continue
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// removeUpload aborts an upload in storage, or deletes the stored file once its parts were
+// assembled, and deletes its session. The session is kept if storage fails, so removing it
+// can be retried.
+func (s *documentService) removeUpload(ctx context.Context, session *models.UploadSession) error {
+	if session.Assembled {
+		if err := s.storageService.Delete(ctx, session.StorageKey); err != nil {
+			return fmt.Errorf("failed to delete assembled upload from storage: %w", err)
+		}
+	} else if err := s.storageService.AbortMultipartUpload(ctx, session.StorageKey, session.StorageUploadID); err != nil {
+		return fmt.Errorf("failed to abort upload in storage: %w", err)
+	}
+
+	if err := s.uploadSessionRepo.Delete(ctx, session.ID); err != nil {
+		if errors.Is(err, repository.ErrUploadSessionNotFound) {
+			return ErrUploadNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// getActiveUpload returns an unexpired upload session started by the user. Other users'
+// sessions are reported as not found rather than forbidden, so their IDs can't be probed.
+func (s *documentService) getActiveUpload(ctx context.Context, uploadID, userID string) (*models.UploadSession, error) {
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return nil, ErrUploadNotFound
+	}
+
+	session, err := s.uploadSessionRepo.GetByID(ctx, uploadID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUploadSessionNotFound) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, err
+	}
+
+	if session.UserID != userID || time.Now().After(session.ExpiresAt) {
+		return nil, ErrUploadNotFound
+	}
+
+	return session, nil
+}
+
+// completedParts checks that every part of an upload has been received with its expected
+// size and lists them in part number order for storage
+func completedParts(session *models.UploadSession, parts []*models.UploadPart) ([]storage.CompletedPart, error) {
+	received := make(map[int]*models.UploadPart, len(parts))
+	for _, part := range parts {
+		received[part.PartNumber] = part
+	}
+
+	completed := make([]storage.CompletedPart, 0, session.PartCount())
+	var missing []int
+	for partNumber := 1; partNumber <= session.PartCount(); partNumber++ {
+		part, ok := received[partNumber]
+		if !ok || part.SizeBytes != session.ExpectedPartSize(partNumber) {
+			missing = append(missing, partNumber)
+			continue
+		}
+		completed = append(completed, storage.CompletedPart{PartNumber: int32(partNumber), ETag: part.ETag})
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: missing parts %v", ErrUploadIncomplete, missing)
+	}
+
+	return completed, nil
+}
+
+// readStoredUpload reads a completed upload back from storage for extraction
+func (s *documentService) readStoredUpload(ctx context.Context, session *models.UploadSession) ([]byte, error) {
+	reader, err := s.storageService.Download(ctx, session.StorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	defer reader.Close()
+
+	buf := bytes.NewBuffer(make([]byte, 0, session.SizeBytes))
+	if _, err := buf.ReadFrom(io.LimitReader(reader, s.maxFileSize+1)); err != nil {
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+
+	if int64(buf.Len()) != session.SizeBytes {
+		return nil, fmt.Errorf("uploaded file is %d bytes, expected %d", buf.Len(), session.SizeBytes)
+	}
+
+	return buf.Bytes(), nil
+}
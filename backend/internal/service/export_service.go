@@ -0,0 +1,271 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/models"
+	"github.com/bipulkrdas/orgmind/backend/internal/repository"
+	"github.com/bipulkrdas/orgmind/backend/internal/storage"
+)
+
+// exportMessageBatchSize is the number of chat messages loaded per query while exporting a thread
+const exportMessageBatchSize = 500
+
+// exportManifest describes an export archive; written last so it can list files that couldn't be included
+type exportManifest struct {
+	UserID       string    `json:"userId"`
+	ExportedAt   time.Time `json:"exportedAt"`
+	Graphs       int       `json:"graphs"`
+	Documents    int       `json:"documents"`
+	ChatThreads  int       `json:"chatThreads"`
+	MissingFiles []string  `json:"missingFiles"`
+}
+
+// exportThread is a chat thread together with its messages
+type exportThread struct {
+	Thread   *models.ChatThread    `json:"thread"`
+	Messages []*models.ChatMessage `json:"messages"`
+}
+
+// exportService implements ExportService interface
+type exportService struct {
+	userRepo       repository.UserRepository
+	graphRepo      repository.GraphRepository
+	documentRepo   repository.DocumentRepository
+	chatRepo       repository.ChatRepository
+	storageService storage.StorageService
+}
+
+// NewExportService creates a new instance of ExportService
+func NewExportService(
+	userRepo repository.UserRepository,
+	graphRepo repository.GraphRepository,
+	documentRepo repository.DocumentRepository,
+	chatRepo repository.ChatRepository,
+	storageService storage.StorageService,
+) ExportService {
+	return &exportService{
+		userRepo:       userRepo,
+		graphRepo:      graphRepo,
+		documentRepo:   documentRepo,
+		chatRepo:       chatRepo,
+		storageService: storageService,
+	}
+}
+
+// ExportUserData writes a ZIP archive of the user's data to w. The archive contains:
+//
+//	profile.json                      the user's profile
+//	memberships.json                  the user's graph memberships
+//	graphs/<graphId>/graph.json       each graph the user created
+//	graphs/<graphId>/documents/...    the user's documents in that graph
+//	documents/...                     the user's documents in graphs they didn't create
+//	chat/<threadId>.json              each chat thread the user started, with its messages
+//	manifest.json                     counts and any original files that couldn't be read
+//
+// Each document is a <documentId>/document.json metadata file with its original file under
+// <documentId>/original/, so no filename can collide with the metadata.
+// Only data belonging to the user is included: documents other members added to the
+// user's graphs are not. Files are streamed from storage, so nothing is written to w
+// if the user's records can't be loaded, but an error part way through leaves a truncated archive.
+func (s *exportService) ExportUserData(ctx context.Context, userID string, w io.Writer) error {
+	// Load every record before writing so lookup failures can still be reported cleanly
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	memberships, err := s.graphRepo.ListMembershipsByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list memberships: %w", err)
+	}
+
+	graphs, err := s.graphRepo.ListByCreatorID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list graphs: %w", err)
+	}
+
+	documents, err := s.documentRepo.ListByAuthorID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	threads, err := s.chatRepo.ListThreadsByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list chat threads: %w", err)
+	}
+
+	// Group documents under the graphs the user created; the rest go to documents/
+	ownedGraphIDs := make(map[string]bool, len(graphs))
+	for _, graph := range graphs {
+		ownedGraphIDs[graph.ID] = true
+	}
+	documentsByGraph := make(map[string][]*models.Document)
+	var otherDocuments []*models.Document
+	for _, doc := range documents {
+		if doc.GraphID != nil && ownedGraphIDs[*doc.GraphID] {
+			documentsByGraph[*doc.GraphID] = append(documentsByGraph[*doc.GraphID], doc)
+		} else {
+			otherDocuments = append(otherDocuments, doc)
+		}
+	}
+
+	manifest := exportManifest{
+		UserID:       userID,
+		ExportedAt:   time.Now(),
+		Graphs:       len(graphs),
+		Documents:    len(documents),
+		ChatThreads:  len(threads),
+		MissingFiles: []string{},
+	}
+
+	zw := zip.NewWriter(w)
+
+	if err := writeJSONEntry(zw, "profile.json", user); err != nil {
+		return err
+	}
+	if err := writeJSONEntry(zw, "memberships.json", memberships); err != nil {
+		return err
+	}
+
+	for _, graph := range graphs {
+		missing, err := s.writeGraph(ctx, zw, "graphs/"+graph.ID, graph, documentsByGraph[graph.ID])
+		if err != nil {
+			return err
+		}
+		manifest.MissingFiles = append(manifest.MissingFiles, missing...)
+	}
+
+	for _, doc := range otherDocuments {
+		missing, err := s.writeDocument(ctx, zw, "documents/"+doc.ID, doc)
+		if err != nil {
+			return err
+		}
+		if missing != "" {
+			manifest.MissingFiles = append(manifest.MissingFiles, missing)
+		}
+	}
+
+	for _, thread := range threads {
+		if err := s.writeThread(ctx, zw, thread); err != nil {
+			return err
+		}
+	}
+
+	if err := writeJSONEntry(zw, "manifest.json", manifest); err != nil {
+		return err
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish export archive: %w", err)
+	}
+
+	return nil
+}
+
+// writeGraph writes a graph's metadata and the given documents under dir.
+// Returns the archive paths of original files that couldn't be read from storage.
+func (s *exportService) writeGraph(ctx context.Context, zw *zip.Writer, dir string, graph *models.Graph, documents []*models.Document) ([]string, error) {
+	if err := writeJSONEntry(zw, dir+"/graph.json", graph); err != nil {
+		return nil, err
+	}
+
+	var missingFiles []string
+	for _, doc := range documents {
+		missing, err := s.writeDocument(ctx, zw, dir+"/documents/"+doc.ID, doc)
+		if err != nil {
+			return nil, err
+		}
+		if missing != "" {
+			missingFiles = append(missingFiles, missing)
+		}
+	}
+
+	return missingFiles, nil
+}
+
+// writeDocument writes a document's metadata under dir and its original file under dir/original.
+// A file missing from storage is skipped and its archive path returned, so one
+// lost object doesn't fail the whole export.
+func (s *exportService) writeDocument(ctx context.Context, zw *zip.Writer, dir string, doc *models.Document) (string, error) {
+	if err := writeJSONEntry(zw, dir+"/document.json", doc); err != nil {
+		return "", err
+	}
+
+	if doc.StorageKey == "" {
+		return "", nil
+	}
+
+	filename := "editor-content.json"
+	if doc.Filename != nil {
+		filename = sanitizeFilename(*doc.Filename)
+	}
+	name := dir + "/original/" + filename
+
+	reader, err := s.storageService.Download(ctx, doc.StorageKey)
+	if err != nil {
+		fmt.Printf("Warning: failed to read document %s for export: %v\n", doc.ID, err)
+		return name, nil
+	}
+	defer reader.Close()
+
+	entry, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: doc.UpdatedAt,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to add %s to export archive: %w", name, err)
+	}
+
+	if _, err := io.Copy(entry, reader); err != nil {
+		return "", fmt.Errorf("failed to write %s to export archive: %w", name, err)
+	}
+
+	return "", nil
+}
+
+// writeThread writes a chat thread and all its messages, loading messages in batches
+func (s *exportService) writeThread(ctx context.Context, zw *zip.Writer, thread *models.ChatThread) error {
+	messages := []*models.ChatMessage{}
+	for offset := 0; ; offset += exportMessageBatchSize {
+		batch, err := s.chatRepo.GetMessagesByThreadID(ctx, thread.ID, models.SortOrderAsc, exportMessageBatchSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to get messages of thread %s: %w", thread.ID, err)
+		}
+		messages = append(messages, batch...)
+		if len(batch) < exportMessageBatchSize {
+			break
+		}
+	}
+
+	return writeJSONEntry(zw, "chat/"+thread.ID+".json", exportThread{
+		Thread:   thread,
+		Messages: messages,
+	})
+}
+
+// writeJSONEntry adds value to the archive as an indented JSON file
+func writeJSONEntry(zw *zip.Writer, name string, value any) error {
+	entry, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to export archive: %w", name, err)
+	}
+
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to write %s to export archive: %w", name, err)
+	}
+
+	return nil
+}
@@ -8,10 +8,12 @@ import (
 	"log"
 	"math"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bipulkrdas/orgmind/backend/internal/models"
 	"github.com/bipulkrdas/orgmind/backend/internal/repository"
+	"github.com/bipulkrdas/orgmind/backend/internal/tracing"
 	"google.golang.org/genai"
 )
 
@@ -24,17 +26,39 @@ var (
 	ErrGeminiAPIKey        = errors.New("Gemini API key not configured")
 )
 
+// geminiHealthCacheTTL is how long a health check result is reused, so frequent
+// readiness probes don't each make a Gemini API call
+const geminiHealthCacheTTL = 30 * time.Second
+
+// storeInitRetryInterval is how long to wait after a failed on-demand store initialization
+// before trying again, so requests don't each retry while Gemini is unavailable
+const storeInitRetryInterval = time.Minute
+
+// TokenUsage is the number of tokens a Gemini request consumed
+type TokenUsage struct {
+	InputTokens  int
+	OutputTokens int
+}
+
 // geminiService implements the GeminiService interface
 type geminiService struct {
 	client          *genai.Client
 	graphRepo       repository.GraphRepository
 	docRepo         repository.DocumentRepository
 	geminiStoreRepo repository.GeminiStoreRepository
-	storeID         string // Shared File Search store ID
 	storeName       string // Store display name
 	apiKey          string
 	projectID       string
 	location        string
+
+	// The shared File Search store ID, empty until the store is initialized
+	storeMu           sync.Mutex
+	storeID           string
+	storeInitFailedAt time.Time
+
+	healthMu        sync.Mutex
+	healthCheckedAt time.Time
+	healthErr       error
 }
 
 // NewGeminiService creates a new Gemini service instance
@@ -50,7 +74,8 @@ func NewGeminiService(
 
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey: apiKey,
+		APIKey:     apiKey,
+		HTTPClient: tracing.HTTPClient(),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
@@ -70,7 +95,51 @@ func NewGeminiService(
 }
 
 // InitializeStore creates or retrieves the shared File Search store
-func (s *geminiService) InitializeStore(ctx context.Context, storeName string) (storeID string, err error) {
+func (s *geminiService) InitializeStore(ctx context.Context, storeName string) (string, error) {
+	s.storeMu.Lock()
+	defer s.storeMu.Unlock()
+
+	storeID, err := s.findOrCreateStore(ctx, storeName)
+	if err != nil {
+		s.storeInitFailedAt = time.Now()
+		return "", err
+	}
+
+	s.storeID = storeID
+	return storeID, nil
+}
+
+// sharedStoreID returns the shared store ID, initializing the store if that never succeeded,
+// such as when Gemini was unavailable at startup. ErrGeminiStoreNotFound is returned while
+// no store can be used; a failed initialization is only retried after storeInitRetryInterval.
+func (s *geminiService) sharedStoreID(ctx context.Context) (string, error) {
+	s.storeMu.Lock()
+	defer s.storeMu.Unlock()
+
+	if s.storeID != "" {
+		return s.storeID, nil
+	}
+	if s.storeName == "" {
+		return "", fmt.Errorf("%w: no store is configured", ErrGeminiStoreNotFound)
+	}
+	if !s.storeInitFailedAt.IsZero() && time.Since(s.storeInitFailedAt) < storeInitRetryInterval {
+		return "", fmt.Errorf("%w: store initialization failed recently", ErrGeminiStoreNotFound)
+	}
+
+	log.Printf("[Gemini] Store Initialization: Store '%s' not initialized, initializing on first use", s.storeName)
+	storeID, err := s.findOrCreateStore(ctx, s.storeName)
+	if err != nil {
+		s.storeInitFailedAt = time.Now()
+		return "", fmt.Errorf("%w: %v", ErrGeminiStoreNotFound, err)
+	}
+
+	s.storeID = storeID
+	return storeID, nil
+}
+
+// findOrCreateStore returns the ID of the File Search store recorded under storeName,
+// creating the store if there is none
+func (s *geminiService) findOrCreateStore(ctx context.Context, storeName string) (storeID string, err error) {
 	// Log store initialization attempt
 	log.Printf("[Gemini] Store Initialization: Checking database for existing store with name '%s'", storeName)
 
@@ -84,7 +153,6 @@ func (s *geminiService) InitializeStore(ctx context.Context, storeName string) (
 	// If store exists in database, use it
 	if existingStore != nil {
 		log.Printf("[Gemini] Store Initialization: FOUND - Using existing store '%s' with ID: %s", storeName, existingStore.StoreID)
-		s.storeID = existingStore.StoreID
 		return existingStore.StoreID, nil
 	}
 
@@ -119,7 +187,6 @@ func (s *geminiService) InitializeStore(ctx context.Context, storeName string) (
 				log.Printf("[Gemini] Store Initialization: Database record created for store '%s'", storeName)
 			}
 
-			s.storeID = storeID
 			return storeID, nil
 		}
 
@@ -139,16 +206,47 @@ func (s *geminiService) InitializeStore(ctx context.Context, storeName string) (
 	return "", fmt.Errorf("%w: %v", ErrGeminiStoreCreation, err)
 }
 
-// UploadDocument uploads a document to a File Search store with metadata
-func (s *geminiService) UploadDocument(ctx context.Context, storeID, graphID, graphName, documentID string, content []byte, mimeType string) (string, error) {
+// HealthCheck verifies that the API key is valid and the shared File Search store
+// is reachable by fetching the store. Results are cached for geminiHealthCacheTTL.
+func (s *geminiService) HealthCheck(ctx context.Context) error {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+
+	if !s.healthCheckedAt.IsZero() && time.Since(s.healthCheckedAt) < geminiHealthCacheTTL {
+		return s.healthErr
+	}
+
+	if storeID, err := s.sharedStoreID(ctx); err != nil {
+		s.healthErr = err
+	} else if _, err := s.client.FileSearchStores.Get(ctx, storeID, nil); err != nil {
+		s.healthErr = fmt.Errorf("failed to reach Gemini File Search store %s: %w", storeID, err)
+	} else {
+		s.healthErr = nil
+	}
+	s.healthCheckedAt = time.Now()
+
+	return s.healthErr
+}
+
+// UploadDocument uploads a document to a File Search store with metadata. Besides the graph,
+// the metadata identifies the document (document_id, filename, content_type), so results can
+// be attributed to it and searches filtered to it.
+func (s *geminiService) UploadDocument(ctx context.Context, storeID, graphID, graphName string, doc *models.Document, content []byte, mimeType string) (string, error) {
 	// Use shared store ID from service if storeID parameter is empty
 	if storeID == "" {
-		storeID = s.storeID
+		sharedID, err := s.sharedStoreID(ctx)
+		if err != nil {
+			return "", err
+		}
+		storeID = sharedID
 	}
 
-	// Log upload with graph_id, domain, version metadata
-	log.Printf("[Gemini] Document Upload: Starting upload for document '%s' to store '%s' | Graph: %s (%s) | Metadata: [graph_id=%s, domain=topeic.com, version=1.1] | Size: %d bytes | Type: %s",
-		documentID, storeID, graphID, graphName, graphID, len(content), mimeType)
+	documentID := doc.ID
+	metadata := fileSearchMetadata(graphID, doc)
+
+	// Log upload with graph_id, domain, version and document metadata
+	log.Printf("[Gemini] Document Upload: Starting upload for document '%s' to store '%s' | Graph: %s (%s) | Metadata: %s | Size: %d bytes | Type: %s",
+		documentID, storeID, graphID, graphName, formatCustomMetadata(metadata), len(content), mimeType)
 
 	var op *genai.UploadToFileSearchStoreOperation
 	var err error
@@ -157,13 +255,9 @@ func (s *geminiService) UploadDocument(ctx context.Context, storeID, graphID, gr
 	for attempt := 1; attempt <= 3; attempt++ {
 		reader := bytes.NewReader(content)
 		op, err = s.client.FileSearchStores.UploadToFileSearchStore(ctx, reader, storeID, &genai.UploadToFileSearchStoreConfig{
-			DisplayName: graphName,
-			MIMEType:    mimeType,
-			CustomMetadata: []*genai.CustomMetadata{
-				{Key: "graph_id", StringValue: graphID},
-				{Key: "domain", StringValue: "topeic.com"},
-				{Key: "version", StringValue: "1.1"},
-			},
+			DisplayName:    graphName,
+			MIMEType:       mimeType,
+			CustomMetadata: metadata,
 		})
 
 		if err == nil {
@@ -238,30 +332,74 @@ func (s *geminiService) UploadDocument(ctx context.Context, storeID, graphID, gr
 	return fileID, nil
 }
 
-// GenerateStreamingResponse generates a streaming AI response using File Search with metadata filtering
-func (s *geminiService) GenerateStreamingResponse(ctx context.Context, storeID, graphID, domain, version, query string, responseChan chan<- string) error {
+// fileSearchMetadata builds the custom metadata of a document uploaded to File Search.
+// Filename and content type are left out for documents without them, such as editor content.
+func fileSearchMetadata(graphID string, doc *models.Document) []*genai.CustomMetadata {
+	metadata := []*genai.CustomMetadata{
+		{Key: "graph_id", StringValue: graphID},
+		{Key: "domain", StringValue: "topeic.com"},
+		{Key: "version", StringValue: "1.1"},
+		{Key: "document_id", StringValue: doc.ID},
+	}
+	if doc.Filename != nil && *doc.Filename != "" {
+		metadata = append(metadata, &genai.CustomMetadata{Key: "filename", StringValue: *doc.Filename})
+	}
+	if doc.ContentType != nil && *doc.ContentType != "" {
+		metadata = append(metadata, &genai.CustomMetadata{Key: "content_type", StringValue: *doc.ContentType})
+	}
+	return metadata
+}
+
+// formatCustomMetadata formats custom metadata for logs, e.g. [graph_id=..., domain=...]
+func formatCustomMetadata(metadata []*genai.CustomMetadata) string {
+	pairs := make([]string, len(metadata))
+	for i, m := range metadata {
+		pairs[i] = m.Key + "=" + m.StringValue
+	}
+	return "[" + strings.Join(pairs, ", ") + "]"
+}
+
+// GenerateStreamingResponse generates a streaming AI response using File Search with metadata filtering.
+// Documents from any of graphIDs are searched, so a question can span several graphs, except
+// excludedDocumentIDs. Exclusion matches the document_id metadata, so files uploaded before
+// documents were tagged with it don't match the filter and are left out as well.
+// The returned usage reflects whatever the stream reported, even when an error is returned.
+func (s *geminiService) GenerateStreamingResponse(ctx context.Context, storeID string, graphIDs, excludedDocumentIDs []string, domain, version, query string, responseChan chan<- string) (TokenUsage, error) {
 	// NOTE: Do NOT close responseChan here - let the caller manage channel lifecycle
 	// The caller needs to know when streaming completes vs when an error occurs
 
 	// Use shared store ID from service if storeID parameter is empty
 	if storeID == "" {
-		storeID = s.storeID
+		sharedID, err := s.sharedStoreID(ctx)
+		if err != nil {
+			log.Printf("[Gemini] Query Filtering: ERROR - No File Search store available: %v", err)
+			return TokenUsage{}, err
+		}
+		storeID = sharedID
 	}
 
+	if len(graphIDs) == 0 {
+		return TokenUsage{}, fmt.Errorf("at least one graph ID is required")
+	}
+
+	// graphID names the graphs in log messages
+	graphID := strings.Join(graphIDs, ",")
+
 	// Log query execution with graph_id
 	log.Printf("[Gemini] Query Filtering: Starting query execution | Store: %s | Graph ID: %s | Domain: %s | Version: %s | Query: %.100s...",
 		storeID, graphID, domain, version, query)
 
 	// Build metadata filter expression
 	// Escape special characters in values to prevent injection
-	escapedGraphID := escapeFilterValue(graphID)
 	escapedDomain := escapeFilterValue(domain)
 	escapedVersion := escapeFilterValue(version)
 
-	// Construct filter: (chunk.custom_metadata.graph_id = "{graphID}" AND chunk.custom_metadata.domain = "{domain}" AND chunk.custom_metadata.version = "{version}")
+	// Construct filter: (graph_id = "{graphID}" AND domain = "{domain}" AND version = "{version}"),
+	// where several graphs become (graph_id = "{a}" OR graph_id = "{b}"), followed by
+	// AND document_id != "{id}" for each excluded document
 	metadataFilter := fmt.Sprintf(
-		`(graph_id = "%s" AND domain = "%s" AND version = "%s")`,
-		escapedGraphID, escapedDomain, escapedVersion,
+		`(%s AND domain = "%s" AND version = "%s"%s)`,
+		graphIDFilter(graphIDs), escapedDomain, escapedVersion, excludedDocumentsFilter(excludedDocumentIDs),
 	)
 
 	// Validate filter syntax (basic check)
@@ -305,6 +443,7 @@ func (s *geminiService) GenerateStreamingResponse(ctx context.Context, storeID,
 
 	// Process the stream
 	chunkCount := 0
+	var usage TokenUsage
 	var lastErr error
 	for resp, err := range responseIter {
 		if err != nil {
@@ -316,6 +455,12 @@ func (s *geminiService) GenerateStreamingResponse(ctx context.Context, storeID,
 			break
 		}
 
+		// Usage metadata is cumulative, so the last chunk that reports it holds the totals
+		if resp.UsageMetadata != nil {
+			usage.InputTokens = int(resp.UsageMetadata.PromptTokenCount)
+			usage.OutputTokens = int(resp.UsageMetadata.CandidatesTokenCount)
+		}
+
 		// Extract text from response
 		for _, cand := range resp.Candidates {
 			if cand.Content != nil {
@@ -329,7 +474,7 @@ func (s *geminiService) GenerateStreamingResponse(ctx context.Context, storeID,
 						case <-ctx.Done():
 							log.Printf("[Gemini] Query Filtering: CANCELLED - Context cancelled during streaming for graph '%s' after %d chunks",
 								graphID, chunkCount)
-							return ctx.Err()
+							return usage, ctx.Err()
 						}
 					}
 				}
@@ -340,27 +485,51 @@ func (s *geminiService) GenerateStreamingResponse(ctx context.Context, storeID,
 	// Check if we got any chunks - if yes, consider it a success even if there was an error at the end
 	if chunkCount > 0 {
 		// Log streaming completion
-		log.Printf("[Gemini] Query Filtering: SUCCESS - Streaming complete for graph '%s' | Total chunks: %d | Filter: [graph_id=%s, domain=%s, version=%s]",
-			graphID, chunkCount, graphID, domain, version)
+		log.Printf("[Gemini] Query Filtering: SUCCESS - Streaming complete for graph '%s' | Total chunks: %d | Tokens: %d in, %d out | Filter: [graph_id=%s, domain=%s, version=%s]",
+			graphID, chunkCount, usage.InputTokens, usage.OutputTokens, graphID, domain, version)
 
 		// If there was an error but we got chunks, log it but don't fail
 		if lastErr != nil {
 			log.Printf("[Gemini] Query Filtering: NOTE - Iterator returned error after successful streaming (this is normal): %v", lastErr)
 		}
 
-		return nil
+		return usage, nil
 	}
 
 	// No chunks received - this is a real error
 	if lastErr != nil {
 		log.Printf("[Gemini] Query Filtering: ERROR - No chunks received and iterator returned error for graph '%s': %v",
 			graphID, lastErr)
-		return fmt.Errorf("%w: %v", ErrGeminiQueryFailed, lastErr)
+		return usage, fmt.Errorf("%w: %v", ErrGeminiQueryFailed, lastErr)
 	}
 
 	// No chunks and no error - empty response
 	log.Printf("[Gemini] Query Filtering: WARNING - No chunks received for graph '%s' (empty response)", graphID)
-	return nil
+	return usage, nil
+}
+
+// graphIDFilter builds the metadata filter term matching any of graphIDs. The filter
+// syntax has no IN operator, so several graphs are combined with OR.
+func graphIDFilter(graphIDs []string) string {
+	terms := make([]string, len(graphIDs))
+	for i, graphID := range graphIDs {
+		terms[i] = fmt.Sprintf(`graph_id = "%s"`, escapeFilterValue(graphID))
+	}
+
+	if len(terms) == 1 {
+		return terms[0]
+	}
+	return "(" + strings.Join(terms, " OR ") + ")"
+}
+
+// excludedDocumentsFilter builds the metadata filter terms leaving out each of documentIDs,
+// each preceded by AND so they can follow the rest of the filter
+func excludedDocumentsFilter(documentIDs []string) string {
+	var terms strings.Builder
+	for _, documentID := range documentIDs {
+		fmt.Fprintf(&terms, ` AND document_id != "%s"`, escapeFilterValue(documentID))
+	}
+	return terms.String()
 }
 
 // escapeFilterValue escapes special characters in metadata filter values
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/models"
+	"github.com/bipulkrdas/orgmind/backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// reindexStaleAfter is how long a running reindex job may go without progress before it is
+// considered interrupted, such as by a server restart, and a new one may start
+const reindexStaleAfter = time.Hour
+
+// Custom errors for graph reindexing
+var (
+	ErrReindexInProgress  = fmt.Errorf("a reindex of this graph is already running")
+	ErrReindexJobNotFound = fmt.Errorf("graph has not been reindexed")
+	ErrReextractFormat    = fmt.Errorf("content type is not a supported upload format")
+)
+
+// ReindexGraph starts rebuilding a graph's Zep data from the stored content of its
+// documents and returns the job tracking the rebuild. With clearGraph the Zep graph is
+// emptied first, so data Zep kept from deleted or changed documents is dropped too.
+// Documents still being processed are skipped, since their processing adds them anyway.
+// Only the graph creator may reindex.
+func (s *documentService) ReindexGraph(ctx context.Context, graphID, userID string, clearGraph bool) (*models.GraphReindexJob, error) {
+	gr, err := s.verifyCreator(ctx, graphID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.failStaleReindexJobs(ctx, graphID); err != nil {
+		return nil, err
+	}
+
+	docs, err := s.documentRepo.ListByGraphID(ctx, graphID, models.DocumentFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list graph documents: %w", err)
+	}
+
+	var pending []*models.Document
+	for _, doc := range docs {
+		if doc.Status != "processing" {
+			pending = append(pending, doc)
+		}
+	}
+
+	return s.startReindexJob(ctx, gr, &models.GraphReindexJob{
+		RequestedBy: userID,
+		ClearGraph:  clearGraph,
+	}, pending)
+}
+
+// ReextractAll starts re-extracting a graph's uploaded documents whose text was extracted
+// by another version of the extraction logic or with other output-affecting options (see
+// extraction.ExtractionService.Version), limited to
+// one content type unless contentType is empty. Each document is downloaded, extracted
+// again, and its preview, metadata and Zep data replaced, in a reindex job that reports the
+// progress. Documents still being processed are skipped. Only the graph creator may re-extract.
+func (s *documentService) ReextractAll(ctx context.Context, graphID, userID, contentType string) (*models.GraphReindexJob, error) {
+	if contentType != "" {
+		contentType = s.extractionService.CanonicalContentType(contentType)
+		if !s.extractionService.IsSupported(contentType) {
+			return nil, fmt.Errorf("%w: %s", ErrReextractFormat, contentType)
+		}
+	}
+
+	gr, err := s.verifyCreator(ctx, graphID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.failStaleReindexJobs(ctx, graphID); err != nil {
+		return nil, err
+	}
+
+	docs, err := s.documentRepo.ListByGraphID(ctx, graphID, models.DocumentFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list graph documents: %w", err)
+	}
+
+	version := s.extractionService.Version()
+	var stale []*models.Document
+	for _, doc := range docs {
+		if doc.Source != "upload" || doc.Status == "processing" || doc.ExtractorVersion == version {
+			continue
+		}
+		// Documents uploaded before aliases were resolved may be stored under a nonstandard type
+		if contentType != "" && (doc.ContentType == nil || s.extractionService.CanonicalContentType(*doc.ContentType) != contentType) {
+			continue
+		}
+		stale = append(stale, doc)
+	}
+
+	job := &models.GraphReindexJob{
+		RequestedBy: userID,
+		Reextract:   true,
+	}
+	if contentType != "" {
+		job.ContentType = &contentType
+	}
+	return s.startReindexJob(ctx, gr, job, stale)
+}
+
+// verifyCreator checks that the graph exists and that userID created it
+func (s *documentService) verifyCreator(ctx context.Context, graphID, userID string) (*models.Graph, error) {
+	graph, err := s.graphRepo.GetByID(ctx, graphID)
+	if err != nil {
+		if errors.Is(err, repository.ErrGraphNotFound) {
+			return nil, ErrGraphNotFound
+		}
+		return nil, fmt.Errorf("failed to get graph: %w", err)
+	}
+
+	if graph.CreatorID != userID {
+		return nil, ErrNotGraphCreator
+	}
+
+	return graph, nil
+}
+
+// startReindexJob records a new running job for reprocessing docs, then reprocesses them
+// in the background
+func (s *documentService) startReindexJob(ctx context.Context, gr *models.Graph, job *models.GraphReindexJob, docs []*models.Document) (*models.GraphReindexJob, error) {
+	now := time.Now().UTC()
+	job.ID = uuid.New().String()
+	job.GraphID = gr.ID
+	job.Status = models.ReindexStatusRunning
+	job.TotalDocuments = len(docs)
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	if err := s.graphRepo.CreateReindexJob(ctx, job); err != nil {
+		if errors.Is(err, repository.ErrReindexJobRunning) {
+			return nil, ErrReindexInProgress
+		}
+		return nil, err
+	}
+
+	// The job runs on its own copy, so the returned job isn't modified while the caller uses it
+	running := *job
+	go s.runReindex(context.Background(), &running, gr, docs)
+
+	return job, nil
+}
+
+// GetReindexJob returns the latest reindex job of a graph
+func (s *documentService) GetReindexJob(ctx context.Context, graphID string) (*models.GraphReindexJob, error) {
+	if err := s.failStaleReindexJobs(ctx, graphID); err != nil {
+		return nil, err
+	}
+
+	job, err := s.graphRepo.GetLatestReindexJob(ctx, graphID)
+	if err != nil {
+		if errors.Is(err, repository.ErrReindexJobNotFound) {
+			return nil, ErrReindexJobNotFound
+		}
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// runReindex reprocesses the documents of a reindex job through the bulk pool, recording
+// progress as each one finishes. A document that fails is counted and scheduled for retry
+// like any failed document, without stopping the job; only failing to clear the Zep graph
+// fails the job.
+func (s *documentService) runReindex(ctx context.Context, job *models.GraphReindexJob, gr *models.Graph, docs []*models.Document) {
+	if job.ClearGraph {
+		if err := s.graphService.ClearZepGraph(ctx, gr); err != nil {
+			fmt.Printf("Error clearing Zep graph for reindex of graph %s: %v\n", gr.ID, err)
+			s.finishReindex(ctx, job, "The knowledge graph could not be cleared.")
+			return
+		}
+	}
+
+	// Guards the job's progress, which documents finishing together update
+	var mu sync.Mutex
+
+	s.bulkPool.Run(ctx, len(docs), func(i int) {
+		doc := docs[i]
+		doc.Status = "processing"
+		doc.ErrorMessage = nil
+		doc.UpdatedAt = time.Now().UTC()
+		if err := s.documentRepo.Update(ctx, doc); err != nil {
+			fmt.Printf("Warning: failed to update status of document %s: %v\n", doc.ID, err)
+		}
+
+		err := s.reprocessFromStorage(ctx, doc)
+		if err != nil {
+			fmt.Printf("Error reindexing document %s: %v\n", doc.ID, err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if err != nil {
+			job.FailedDocuments++
+		}
+		job.ProcessedDocuments++
+
+		job.UpdatedAt = time.Now().UTC()
+		if err := s.graphRepo.UpdateReindexJob(ctx, job); err != nil {
+			fmt.Printf("Warning: failed to record progress of reindex job %s: %v\n", job.ID, err)
+		}
+	})
+
+	s.finishReindex(ctx, job, "")
+}
+
+// finishReindex records the end of a reindex job, as failed if errorMessage is set
+func (s *documentService) finishReindex(ctx context.Context, job *models.GraphReindexJob, errorMessage string) {
+	now := time.Now().UTC()
+	job.Status = models.ReindexStatusCompleted
+	if errorMessage != "" {
+		job.Status = models.ReindexStatusFailed
+		job.ErrorMessage = &errorMessage
+	}
+	job.UpdatedAt = now
+	job.FinishedAt = &now
+
+	if err := s.graphRepo.UpdateReindexJob(ctx, job); err != nil {
+		fmt.Printf("Warning: failed to record end of reindex job %s: %v\n", job.ID, err)
+	}
+}
+
+// failStaleReindexJobs fails a graph's running reindex jobs that stopped making progress
+func (s *documentService) failStaleReindexJobs(ctx context.Context, graphID string) error {
+	updatedBefore := time.Now().UTC().Add(-reindexStaleAfter)
+	if err := s.graphRepo.FailStaleReindexJobs(ctx, graphID, updatedBefore, "The reindex was interrupted."); err != nil {
+		return fmt.Errorf("failed to check for interrupted reindex jobs: %w", err)
+	}
+	return nil
+}
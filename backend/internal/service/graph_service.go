@@ -2,11 +2,15 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/bipulkrdas/orgmind/backend/internal/models"
 	"github.com/bipulkrdas/orgmind/backend/internal/repository"
+	"github.com/bipulkrdas/orgmind/backend/pkg/utils"
 	"github.com/google/uuid"
 )
 
@@ -19,27 +23,108 @@ var (
 	ErrMemberAlreadyExists = fmt.Errorf("user is already a member of this graph")
 	ErrZepGraphCreation    = fmt.Errorf("failed to create graph in Zep Cloud")
 	ErrZepGraphDeletion    = fmt.Errorf("failed to delete graph from Zep Cloud")
+	ErrGraphLimitReached   = fmt.Errorf("you have reached the maximum number of graphs")
+	ErrDuplicateGraphName  = fmt.Errorf("you already have a graph with this name")
+
+	ErrInvalidIdempotencyKey = fmt.Errorf("idempotency key must be at most %d characters", MaxIdempotencyKeyLength)
+
+	ErrInvalidAllowedContentTypes = fmt.Errorf("allowed content types must be at most %d MIME types such as application/pdf", MaxAllowedContentTypes)
+
+	ErrInvalidBulkMembers = fmt.Errorf("between 1 and %d members are required", MaxBulkMembers)
+
+	ErrInvalidRole = fmt.Errorf("role must be one of: %s", strings.Join(memberRoles, ", "))
+
+	ErrInvalidGraphRoleFilter = fmt.Errorf("role filter must be one of: %s, %s", models.GraphRoleOwner, models.GraphRoleMember)
+
+	ErrShareLinkNotFound      = fmt.Errorf("share link not found")
+	ErrInvalidShareLink       = fmt.Errorf("share link is invalid, expired or revoked")
+	ErrInvalidShareLinkExpiry = fmt.Errorf("share link expiry must be in the future")
 )
 
+// memberRoles are the roles members can be given; the owner role is reserved for a graph's creator
+var memberRoles = []string{models.RoleMember, models.RoleEditor, models.RoleViewer}
+
+const (
+	// MaxIdempotencyKeyLength is the maximum length of an Idempotency-Key header
+	MaxIdempotencyKeyLength = 255
+
+	// MaxAllowedContentTypes is the maximum number of entries in a graph's allowed content types
+	MaxAllowedContentTypes = 50
+
+	// MaxBulkMembers is the maximum number of members added in a single bulk request
+	MaxBulkMembers = 100
+)
+
+// graphIDNamespace derives graph IDs from idempotency keys, so a retried creation maps to the same graph
+var graphIDNamespace = uuid.MustParse("5b0c6a3e-8f1d-4d7a-9c2e-1f4b8a6d3e70")
+
 // graphService implements the GraphService interface
 type graphService struct {
-	graphRepo repository.GraphRepository
-	zepSvc    ZepService
+	graphRepo        repository.GraphRepository
+	userRepo         repository.UserRepository
+	txManager        repository.TxManager
+	zepSvc           ZepService
+	maxGraphsPerUser int    // 0 = unlimited
+	zepGraphIDPrefix string // Prepended to graph IDs to form their Zep graph IDs
+	uniqueGraphNames bool   // Reject graph names the creator already uses
+
+	// Signs and verifies public share link tokens
+	jwtKeys *utils.JWTKeys
 }
 
-// NewGraphService creates a new graph service instance
-func NewGraphService(graphRepo repository.GraphRepository, zepSvc ZepService) GraphService {
+// NewGraphService creates a new graph service instance.
+// maxGraphsPerUser caps the graphs each user can create; 0 disables the limit.
+// zepGraphIDPrefix namespaces the Zep graphs it creates, e.g. per environment.
+// uniqueGraphNames rejects creating or renaming a graph to a name its creator already uses.
+// jwtKeys signs the tokens of public share links.
+func NewGraphService(graphRepo repository.GraphRepository, userRepo repository.UserRepository, txManager repository.TxManager, zepSvc ZepService, maxGraphsPerUser int, zepGraphIDPrefix string, uniqueGraphNames bool, jwtKeys *utils.JWTKeys) GraphService {
 	return &graphService{
-		graphRepo: graphRepo,
-		zepSvc:    zepSvc,
+		graphRepo:        graphRepo,
+		userRepo:         userRepo,
+		txManager:        txManager,
+		zepSvc:           zepSvc,
+		maxGraphsPerUser: maxGraphsPerUser,
+		zepGraphIDPrefix: zepGraphIDPrefix,
+		uniqueGraphNames: uniqueGraphNames,
+		jwtKeys:          jwtKeys,
+	}
+}
+
+// checkGraphName rejects a name the creator already uses for another graph than excludeGraphID,
+// when unique graph names are enforced. Names are compared ignoring case and surrounding spaces.
+func (s *graphService) checkGraphName(ctx context.Context, creatorID, name, excludeGraphID string) error {
+	if !s.uniqueGraphNames {
+		return nil
+	}
+
+	exists, err := s.graphRepo.ExistsByCreatorAndName(ctx, creatorID, name, excludeGraphID)
+	if err != nil {
+		return fmt.Errorf("failed to check graph name: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("%w: %q", ErrDuplicateGraphName, strings.TrimSpace(name))
+	}
+
+	return nil
+}
+
+// getGraph retrieves a graph, translating the repository's not-found error to ErrGraphNotFound
+func (s *graphService) getGraph(ctx context.Context, graphID string) (*models.Graph, error) {
+	graph, err := s.graphRepo.GetByID(ctx, graphID)
+	if err != nil {
+		if errors.Is(err, repository.ErrGraphNotFound) {
+			return nil, ErrGraphNotFound
+		}
+		return nil, fmt.Errorf("failed to get graph: %w", err)
 	}
+	return graph, nil
 }
 
 // verifyMembership checks if user is a member of the graph
 func (s *graphService) verifyMembership(ctx context.Context, graphID, userID string) (*models.Graph, error) {
-	graph, err := s.graphRepo.GetByID(ctx, graphID)
+	graph, err := s.getGraph(ctx, graphID)
 	if err != nil {
-		return nil, ErrGraphNotFound
+		return nil, err
 	}
 
 	isMember, err := s.graphRepo.IsMember(ctx, graphID, userID)
@@ -56,9 +141,9 @@ func (s *graphService) verifyMembership(ctx context.Context, graphID, userID str
 
 // verifyCreator checks if user is the creator of the graph
 func (s *graphService) verifyCreator(ctx context.Context, graphID, userID string) (*models.Graph, error) {
-	graph, err := s.graphRepo.GetByID(ctx, graphID)
+	graph, err := s.getGraph(ctx, graphID)
 	if err != nil {
-		return nil, ErrGraphNotFound
+		return nil, err
 	}
 
 	if graph.CreatorID != userID {
@@ -68,14 +153,58 @@ func (s *graphService) verifyCreator(ctx context.Context, graphID, userID string
 	return graph, nil
 }
 
-// Create creates a new graph in Zep Cloud, saves to DB, and creates owner membership
+// Create creates a new graph in Zep Cloud, saves to DB, and creates owner membership.
+// With an idempotency key the graph ID is derived from the creator and key, so a retry
+// returns the graph if it was created, or resumes from the Zep graph a failed attempt left behind.
 func (s *graphService) Create(ctx context.Context, creatorID string, req *models.CreateGraphRequest) (*models.Graph, error) {
+	if len(req.IdempotencyKey) > MaxIdempotencyKeyLength {
+		return nil, ErrInvalidIdempotencyKey
+	}
+
+	allowedContentTypes, err := normalizeAllowedContentTypes(req.AllowedContentTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultMemberRole, err := resolveMemberRole(req.DefaultMemberRole, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate a unique graph ID
 	graphID := uuid.New().String()
+	if req.IdempotencyKey != "" {
+		graphID = uuid.NewSHA1(graphIDNamespace, []byte(creatorID+":"+req.IdempotencyKey)).String()
+
+		// An earlier request with this key already completed
+		existing, err := s.graphRepo.GetByID(ctx, graphID)
+		if err != nil && !errors.Is(err, repository.ErrGraphNotFound) {
+			return nil, fmt.Errorf("failed to get graph: %w", err)
+		}
+		if err == nil && existing.CreatorID == creatorID {
+			return existing, nil
+		}
+	}
+
+	// Check the limit before creating anything in Zep, since each graph uses Zep quota
+	if s.maxGraphsPerUser > 0 {
+		count, err := s.graphRepo.CountByCreatorID(ctx, creatorID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count graphs: %w", err)
+		}
+		if count >= s.maxGraphsPerUser {
+			return nil, fmt.Errorf("%w (%d)", ErrGraphLimitReached, s.maxGraphsPerUser)
+		}
+	}
+
+	if err := s.checkGraphName(ctx, creatorID, req.Name, ""); err != nil {
+		return nil, err
+	}
 
 	// Step 1: Create graph in Zep Cloud FIRST (critical dependency)
-	// If Zep creation fails, we don't create database records
-	zepGraphID := fmt.Sprintf("graph-%s", graphID)
+	// If Zep creation fails, we don't create database records.
+	// The Zep graph ID is derived from the graph ID, so a retry finds the graph an earlier attempt created.
+	zepGraphID := s.zepGraphIDPrefix + graphID
 
 	actualZepGraphID, err := s.zepSvc.CreateGraph(ctx, zepGraphID, req.Name, req.Description)
 	if err != nil {
@@ -90,20 +219,19 @@ func (s *graphService) Create(ctx context.Context, creatorID string, req *models
 	// Step 2: Create graph record in database
 	now := time.Now()
 	graph := &models.Graph{
-		ID:            graphID,
-		CreatorID:     creatorID,
-		ZepGraphID:    zepGraphID,
-		Name:          req.Name,
-		Description:   req.Description,
-		DocumentCount: 0,
-		CreatedAt:     now,
-		UpdatedAt:     now,
-	}
+		ID:             graphID,
+		CreatorID:      creatorID,
+		ZepGraphID:     zepGraphID,
+		Name:           req.Name,
+		Description:    req.Description,
+		DocumentCount:  0,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		LastActivityAt: now,
 
-	if err := s.graphRepo.Create(ctx, graph); err != nil {
-		// Rollback: delete from Zep if database creation fails
-		_ = s.zepSvc.DeleteGraph(ctx, zepGraphID)
-		return nil, fmt.Errorf("failed to create graph in database: %w", err)
+		AllowedContentTypes: allowedContentTypes,
+		DefaultMemberRole:   defaultMemberRole,
+		ChatEnabled:         req.ChatEnabled == nil || *req.ChatEnabled,
 	}
 
 	// Step 3: Create owner membership for the creator
@@ -111,15 +239,34 @@ func (s *graphService) Create(ctx context.Context, creatorID string, req *models
 		ID:        uuid.New().String(),
 		GraphID:   graphID,
 		UserID:    creatorID,
-		Role:      "owner",
+		Role:      models.RoleOwner,
 		CreatedAt: now,
 	}
 
-	if err := s.graphRepo.CreateMembership(ctx, membership); err != nil {
-		// Rollback: delete both database record and Zep graph
-		_ = s.graphRepo.Delete(ctx, graphID)
-		_ = s.zepSvc.DeleteGraph(ctx, zepGraphID)
-		return nil, fmt.Errorf("failed to create owner membership: %w", err)
+	if err := membership.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid membership: %w", err)
+	}
+
+	// The graph record and owner membership are written atomically
+	err = s.txManager.WithTx(ctx, func(txCtx context.Context) error {
+		if err := s.graphRepo.Create(txCtx, graph); err != nil {
+			return fmt.Errorf("failed to create graph in database: %w", err)
+		}
+		if err := s.graphRepo.CreateMembership(txCtx, membership); err != nil {
+			return fmt.Errorf("failed to create owner membership: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		// Rollback: delete from Zep if database creation fails. With an idempotency key the
+		// Zep graph is kept, since it may belong to a concurrent request with the same key,
+		// and a retry with the key reuses it.
+		if req.IdempotencyKey == "" {
+			if deleteErr := s.zepSvc.DeleteGraph(ctx, zepGraphID); deleteErr != nil {
+				fmt.Printf("Warning: failed to delete Zep graph %s after failed creation: %v\n", zepGraphID, deleteErr)
+			}
+		}
+		return nil, err
 	}
 
 	return graph, nil
@@ -130,14 +277,29 @@ func (s *graphService) GetByID(ctx context.Context, graphID, userID string) (*mo
 	return s.verifyMembership(ctx, graphID, userID)
 }
 
-// ListByUserID returns all graphs the user is a member of
-func (s *graphService) ListByUserID(ctx context.Context, userID string) ([]*models.Graph, error) {
-	graphs, err := s.graphRepo.ListByUserID(ctx, userID)
+// ListByUserID returns all graphs the user is a member of, in the requested order
+func (s *graphService) ListByUserID(ctx context.Context, userID string, filter models.GraphFilter) ([]*models.Graph, int, error) {
+	if err := validateListSort(filter.Sort, models.SortByName, models.SortByCreated, models.SortByUpdated, models.SortByActivity); err != nil {
+		return nil, 0, err
+	}
+
+	switch filter.Role {
+	case "", models.GraphRoleOwner, models.GraphRoleMember:
+	default:
+		return nil, 0, ErrInvalidGraphRoleFilter
+	}
+
+	graphs, err := s.graphRepo.ListByUserID(ctx, userID, filter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list graphs: %w", err)
+		return nil, 0, fmt.Errorf("failed to list graphs: %w", err)
 	}
 
-	return graphs, nil
+	total, err := s.graphRepo.CountByUserID(ctx, userID, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count graphs: %w", err)
+	}
+
+	return graphs, total, nil
 }
 
 // Update updates graph metadata (creator only)
@@ -150,11 +312,31 @@ func (s *graphService) Update(ctx context.Context, graphID, userID string, req *
 
 	// Update fields if provided
 	if req.Name != nil {
+		if err := s.checkGraphName(ctx, graph.CreatorID, *req.Name, graph.ID); err != nil {
+			return nil, err
+		}
 		graph.Name = *req.Name
 	}
 	if req.Description != nil {
 		graph.Description = req.Description
 	}
+	if req.AllowedContentTypes != nil {
+		allowed, err := normalizeAllowedContentTypes(*req.AllowedContentTypes)
+		if err != nil {
+			return nil, err
+		}
+		graph.AllowedContentTypes = allowed
+	}
+	if req.DefaultMemberRole != nil {
+		role, err := resolveMemberRole(*req.DefaultMemberRole, models.RoleMember)
+		if err != nil {
+			return nil, err
+		}
+		graph.DefaultMemberRole = role
+	}
+	if req.ChatEnabled != nil {
+		graph.ChatEnabled = *req.ChatEnabled
+	}
 	graph.UpdatedAt = time.Now()
 
 	// Save to database
@@ -162,6 +344,14 @@ func (s *graphService) Update(ctx context.Context, graphID, userID string, req *
 		return nil, fmt.Errorf("failed to update graph: %w", err)
 	}
 
+	// Keep Zep's graph metadata in sync with ours
+	if req.Name != nil || req.Description != nil {
+		if err := s.zepSvc.UpdateGraph(ctx, graph.ZepGraphID, graph.Name, graph.Description); err != nil {
+			// The database is the source of truth for graph metadata, so don't fail the update
+			fmt.Printf("Warning: failed to update graph %s in Zep: %v\n", graph.ID, err)
+		}
+	}
+
 	return graph, nil
 }
 
@@ -191,7 +381,13 @@ func (s *graphService) Delete(ctx context.Context, graphID, userID string) error
 // AddMember adds a member to a graph (creator only)
 func (s *graphService) AddMember(ctx context.Context, graphID, creatorID string, req *models.AddMemberRequest) error {
 	// Verify user is the creator
-	_, err := s.verifyCreator(ctx, graphID, creatorID)
+	graph, err := s.verifyCreator(ctx, graphID, creatorID)
+	if err != nil {
+		return err
+	}
+
+	// Use the graph's default role if none is given
+	role, err := resolveMemberRole(req.Role, graph.DefaultMemberRole)
 	if err != nil {
 		return err
 	}
@@ -205,12 +401,6 @@ func (s *graphService) AddMember(ctx context.Context, graphID, creatorID string,
 		return ErrMemberAlreadyExists
 	}
 
-	// Set default role if not provided
-	role := req.Role
-	if role == "" {
-		role = "member"
-	}
-
 	// Create membership
 	membership := &models.GraphMembership{
 		ID:        uuid.New().String(),
@@ -220,6 +410,10 @@ func (s *graphService) AddMember(ctx context.Context, graphID, creatorID string,
 		CreatedAt: time.Now(),
 	}
 
+	if err := membership.Validate(); err != nil {
+		return fmt.Errorf("invalid membership: %w", err)
+	}
+
 	if err := s.graphRepo.CreateMembership(ctx, membership); err != nil {
 		return fmt.Errorf("failed to add member: %w", err)
 	}
@@ -227,6 +421,93 @@ func (s *graphService) AddMember(ctx context.Context, graphID, creatorID string,
 	return nil
 }
 
+// AddMembers adds several members to a graph at once (creator only), identifying each by user
+// ID or email. Entries are processed independently, so one bad entry doesn't stop the rest:
+// users who are already members are skipped, and unknown users are reported as not found.
+// The results are in the same order as the entries.
+func (s *graphService) AddMembers(ctx context.Context, graphID, creatorID string, req *models.AddMembersRequest) ([]models.AddMemberResult, error) {
+	if len(req.Members) == 0 || len(req.Members) > MaxBulkMembers {
+		return nil, ErrInvalidBulkMembers
+	}
+
+	// Verify user is the creator
+	graph, err := s.verifyCreator(ctx, graphID, creatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.AddMemberResult, len(req.Members))
+	for i, entry := range req.Members {
+		results[i] = s.addMembersEntry(ctx, graph, entry)
+	}
+	return results, nil
+}
+
+// addMembersEntry adds the user of one bulk entry to a graph and reports the outcome
+func (s *graphService) addMembersEntry(ctx context.Context, graph *models.Graph, entry models.AddMembersEntry) models.AddMemberResult {
+	graphID := graph.ID
+	userID := strings.TrimSpace(entry.UserID)
+	email := strings.TrimSpace(entry.Email)
+	result := models.AddMemberResult{UserID: userID, Email: email, Role: entry.Role}
+
+	role, err := resolveMemberRole(entry.Role, graph.DefaultMemberRole)
+	if err != nil || (userID == "") == (email == "") {
+		result.Status = models.AddMemberResultInvalid
+		return result
+	}
+	result.Role = role
+
+	var user *models.User
+	if userID != "" {
+		user, err = s.userRepo.GetByID(ctx, userID)
+	} else {
+		user, err = s.userRepo.GetByEmail(ctx, email)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			result.Status = models.AddMemberResultNotFound
+		} else {
+			fmt.Printf("Warning: failed to look up user for bulk member addition to graph %s: %v\n", graphID, err)
+			result.Status = models.AddMemberResultFailed
+		}
+		return result
+	}
+	result.UserID = user.ID
+
+	// Also catches users listed twice in the same request
+	isMember, err := s.graphRepo.IsMember(ctx, graphID, user.ID)
+	if err != nil {
+		fmt.Printf("Warning: failed to check membership of user %s in graph %s: %v\n", user.ID, graphID, err)
+		result.Status = models.AddMemberResultFailed
+		return result
+	}
+	if isMember {
+		result.Status = models.AddMemberResultAlreadyMember
+		return result
+	}
+
+	membership := &models.GraphMembership{
+		ID:        uuid.New().String(),
+		GraphID:   graphID,
+		UserID:    user.ID,
+		Role:      result.Role,
+		CreatedAt: time.Now(),
+	}
+	if err := membership.Validate(); err != nil {
+		fmt.Printf("Warning: invalid membership for user %s in graph %s: %v\n", user.ID, graphID, err)
+		result.Status = models.AddMemberResultInvalid
+		return result
+	}
+	if err := s.graphRepo.CreateMembership(ctx, membership); err != nil {
+		fmt.Printf("Warning: failed to add user %s to graph %s: %v\n", user.ID, graphID, err)
+		result.Status = models.AddMemberResultFailed
+		return result
+	}
+
+	result.Status = models.AddMemberResultAdded
+	return result
+}
+
 // RemoveMember removes a member from a graph (creator only)
 func (s *graphService) RemoveMember(ctx context.Context, graphID, creatorID, memberUserID string) error {
 	// Verify user is the creator
@@ -248,21 +529,25 @@ func (s *graphService) RemoveMember(ctx context.Context, graphID, creatorID, mem
 	return nil
 }
 
-// ListMembers lists all members of a graph (requires membership)
-func (s *graphService) ListMembers(ctx context.Context, graphID, userID string) ([]*models.GraphMembership, error) {
+// ListMembers lists a page of a graph's members and counts them all (requires membership)
+func (s *graphService) ListMembers(ctx context.Context, graphID, userID string, limit, offset int) ([]*models.GraphMembership, int, error) {
 	// Verify user is a member
 	_, err := s.verifyMembership(ctx, graphID, userID)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	// Get all members
-	members, err := s.graphRepo.ListMembersByGraphID(ctx, graphID)
+	members, err := s.graphRepo.ListMembersByGraphID(ctx, graphID, limit, offset)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list members: %w", err)
+		return nil, 0, fmt.Errorf("failed to list members: %w", err)
 	}
 
-	return members, nil
+	total, err := s.graphRepo.CountMembersByGraphID(ctx, graphID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count members: %w", err)
+	}
+
+	return members, total, nil
 }
 
 // IsMember checks if user is a member of a graph
@@ -275,6 +560,39 @@ func (s *graphService) IsMember(ctx context.Context, graphID, userID string) (bo
 	return isMember, nil
 }
 
+// AddFavorite pins a graph to the top of the user's graph list
+func (s *graphService) AddFavorite(ctx context.Context, graphID, userID string) error {
+	if _, err := s.verifyMembership(ctx, graphID, userID); err != nil {
+		return err
+	}
+
+	if err := s.graphRepo.AddFavorite(ctx, graphID, userID); err != nil {
+		return fmt.Errorf("failed to add favorite: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveFavorite unpins a graph for the user. Membership is not required, so users
+// removed from a graph can still clear their favorite.
+func (s *graphService) RemoveFavorite(ctx context.Context, graphID, userID string) error {
+	if err := s.graphRepo.RemoveFavorite(ctx, graphID, userID); err != nil {
+		return fmt.Errorf("failed to remove favorite: %w", err)
+	}
+
+	return nil
+}
+
+// IsFavorite checks if the user has pinned a graph
+func (s *graphService) IsFavorite(ctx context.Context, graphID, userID string) (bool, error) {
+	isFavorite, err := s.graphRepo.IsFavorite(ctx, graphID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check favorite: %w", err)
+	}
+
+	return isFavorite, nil
+}
+
 // IncrementDocumentCount increments the document count for a graph
 func (s *graphService) IncrementDocumentCount(ctx context.Context, graphID string) error {
 	if err := s.graphRepo.UpdateDocumentCount(ctx, graphID, 1); err != nil {
@@ -292,3 +610,173 @@ func (s *graphService) DecrementDocumentCount(ctx context.Context, graphID strin
 
 	return nil
 }
+
+// ClearZepGraph deletes a graph's Zep graph and creates it again empty, under the same ID
+func (s *graphService) ClearZepGraph(ctx context.Context, graph *models.Graph) error {
+	if err := s.zepSvc.DeleteGraph(ctx, graph.ZepGraphID); err != nil {
+		// The Zep graph might already be deleted, in which case it only needs creating
+		fmt.Printf("Warning: failed to delete graph %s from Zep before recreating it: %v\n", graph.ID, err)
+	}
+
+	actualZepGraphID, err := s.zepSvc.CreateGraph(ctx, graph.ZepGraphID, graph.Name, graph.Description)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrZepGraphCreation, err)
+	}
+
+	if actualZepGraphID != "" && actualZepGraphID != graph.ZepGraphID {
+		return fmt.Errorf("%w: Zep assigned graph ID %s instead of %s", ErrZepGraphCreation, actualZepGraphID, graph.ZepGraphID)
+	}
+
+	return nil
+}
+
+// resolveMemberRole validates the role to give a member, using fallback when none is given
+func resolveMemberRole(role, fallback string) (string, error) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role == "" {
+		role = fallback
+	}
+	if !slices.Contains(memberRoles, role) {
+		return "", fmt.Errorf("%w, got %q", ErrInvalidRole, role)
+	}
+	return role, nil
+}
+
+// normalizeAllowedContentTypes validates a graph's allowed content types, lowercasing them,
+// dropping parameters such as charset and removing duplicates. The result is never nil,
+// since the column is NOT NULL and an empty list means every supported format is allowed.
+func normalizeAllowedContentTypes(contentTypes []string) ([]string, error) {
+	if len(contentTypes) > MaxAllowedContentTypes {
+		return nil, ErrInvalidAllowedContentTypes
+	}
+
+	normalized := make([]string, 0, len(contentTypes))
+	seen := make(map[string]bool, len(contentTypes))
+	for _, contentType := range contentTypes {
+		contentType = mimeType(contentType)
+
+		mainType, subType, ok := strings.Cut(contentType, "/")
+		if !ok || mainType == "" || subType == "" || strings.ContainsAny(subType, "/ \t") || strings.ContainsAny(mainType, " \t") {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidAllowedContentTypes, contentType)
+		}
+		if seen[contentType] {
+			continue
+		}
+		seen[contentType] = true
+		normalized = append(normalized, contentType)
+	}
+
+	return normalized, nil
+}
+
+// mimeType reduces a content type to its lowercase MIME type, without parameters
+func mimeType(contentType string) string {
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// CreateShareLink creates a read-only public share link for a graph (creator only) and
+// returns it with its signed token. The token is only returned here; a lost token can't be
+// recovered, only revoked and replaced. A nil expiresAt gives a link that lasts until revoked.
+func (s *graphService) CreateShareLink(ctx context.Context, graphID, ownerID string, expiresAt *time.Time) (*models.GraphShareLink, string, error) {
+	if _, err := s.verifyCreator(ctx, graphID, ownerID); err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now().UTC()
+	if expiresAt != nil {
+		if !expiresAt.After(now) {
+			return nil, "", ErrInvalidShareLinkExpiry
+		}
+		utc := expiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	link := &models.GraphShareLink{
+		ID:        uuid.New().String(),
+		GraphID:   graphID,
+		CreatedBy: ownerID,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}
+
+	token, err := utils.GenerateShareToken(link.ID, graphID, s.jwtKeys, expiresAt)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to sign share link: %w", err)
+	}
+
+	if err := s.graphRepo.CreateShareLink(ctx, link); err != nil {
+		return nil, "", err
+	}
+
+	return link, token, nil
+}
+
+// ListShareLinks lists a page of a graph's share links, including revoked and expired ones,
+// and counts them all (creator only)
+func (s *graphService) ListShareLinks(ctx context.Context, graphID, ownerID string, limit, offset int) ([]*models.GraphShareLink, int, error) {
+	if _, err := s.verifyCreator(ctx, graphID, ownerID); err != nil {
+		return nil, 0, err
+	}
+
+	links, err := s.graphRepo.ListShareLinksByGraphID(ctx, graphID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.graphRepo.CountShareLinksByGraphID(ctx, graphID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return links, total, nil
+}
+
+// RevokeShareLink stops a graph's share link from working (creator only)
+func (s *graphService) RevokeShareLink(ctx context.Context, graphID, ownerID, linkID string) error {
+	if _, err := s.verifyCreator(ctx, graphID, ownerID); err != nil {
+		return err
+	}
+
+	if err := s.graphRepo.RevokeShareLink(ctx, graphID, linkID, time.Now().UTC()); err != nil {
+		if errors.Is(err, repository.ErrShareLinkNotFound) {
+			return ErrShareLinkNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ResolveShareLink returns the graph a share link token grants read-only access to. The
+// signature, expiry and revocation are all checked; any failure is ErrInvalidShareLink.
+func (s *graphService) ResolveShareLink(ctx context.Context, token string) (*models.Graph, error) {
+	claims, err := utils.ValidateShareToken(token, s.jwtKeys)
+	if err != nil {
+		return nil, ErrInvalidShareLink
+	}
+
+	link, err := s.graphRepo.GetShareLink(ctx, claims.ShareLinkID)
+	if err != nil {
+		if errors.Is(err, repository.ErrShareLinkNotFound) {
+			return nil, ErrInvalidShareLink
+		}
+		return nil, err
+	}
+
+	if link.GraphID != claims.GraphID || !link.IsActive(time.Now()) {
+		return nil, ErrInvalidShareLink
+	}
+
+	graph, err := s.graphRepo.GetByID(ctx, link.GraphID)
+	if err != nil {
+		if errors.Is(err, repository.ErrGraphNotFound) {
+			return nil, ErrInvalidShareLink
+		}
+		return nil, fmt.Errorf("failed to get graph: %w", err)
+	}
+
+	return graph, nil
+}
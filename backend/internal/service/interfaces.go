@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/bipulkrdas/orgmind/backend/internal/models"
 )
@@ -14,23 +16,48 @@ type AuthService interface {
 	HandleOAuthCallback(ctx context.Context, provider, code string) (string, error)
 	ResetPassword(ctx context.Context, email string) error
 	UpdatePassword(ctx context.Context, token, newPassword string) error
+	DeleteAccount(ctx context.Context, userID, password string) error
+}
+
+// ExportService defines the interface for exporting a user's data
+type ExportService interface {
+	// Stream a ZIP archive of everything the user owns to w
+	ExportUserData(ctx context.Context, userID string, w io.Writer) error
 }
 
 // ProcessingService defines the interface for document processing operations
 type ProcessingService interface {
-	ProcessDocument(ctx context.Context, userID, graphID, documentID, content string) error
+	ProcessDocument(ctx context.Context, userID, graphID, documentID, filename, content, contentType string) error
+	// ReprocessDocument replaces the data a document added to its Zep graph with data from its new content
+	ReprocessDocument(ctx context.Context, userID, graphID, documentID, filename, content, contentType string) error
+	RemoveDocument(ctx context.Context, documentID string) error
+	// RecordFailedAttempt counts a failed attempt that never reached Zep, scheduling a retry if it was transient
+	RecordFailedAttempt(ctx context.Context, documentID string, retryable bool) error
 }
 
 // ZepService defines the interface for Zep Cloud integration
 type ZepService interface {
+	// Verify Zep is reachable and the API key is valid (for readiness checks)
+	HealthCheck(ctx context.Context) error
+
 	// Create a new graph in Zep Cloud
 	CreateGraph(ctx context.Context, graphID, name string, description *string) (string, error)
 
+	// Update a graph's name and description in Zep Cloud
+	UpdateGraph(ctx context.Context, zepGraphID, name string, description *string) error
+
 	// Delete a graph from Zep Cloud
 	DeleteGraph(ctx context.Context, zepGraphID string) error
 
-	// Add memory to a specific graph
-	AddMemory(ctx context.Context, graphID string, chunks []string, metadata map[string]any) error
+	// List every graph in the Zep project
+	ListGraphs(ctx context.Context) ([]ZepGraphInfo, error)
+
+	// Add memory to a specific graph, sending chunks concurrently as the given Zep data type.
+	// Returns the IDs of the episodes created, including those added before a failure.
+	AddMemory(ctx context.Context, graphID string, chunks []string, dataType MemoryDataType, metadata map[string]any) ([]string, error)
+
+	// Remove the episodes created from a document, and the facts derived from them
+	RemoveDocumentData(ctx context.Context, episodeIDs []string) error
 
 	// Get graph data for visualization with optional query filter
 	GetGraph(ctx context.Context, graphID, query string) (*models.GraphData, error)
@@ -41,14 +68,58 @@ type ZepService interface {
 
 // DocumentService defines the interface for document operations
 type DocumentService interface {
-	CreateFromEditor(ctx context.Context, userID, graphID, plainText, lexicalState string) (*models.Document, error)
-	CreateFromFile(ctx context.Context, userID, graphID string, file []byte, filename, contentType string) (*models.Document, error)
+	CreateFromEditor(ctx context.Context, userID, graphID, content, lexicalState, contentFormat string) (*models.Document, error)
+	CreateFromFile(ctx context.Context, userID, graphID string, file io.ReadSeeker, size int64, filename, contentType string) (*models.Document, error)
+
+	// Resumable uploads: a large file is uploaded in parts, which can be retried individually,
+	// and becomes a document like CreateFromFile's once the upload is completed (uploader only)
+	InitiateUpload(ctx context.Context, userID string, req *models.InitiateUploadRequest) (*models.UploadSession, error)
+	GetUpload(ctx context.Context, uploadID, userID string) (*models.UploadSession, []*models.UploadPart, error)
+	UploadPart(ctx context.Context, uploadID, userID string, partNumber int, content io.Reader) (*models.UploadPart, error)
+	CompleteUpload(ctx context.Context, uploadID, userID string) (*models.Document, error)
+	AbortUpload(ctx context.Context, uploadID, userID string) error
+	// CleanupExpiredUploads aborts upload sessions that expired before being completed
+	CleanupExpiredUploads(ctx context.Context) (int, error)
+
 	GetDocument(ctx context.Context, documentID, userID string) (*models.Document, error)
 	GetDocumentContent(ctx context.Context, documentID, userID string) (map[string]interface{}, error)
-	ListUserDocuments(ctx context.Context, userID string) ([]*models.Document, error)
-	ListGraphDocuments(ctx context.Context, graphID string) ([]*models.Document, error)
-	UpdateDocument(ctx context.Context, documentID, userID, plainText, lexicalState string) (*models.Document, error)
+	// Graph-scoped access: the user must be a member of graphID and the document must belong to it
+	GetGraphDocument(ctx context.Context, graphID, documentID, userID string) (*models.Document, error)
+	GetGraphDocumentContent(ctx context.Context, graphID, documentID, userID string) (map[string]interface{}, error)
+	// BatchStatus returns the processing status of each document, by ID (graph members only)
+	BatchStatus(ctx context.Context, userID string, documentIDs []string) (map[string]string, error)
+	// ListUserDocuments and ListGraphDocuments return the filter's page of documents and the total matching it
+	ListUserDocuments(ctx context.Context, userID string, filter models.DocumentFilter) ([]*models.Document, int, error)
+	ListGraphDocuments(ctx context.Context, graphID string, filter models.DocumentFilter) ([]*models.Document, int, error)
+	// SearchGraphDocuments returns a page of a graph's documents matching a query, and the total
+	// matching it (callers verify membership)
+	SearchGraphDocuments(ctx context.Context, graphID, query string, limit, offset int) ([]*models.Document, int, error)
+	// GetGraphStats counts a graph's documents by status (callers verify membership)
+	GetGraphStats(ctx context.Context, graphID string) (*models.GraphStats, error)
+	// LastGraphChange returns when a graph's documents or Zep data last changed (callers verify membership)
+	LastGraphChange(ctx context.Context, graph *models.Graph) (time.Time, error)
+	// RetryFailedDocuments reprocesses failed documents whose automatic retry is due
+	RetryFailedDocuments(ctx context.Context) (int, error)
+	UpdateDocument(ctx context.Context, documentID, userID, content, lexicalState, contentFormat string) (*models.Document, error)
 	DeleteDocument(ctx context.Context, documentID, userID string) error
+
+	// Tag management (graph members only)
+	SetTags(ctx context.Context, documentID, userID string, tags []string) (*models.Document, error)
+	AddTag(ctx context.Context, documentID, userID, tag string) (*models.Document, error)
+	RemoveTag(ctx context.Context, documentID, userID, tag string) (*models.Document, error)
+
+	// Check that every document in a graph still has its storage object
+	VerifyIntegrity(ctx context.Context, graphID string) (*models.DocumentIntegrityReport, error)
+
+	// Rebuild a graph's Zep data from its documents' stored content in the background (creator only)
+	ReindexGraph(ctx context.Context, graphID, userID string, clearGraph bool) (*models.GraphReindexJob, error)
+
+	// Report the progress of the graph's latest rebuild (callers verify the creator)
+	GetReindexJob(ctx context.Context, graphID string) (*models.GraphReindexJob, error)
+
+	// Re-extract the graph's uploaded documents extracted by an older extractor, optionally of
+	// one content type, as a reindex job reported by GetReindexJob (creator only)
+	ReextractAll(ctx context.Context, graphID, userID, contentType string) (*models.GraphReindexJob, error)
 }
 
 // GraphService defines the interface for graph operations
@@ -59,8 +130,8 @@ type GraphService interface {
 	// Get a graph by ID with membership verification
 	GetByID(ctx context.Context, graphID, userID string) (*models.Graph, error)
 
-	// List all graphs the user is a member of
-	ListByUserID(ctx context.Context, userID string) ([]*models.Graph, error)
+	// List the filter's page of graphs the user is a member of, and the total matching it
+	ListByUserID(ctx context.Context, userID string, filter models.GraphFilter) ([]*models.Graph, int, error)
 
 	// Update graph metadata (creator only)
 	Update(ctx context.Context, graphID, userID string, req *models.UpdateGraphRequest) (*models.Graph, error)
@@ -71,20 +142,37 @@ type GraphService interface {
 	// Add a member to a graph (creator only)
 	AddMember(ctx context.Context, graphID, creatorID string, req *models.AddMemberRequest) error
 
+	// Add several members at once, reporting the outcome of each entry (creator only)
+	AddMembers(ctx context.Context, graphID, creatorID string, req *models.AddMembersRequest) ([]models.AddMemberResult, error)
+
 	// Remove a member from a graph (creator only)
 	RemoveMember(ctx context.Context, graphID, creatorID, memberUserID string) error
 
-	// List all members of a graph
-	ListMembers(ctx context.Context, graphID, userID string) ([]*models.GraphMembership, error)
+	// List a page of a graph's members, and the total number of members
+	ListMembers(ctx context.Context, graphID, userID string, limit, offset int) ([]*models.GraphMembership, int, error)
 
 	// Check if user is a member of a graph
 	IsMember(ctx context.Context, graphID, userID string) (bool, error)
 
+	// Pin or unpin a graph for the user (members only for pinning)
+	AddFavorite(ctx context.Context, graphID, userID string) error
+	RemoveFavorite(ctx context.Context, graphID, userID string) error
+	IsFavorite(ctx context.Context, graphID, userID string) (bool, error)
+
 	// Increment document count for a graph
 	IncrementDocumentCount(ctx context.Context, graphID string) error
 
 	// Decrement document count for a graph
 	DecrementDocumentCount(ctx context.Context, graphID string) error
+
+	// Replace a graph's Zep graph with an empty one (callers verify the creator)
+	ClearZepGraph(ctx context.Context, graph *models.Graph) error
+
+	// Read-only public share links (creator only); ResolveShareLink returns the graph a token grants access to
+	CreateShareLink(ctx context.Context, graphID, ownerID string, expiresAt *time.Time) (*models.GraphShareLink, string, error)
+	ListShareLinks(ctx context.Context, graphID, ownerID string, limit, offset int) ([]*models.GraphShareLink, int, error)
+	RevokeShareLink(ctx context.Context, graphID, ownerID, linkID string) error
+	ResolveShareLink(ctx context.Context, token string) (*models.Graph, error)
 }
 
 // GeminiService defines the interface for Google Gemini File Search integration
@@ -92,22 +180,47 @@ type GeminiService interface {
 	// Store initialization (called once at startup)
 	InitializeStore(ctx context.Context, storeName string) (storeID string, err error)
 
-	// Document management (uses shared store with metadata)
-	UploadDocument(ctx context.Context, storeID, graphID, graphName, documentID string, content []byte, mimeType string) (string, error)
+	// Verify the API key and shared store are usable (for readiness checks)
+	HealthCheck(ctx context.Context) error
+
+	// Document management (uses shared store with metadata identifying the graph and document)
+	UploadDocument(ctx context.Context, storeID, graphID, graphName string, doc *models.Document, content []byte, mimeType string) (string, error)
+
+	// Chat interaction (with metadata filtering); returns the tokens the request used
+	GenerateStreamingResponse(ctx context.Context, storeID string, graphIDs, excludedDocumentIDs []string, domain, version, query string, responseChan chan<- string) (TokenUsage, error)
+}
+
+// ChatBackend answers chat messages from the documents of the graphs being searched
+// (selected with CHAT_BACKEND)
+type ChatBackend interface {
+	// Stream the answer to query, skipping excludedDocumentIDs; returns the tokens it used
+	GenerateStreamingResponse(ctx context.Context, graphs []*models.Graph, excludedDocumentIDs []string, query string, responseChan chan<- string) (TokenUsage, error)
 
-	// Chat interaction (with metadata filtering)
-	GenerateStreamingResponse(ctx context.Context, storeID, graphID, domain, version, query string, responseChan chan<- string) error
+	// Whether answers count against graphs' monthly chat budgets
+	MetersUsage() bool
 }
 
 // ChatService defines the interface for chat operations
 type ChatService interface {
 	// Thread management
-	CreateThread(ctx context.Context, graphID, userID string) (*models.ChatThread, error)
+	// Threads are shared with the graph's members unless private, in which case only their
+	// author sees them; only the author or the graph's owner can archive or delete a thread
+	CreateThread(ctx context.Context, graphID, userID string, private bool) (*models.ChatThread, error)
+	// StartThread creates a thread together with its first user message
+	StartThread(ctx context.Context, graphID, userID, content string, private bool) (*models.ChatThread, *models.ChatMessage, error)
 	GetThread(ctx context.Context, threadID, userID string) (*models.ChatThread, error)
-	ListThreads(ctx context.Context, graphID, userID string) ([]*models.ChatThread, error)
+	// ListThreads returns the filter's page of threads and the total matching it
+	ListThreads(ctx context.Context, graphID, userID string, filter models.ChatThreadFilter) ([]*models.ChatThread, int, error)
+	ArchiveThread(ctx context.Context, threadID, userID string) (*models.ChatThread, error)
+	UnarchiveThread(ctx context.Context, threadID, userID string) (*models.ChatThread, error)
+	// SetThreadPrivate hides a thread from other members or shares it again (author only)
+	SetThreadPrivate(ctx context.Context, threadID, userID string, private bool) (*models.ChatThread, error)
+	DeleteThread(ctx context.Context, threadID, userID string) error
 
 	// Message management
-	GetMessages(ctx context.Context, threadID string, limit, offset int) ([]*models.ChatMessage, error)
+	// GetMessages returns a page of a thread's messages, in reading order, and the thread's total
+	// message count. Pages are counted back from the newest message unless order is "asc".
+	GetMessages(ctx context.Context, threadID, order string, limit, offset int) ([]*models.ChatMessage, int, error)
 	SaveMessage(ctx context.Context, message *models.ChatMessage) error
 	SaveUserMessage(ctx context.Context, threadID, userID, content string) (*models.ChatMessage, error)
 
@@ -115,5 +228,17 @@ type ChatService interface {
 	// GenerateResponse is the old method - kept for backward compatibility
 	GenerateResponse(ctx context.Context, threadID, userID, userMessage string, responseChan chan<- string) error
 	// GenerateResponseForMessage generates AI response for a specific user message
-	GenerateResponseForMessage(ctx context.Context, threadID, userMessageID, graphID string, responseChan chan<- string) (assistantMessageID string, err error)
+	// ResolveChatGraphs validates the extra graphs a message should also search and returns the full list
+	ResolveChatGraphs(ctx context.Context, threadGraphID, userID string, additionalGraphIDs []string) ([]string, error)
+	// ResolveExcludedDocuments validates the documents a message should leave out of its answer
+	ResolveExcludedDocuments(ctx context.Context, graphIDs, documentIDs []string) ([]string, error)
+	// graphIDs lists the graphs to search: the thread's graph first, optionally followed by other graphs the user is a member of
+	GenerateResponseForMessage(ctx context.Context, threadID, userMessageID string, graphIDs, excludedDocumentIDs []string, responseChan chan<- string) (assistantMessageID string, err error)
+
+	// Feedback on assistant messages
+	SubmitFeedback(ctx context.Context, messageID, userID, rating string, comment *string) (*models.MessageFeedback, error)
+	GetFeedbackSummary(ctx context.Context, graphID, userID string) (*models.FeedbackSummary, error)
+
+	// Month-to-date Gemini usage of a graph (callers check access)
+	GetGeminiUsage(ctx context.Context, graphID string) (*models.GeminiUsage, error)
 }
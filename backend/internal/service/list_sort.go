@@ -0,0 +1,27 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/models"
+)
+
+// Custom errors for list sorting
+var (
+	ErrInvalidSortField = fmt.Errorf("invalid sort field")
+	ErrInvalidSortOrder = fmt.Errorf("invalid sort order: must be %q or %q", models.SortOrderAsc, models.SortOrderDesc)
+)
+
+// validateListSort checks a requested ordering against the fields a listing supports
+func validateListSort(sort models.ListSort, fields ...string) error {
+	if sort.Field != "" && !slices.Contains(fields, sort.Field) {
+		return fmt.Errorf("%w %q: must be one of %v", ErrInvalidSortField, sort.Field, fields)
+	}
+
+	if sort.Order != "" && sort.Order != models.SortOrderAsc && sort.Order != models.SortOrderDesc {
+		return ErrInvalidSortOrder
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"fmt"
+
+	v3 "github.com/getzep/zep-go/v3"
+)
+
+// builtinNodeColors are the colors of common entity types, used unless a deployment maps
+// the label to its own color
+var builtinNodeColors = map[string]string{
+	"Person":       "#ef4444", // Red
+	"Organization": "#10b981", // Green
+	"Location":     "#f59e0b", // Orange
+	"Event":        "#8b5cf6", // Purple
+	"Concept":      "#06b6d4", // Cyan
+	"Document":     "#ec4899", // Pink
+}
+
+// NodeStyle sets the colors and sizes of graph visualization nodes. A node's color comes
+// from its primary (first) label; labels without a color get one derived from the label,
+// so the same label always has the same color.
+type NodeStyle struct {
+	LabelColors  map[string]string // Hex color of each primary label
+	DefaultColor string            // Hex color of nodes without labels
+
+	// A node's size is BaseSize, plus SizePerLabel for each label, plus its search score
+	// times SizePerScore
+	BaseSize     float64
+	SizePerLabel float64
+	SizePerScore float64
+}
+
+// withBuiltinColors returns the style with the built-in palette filling in the labels it
+// has no color for
+func (s NodeStyle) withBuiltinColors() NodeStyle {
+	colors := make(map[string]string, len(builtinNodeColors)+len(s.LabelColors))
+	for label, color := range builtinNodeColors {
+		colors[label] = color
+	}
+	for label, color := range s.LabelColors {
+		colors[label] = color
+	}
+	s.LabelColors = colors
+	return s
+}
+
+// nodeSize determines the size of a node: more labels and a higher score make it larger
+func (s NodeStyle) nodeSize(node *v3.EntityNode) float64 {
+	size := s.BaseSize
+	size += float64(len(node.Labels)) * s.SizePerLabel
+	if node.Score != nil {
+		size += *node.Score * s.SizePerScore
+	}
+	return size
+}
+
+// nodeColor picks the color of a node from its primary label
+func (s NodeStyle) nodeColor(node *v3.EntityNode) string {
+	if len(node.Labels) == 0 {
+		return s.DefaultColor
+	}
+
+	primaryLabel := node.Labels[0]
+	if color, exists := s.LabelColors[primaryLabel]; exists {
+		return color
+	}
+
+	// Generate a consistent color based on label hash
+	hash := 0
+	for _, char := range primaryLabel {
+		hash = int(char) + ((hash << 5) - hash)
+	}
+
+	// Use hash to generate RGB values
+	r := (hash & 0xFF0000) >> 16
+	g := (hash & 0x00FF00) >> 8
+	b := hash & 0x0000FF
+
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
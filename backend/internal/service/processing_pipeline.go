@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/repository"
+)
+
+// ProcessingJob is one document to be processed into its Zep graph
+type ProcessingJob struct {
+	UserID      string
+	GraphID     string // Zep graph ID
+	DocumentID  string
+	Filename    string
+	Content     string
+	ContentType string
+}
+
+// The processing pipeline runs text → chunk → ingest → update status. Each stage is an
+// interface so it can be replaced, e.g. by a mock in tests, through ProcessingStages.
+
+// TextSource provides the text of a document to process
+type TextSource interface {
+	Text(ctx context.Context, job ProcessingJob) (string, error)
+}
+
+// DocumentChunker splits a document's text into chunks for Zep and picks their data type
+type DocumentChunker interface {
+	Chunk(content, contentType string) (MemoryDataType, []string, error)
+}
+
+// EpisodeIngester adds chunks to a Zep graph, returning the IDs of the episodes it created.
+// IDs are returned even on failure, for the episodes created before it.
+type EpisodeIngester interface {
+	Ingest(ctx context.Context, graphID string, chunks []string, dataType MemoryDataType, metadata map[string]any) ([]string, error)
+}
+
+// DocumentStatusUpdater records the processing status of a document
+type DocumentStatusUpdater interface {
+	UpdateStatus(ctx context.Context, documentID, status string) error
+}
+
+// ProcessingStages overrides stages of the processing pipeline. Nil stages use the defaults:
+// the text the caller received (DocumentService extracts it from uploads), prepareChunks,
+// ZepService.AddMemory and the document repository.
+type ProcessingStages struct {
+	Text          TextSource
+	Chunker       DocumentChunker
+	Ingester      EpisodeIngester
+	StatusUpdater DocumentStatusUpdater
+}
+
+// withDefaults fills in the default implementation of every stage left nil
+func (p ProcessingStages) withDefaults(documentRepo repository.DocumentRepository, zepService ZepService) ProcessingStages {
+	if p.Text == nil {
+		p.Text = receivedText{}
+	}
+	if p.Chunker == nil {
+		p.Chunker = defaultChunker{}
+	}
+	if p.Ingester == nil {
+		p.Ingester = zepIngester{zepService: zepService}
+	}
+	if p.StatusUpdater == nil {
+		p.StatusUpdater = repositoryStatusUpdater{documentRepo: documentRepo}
+	}
+	return p
+}
+
+// receivedText uses the content passed to ProcessDocument as is
+type receivedText struct{}
+
+func (receivedText) Text(ctx context.Context, job ProcessingJob) (string, error) {
+	return job.Content, nil
+}
+
+// defaultChunker keeps JSON documents as JSON and cleans and chunks everything else as text
+type defaultChunker struct{}
+
+func (defaultChunker) Chunk(content, contentType string) (MemoryDataType, []string, error) {
+	return prepareChunks(content, contentType)
+}
+
+// zepIngester adds chunks to Zep as memory
+type zepIngester struct {
+	zepService ZepService
+}
+
+func (i zepIngester) Ingest(ctx context.Context, graphID string, chunks []string, dataType MemoryDataType, metadata map[string]any) ([]string, error) {
+	return i.zepService.AddMemory(ctx, graphID, chunks, dataType, metadata)
+}
+
+// repositoryStatusUpdater stores the status on the document record
+type repositoryStatusUpdater struct {
+	documentRepo repository.DocumentRepository
+}
+
+func (u repositoryStatusUpdater) UpdateStatus(ctx context.Context, documentID, status string) error {
+	doc, err := u.documentRepo.GetByID(ctx, documentID)
+	if err != nil {
+		return fmt.Errorf("failed to get document: %w", err)
+	}
+
+	doc.Status = status
+	doc.UpdatedAt = time.Now().UTC()
+
+	if err := u.documentRepo.Update(ctx, doc); err != nil {
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+
+	return nil
+}
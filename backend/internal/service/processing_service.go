@@ -2,85 +2,299 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/bipulkrdas/orgmind/backend/internal/repository"
 	"github.com/bipulkrdas/orgmind/backend/pkg/utils"
 )
 
+// maxRetryDelay caps the exponential backoff between automatic retries
+const maxRetryDelay = 6 * time.Hour
+
+// ErrProcessingTimeout is returned when processing a document takes longer than the processing timeout
+var ErrProcessingTimeout = fmt.Errorf("document processing timed out")
+
+// RetryPolicy controls automatic retries of documents whose processing failed transiently
+type RetryPolicy struct {
+	MaxAttempts int           // Processing attempts, including the first, before a document stays failed (<= 1 disables retries)
+	BaseDelay   time.Duration // Delay before the first retry, doubled for each further attempt up to maxRetryDelay
+}
+
+// nextRetry returns when to retry a document after its given number of failed attempts,
+// or false once the attempts are used up
+func (p RetryPolicy) nextRetry(attempts int, now time.Time) (time.Time, bool) {
+	if attempts < 1 || attempts >= p.MaxAttempts {
+		return time.Time{}, false
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return now.Add(min(delay, maxRetryDelay)), true
+}
+
 // processingService implements ProcessingService interface
 type processingService struct {
 	documentRepo repository.DocumentRepository
 	zepService   ZepService
+	retryPolicy  RetryPolicy
+	stages       ProcessingStages
+
+	// Upper bound on a whole ProcessDocument run, so a stuck ingestion can't leave a
+	// document processing forever
+	timeout time.Duration
+}
+
+// NewProcessingService creates a new instance of ProcessingService. Documents that fail
+// with a transient error, or take longer than timeout to process, are scheduled for retry
+// according to retryPolicy.
+func NewProcessingService(documentRepo repository.DocumentRepository, zepService ZepService, retryPolicy RetryPolicy, timeout time.Duration) ProcessingService {
+	return NewProcessingServiceWithStages(documentRepo, zepService, retryPolicy, timeout, ProcessingStages{})
 }
 
-// NewProcessingService creates a new instance of ProcessingService
-func NewProcessingService(documentRepo repository.DocumentRepository, zepService ZepService) ProcessingService {
+// NewProcessingServiceWithStages creates a ProcessingService whose pipeline uses the given
+// stages in place of the defaults
+func NewProcessingServiceWithStages(documentRepo repository.DocumentRepository, zepService ZepService, retryPolicy RetryPolicy, timeout time.Duration, stages ProcessingStages) ProcessingService {
 	return &processingService{
 		documentRepo: documentRepo,
 		zepService:   zepService,
+		retryPolicy:  retryPolicy,
+		stages:       stages.withDefaults(documentRepo, zepService),
+		timeout:      timeout,
 	}
 }
 
-// ProcessDocument orchestrates the document processing workflow:
-// 1. Clean the text content
-// 2. Chunk the document into manageable pieces
-// 3. Send chunks to Zep for knowledge graph creation
+// ProcessDocument runs the document processing pipeline:
+// 1. Get the text content (by default, the content passed in)
+// 2. Chunk the document into manageable pieces, keeping JSON documents as JSON
+// 3. Ingest the chunks into Zep for knowledge graph creation, tracking the episodes created
 // 4. Update document status in database
-func (s *processingService) ProcessDocument(ctx context.Context, userID, graphID, documentID, content string) error {
-	// Step 1: Clean the text content
-	cleanedContent := utils.CleanText(content)
-	if cleanedContent == "" {
-		return fmt.Errorf("content is empty after cleaning")
+//
+// Each call counts as a processing attempt. A failure to get the text or ingest it is
+// treated as transient and schedules a retry while the retry policy allows. The whole run is
+// bounded by the processing timeout; a run that exceeds it fails the same way.
+func (s *processingService) ProcessDocument(ctx context.Context, userID, graphID, documentID, filename, content, contentType string) error {
+	// Bookkeeping after a failure must still work once the deadline has passed
+	cleanupCtx := context.WithoutCancel(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	attempts, err := s.documentRepo.IncrementProcessingAttempts(ctx, documentID)
+	if err != nil {
+		// Without the count no retry is scheduled, but processing can still go ahead
+		fmt.Printf("Warning: failed to record processing attempt for document %s: %v\n", documentID, err)
 	}
 
-	// Step 2: Chunk the document
-	chunks := utils.ChunkDocument(cleanedContent)
-	if len(chunks) == 0 {
-		return fmt.Errorf("no chunks created from document")
+	job := ProcessingJob{
+		UserID:      userID,
+		GraphID:     graphID,
+		DocumentID:  documentID,
+		Filename:    filename,
+		Content:     content,
+		ContentType: contentType,
 	}
 
-	// Step 3: Send chunks to Zep for memory creation
+	// Step 1: Get the text
+	text, err := s.stages.Text.Text(ctx, job)
+	if err != nil {
+		if timedOut(ctx) {
+			err = s.timeoutError(documentID, err)
+		}
+		return s.failProcessing(cleanupCtx, documentID, attempts, fmt.Errorf("failed to get document text: %w", err))
+	}
+
+	// Step 2: Clean and chunk the content
+	dataType, chunks, err := s.stages.Chunker.Chunk(text, contentType)
+	if err != nil {
+		// Chunking the same text fails the same way every time, e.g. when nothing is left
+		// after cleaning, so the document is marked failed without a retry
+		return s.rejectProcessing(cleanupCtx, documentID, fmt.Errorf("failed to chunk document: %w", err))
+	}
+	if timedOut(ctx) {
+		return s.failProcessing(cleanupCtx, documentID, attempts, s.timeoutError(documentID, ctx.Err()))
+	}
+
+	// Step 3: Ingest the chunks into Zep
 	metadata := map[string]any{
 		"documentId": documentID,
 		"userId":     userID,
 		"chunkCount": len(chunks),
 		"timestamp":  time.Now().UTC().Format(time.RFC3339),
+		// Attributes each episode to its document so facts can be traced back to their source
+		"source_description": documentSourceDescription(documentID, filename),
+	}
+
+	episodeIDs, err := s.stages.Ingester.Ingest(ctx, graphID, chunks, dataType, metadata)
+
+	// Track the episodes, even from a failed upload, so the data can be removed when the document is deleted
+	if trackErr := s.documentRepo.AddZepEpisodes(cleanupCtx, documentID, episodeIDs); trackErr != nil {
+		fmt.Printf("Warning: failed to record Zep episodes for document %s: %v\n", documentID, trackErr)
 	}
 
-	err := s.zepService.AddMemory(ctx, graphID, chunks, metadata)
 	if err != nil {
-		// Update document status to failed
-		if updateErr := s.updateDocumentStatus(ctx, documentID, "failed"); updateErr != nil {
-			return fmt.Errorf("failed to add memory to Zep: %w, and failed to update document status: %v", err, updateErr)
+		if timedOut(ctx) {
+			err = s.timeoutError(documentID, err)
 		}
-		return fmt.Errorf("failed to add memory to Zep: %w", err)
+		return s.failProcessing(cleanupCtx, documentID, attempts, fmt.Errorf("failed to add memory to Zep: %w", err))
 	}
 
 	// Step 4: Update document status to completed
-	err = s.updateDocumentStatus(ctx, documentID, "completed")
-	if err != nil {
+	if err := s.stages.StatusUpdater.UpdateStatus(cleanupCtx, documentID, "completed"); err != nil {
 		return fmt.Errorf("failed to update document status: %w", err)
 	}
 
 	return nil
 }
 
-// updateDocumentStatus updates the status of a document in the database
-func (s *processingService) updateDocumentStatus(ctx context.Context, documentID, status string) error {
-	doc, err := s.documentRepo.GetByID(ctx, documentID)
+// failProcessing marks a document failed after a transient processing error and schedules
+// a retry while the retry policy allows
+func (s *processingService) failProcessing(ctx context.Context, documentID string, attempts int, err error) error {
+	if updateErr := s.stages.StatusUpdater.UpdateStatus(ctx, documentID, "failed"); updateErr != nil {
+		return fmt.Errorf("%w, and failed to update document status: %v", err, updateErr)
+	}
+	s.scheduleRetry(ctx, documentID, attempts)
+	return err
+}
+
+// rejectProcessing marks a document failed after an error that retrying wouldn't fix
+func (s *processingService) rejectProcessing(ctx context.Context, documentID string, err error) error {
+	if updateErr := s.stages.StatusUpdater.UpdateStatus(ctx, documentID, "failed"); updateErr != nil {
+		return fmt.Errorf("%w, and failed to update document status: %v", err, updateErr)
+	}
+	return err
+}
+
+// timeoutError logs and reports a processing run that exceeded the processing timeout
+func (s *processingService) timeoutError(documentID string, err error) error {
+	fmt.Printf("Warning: processing of document %s timed out after %s\n", documentID, s.timeout)
+	return fmt.Errorf("%w after %s: %v", ErrProcessingTimeout, s.timeout, err)
+}
+
+// timedOut reports whether ctx ended because its deadline passed
+func timedOut(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// ReprocessDocument ingests a document's new content and then removes the episodes created
+// from its previous content, so edits don't pile up stale or contradictory facts in the graph.
+// The old episodes are removed even if ingesting the new content fails, since they no longer
+// match the stored document. Episodes that can't be removed stay tracked and are retried when
+// the document is next updated or deleted.
+func (s *processingService) ReprocessDocument(ctx context.Context, userID, graphID, documentID, filename, content, contentType string) error {
+	previousEpisodeIDs, err := s.documentRepo.ListZepEpisodes(ctx, documentID)
 	if err != nil {
-		return fmt.Errorf("failed to get document: %w", err)
+		return fmt.Errorf("failed to get document episodes: %w", err)
 	}
 
-	doc.Status = status
-	doc.UpdatedAt = time.Now().UTC()
+	processErr := s.ProcessDocument(ctx, userID, graphID, documentID, filename, content, contentType)
 
-	err = s.documentRepo.Update(ctx, doc)
+	if err := s.removeEpisodes(ctx, documentID, previousEpisodeIDs); err != nil {
+		if processErr != nil {
+			return fmt.Errorf("%w, and failed to remove previous data: %v", processErr, err)
+		}
+		return fmt.Errorf("failed to remove previous data: %w", err)
+	}
+
+	return processErr
+}
+
+// documentSourceDescription describes a document for Zep's source attribution, e.g.
+// `"report.pdf" (document 1b4e...)`. Documents without a filename, such as editor
+// content, are described by their ID alone.
+func documentSourceDescription(documentID, filename string) string {
+	filename = strings.TrimSpace(filename)
+	if filename == "" {
+		return fmt.Sprintf("Editor document %s", documentID)
+	}
+	return fmt.Sprintf("%q (document %s)", filename, documentID)
+}
+
+// RecordFailedAttempt counts a processing attempt that failed before reaching Zep, such as
+// a failed text extraction, and schedules a retry if the failure was transient
+func (s *processingService) RecordFailedAttempt(ctx context.Context, documentID string, retryable bool) error {
+	attempts, err := s.documentRepo.IncrementProcessingAttempts(ctx, documentID)
 	if err != nil {
-		return fmt.Errorf("failed to update document: %w", err)
+		return fmt.Errorf("failed to record processing attempt: %w", err)
+	}
+
+	if retryable {
+		s.scheduleRetry(ctx, documentID, attempts)
+	}
+	return nil
+}
+
+// scheduleRetry schedules a failed document's next automatic retry, unless its attempts are used up
+func (s *processingService) scheduleRetry(ctx context.Context, documentID string, attempts int) {
+	retryAt, ok := s.retryPolicy.nextRetry(attempts, time.Now().UTC())
+	if !ok {
+		return
+	}
+
+	if err := s.documentRepo.ScheduleRetry(ctx, documentID, retryAt); err != nil {
+		fmt.Printf("Warning: failed to schedule retry for document %s: %v\n", documentID, err)
+	}
+}
+
+// RemoveDocument removes the data a document added to its Zep graph
+func (s *processingService) RemoveDocument(ctx context.Context, documentID string) error {
+	episodeIDs, err := s.documentRepo.ListZepEpisodes(ctx, documentID)
+	if err != nil {
+		return fmt.Errorf("failed to get document episodes: %w", err)
+	}
+
+	return s.removeEpisodes(ctx, documentID, episodeIDs)
+}
+
+// removeEpisodes deletes episodes from Zep and stops tracking them
+func (s *processingService) removeEpisodes(ctx context.Context, documentID string, episodeIDs []string) error {
+	if len(episodeIDs) == 0 {
+		return nil
+	}
+
+	if err := s.zepService.RemoveDocumentData(ctx, episodeIDs); err != nil {
+		return fmt.Errorf("failed to remove document data from Zep: %w", err)
+	}
+
+	if err := s.documentRepo.DeleteZepEpisodes(ctx, documentID, episodeIDs); err != nil {
+		return fmt.Errorf("failed to stop tracking removed episodes: %w", err)
 	}
 
 	return nil
 }
+
+// prepareChunks splits content into chunks for Zep and picks the matching data type.
+// JSON content is sent as JSON so Zep can use its structure; anything else, including
+// JSON that can't be split into valid chunks, is cleaned and sent as text.
+func prepareChunks(content, contentType string) (MemoryDataType, []string, error) {
+	if isJSONContentType(contentType) {
+		if chunks, ok := utils.ChunkJSON(content); ok {
+			return MemoryDataTypeJSON, chunks, nil
+		}
+	}
+
+	cleanedContent := utils.CleanText(content)
+	if cleanedContent == "" {
+		return "", nil, fmt.Errorf("content is empty after cleaning")
+	}
+
+	chunks := utils.ChunkDocument(cleanedContent)
+	if len(chunks) == 0 {
+		return "", nil, fmt.Errorf("no chunks created from document")
+	}
+
+	return MemoryDataTypeText, chunks, nil
+}
+
+// isJSONContentType reports whether contentType is a JSON media type, ignoring parameters
+func isJSONContentType(contentType string) bool {
+	if idx := strings.Index(contentType, ";"); idx != -1 {
+		contentType = contentType[:idx]
+	}
+	contentType = strings.TrimSpace(strings.ToLower(contentType))
+	return contentType == "application/json" || contentType == "text/json"
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/repository"
+)
+
+// attemptTrackingRepo records processing attempts and scheduled retries; the other
+// repository methods are not used by these tests
+type attemptTrackingRepo struct {
+	repository.DocumentRepository
+	attempts int
+	retryAt  *time.Time
+}
+
+func (r *attemptTrackingRepo) IncrementProcessingAttempts(ctx context.Context, docID string) (int, error) {
+	r.attempts++
+	return r.attempts, nil
+}
+
+func (r *attemptTrackingRepo) ScheduleRetry(ctx context.Context, docID string, retryAt time.Time) error {
+	r.retryAt = &retryAt
+	return nil
+}
+
+// recordingStatusUpdater records the last status set on each document
+type recordingStatusUpdater struct {
+	statuses map[string]string
+}
+
+func (u *recordingStatusUpdater) UpdateStatus(ctx context.Context, documentID, status string) error {
+	u.statuses[documentID] = status
+	return nil
+}
+
+// failingText is a text stage that always fails with err
+type failingText struct {
+	err error
+}
+
+func (t failingText) Text(ctx context.Context, job ProcessingJob) (string, error) {
+	return "", t.err
+}
+
+func TestProcessDocumentTextErrorMarksDocumentFailed(t *testing.T) {
+	textErr := errors.New("download failed")
+	repo := &attemptTrackingRepo{}
+	statuses := &recordingStatusUpdater{statuses: map[string]string{}}
+	s := NewProcessingServiceWithStages(repo, nil, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute}, time.Minute, ProcessingStages{
+		Text:          failingText{err: textErr},
+		StatusUpdater: statuses,
+	})
+
+	err := s.ProcessDocument(context.Background(), "user-1", "graph-1", "doc-1", "report.pdf", "", "application/pdf")
+	if !errors.Is(err, textErr) {
+		t.Fatalf("ProcessDocument() error = %v, want %v", err, textErr)
+	}
+	if got := statuses.statuses["doc-1"]; got != "failed" {
+		t.Errorf("document status = %q, want %q", got, "failed")
+	}
+	if repo.retryAt == nil {
+		t.Error("no retry was scheduled for the failed document")
+	}
+}
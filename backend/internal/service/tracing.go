@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/models"
+	"github.com/bipulkrdas/orgmind/backend/internal/tracing"
+)
+
+// tracedZepService records a span for every Zep call
+type tracedZepService struct {
+	zep ZepService
+}
+
+// NewTracedZepService wraps a Zep service so each call is recorded as a span of the trace in
+// its context (see the tracing package)
+func NewTracedZepService(zep ZepService) ZepService {
+	return &tracedZepService{zep: zep}
+}
+
+func (t *tracedZepService) HealthCheck(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "zep.HealthCheck")
+	err := t.zep.HealthCheck(ctx)
+	span.Finish(err)
+	return err
+}
+
+func (t *tracedZepService) CreateGraph(ctx context.Context, graphID, name string, description *string) (string, error) {
+	ctx, span := tracing.Start(ctx, "zep.CreateGraph", "graph.id", graphID)
+	zepGraphID, err := t.zep.CreateGraph(ctx, graphID, name, description)
+	span.Finish(err)
+	return zepGraphID, err
+}
+
+func (t *tracedZepService) UpdateGraph(ctx context.Context, zepGraphID, name string, description *string) error {
+	ctx, span := tracing.Start(ctx, "zep.UpdateGraph", "zep.graph_id", zepGraphID)
+	err := t.zep.UpdateGraph(ctx, zepGraphID, name, description)
+	span.Finish(err)
+	return err
+}
+
+func (t *tracedZepService) DeleteGraph(ctx context.Context, zepGraphID string) error {
+	ctx, span := tracing.Start(ctx, "zep.DeleteGraph", "zep.graph_id", zepGraphID)
+	err := t.zep.DeleteGraph(ctx, zepGraphID)
+	span.Finish(err)
+	return err
+}
+
+func (t *tracedZepService) ListGraphs(ctx context.Context) ([]ZepGraphInfo, error) {
+	ctx, span := tracing.Start(ctx, "zep.ListGraphs")
+	graphs, err := t.zep.ListGraphs(ctx)
+	span.SetAttribute("zep.graph_count", len(graphs))
+	span.Finish(err)
+	return graphs, err
+}
+
+func (t *tracedZepService) AddMemory(ctx context.Context, graphID string, chunks []string, dataType MemoryDataType, metadata map[string]any) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "zep.AddMemory", "zep.graph_id", graphID, "zep.chunk_count", len(chunks), "zep.data_type", string(dataType))
+	episodeIDs, err := t.zep.AddMemory(ctx, graphID, chunks, dataType, metadata)
+	span.SetAttribute("zep.episode_count", len(episodeIDs))
+	span.Finish(err)
+	return episodeIDs, err
+}
+
+func (t *tracedZepService) RemoveDocumentData(ctx context.Context, episodeIDs []string) error {
+	ctx, span := tracing.Start(ctx, "zep.RemoveDocumentData", "zep.episode_count", len(episodeIDs))
+	err := t.zep.RemoveDocumentData(ctx, episodeIDs)
+	span.Finish(err)
+	return err
+}
+
+func (t *tracedZepService) GetGraph(ctx context.Context, graphID, query string) (*models.GraphData, error) {
+	ctx, span := tracing.Start(ctx, "zep.GetGraph", "zep.graph_id", graphID, "zep.filtered", query != "")
+	data, err := t.zep.GetGraph(ctx, graphID, query)
+	span.Finish(err)
+	return data, err
+}
+
+func (t *tracedZepService) SearchMemory(ctx context.Context, graphID, query string) ([]models.MemoryResult, error) {
+	ctx, span := tracing.Start(ctx, "zep.SearchMemory", "zep.graph_id", graphID)
+	results, err := t.zep.SearchMemory(ctx, graphID, query)
+	span.SetAttribute("zep.result_count", len(results))
+	span.Finish(err)
+	return results, err
+}
+
+// tracedGeminiService records a span for every Gemini call
+type tracedGeminiService struct {
+	gemini GeminiService
+}
+
+// NewTracedGeminiService wraps a Gemini service so each call is recorded as a span of the
+// trace in its context (see the tracing package)
+func NewTracedGeminiService(gemini GeminiService) GeminiService {
+	return &tracedGeminiService{gemini: gemini}
+}
+
+func (t *tracedGeminiService) InitializeStore(ctx context.Context, storeName string) (string, error) {
+	ctx, span := tracing.Start(ctx, "gemini.InitializeStore")
+	storeID, err := t.gemini.InitializeStore(ctx, storeName)
+	span.Finish(err)
+	return storeID, err
+}
+
+func (t *tracedGeminiService) HealthCheck(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "gemini.HealthCheck")
+	err := t.gemini.HealthCheck(ctx)
+	span.Finish(err)
+	return err
+}
+
+func (t *tracedGeminiService) UploadDocument(ctx context.Context, storeID, graphID, graphName string, doc *models.Document, content []byte, mimeType string) (string, error) {
+	ctx, span := tracing.Start(ctx, "gemini.UploadDocument", "graph.id", graphID, "document.id", doc.ID, "document.size_bytes", len(content))
+	fileID, err := t.gemini.UploadDocument(ctx, storeID, graphID, graphName, doc, content, mimeType)
+	span.Finish(err)
+	return fileID, err
+}
+
+func (t *tracedGeminiService) GenerateStreamingResponse(ctx context.Context, storeID string, graphIDs, excludedDocumentIDs []string, domain, version, query string, responseChan chan<- string) (TokenUsage, error) {
+	ctx, span := tracing.Start(ctx, "gemini.GenerateStreamingResponse", "gemini.graph_count", len(graphIDs), "gemini.excluded_document_count", len(excludedDocumentIDs))
+	usage, err := t.gemini.GenerateStreamingResponse(ctx, storeID, graphIDs, excludedDocumentIDs, domain, version, query, responseChan)
+	span.SetAttribute("gemini.input_tokens", usage.InputTokens)
+	span.SetAttribute("gemini.output_tokens", usage.OutputTokens)
+	span.Finish(err)
+	return usage, err
+}
@@ -2,34 +2,92 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bipulkrdas/orgmind/backend/internal/models"
+	"github.com/bipulkrdas/orgmind/backend/internal/tracing"
 	v3 "github.com/getzep/zep-go/v3"
 	v3client "github.com/getzep/zep-go/v3/client"
 	"github.com/getzep/zep-go/v3/option"
 )
 
+// MemoryDataType is the kind of data sent to Zep, which determines how it extracts entities
+type MemoryDataType string
+
+const (
+	MemoryDataTypeText    MemoryDataType = MemoryDataType(v3.GraphDataTypeText)
+	MemoryDataTypeJSON    MemoryDataType = MemoryDataType(v3.GraphDataTypeJSON)
+	MemoryDataTypeMessage MemoryDataType = MemoryDataType(v3.GraphDataTypeMessage)
+)
+
+// zepGraphListPageSize is the number of graphs requested per page when listing Zep graphs
+const zepGraphListPageSize = 100
+
+// ZepGraphInfo identifies a graph stored in Zep Cloud
+type ZepGraphInfo struct {
+	GraphID   string
+	Name      string
+	CreatedAt time.Time // Zero if Zep didn't report a parseable creation time
+}
+
+// zepHealthCacheTTL is how long a health check result is reused, so frequent
+// readiness probes don't each make a Zep API call
+const zepHealthCacheTTL = 30 * time.Second
+
 // zepService implements the ZepService interface
 type zepService struct {
-	client *v3client.Client
+	client    *v3client.Client
+	nodeStyle NodeStyle
+
+	healthMu        sync.Mutex
+	healthCheckedAt time.Time
+	healthErr       error
 }
 
-// NewZepService creates a new Zep service instance
-func NewZepService(apiKey string) (ZepService, error) {
+// NewZepService creates a new Zep service instance, styling visualization nodes with
+// nodeStyle. Its label colors are merged over the built-in palette.
+func NewZepService(apiKey string, nodeStyle NodeStyle) (ZepService, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("zep API key is required")
 	}
 
-	opts := option.WithAPIKey(apiKey)
-	client := v3client.NewClient(opts)
+	// Requests carry the trace of their context, so Zep calls join the request's trace
+	client := v3client.NewClient(option.WithAPIKey(apiKey), option.WithHTTPClient(tracing.HTTPClient()))
 
 	return &zepService{
-		client: client,
+		client:    client,
+		nodeStyle: nodeStyle.withBuiltinColors(),
 	}, nil
 }
 
+// HealthCheck verifies that Zep is reachable and accepts the API key by listing a
+// single graph. Results are cached for zepHealthCacheTTL.
+func (s *zepService) HealthCheck(ctx context.Context) error {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+
+	if !s.healthCheckedAt.IsZero() && time.Since(s.healthCheckedAt) < zepHealthCacheTTL {
+		return s.healthErr
+	}
+
+	_, err := s.client.Graph.ListAll(ctx, &v3.GraphListAllRequest{
+		PageNumber: v3.Int(1),
+		PageSize:   v3.Int(1),
+	})
+	if err != nil {
+		s.healthErr = fmt.Errorf("zep health check failed: %w", err)
+	} else {
+		s.healthErr = nil
+	}
+	s.healthCheckedAt = time.Now()
+
+	return s.healthErr
+}
+
 // CreateGraph creates a new graph in Zep Cloud with retry logic
 func (s *zepService) CreateGraph(ctx context.Context, graphID, name string, description *string) (string, error) {
 	const maxRetries = 3
@@ -59,7 +117,9 @@ func (s *zepService) CreateGraph(ctx context.Context, graphID, name string, desc
 	return "", fmt.Errorf("failed to create graph after %d attempts: %w", maxRetries, lastErr)
 }
 
-// createGraphAttempt performs a single attempt to create a graph in Zep
+// createGraphAttempt performs a single attempt to create a graph in Zep.
+// If the graph already exists, because an earlier attempt succeeded without us seeing
+// the response, the existing graph is used instead.
 func (s *zepService) createGraphAttempt(ctx context.Context, graphID, name string, description *string) (string, error) {
 	request := &v3.CreateGraphRequest{
 		GraphID:     graphID,
@@ -69,7 +129,11 @@ func (s *zepService) createGraphAttempt(ctx context.Context, graphID, name strin
 
 	graph, err := s.client.Graph.Create(ctx, request)
 	if err != nil {
-		return "", fmt.Errorf("failed to create graph in Zep: %w", err)
+		existing, getErr := s.client.Graph.Get(ctx, graphID)
+		if getErr != nil || existing == nil {
+			return "", fmt.Errorf("failed to create graph in Zep: %w", err)
+		}
+		graph = existing
 	}
 
 	// Return the Zep-assigned graph ID
@@ -79,6 +143,21 @@ func (s *zepService) createGraphAttempt(ctx context.Context, graphID, name strin
 	return graphID, nil
 }
 
+// UpdateGraph updates the name and description of a graph in Zep Cloud
+func (s *zepService) UpdateGraph(ctx context.Context, zepGraphID, name string, description *string) error {
+	request := &v3.UpdateGraphRequest{
+		Name:        &name,
+		Description: description,
+	}
+
+	_, err := s.client.Graph.Update(ctx, zepGraphID, request)
+	if err != nil {
+		return fmt.Errorf("failed to update graph in Zep: %w", err)
+	}
+
+	return nil
+}
+
 // DeleteGraph deletes a graph from Zep Cloud
 func (s *zepService) DeleteGraph(ctx context.Context, zepGraphID string) error {
 	_, err := s.client.Graph.Delete(ctx, zepGraphID)
@@ -89,57 +168,181 @@ func (s *zepService) DeleteGraph(ctx context.Context, zepGraphID string) error {
 	return nil
 }
 
-// AddMemory adds document chunks to a specific graph in Zep Cloud with retry logic
-func (s *zepService) AddMemory(ctx context.Context, graphID string, chunks []string, metadata map[string]any) error {
-	const maxRetries = 3
-	const baseDelay = 1 * time.Second
+// Limits for adding document chunks to Zep
+const (
+	addMemoryConcurrency = 5 // Chunks sent to Zep in parallel
+	addChunkMaxRetries   = 3 // Attempts per chunk before the document fails
+	addChunkBaseDelay    = 1 * time.Second
+)
+
+// ListGraphs lists every graph in the Zep project, paging through the results
+func (s *zepService) ListGraphs(ctx context.Context) ([]ZepGraphInfo, error) {
+	var graphs []ZepGraphInfo
+
+	for page := 1; ; page++ {
+		response, err := s.client.Graph.ListAll(ctx, &v3.GraphListAllRequest{
+			PageNumber: v3.Int(page),
+			PageSize:   v3.Int(zepGraphListPageSize),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list graphs in Zep (page %d): %w", page, err)
+		}
+
+		for _, graph := range response.Graphs {
+			if graph == nil || graph.GraphID == nil {
+				continue
+			}
+
+			info := ZepGraphInfo{GraphID: *graph.GraphID}
+			if graph.Name != nil {
+				info.Name = *graph.Name
+			}
+			if graph.CreatedAt != nil {
+				if createdAt, err := time.Parse(time.RFC3339Nano, *graph.CreatedAt); err == nil {
+					info.CreatedAt = createdAt
+				}
+			}
+			graphs = append(graphs, info)
+		}
+
+		if len(response.Graphs) < zepGraphListPageSize {
+			return graphs, nil
+		}
+	}
+}
+
+// AddMemory adds document chunks to a specific graph in Zep Cloud. Chunks are sent by a
+// bounded pool of workers and retried individually, so a large document isn't one
+// sequential round trip per chunk and a transient failure doesn't resend chunks that
+// were already added. Each episode is stamped with its chunk index and a creation time
+// in chunk order, since chunks finish out of order.
+//
+// Once a chunk has exhausted its retries no further chunks are started. The returned
+// episode IDs, in chunk order, include those added before the failure so they can still
+// be removed later; the error reports how many chunks were not added.
+func (s *zepService) AddMemory(ctx context.Context, graphID string, chunks []string, dataType MemoryDataType, metadata map[string]any) ([]string, error) {
+	sourceDesc := "Document"
+	if desc, ok := metadata["source_description"].(string); ok {
+		sourceDesc = desc
+	}
+	createdAt := time.Now().UTC()
+
+	episodeIDs := make([]string, len(chunks))
+	errs := make([]error, len(chunks))
+	semaphore := make(chan struct{}, addMemoryConcurrency)
+
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+
+dispatch:
+	for i, chunk := range chunks {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case semaphore <- struct{}{}:
+		}
 
+		// Chunks already in flight finish so their episodes are tracked
+		if failed.Load() {
+			<-semaphore
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			request := &v3.AddDataRequest{
+				GraphID:           v3.String(graphID),
+				Data:              chunk,
+				Type:              v3.GraphDataType(dataType),
+				SourceDescription: v3.String(fmt.Sprintf("%s, chunk %d of %d", sourceDesc, i+1, len(chunks))),
+				CreatedAt:         v3.String(createdAt.Add(time.Duration(i) * time.Millisecond).Format(time.RFC3339Nano)),
+			}
+
+			episodeIDs[i], errs[i] = s.addChunk(ctx, request)
+			if errs[i] != nil {
+				failed.Store(true)
+			}
+		}()
+	}
+	wg.Wait()
+
+	added := make([]string, 0, len(chunks))
+	for _, episodeID := range episodeIDs {
+		if episodeID != "" {
+			added = append(added, episodeID)
+		}
+	}
+
+	for i, err := range errs {
+		if err != nil {
+			return added, fmt.Errorf("failed to add %d of %d chunks to graph, chunk %d: %w", len(chunks)-len(added), len(chunks), i, err)
+		}
+	}
+
+	if len(added) < len(chunks) {
+		if err := ctx.Err(); err != nil {
+			return added, fmt.Errorf("failed to add %d of %d chunks to graph: %w", len(chunks)-len(added), len(chunks), err)
+		}
+	}
+
+	return added, nil
+}
+
+// addChunk adds a single chunk to Zep, retrying with exponential backoff
+func (s *zepService) addChunk(ctx context.Context, request *v3.AddDataRequest) (string, error) {
 	var lastErr error
 
-	for attempt := range maxRetries {
+	for attempt := range addChunkMaxRetries {
 		if attempt > 0 {
 			// Exponential backoff
-			delay := baseDelay * time.Duration(1<<uint(attempt-1))
+			delay := addChunkBaseDelay * time.Duration(1<<uint(attempt-1))
 			select {
 			case <-ctx.Done():
-				return ctx.Err()
+				return "", ctx.Err()
 			case <-time.After(delay):
 			}
 		}
 
-		err := s.addMemoryAttempt(ctx, graphID, chunks, metadata)
+		episode, err := s.client.Graph.Add(ctx, request)
 		if err == nil {
-			return nil
+			if episode == nil {
+				return "", nil
+			}
+			return episode.UUID, nil
 		}
 
 		lastErr = err
 	}
 
-	return fmt.Errorf("failed to add memory after %d attempts: %w", maxRetries, lastErr)
+	return "", fmt.Errorf("failed after %d attempts: %w", addChunkMaxRetries, lastErr)
 }
 
-// addMemoryAttempt performs a single attempt to add memory to Zep
-func (s *zepService) addMemoryAttempt(ctx context.Context, graphID string, chunks []string, metadata map[string]any) error {
-	// Add each chunk as graph data using the Graph API
-	// This will automatically build the knowledge graph through Zep's Grafiti
-	for i, chunk := range chunks {
-		// Create source description from metadata if available
-		sourceDesc := fmt.Sprintf("Document chunk %d", i+1)
-		if desc, ok := metadata["source_description"].(string); ok {
-			sourceDesc = desc
-		}
+// RemoveDocumentData deletes episodes from Zep, which also removes the facts extracted from them.
+// Episodes that no longer exist are skipped; other failures are reported after trying every episode.
+func (s *zepService) RemoveDocumentData(ctx context.Context, episodeIDs []string) error {
+	var failed int
+	var lastErr error
 
-		request := &v3.AddDataRequest{
-			GraphID:           v3.String(graphID),
-			Data:              chunk,
-			Type:              v3.GraphDataTypeText,
-			SourceDescription: &sourceDesc,
+	for _, episodeID := range episodeIDs {
+		_, err := s.client.Graph.Episode.Delete(ctx, episodeID)
+		if err == nil {
+			continue
 		}
 
-		_, err := s.client.Graph.Add(ctx, request)
-		if err != nil {
-			return fmt.Errorf("failed to add chunk %d to graph: %w", i, err)
+		var notFound *v3.NotFoundError
+		if errors.As(err, &notFound) {
+			continue
 		}
+
+		failed++
+		lastErr = err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d episodes from Zep: %w", failed, len(episodeIDs), lastErr)
 	}
 
 	return nil
@@ -202,14 +405,14 @@ func (s *zepService) GetGraph(ctx context.Context, graphID, query string) (*mode
 	fmt.Printf("Total nodes in graph: %d\n", len(allNodes))
 
 	// Step 4: Transform to internal format, filtering nodes to only those referenced by edges
-	graphData := transformZepGraphToInternal(searchResults, allNodes, nodeIDs)
+	graphData := transformZepGraphToInternal(searchResults, allNodes, nodeIDs, s.nodeStyle)
 
 	return graphData, nil
 }
 
 // transformZepGraphToInternal converts Zep's graph format to our internal format
-// preserving all metadata from Zep for rich visualization
-func transformZepGraphToInternal(searchResults *v3.GraphSearchResults, allNodes []*v3.EntityNode, nodeIDsToInclude map[string]bool) *models.GraphData {
+// preserving all metadata from Zep for rich visualization, with nodes styled by style
+func transformZepGraphToInternal(searchResults *v3.GraphSearchResults, allNodes []*v3.EntityNode, nodeIDsToInclude map[string]bool, style NodeStyle) *models.GraphData {
 	// Create a map of nodes that are referenced by edges
 	nodeMap := make(map[string]*v3.EntityNode)
 
@@ -233,8 +436,8 @@ func transformZepGraphToInternal(searchResults *v3.GraphSearchResults, allNodes
 			Attributes: zepNode.Attributes,
 			CreatedAt:  zepNode.CreatedAt,
 			// Visualization properties
-			Size:  calculateNodeSize(zepNode),
-			Color: generateNodeColor(zepNode),
+			Size:  style.nodeSize(zepNode),
+			Color: style.nodeColor(zepNode),
 		}
 		nodes = append(nodes, node)
 	}
@@ -288,64 +491,6 @@ func transformZepGraphToInternal(searchResults *v3.GraphSearchResults, allNodes
 	}
 }
 
-// calculateNodeSize determines the size of a node based on its properties
-func calculateNodeSize(node *v3.EntityNode) float64 {
-	// Base size
-	size := 10.0
-
-	// Increase size based on number of labels (more labels = more important)
-	if node.Labels != nil {
-		size += float64(len(node.Labels)) * 2.0
-	}
-
-	// Use score if available
-	if node.Score != nil {
-		size += *node.Score * 10.0
-	}
-
-	return size
-}
-
-// generateNodeColor generates a color for a node based on its labels
-func generateNodeColor(node *v3.EntityNode) string {
-	// Default color
-	defaultColor := "#3b82f6" // Blue
-
-	if len(node.Labels) == 0 {
-		return defaultColor
-	}
-
-	// Generate color based on primary label
-	primaryLabel := node.Labels[0]
-
-	// Simple color mapping based on common entity types
-	colorMap := map[string]string{
-		"Person":       "#ef4444", // Red
-		"Organization": "#10b981", // Green
-		"Location":     "#f59e0b", // Orange
-		"Event":        "#8b5cf6", // Purple
-		"Concept":      "#06b6d4", // Cyan
-		"Document":     "#ec4899", // Pink
-	}
-
-	if color, exists := colorMap[primaryLabel]; exists {
-		return color
-	}
-
-	// Generate a consistent color based on label hash
-	hash := 0
-	for _, char := range primaryLabel {
-		hash = int(char) + ((hash << 5) - hash)
-	}
-
-	// Use hash to generate RGB values
-	r := (hash & 0xFF0000) >> 16
-	g := (hash & 0x00FF00) >> 8
-	b := hash & 0x0000FF
-
-	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
-}
-
 // SearchMemory searches for memory in a specific graph
 func (s *zepService) SearchMemory(ctx context.Context, graphID, query string) ([]models.MemoryResult, error) {
 	// Search for episodes (memory entries) in the graph
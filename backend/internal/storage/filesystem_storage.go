@@ -0,0 +1,299 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// multipartDir is the directory under the storage root holding the parts of unfinished
+// multipart uploads, one subdirectory per upload
+const multipartDir = ".multipart"
+
+// FilesystemStorageService implements StorageService on the local filesystem.
+// Intended for development and single-node deployments that don't need S3.
+type FilesystemStorageService struct {
+	root      string
+	keyLayout string
+}
+
+// NewFilesystemStorageService creates a new filesystem storage service rooted at root.
+// The root directory is created if it doesn't exist. An empty keyLayout uses DefaultKeyLayout.
+func NewFilesystemStorageService(root, keyLayout string) (*FilesystemStorageService, error) {
+	if root == "" {
+		return nil, fmt.Errorf("filesystem storage root is required")
+	}
+
+	if keyLayout == "" {
+		keyLayout = DefaultKeyLayout
+	}
+	if err := ValidateKeyLayout(keyLayout); err != nil {
+		return nil, err
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage root: %w", err)
+	}
+
+	if err := os.MkdirAll(absRoot, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage root: %w", err)
+	}
+
+	return &FilesystemStorageService{root: absRoot, keyLayout: keyLayout}, nil
+}
+
+// Upload writes content to a file under the storage root
+func (s *FilesystemStorageService) Upload(ctx context.Context, userID string, graphID string, documentID string, filename string, content io.Reader, contentType string) (string, error) {
+	// Keys are built the same way as for S3 so documents can move between backends
+	storageKey, err := buildStorageKey(s.keyLayout, userID, graphID, documentID, filename)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := s.pathForKey(storageKey)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	// Write to a temporary file and rename so readers never see a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to store file: %w", err)
+	}
+
+	return storageKey, nil
+}
+
+// Download opens the file stored under storageKey
+func (s *FilesystemStorageService) Download(ctx context.Context, storageKey string) (io.ReadCloser, error) {
+	path, err := s.pathForKey(storageKey)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	return file, nil
+}
+
+// Delete removes the file stored under storageKey. Deleting a missing file is not an error.
+func (s *FilesystemStorageService) Delete(ctx context.Context, storageKey string) error {
+	path, err := s.pathForKey(storageKey)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
+
+// GetURL is not supported, since files on local disk have no shareable URL
+func (s *FilesystemStorageService) GetURL(ctx context.Context, storageKey string, expirationMinutes int) (string, error) {
+	return "", fmt.Errorf("presigned URLs are not supported by filesystem storage")
+}
+
+// Exists checks whether a file is stored under storageKey
+func (s *FilesystemStorageService) Exists(ctx context.Context, storageKey string) (bool, error) {
+	path, err := s.pathForKey(storageKey)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check file: %w", err)
+	}
+
+	return true, nil
+}
+
+// CreateMultipartUpload starts a multipart upload. Parts are kept in their own directory
+// until the upload is completed or aborted.
+func (s *FilesystemStorageService) CreateMultipartUpload(ctx context.Context, userID string, graphID string, documentID string, filename string, contentType string) (string, string, error) {
+	storageKey, err := buildStorageKey(s.keyLayout, userID, graphID, documentID, filename)
+	if err != nil {
+		return "", "", err
+	}
+
+	uploadID := uuid.New().String()
+	if err := os.MkdirAll(filepath.Join(s.root, multipartDir, uploadID), 0o750); err != nil {
+		return "", "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	return storageKey, uploadID, nil
+}
+
+// UploadPart writes one part of a multipart upload and returns its SHA-256 as the ETag.
+// Uploading a part number again replaces the earlier part.
+func (s *FilesystemStorageService) UploadPart(ctx context.Context, storageKey string, uploadID string, partNumber int32, content io.ReadSeeker, size int64) (string, error) {
+	path, err := s.pathForPart(uploadID, partNumber)
+	if err != nil {
+		return "", err
+	}
+
+	// Write to a temporary file and rename so a failed write never leaves a partial part
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".part-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), content); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write part %d: %w", partNumber, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write part %d: %w", partNumber, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to store part %d: %w", partNumber, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// CompleteMultipartUpload concatenates the parts, in the given order, into the file stored
+// under storageKey and removes the upload's directory
+func (s *FilesystemStorageService) CompleteMultipartUpload(ctx context.Context, storageKey string, uploadID string, parts []CompletedPart) error {
+	path, err := s.pathForKey(storageKey)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	for _, part := range parts {
+		if err := s.appendPart(tmp, uploadID, part); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store file: %w", err)
+	}
+
+	return s.AbortMultipartUpload(ctx, storageKey, uploadID)
+}
+
+// appendPart copies an uploaded part to w after checking it is the part that was uploaded
+func (s *FilesystemStorageService) appendPart(w io.Writer, uploadID string, part CompletedPart) error {
+	path, err := s.pathForPart(uploadID, part.PartNumber)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open part %d: %w", part.PartNumber, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, hash), file); err != nil {
+		return fmt.Errorf("failed to copy part %d: %w", part.PartNumber, err)
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != part.ETag {
+		return fmt.Errorf("part %d does not match its ETag", part.PartNumber)
+	}
+
+	return nil
+}
+
+// AbortMultipartUpload removes the parts of a multipart upload. Aborting an upload that no
+// longer exists is not an error.
+func (s *FilesystemStorageService) AbortMultipartUpload(ctx context.Context, storageKey string, uploadID string) error {
+	dir, err := s.pathForUpload(uploadID)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove upload parts: %w", err)
+	}
+
+	return nil
+}
+
+// pathForUpload returns the directory holding a multipart upload's parts. Upload IDs are
+// UUIDs generated by CreateMultipartUpload, so anything else is rejected.
+func (s *FilesystemStorageService) pathForUpload(uploadID string) (string, error) {
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return "", fmt.Errorf("invalid upload ID %q", uploadID)
+	}
+
+	return filepath.Join(s.root, multipartDir, uploadID), nil
+}
+
+// pathForPart returns the path of a part of an existing multipart upload
+func (s *FilesystemStorageService) pathForPart(uploadID string, partNumber int32) (string, error) {
+	dir, err := s.pathForUpload(uploadID)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf("multipart upload %s not found: %w", uploadID, err)
+	}
+
+	return filepath.Join(dir, fmt.Sprintf("%05d", partNumber)), nil
+}
+
+// pathForKey maps a storage key to a path under the root, rejecting keys that escape it
+func (s *FilesystemStorageService) pathForKey(storageKey string) (string, error) {
+	if err := validateStorageKey(storageKey); err != nil {
+		return "", err
+	}
+	if !filepath.IsLocal(filepath.FromSlash(storageKey)) {
+		return "", fmt.Errorf("invalid storage key %q", storageKey)
+	}
+
+	return filepath.Join(s.root, filepath.FromSlash(storageKey)), nil
+}
@@ -2,14 +2,23 @@ package storage
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/bipulkrdas/orgmind/backend/internal/tracing"
 	"github.com/google/uuid"
 )
 
@@ -18,6 +27,12 @@ type S3StorageService struct {
 	client *s3.Client
 	bucket string
 	region string
+
+	serverSideEncryption types.ServerSideEncryption
+	kmsKeyID             string
+	storageClass         types.StorageClass
+
+	keyLayout string
 }
 
 // S3Config holds configuration for S3 storage
@@ -26,55 +41,180 @@ type S3Config struct {
 	Bucket          string
 	AccessKeyID     string
 	SecretAccessKey string
+
+	// Optional settings for S3-compatible stores such as MinIO
+	Endpoint     string // Custom endpoint URL; empty uses AWS S3
+	UsePathStyle bool   // Address buckets as endpoint/bucket instead of bucket.endpoint
+
+	// Optional upload settings; empty values fall back to the bucket defaults
+	ServerSideEncryption string // "AES256" (SSE-S3) or "aws:kms" (SSE-KMS)
+	KMSKeyID             string // KMS key ID or ARN; requires ServerSideEncryption "aws:kms"
+	StorageClass         string // e.g. STANDARD, STANDARD_IA, INTELLIGENT_TIERING
+
+	// Layout of object keys, e.g. "{graphId}/{documentId}/{filename}"; empty uses DefaultKeyLayout
+	KeyLayout string
 }
 
 // NewS3StorageService creates a new S3 storage service
 func NewS3StorageService(ctx context.Context, cfg S3Config) (*S3StorageService, error) {
-	// Create AWS config with credentials
-	awsCfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion(cfg.Region),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+	if err := cfg.validateUploadOptions(); err != nil {
+		return nil, err
+	}
+
+	keyLayout := cfg.KeyLayout
+	if keyLayout == "" {
+		keyLayout = DefaultKeyLayout
+	}
+	if err := ValidateKeyLayout(keyLayout); err != nil {
+		return nil, err
+	}
+
+	// S3-compatible stores generally ignore the region, but request signing still needs one
+	region := cfg.Region
+	if region == "" && cfg.Endpoint != "" {
+		region = "us-east-1"
+	}
+
+	// Create AWS config; static credentials take precedence over the default chain
+	// (environment, shared config, IAM role) when both keys are provided
+	loadOptions := []func(*config.LoadOptions) error{config.WithRegion(region), config.WithHTTPClient(tracing.HTTPClient())}
+	if cfg.AccessKeyID != "" && cfg.SecretAccessKey != "" {
+		loadOptions = append(loadOptions, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
 			cfg.AccessKeyID,
 			cfg.SecretAccessKey,
 			"",
-		)),
-	)
+		)))
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx, loadOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	// Create S3 client
-	client := s3.NewFromConfig(awsCfg)
+	// Create S3 client, pointed at the custom endpoint if one is configured
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+		o.UsePathStyle = cfg.UsePathStyle
+	})
 
 	return &S3StorageService{
 		client: client,
 		bucket: cfg.Bucket,
-		region: cfg.Region,
+		region: region,
+
+		serverSideEncryption: types.ServerSideEncryption(cfg.ServerSideEncryption),
+		kmsKeyID:             cfg.KMSKeyID,
+		storageClass:         types.StorageClass(cfg.StorageClass),
+
+		keyLayout: keyLayout,
 	}, nil
 }
 
-// Upload uploads content to S3 with retry logic
-func (s *S3StorageService) Upload(ctx context.Context, userID string, documentID string, filename string, content io.Reader, contentType string) (string, error) {
-	// Generate storage key with user-specific prefix
-	storageKey := fmt.Sprintf("%s/%s", userID, documentID)
+// validateUploadOptions checks the encryption and storage class settings against the values S3 accepts
+func (cfg S3Config) validateUploadOptions() error {
+	if cfg.ServerSideEncryption != "" && !slices.Contains(types.ServerSideEncryption("").Values(), types.ServerSideEncryption(cfg.ServerSideEncryption)) {
+		return fmt.Errorf("unsupported S3 server-side encryption %q", cfg.ServerSideEncryption)
+	}
+
+	if cfg.KMSKeyID != "" && cfg.ServerSideEncryption != string(types.ServerSideEncryptionAwsKms) {
+		return fmt.Errorf("S3 KMS key ID requires server-side encryption %q", types.ServerSideEncryptionAwsKms)
+	}
+
+	if cfg.StorageClass != "" && !slices.Contains(types.StorageClass("").Values(), types.StorageClass(cfg.StorageClass)) {
+		return fmt.Errorf("unsupported S3 storage class %q", cfg.StorageClass)
+	}
+
+	return nil
+}
+
+// Upload uploads content to S3 with retry logic. Seekable content is verified: S3 rejects a
+// body that doesn't match its MD5 digest, and the stored object's size and ETag are checked
+// afterwards. A new object that fails verification is deleted and the upload attempted again.
+// Verified content replacing an existing object is uploaded under a temporary key and only
+// copied over the object once it passes, so a failed upload leaves the old content intact.
+func (s *S3StorageService) Upload(ctx context.Context, userID string, graphID string, documentID string, filename string, content io.Reader, contentType string) (string, error) {
+	// Generate storage key from the configured layout
+	storageKey, err := buildStorageKey(s.keyLayout, userID, graphID, documentID, filename)
+	if err != nil {
+		return "", err
+	}
+
+	// Measure seekable content up front so the stored object can be checked against it
+	var size int64
+	var digest []byte
+	readSeeker, verify := content.(io.ReadSeeker)
+	if verify {
+		size, digest, err = contentDigest(readSeeker)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	uploadKey := storageKey
+	if verify {
+		exists, err := s.Exists(ctx, storageKey)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			uploadKey = storageKey + ".upload-" + uuid.New().String()
+			// The temporary object is never referenced, whether or not it was copied
+			defer func() { _ = s.Delete(ctx, uploadKey) }()
+		}
+	}
 
 	// Prepare upload input
 	input := &s3.PutObjectInput{
 		Bucket:      aws.String(s.bucket),
-		Key:         aws.String(storageKey),
+		Key:         aws.String(uploadKey),
 		Body:        content,
 		ContentType: aws.String(contentType),
 		Metadata: map[string]string{
 			"filename":    filename,
 			"document-id": documentID,
 			"user-id":     userID,
+			"graph-id":    graphID,
 		},
 	}
 
+	// Apply encryption at rest and storage class when configured
+	if s.serverSideEncryption != "" {
+		input.ServerSideEncryption = s.serverSideEncryption
+	}
+	if s.kmsKeyID != "" {
+		input.SSEKMSKeyId = aws.String(s.kmsKeyID)
+	}
+	if s.storageClass != "" {
+		input.StorageClass = s.storageClass
+	}
+	if verify {
+		input.ContentMD5 = aws.String(base64.StdEncoding.EncodeToString(digest))
+	}
+
 	// Implement retry logic (3 attempts with exponential backoff)
 	var lastErr error
 	for attempt := 1; attempt <= 3; attempt++ {
-		_, err := s.client.PutObject(ctx, input)
+		// Rewind streamed content consumed by a failed attempt
+		if seeker, ok := content.(io.Seeker); ok && attempt > 1 {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return "", fmt.Errorf("failed to rewind content for retry: %w", err)
+			}
+		}
+
+		output, err := s.client.PutObject(ctx, input)
+		if err == nil && verify {
+			err = s.verifyUpload(ctx, uploadKey, aws.ToString(output.ETag), size, digest)
+			// Don't leave a corrupt new object behind if no later attempt overwrites it. A HEAD
+			// request that failed says nothing about the object, so it is kept then.
+			if errors.Is(err, ErrUploadVerificationFailed) && uploadKey == storageKey {
+				_ = s.Delete(ctx, storageKey)
+			}
+		}
+		if err == nil && uploadKey != storageKey {
+			err = s.copyObject(ctx, uploadKey, storageKey)
+		}
 		if err == nil {
 			return storageKey, nil
 		}
@@ -92,6 +232,68 @@ func (s *S3StorageService) Upload(ctx context.Context, userID string, documentID
 	return "", fmt.Errorf("failed to upload to S3 after 3 attempts: %w", lastErr)
 }
 
+// copyObject copies the object under sourceKey to destinationKey, keeping its content type
+// and metadata and applying the configured encryption and storage class
+func (s *S3StorageService) copyObject(ctx context.Context, sourceKey, destinationKey string) error {
+	input := &s3.CopyObjectInput{
+		Bucket:     aws.String(s.bucket),
+		Key:        aws.String(destinationKey),
+		CopySource: aws.String((&url.URL{Path: s.bucket + "/" + sourceKey}).EscapedPath()),
+	}
+	if s.serverSideEncryption != "" {
+		input.ServerSideEncryption = s.serverSideEncryption
+	}
+	if s.kmsKeyID != "" {
+		input.SSEKMSKeyId = aws.String(s.kmsKeyID)
+	}
+	if s.storageClass != "" {
+		input.StorageClass = s.storageClass
+	}
+
+	if _, err := s.client.CopyObject(ctx, input); err != nil {
+		return fmt.Errorf("failed to replace object in S3: %w", err)
+	}
+
+	return nil
+}
+
+// contentDigest returns the size and MD5 digest of content, rewinding it afterwards
+func contentDigest(content io.ReadSeeker) (int64, []byte, error) {
+	hash := md5.New()
+	size, err := io.Copy(hash, content)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read content: %w", err)
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return 0, nil, fmt.Errorf("failed to rewind content: %w", err)
+	}
+	return size, hash.Sum(nil), nil
+}
+
+// verifyUpload checks that the object stored under storageKey has the uploaded size and, when
+// its ETag is the MD5 digest of its content, the uploaded digest. ETags of objects encrypted
+// with KMS keys are not digests, so those are only checked by size.
+func (s *S3StorageService) verifyUpload(ctx context.Context, storageKey, etag string, size int64, digest []byte) error {
+	head, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(storageKey),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to verify upload: %w", err)
+	}
+
+	if stored := aws.ToInt64(head.ContentLength); stored != size {
+		return fmt.Errorf("%w: stored %d of %d bytes", ErrUploadVerificationFailed, stored, size)
+	}
+
+	etag = strings.Trim(etag, `"`)
+	if s.serverSideEncryption != types.ServerSideEncryptionAwsKms && etag != "" && !strings.Contains(etag, "-") && etag != hex.EncodeToString(digest) {
+		return fmt.Errorf("%w: ETag %s does not match the content's MD5 digest", ErrUploadVerificationFailed, etag)
+	}
+
+	return nil
+}
+
 // Download retrieves content from S3
 func (s *S3StorageService) Download(ctx context.Context, storageKey string) (io.ReadCloser, error) {
 	input := &s3.GetObjectInput{
@@ -122,6 +324,25 @@ func (s *S3StorageService) Delete(ctx context.Context, storageKey string) error
 	return nil
 }
 
+// Exists checks whether an object exists in S3 without downloading it
+func (s *S3StorageService) Exists(ctx context.Context, storageKey string) (bool, error) {
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(storageKey),
+	}
+
+	_, err := s.client.HeadObject(ctx, input)
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check object in S3: %w", err)
+	}
+
+	return true, nil
+}
+
 // GetURL returns a presigned URL for accessing the content
 func (s *S3StorageService) GetURL(ctx context.Context, storageKey string, expirationMinutes int) (string, error) {
 	// Create presign client
@@ -143,6 +364,103 @@ func (s *S3StorageService) GetURL(ctx context.Context, storageKey string, expira
 	return result.URL, nil
 }
 
+// CreateMultipartUpload starts a multipart upload to the key built from the configured layout,
+// with the same metadata, encryption and storage class as Upload
+func (s *S3StorageService) CreateMultipartUpload(ctx context.Context, userID string, graphID string, documentID string, filename string, contentType string) (string, string, error) {
+	storageKey, err := buildStorageKey(s.keyLayout, userID, graphID, documentID, filename)
+	if err != nil {
+		return "", "", err
+	}
+
+	input := &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(storageKey),
+		ContentType: aws.String(contentType),
+		Metadata: map[string]string{
+			"filename":    filename,
+			"document-id": documentID,
+			"user-id":     userID,
+			"graph-id":    graphID,
+		},
+	}
+
+	if s.serverSideEncryption != "" {
+		input.ServerSideEncryption = s.serverSideEncryption
+	}
+	if s.kmsKeyID != "" {
+		input.SSEKMSKeyId = aws.String(s.kmsKeyID)
+	}
+	if s.storageClass != "" {
+		input.StorageClass = s.storageClass
+	}
+
+	result, err := s.client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create multipart upload in S3: %w", err)
+	}
+
+	return storageKey, aws.ToString(result.UploadId), nil
+}
+
+// UploadPart uploads one part of a multipart upload and returns its ETag. Uploading a part
+// number again replaces the earlier part.
+func (s *S3StorageService) UploadPart(ctx context.Context, storageKey string, uploadID string, partNumber int32, content io.ReadSeeker, size int64) (string, error) {
+	result, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(storageKey),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(partNumber),
+		Body:          content,
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload part %d to S3: %w", partNumber, err)
+	}
+
+	return aws.ToString(result.ETag), nil
+}
+
+// CompleteMultipartUpload assembles the uploaded parts, in part number order, into the object
+func (s *S3StorageService) CompleteMultipartUpload(ctx context.Context, storageKey string, uploadID string, parts []CompletedPart) error {
+	completed := make([]types.CompletedPart, len(parts))
+	for i, part := range parts {
+		completed[i] = types.CompletedPart{
+			ETag:       aws.String(part.ETag),
+			PartNumber: aws.Int32(part.PartNumber),
+		}
+	}
+
+	_, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(storageKey),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload in S3: %w", err)
+	}
+
+	return nil
+}
+
+// AbortMultipartUpload discards a multipart upload and the parts uploaded so far
+func (s *S3StorageService) AbortMultipartUpload(ctx context.Context, storageKey string, uploadID string) error {
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(storageKey),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		var noSuchUpload *types.NoSuchUpload
+		if errors.As(err, &noSuchUpload) {
+			return nil
+		}
+		return fmt.Errorf("failed to abort multipart upload in S3: %w", err)
+	}
+
+	return nil
+}
+
 // GenerateDocumentID generates a unique document ID
 func GenerateDocumentID() string {
 	return uuid.New().String()
@@ -2,13 +2,31 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"strings"
+	"unicode"
 )
 
+// maxStorageKeyLength is the longest object key S3 accepts, in bytes
+const maxStorageKeyLength = 1024
+
+// DefaultKeyLayout is the storage key layout used when none is configured. Documents are
+// stored under their uploader, which was the only layout before layouts were configurable.
+const DefaultKeyLayout = "{userId}/{documentId}"
+
+// ErrUploadVerificationFailed is returned when the stored object doesn't match the uploaded
+// content, such as after a truncated upload. The incomplete object is removed.
+var ErrUploadVerificationFailed = errors.New("stored content does not match the uploaded file")
+
+// keyLayoutPlaceholders are the values a key layout can refer to
+var keyLayoutPlaceholders = []string{"{userId}", "{graphId}", "{documentId}", "{filename}"}
+
 // StorageService defines the interface for document storage operations
 type StorageService interface {
-	// Upload uploads content to storage and returns the storage key
-	Upload(ctx context.Context, userID string, documentID string, filename string, content io.Reader, contentType string) (string, error)
+	// Upload uploads content to storage and returns the storage key, built from the configured key layout
+	Upload(ctx context.Context, userID string, graphID string, documentID string, filename string, content io.Reader, contentType string) (string, error)
 	
 	// Download retrieves content from storage
 	Download(ctx context.Context, storageKey string) (io.ReadCloser, error)
@@ -18,4 +36,94 @@ type StorageService interface {
 	
 	// GetURL returns a presigned URL for accessing the content
 	GetURL(ctx context.Context, storageKey string, expirationMinutes int) (string, error)
+
+	// Exists reports whether content is stored under the given key
+	Exists(ctx context.Context, storageKey string) (bool, error)
+
+	// Multipart uploads store a large file in parts that can be retried individually. The
+	// parts become one object under the returned storage key once the upload is completed.
+	CreateMultipartUpload(ctx context.Context, userID string, graphID string, documentID string, filename string, contentType string) (storageKey string, uploadID string, err error)
+	UploadPart(ctx context.Context, storageKey string, uploadID string, partNumber int32, content io.ReadSeeker, size int64) (etag string, err error)
+	CompleteMultipartUpload(ctx context.Context, storageKey string, uploadID string, parts []CompletedPart) error
+	AbortMultipartUpload(ctx context.Context, storageKey string, uploadID string) error
+}
+
+// CompletedPart identifies an uploaded part of a multipart upload
+type CompletedPart struct {
+	PartNumber int32
+	ETag       string
+}
+
+// validateStorageKey rejects keys that are empty, too long, absolute, or that
+// contain control characters or "." / ".." segments
+func validateStorageKey(storageKey string) error {
+	if storageKey == "" || len(storageKey) > maxStorageKeyLength {
+		return fmt.Errorf("invalid storage key length %d", len(storageKey))
+	}
+
+	if strings.IndexFunc(storageKey, unicode.IsControl) >= 0 || strings.Contains(storageKey, "\\") {
+		return fmt.Errorf("invalid storage key %q: contains control characters or backslashes", storageKey)
+	}
+
+	for _, segment := range strings.Split(storageKey, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid storage key %q: empty or relative path segment", storageKey)
+		}
+	}
+
+	return nil
+}
+
+// ValidateKeyLayout checks a storage key layout such as "{graphId}/{documentId}/{filename}".
+// Layouts must include {documentId}, so every document gets its own key, and may only use
+// the placeholders userId, graphId, documentId and filename.
+func ValidateKeyLayout(layout string) error {
+	if !strings.Contains(layout, "{documentId}") {
+		return fmt.Errorf("storage key layout %q must contain {documentId}", layout)
+	}
+
+	rest := layout
+	for _, placeholder := range keyLayoutPlaceholders {
+		rest = strings.ReplaceAll(rest, placeholder, "")
+	}
+	if strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("storage key layout %q contains an unknown placeholder; use %s", layout, strings.Join(keyLayoutPlaceholders, ", "))
+	}
+
+	return nil
+}
+
+// buildStorageKey fills in a key layout. The filename is reduced to a single safe path
+// segment; other values are used as they are and the result is validated.
+func buildStorageKey(layout, userID, graphID, documentID, filename string) (string, error) {
+	storageKey := strings.NewReplacer(
+		"{userId}", userID,
+		"{graphId}", graphID,
+		"{documentId}", documentID,
+		"{filename}", keySafeFilename(filename),
+	).Replace(layout)
+
+	if err := validateStorageKey(storageKey); err != nil {
+		return "", err
+	}
+	return storageKey, nil
+}
+
+// keySafeFilename makes a filename usable as one storage key segment
+func keySafeFilename(filename string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, filename)
+
+	// Keep the end, which has the extension, if the name is very long
+	if len(safe) > 200 {
+		safe = strings.ToValidUTF8(safe[len(safe)-200:], "")
+	}
+	if safe == "" || safe == "." || safe == ".." {
+		return "content"
+	}
+	return safe
 }
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"io"
+
+	"github.com/bipulkrdas/orgmind/backend/internal/tracing"
+)
+
+// tracedStorageService records a span for every storage call
+type tracedStorageService struct {
+	storage StorageService
+}
+
+// NewTracedStorageService wraps a storage service so each call is recorded as a span of the
+// trace in its context (see the tracing package). Downloads are timed until the content
+// starts arriving, not until it has been read.
+func NewTracedStorageService(storage StorageService) StorageService {
+	return &tracedStorageService{storage: storage}
+}
+
+func (t *tracedStorageService) Upload(ctx context.Context, userID string, graphID string, documentID string, filename string, content io.Reader, contentType string) (string, error) {
+	ctx, span := tracing.Start(ctx, "storage.Upload", "document.id", documentID, "storage.content_type", contentType)
+	storageKey, err := t.storage.Upload(ctx, userID, graphID, documentID, filename, content, contentType)
+	span.Finish(err)
+	return storageKey, err
+}
+
+func (t *tracedStorageService) Download(ctx context.Context, storageKey string) (io.ReadCloser, error) {
+	ctx, span := tracing.Start(ctx, "storage.Download", "storage.key", storageKey)
+	content, err := t.storage.Download(ctx, storageKey)
+	span.Finish(err)
+	return content, err
+}
+
+func (t *tracedStorageService) Delete(ctx context.Context, storageKey string) error {
+	ctx, span := tracing.Start(ctx, "storage.Delete", "storage.key", storageKey)
+	err := t.storage.Delete(ctx, storageKey)
+	span.Finish(err)
+	return err
+}
+
+func (t *tracedStorageService) GetURL(ctx context.Context, storageKey string, expirationMinutes int) (string, error) {
+	ctx, span := tracing.Start(ctx, "storage.GetURL", "storage.key", storageKey)
+	url, err := t.storage.GetURL(ctx, storageKey, expirationMinutes)
+	span.Finish(err)
+	return url, err
+}
+
+func (t *tracedStorageService) Exists(ctx context.Context, storageKey string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "storage.Exists", "storage.key", storageKey)
+	exists, err := t.storage.Exists(ctx, storageKey)
+	span.Finish(err)
+	return exists, err
+}
+
+func (t *tracedStorageService) CreateMultipartUpload(ctx context.Context, userID string, graphID string, documentID string, filename string, contentType string) (string, string, error) {
+	ctx, span := tracing.Start(ctx, "storage.CreateMultipartUpload", "document.id", documentID)
+	storageKey, uploadID, err := t.storage.CreateMultipartUpload(ctx, userID, graphID, documentID, filename, contentType)
+	span.Finish(err)
+	return storageKey, uploadID, err
+}
+
+func (t *tracedStorageService) UploadPart(ctx context.Context, storageKey string, uploadID string, partNumber int32, content io.ReadSeeker, size int64) (string, error) {
+	ctx, span := tracing.Start(ctx, "storage.UploadPart", "storage.key", storageKey, "storage.part_number", int(partNumber), "storage.part_size_bytes", size)
+	etag, err := t.storage.UploadPart(ctx, storageKey, uploadID, partNumber, content, size)
+	span.Finish(err)
+	return etag, err
+}
+
+func (t *tracedStorageService) CompleteMultipartUpload(ctx context.Context, storageKey string, uploadID string, parts []CompletedPart) error {
+	ctx, span := tracing.Start(ctx, "storage.CompleteMultipartUpload", "storage.key", storageKey, "storage.part_count", len(parts))
+	err := t.storage.CompleteMultipartUpload(ctx, storageKey, uploadID, parts)
+	span.Finish(err)
+	return err
+}
+
+func (t *tracedStorageService) AbortMultipartUpload(ctx context.Context, storageKey string, uploadID string) error {
+	ctx, span := tracing.Start(ctx, "storage.AbortMultipartUpload", "storage.key", storageKey)
+	err := t.storage.AbortMultipartUpload(ctx, storageKey, uploadID)
+	span.Finish(err)
+	return err
+}
@@ -0,0 +1,37 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// logExporter writes each finished span to the log
+type logExporter struct{}
+
+// ExportSpans logs each span's timing, attributes and error
+func (logExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	for _, span := range spans {
+		var details strings.Builder
+		for _, attr := range span.Attributes() {
+			fmt.Fprintf(&details, " %s=%s", attr.Key, attr.Value.Emit())
+		}
+		if status := span.Status(); status.Code == codes.Error {
+			fmt.Fprintf(&details, " error=%q", status.Description)
+		}
+
+		log.Printf("Trace %s span %s (parent %s): %s took %s%s",
+			span.SpanContext().TraceID(), span.SpanContext().SpanID(), span.Parent().SpanID(),
+			span.Name(), span.EndTime().Sub(span.StartTime()), details.String())
+	}
+	return nil
+}
+
+// Shutdown does nothing; spans are logged as they finish
+func (logExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
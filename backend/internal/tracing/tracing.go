@@ -0,0 +1,163 @@
+// Package tracing records spans around requests and the external calls they make (Zep,
+// Gemini, storage), so slow requests can be broken down by where the time went.
+//
+// Spans are OpenTelemetry spans. Traces follow the W3C Trace Context format, so they join
+// traces started by callers that send a traceparent header, and the trace is passed on to
+// Zep, Gemini and S3 through the headers of their HTTP requests. Spans are exported to an
+// OpenTelemetry collector over OTLP/HTTP or written to the log. Tracing is off until
+// Configure installs an exporter; until then the no-op tracer provider records nothing.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters selectable with TRACING_EXPORTER
+const (
+	ExporterNone = "none" // Don't record spans (default)
+	ExporterLog  = "log"  // Log each finished span
+	ExporterOTLP = "otlp" // Send spans to an OpenTelemetry collector over OTLP/HTTP
+)
+
+// TraceparentHeader carries the caller's trace context into a request
+const TraceparentHeader = "traceparent"
+
+// scopeName identifies the code producing the spans
+const scopeName = "github.com/bipulkrdas/orgmind/backend/internal/tracing"
+
+// Config holds tracing configuration
+type Config struct {
+	Exporter      string // One of the Exporter constants
+	OTLPEndpoint  string // OTLP/HTTP traces endpoint, e.g. http://localhost:4318/v1/traces
+	ServiceName   string // service.name of the exported spans
+	SamplePercent int    // Percentage of new traces recorded; traces continued from a caller follow its decision
+}
+
+func init() {
+	// Pass trace context through even while tracing is off, so a caller's trace reaches Zep,
+	// Gemini and S3
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+// Configure installs the exporter selected by cfg as the global tracer provider and returns
+// a function that flushes and stops it. With ExporterNone, tracing stays off.
+func Configure(cfg Config) (shutdown func(context.Context) error, err error) {
+	var processor sdktrace.SpanProcessor
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterLog:
+		processor = sdktrace.NewSimpleSpanProcessor(logExporter{})
+	case ExporterOTLP:
+		exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		processor = sdktrace.NewBatchSpanProcessor(exporter)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(float64(cfg.SamplePercent)/100))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Span is an operation being timed. Spans of traces that aren't recorded, because tracing
+// is off or the trace wasn't sampled, ignore every call.
+type Span struct {
+	span trace.Span
+}
+
+// Start begins a span named name as a child of the span in ctx, or as the root of a new
+// trace, and returns a context carrying it. Attributes are given as key, value pairs.
+func Start(ctx context.Context, name string, attributes ...any) (context.Context, *Span) {
+	ctx, span := otel.Tracer(scopeName).Start(ctx, name, trace.WithAttributes(keyValues(attributes)...))
+	return ctx, &Span{span: span}
+}
+
+// SetAttribute records a value describing the operation, such as a status code
+func (s *Span) SetAttribute(key string, value any) {
+	s.span.SetAttributes(keyValue(key, value))
+}
+
+// Fail marks the operation as failed with message
+func (s *Span) Fail(message string) {
+	s.span.SetStatus(codes.Error, message)
+}
+
+// Finish ends the span, marking it failed if err is set. Only the first call has an effect.
+func (s *Span) Finish(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.Fail(err.Error())
+	}
+	s.span.End()
+}
+
+// TraceID returns the ID of the span's trace, or "" if it isn't part of one
+func (s *Span) TraceID() string {
+	if spanContext := s.span.SpanContext(); spanContext.HasTraceID() {
+		return spanContext.TraceID().String()
+	}
+	return ""
+}
+
+// Extract continues the trace described by the traceparent header of an incoming request,
+// so spans started from the returned context join the caller's trace and follow its
+// sampling decision. Missing or malformed headers leave ctx unchanged.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// HTTPClient returns an HTTP client for calls to external services. Each request is
+// recorded as a span and carries the trace of its context in a traceparent header.
+func HTTPClient() *http.Client {
+	return &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+}
+
+// keyValues converts key, value pairs to attributes, skipping pairs without a string key
+func keyValues(pairs []any) []attribute.KeyValue {
+	attributes := make([]attribute.KeyValue, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if key, ok := pairs[i].(string); ok {
+			attributes = append(attributes, keyValue(key, pairs[i+1]))
+		}
+	}
+	return attributes
+}
+
+// keyValue converts a value to an attribute of the matching type, or its string form
+func keyValue(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
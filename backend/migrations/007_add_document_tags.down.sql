@@ -0,0 +1,3 @@
+-- Remove tags column from documents table
+DROP INDEX IF EXISTS idx_documents_tags;
+ALTER TABLE documents DROP COLUMN tags;
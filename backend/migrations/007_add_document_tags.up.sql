@@ -0,0 +1,5 @@
+-- Add tags column to documents table for organizing documents within a graph
+ALTER TABLE documents ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Add GIN index on tags for efficient tag filtering
+CREATE INDEX IF NOT EXISTS idx_documents_tags ON documents USING GIN(tags);
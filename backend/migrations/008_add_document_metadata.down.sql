@@ -0,0 +1,2 @@
+-- Remove metadata column from documents table
+ALTER TABLE documents DROP COLUMN metadata;
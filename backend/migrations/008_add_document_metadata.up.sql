@@ -0,0 +1,2 @@
+-- Add metadata column to documents table for embedded document metadata (title, author, page count, ...)
+ALTER TABLE documents ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
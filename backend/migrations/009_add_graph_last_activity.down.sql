@@ -0,0 +1,3 @@
+-- Remove last_activity_at column from graphs table
+DROP INDEX IF EXISTS idx_graphs_last_activity_at;
+ALTER TABLE graphs DROP COLUMN last_activity_at;
@@ -0,0 +1,19 @@
+-- Add last_activity_at to graphs so they can be ordered by recent activity (?sort=activity).
+-- Kept up to date by the application on document uploads and chat messages.
+ALTER TABLE graphs ADD COLUMN last_activity_at TIMESTAMP WITH TIME ZONE;
+
+-- Backfill from the latest document upload and chat message in each graph
+UPDATE graphs g
+SET last_activity_at = GREATEST(
+    COALESCE(g.created_at, CURRENT_TIMESTAMP),
+    (SELECT MAX(d.created_at) FROM documents d WHERE d.graph_id = g.id),
+    (SELECT MAX(m.created_at)
+     FROM chat_messages m
+     JOIN chat_threads t ON m.thread_id = t.id
+     WHERE t.graph_id = g.id)
+);
+
+ALTER TABLE graphs ALTER COLUMN last_activity_at SET DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE graphs ALTER COLUMN last_activity_at SET NOT NULL;
+
+CREATE INDEX idx_graphs_last_activity_at ON graphs(last_activity_at DESC);
@@ -0,0 +1,2 @@
+-- Drop graph_favorites table
+DROP TABLE IF EXISTS graph_favorites;
@@ -0,0 +1,9 @@
+-- Create graph_favorites table (per-user pinned graphs, independent of membership role)
+CREATE TABLE graph_favorites (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    graph_id UUID NOT NULL REFERENCES graphs(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, graph_id)
+);
+
+CREATE INDEX idx_graph_favorites_graph_id ON graph_favorites(graph_id);
@@ -0,0 +1,2 @@
+-- Drop document_zep_episodes table
+DROP TABLE IF EXISTS document_zep_episodes;
@@ -0,0 +1,8 @@
+-- Create document_zep_episodes table (Zep episodes created from each document's chunks)
+CREATE TABLE document_zep_episodes (
+    episode_uuid VARCHAR(255) PRIMARY KEY,
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_document_zep_episodes_document_id ON document_zep_episodes(document_id);
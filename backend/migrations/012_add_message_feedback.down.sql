@@ -0,0 +1,2 @@
+-- Drop message_feedback table
+DROP TABLE IF EXISTS message_feedback;
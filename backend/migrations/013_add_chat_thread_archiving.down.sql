@@ -0,0 +1,6 @@
+-- Remove archiving from chat threads
+DROP INDEX IF EXISTS idx_chat_threads_graph_id_archived;
+
+ALTER TABLE chat_threads
+    DROP COLUMN IF EXISTS archived_at,
+    DROP COLUMN IF EXISTS archived;
@@ -0,0 +1,6 @@
+-- Add archiving to chat threads (archived threads are hidden from the default thread list)
+ALTER TABLE chat_threads
+    ADD COLUMN archived BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN archived_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_chat_threads_graph_id_archived ON chat_threads(graph_id, archived);
@@ -0,0 +1,2 @@
+-- Remove content format from documents table
+ALTER TABLE documents DROP COLUMN content_format;
@@ -0,0 +1,5 @@
+-- Add content format to documents so editor content in formats other than Lexical round-trips
+ALTER TABLE documents ADD COLUMN content_format VARCHAR(20);
+
+-- Existing editor documents were all written by the Lexical editor
+UPDATE documents SET content_format = 'lexical' WHERE source = 'editor';
@@ -0,0 +1,9 @@
+-- Restore HTML-escaped chat message content (&amp; is replaced first so it isn't escaped twice)
+UPDATE chat_messages
+SET content = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(content,
+    '&', '&amp;'),
+    '<', '&lt;'),
+    '>', '&gt;'),
+    '"', '&#34;'),
+    '''', '&#39;')
+WHERE content ~ '[&<>"'']';
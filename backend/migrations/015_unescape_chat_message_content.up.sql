@@ -0,0 +1,11 @@
+-- Chat messages used to be stored HTML-escaped; store them as written, as new messages now are.
+-- Reverses html.EscapeString exactly: &amp; is replaced last so escaped entities such as
+-- "&amp;lt;" (originally "&lt;") come back as typed.
+UPDATE chat_messages
+SET content = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(content,
+    '&lt;', '<'),
+    '&gt;', '>'),
+    '&#34;', '"'),
+    '&#39;', ''''),
+    '&amp;', '&')
+WHERE content LIKE '%&%';
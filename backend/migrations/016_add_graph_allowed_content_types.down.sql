@@ -0,0 +1,2 @@
+-- Remove allowed content types from graphs table
+ALTER TABLE graphs DROP COLUMN allowed_content_types;
@@ -0,0 +1,2 @@
+-- Add an optional per-graph list of MIME types that may be uploaded (empty = all supported formats)
+ALTER TABLE graphs ADD COLUMN allowed_content_types TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,4 @@
+-- Remove processing retry tracking from documents table
+DROP INDEX IF EXISTS idx_documents_next_retry_at;
+ALTER TABLE documents DROP COLUMN next_retry_at;
+ALTER TABLE documents DROP COLUMN processing_attempts;
@@ -0,0 +1,6 @@
+-- Track processing attempts so documents whose processing failed transiently can be retried automatically
+ALTER TABLE documents ADD COLUMN processing_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE documents ADD COLUMN next_retry_at TIMESTAMP WITH TIME ZONE;
+
+-- Partial index for finding failed documents whose retry is due
+CREATE INDEX idx_documents_next_retry_at ON documents(next_retry_at) WHERE next_retry_at IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Remove last processing attempt time from documents table
+ALTER TABLE documents DROP COLUMN last_attempt_at;
@@ -0,0 +1,2 @@
+-- Record when processing was last attempted, so documents that keep failing are visible to operators
+ALTER TABLE documents ADD COLUMN last_attempt_at TIMESTAMP WITH TIME ZONE;
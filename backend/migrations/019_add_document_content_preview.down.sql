@@ -0,0 +1,2 @@
+-- Remove content preview from documents table
+ALTER TABLE documents DROP COLUMN content_preview;
@@ -0,0 +1,3 @@
+-- Store a short snippet of each document's text so document lists can show a preview
+-- without downloading content from storage
+ALTER TABLE documents ADD COLUMN content_preview TEXT;
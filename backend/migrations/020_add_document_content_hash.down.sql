@@ -0,0 +1,2 @@
+-- Remove content hash from documents table
+ALTER TABLE documents DROP COLUMN content_hash;
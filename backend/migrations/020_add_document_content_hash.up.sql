@@ -0,0 +1,3 @@
+-- Store a hash of each document's stored content, so clients can revalidate cached content
+-- with an ETag instead of downloading it again
+ALTER TABLE documents ADD COLUMN content_hash TEXT;
@@ -0,0 +1,2 @@
+-- Remove default member role from graphs table
+ALTER TABLE graphs DROP COLUMN default_member_role;
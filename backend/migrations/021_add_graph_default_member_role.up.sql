@@ -0,0 +1,2 @@
+-- Role given to members added to a graph without an explicit role
+ALTER TABLE graphs ADD COLUMN default_member_role VARCHAR(50) NOT NULL DEFAULT 'member';
@@ -0,0 +1,4 @@
+-- Remove role constraints (normalized roles are kept)
+ALTER TABLE graphs DROP CONSTRAINT IF EXISTS graphs_default_member_role_check;
+ALTER TABLE graph_memberships DROP CONSTRAINT IF EXISTS graph_memberships_role_check;
+ALTER TABLE graph_memberships ALTER COLUMN role DROP NOT NULL;
@@ -0,0 +1,21 @@
+-- Normalize membership roles: trim and lowercase them, make each graph's creator its owner,
+-- and demote any other unknown or owner role to member
+UPDATE graph_memberships SET role = LOWER(TRIM(role)) WHERE role IS NOT NULL;
+
+UPDATE graph_memberships gm SET role = 'owner'
+FROM graphs g
+WHERE gm.graph_id = g.id AND gm.user_id = g.creator_id AND gm.role IS DISTINCT FROM 'owner';
+
+UPDATE graph_memberships gm SET role = 'member'
+FROM graphs g
+WHERE gm.graph_id = g.id AND gm.user_id <> g.creator_id
+  AND (gm.role IS NULL OR gm.role NOT IN ('editor', 'member', 'viewer'));
+
+-- Reject unknown roles from now on
+ALTER TABLE graph_memberships ALTER COLUMN role SET NOT NULL;
+ALTER TABLE graph_memberships ADD CONSTRAINT graph_memberships_role_check
+    CHECK (role IN ('owner', 'editor', 'member', 'viewer'));
+
+UPDATE graphs SET default_member_role = 'member' WHERE default_member_role NOT IN ('editor', 'member', 'viewer');
+ALTER TABLE graphs ADD CONSTRAINT graphs_default_member_role_check
+    CHECK (default_member_role IN ('editor', 'member', 'viewer'));
@@ -0,0 +1,2 @@
+-- Remove chat setting from graphs table
+ALTER TABLE graphs DROP COLUMN chat_enabled;
@@ -0,0 +1,2 @@
+-- Let graph owners turn off AI chat for a graph
+ALTER TABLE graphs ADD COLUMN chat_enabled BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,2 @@
+-- Remove Gemini usage tracking
+DROP TABLE IF EXISTS gemini_usage;
@@ -0,0 +1,9 @@
+-- Daily Gemini usage per graph, for cost reporting and monthly token caps
+CREATE TABLE IF NOT EXISTS gemini_usage (
+    graph_id UUID NOT NULL REFERENCES graphs(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    request_count BIGINT NOT NULL DEFAULT 0,
+    input_tokens BIGINT NOT NULL DEFAULT 0,
+    output_tokens BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (graph_id, day)
+);
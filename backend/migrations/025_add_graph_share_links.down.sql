@@ -0,0 +1,2 @@
+-- Remove graph share links
+DROP TABLE IF EXISTS graph_share_links;
@@ -0,0 +1,12 @@
+-- Read-only public share links for graphs. The link token is a signed JWT carrying the
+-- link ID, so only the link's metadata is stored and revocation is checked here.
+CREATE TABLE IF NOT EXISTS graph_share_links (
+    id UUID PRIMARY KEY,
+    graph_id UUID NOT NULL REFERENCES graphs(id) ON DELETE CASCADE,
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_graph_share_links_graph_id ON graph_share_links(graph_id);
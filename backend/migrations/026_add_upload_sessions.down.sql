@@ -0,0 +1,3 @@
+-- Remove resumable upload sessions
+DROP TABLE IF EXISTS upload_session_parts;
+DROP TABLE IF EXISTS upload_sessions;
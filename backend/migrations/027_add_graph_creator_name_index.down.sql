@@ -0,0 +1,2 @@
+-- Remove the graph name lookup index
+DROP INDEX IF EXISTS idx_graphs_creator_name;
@@ -0,0 +1,3 @@
+-- Looks up a user's graphs by name when unique graph names are enforced (UNIQUE_GRAPH_NAMES).
+-- Not a unique index: enforcement is optional and existing graphs may share names.
+CREATE INDEX IF NOT EXISTS idx_graphs_creator_name ON graphs(creator_id, LOWER(TRIM(name)));
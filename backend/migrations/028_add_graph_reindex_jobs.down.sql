@@ -0,0 +1,2 @@
+-- Remove graph reindex jobs
+DROP TABLE IF EXISTS graph_reindex_jobs;
@@ -0,0 +1,20 @@
+-- Background jobs rebuilding a graph's Zep data from its documents' stored content.
+-- At most one job per graph runs at a time.
+CREATE TABLE IF NOT EXISTS graph_reindex_jobs (
+    id UUID PRIMARY KEY,
+    graph_id UUID NOT NULL REFERENCES graphs(id) ON DELETE CASCADE,
+    requested_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    clear_graph BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'running'
+        CHECK (status IN ('running', 'completed', 'failed')),
+    total_documents INTEGER NOT NULL DEFAULT 0,
+    processed_documents INTEGER NOT NULL DEFAULT 0,
+    failed_documents INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_graph_reindex_jobs_graph_id ON graph_reindex_jobs(graph_id, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_graph_reindex_jobs_running ON graph_reindex_jobs(graph_id) WHERE status = 'running';
@@ -0,0 +1,4 @@
+-- Remove extractor versions and re-extraction jobs' settings
+ALTER TABLE graph_reindex_jobs DROP COLUMN content_type;
+ALTER TABLE graph_reindex_jobs DROP COLUMN reextract;
+ALTER TABLE documents DROP COLUMN extractor_version;
@@ -0,0 +1,8 @@
+-- Record which version of the extraction logic produced each uploaded document's text, so
+-- documents extracted by older extractors can be re-extracted when the logic improves.
+-- Existing documents start at 0, older than any current extractor.
+ALTER TABLE documents ADD COLUMN extractor_version INTEGER NOT NULL DEFAULT 0;
+
+-- Re-extraction runs as a reindex job limited to stale documents, optionally of one content type
+ALTER TABLE graph_reindex_jobs ADD COLUMN reextract BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE graph_reindex_jobs ADD COLUMN content_type TEXT;
@@ -0,0 +1,2 @@
+-- Remove chat thread privacy
+ALTER TABLE chat_threads DROP COLUMN IF EXISTS private;
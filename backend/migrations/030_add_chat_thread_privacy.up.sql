@@ -0,0 +1,2 @@
+-- Let members keep a chat thread to themselves (private threads are only visible to their author)
+ALTER TABLE chat_threads ADD COLUMN private BOOLEAN NOT NULL DEFAULT FALSE;
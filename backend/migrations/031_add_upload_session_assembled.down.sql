@@ -0,0 +1,2 @@
+-- Remove the assembled flag of upload sessions
+ALTER TABLE upload_sessions DROP COLUMN IF EXISTS assembled;
@@ -0,0 +1,3 @@
+-- Remember that a session's parts were already assembled in storage, so a completion that
+-- could not be extracted can be retried without assembling them again
+ALTER TABLE upload_sessions ADD COLUMN assembled BOOLEAN NOT NULL DEFAULT FALSE;
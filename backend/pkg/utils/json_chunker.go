@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// ChunkJSON splits a JSON document into chunks that are each valid JSON and at most
+// MaxChunkSize characters. Small documents are returned whole (compacted); larger
+// top-level arrays are split into sub-arrays of consecutive elements, and larger
+// top-level objects into sub-objects of their keys. Returns false if the content is
+// not valid JSON or a single element is too large to fit in a chunk.
+func ChunkJSON(content string) ([]string, bool) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(strings.TrimSpace(content))); err != nil {
+		return nil, false
+	}
+
+	if compact.Len() <= MaxChunkSize {
+		return []string{compact.String()}, true
+	}
+
+	data := compact.Bytes()
+	switch data[0] {
+	case '[':
+		var elements []json.RawMessage
+		if err := json.Unmarshal(data, &elements); err != nil {
+			return nil, false
+		}
+
+		parts := make([]string, len(elements))
+		for i, element := range elements {
+			parts[i] = string(element)
+		}
+		return groupJSONParts(parts, "[", "]")
+
+	case '{':
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, false
+		}
+
+		// Sort keys so chunking is deterministic
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		parts := make([]string, len(keys))
+		for i, key := range keys {
+			encodedKey, err := json.Marshal(key)
+			if err != nil {
+				return nil, false
+			}
+			parts[i] = string(encodedKey) + ":" + string(fields[key])
+		}
+		return groupJSONParts(parts, "{", "}")
+	}
+
+	// A scalar this large can't be split into valid JSON
+	return nil, false
+}
+
+// groupJSONParts packs comma-separated JSON members into as few open...close
+// wrapped chunks as possible without exceeding MaxChunkSize
+func groupJSONParts(parts []string, open, close string) ([]string, bool) {
+	var chunks []string
+	var current strings.Builder
+
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, open+current.String()+close)
+			current.Reset()
+		}
+	}
+
+	maxPartSize := MaxChunkSize - len(open) - len(close)
+	for _, part := range parts {
+		if len(part) > maxPartSize {
+			return nil, false
+		}
+
+		// +1 for the separating comma
+		if current.Len() > 0 && current.Len()+1+len(part) > maxPartSize {
+			flush()
+		}
+
+		if current.Len() > 0 {
+			current.WriteString(",")
+		}
+		current.WriteString(part)
+	}
+	flush()
+
+	return chunks, len(chunks) > 0
+}
@@ -1,7 +1,13 @@
 package utils
 
 import (
+	"crypto/rsa"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -16,6 +22,12 @@ var (
 	ErrMissingClaims = errors.New("missing required claims")
 )
 
+// Supported JWT signing algorithms
+const (
+	JWTAlgorithmHS256 = "HS256" // Shared secret; the same key signs and verifies
+	JWTAlgorithmRS256 = "RS256" // RSA key pair; the public key can be shared for verification
+)
+
 // Claims represents the JWT claims structure
 type Claims struct {
 	UserID string `json:"userId"`
@@ -23,8 +35,162 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
+// JWTKeyOptions configures the keys used to sign and verify tokens
+type JWTKeyOptions struct {
+	Algorithm     string // HS256 (default) or RS256
+	KeyID         string // "kid" header of issued tokens; empty omits the header
+	Secret        string // Signing secret for HS256
+	PrivateKeyPEM []byte // PEM-encoded RSA private key (PKCS#1 or PKCS#8) for RS256
+
+	// Additional keys accepted for verification, by kid, so tokens signed with a
+	// rotated-out key stay valid until they expire. Values are secrets for HS256
+	// and PEM-encoded RSA public keys for RS256.
+	VerificationKeys map[string][]byte
+}
+
+// JWTKeys signs tokens with the current key and verifies them against the
+// current key and any rotated-out keys, selected by the token's kid header
+type JWTKeys struct {
+	method     jwt.SigningMethod
+	keyID      string
+	signingKey interface{}
+	verifyKey  interface{}            // Verification key for the current signing key
+	verifyKeys map[string]interface{} // All verification keys by kid, including the current one
+}
+
+// NewJWTKeys builds the signing and verification keys from opts
+func NewJWTKeys(opts JWTKeyOptions) (*JWTKeys, error) {
+	keys := &JWTKeys{
+		keyID:      opts.KeyID,
+		verifyKeys: make(map[string]interface{}),
+	}
+
+	switch opts.Algorithm {
+	case "", JWTAlgorithmHS256:
+		if opts.Secret == "" {
+			return nil, fmt.Errorf("a secret is required for %s", JWTAlgorithmHS256)
+		}
+		keys.method = jwt.SigningMethodHS256
+		keys.signingKey = []byte(opts.Secret)
+		keys.verifyKey = []byte(opts.Secret)
+
+		for kid, secret := range opts.VerificationKeys {
+			if len(secret) == 0 {
+				return nil, fmt.Errorf("verification key %q is empty", kid)
+			}
+			keys.verifyKeys[kid] = secret
+		}
+
+	case JWTAlgorithmRS256:
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(opts.PrivateKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RSA private key: %w", err)
+		}
+		keys.method = jwt.SigningMethodRS256
+		keys.signingKey = privateKey
+		keys.verifyKey = &privateKey.PublicKey
+
+		for kid, pemBytes := range opts.VerificationKeys {
+			publicKey, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse RSA public key %q: %w", kid, err)
+			}
+			keys.verifyKeys[kid] = publicKey
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q (supported: %s, %s)", opts.Algorithm, JWTAlgorithmHS256, JWTAlgorithmRS256)
+	}
+
+	if opts.KeyID != "" {
+		if _, exists := keys.verifyKeys[opts.KeyID]; exists {
+			return nil, fmt.Errorf("verification key %q has the same kid as the signing key", opts.KeyID)
+		}
+		keys.verifyKeys[opts.KeyID] = keys.verifyKey
+	}
+
+	return keys, nil
+}
+
+// Algorithm returns the name of the signing algorithm
+func (k *JWTKeys) Algorithm() string {
+	return k.method.Alg()
+}
+
+// keyFunc selects the verification key for a parsed token
+func (k *JWTKeys) keyFunc(token *jwt.Token) (interface{}, error) {
+	// Only accept the configured algorithm, so an RS256 public key can never be used as an HMAC secret
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, ErrInvalidToken
+	}
+
+	kid, hasKid := token.Header["kid"]
+	if !hasKid {
+		// Tokens issued before a kid was configured are verified with the current key
+		return k.verifyKey, nil
+	}
+
+	kidStr, ok := kid.(string)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	key, ok := k.verifyKeys[kidStr]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return key, nil
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS is a JSON Web Key Set, published so other services can verify tokens
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicKeys returns the RSA verification keys as a JSON Web Key Set.
+// The set is empty for HS256, since shared secrets must never be published.
+func (k *JWTKeys) PublicKeys() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	if k.method != jwt.SigningMethodRS256 {
+		return jwks
+	}
+
+	// Without a kid, the current key is only reachable as the default key
+	if k.keyID == "" {
+		jwks.Keys = append(jwks.Keys, rsaJWK("", k.verifyKey.(*rsa.PublicKey)))
+	}
+	for _, kid := range slices.Sorted(maps.Keys(k.verifyKeys)) {
+		jwks.Keys = append(jwks.Keys, rsaJWK(kid, k.verifyKeys[kid].(*rsa.PublicKey)))
+	}
+
+	return jwks
+}
+
+// rsaJWK encodes an RSA public key as a JWK
+func rsaJWK(kid string, key *rsa.PublicKey) JWK {
+	return JWK{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: JWTAlgorithmRS256,
+		Kid: kid,
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
 // GenerateToken creates a new JWT token with user claims
-func GenerateToken(userID, email, secret string, expirationHours int) (string, error) {
+func GenerateToken(userID, email string, keys *JWTKeys, expirationHours int) (string, error) {
 	// Create claims with user data and expiration
 	claims := Claims{
 		UserID: userID,
@@ -36,11 +202,14 @@ func GenerateToken(userID, email, secret string, expirationHours int) (string, e
 		},
 	}
 
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// Create token with claims, tagging it with the signing key's ID for rotation
+	token := jwt.NewWithClaims(keys.method, claims)
+	if keys.keyID != "" {
+		token.Header["kid"] = keys.keyID
+	}
 
-	// Sign token with secret
-	tokenString, err := token.SignedString([]byte(secret))
+	// Sign token with the current key
+	tokenString, err := token.SignedString(keys.signingKey)
 	if err != nil {
 		return "", err
 	}
@@ -49,15 +218,9 @@ func GenerateToken(userID, email, secret string, expirationHours int) (string, e
 }
 
 // ValidateToken validates a JWT token and returns the claims
-func ValidateToken(tokenString, secret string) (*Claims, error) {
+func ValidateToken(tokenString string, keys *JWTKeys) (*Claims, error) {
 	// Parse token with claims
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, ErrInvalidToken
-		}
-		return []byte(secret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, keys.keyFunc)
 
 	if err != nil {
 		// Check if error is due to expiration
@@ -80,3 +243,58 @@ func ValidateToken(tokenString, secret string) (*Claims, error) {
 
 	return claims, nil
 }
+
+// shareTokenAudience marks share link tokens, so they can't be used as user tokens or vice versa
+const shareTokenAudience = "graph-share"
+
+// ShareClaims represents the claims of a graph share link token
+type ShareClaims struct {
+	ShareLinkID string `json:"shareLinkId"`
+	GraphID     string `json:"graphId"`
+	jwt.RegisteredClaims
+}
+
+// GenerateShareToken creates a signed token for a graph share link. A nil expiresAt gives a
+// token that is valid until the link is revoked.
+func GenerateShareToken(shareLinkID, graphID string, keys *JWTKeys, expiresAt *time.Time) (string, error) {
+	claims := ShareClaims{
+		ShareLinkID: shareLinkID,
+		GraphID:     graphID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience: jwt.ClaimStrings{shareTokenAudience},
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+	}
+	if expiresAt != nil {
+		claims.RegisteredClaims.ExpiresAt = jwt.NewNumericDate(*expiresAt)
+	}
+
+	token := jwt.NewWithClaims(keys.method, claims)
+	if keys.keyID != "" {
+		token.Header["kid"] = keys.keyID
+	}
+
+	return token.SignedString(keys.signingKey)
+}
+
+// ValidateShareToken validates a share link token and returns its claims
+func ValidateShareToken(tokenString string, keys *JWTKeys) (*ShareClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ShareClaims{}, keys.keyFunc, jwt.WithAudience(shareTokenAudience))
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(*ShareClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.ShareLinkID == "" || claims.GraphID == "" {
+		return nil, ErrMissingClaims
+	}
+
+	return claims, nil
+}
@@ -178,6 +178,7 @@ export default function DocumentViewPage() {
               <span className={`capitalize ${
                 document.status === 'completed' ? 'text-green-600' :
                 document.status === 'processing' ? 'text-yellow-600' :
+                document.status === 'empty' ? 'text-gray-500' :
                 'text-red-600'
               }`}>
                 {document.status}
@@ -185,6 +186,18 @@ export default function DocumentViewPage() {
             </>
           )}
         </div>
+        {document.errorMessage && (
+          <p className="mt-2 text-sm text-gray-500">{document.errorMessage}</p>
+        )}
+        {document.status === 'failed' && document.processingAttempts > 0 && (
+          <p className="mt-1 text-sm text-gray-500">
+            {document.permanentlyFailed
+              ? `Processing failed after ${document.processingAttempts} attempt(s).`
+              : document.nextRetryAt
+                ? `Attempt ${document.processingAttempts} failed; retrying at ${new Date(document.nextRetryAt).toLocaleString()}.`
+                : null}
+          </p>
+        )}
       </div>
 
       {/* Content */}
@@ -58,7 +58,7 @@ export default function GraphDetailPage() {
         
         // Sequential workaround:
         const graphDetails = await getGraph(graphId);
-        const graphDocs = await listGraphDocuments(graphId);
+        const graphDocs = await listGraphDocuments(graphId, true);
 
         setGraph(graphDetails);
         setDocuments(graphDocs);
@@ -173,13 +173,19 @@ export default function GraphDetailPage() {
         onManageMembers={handleManageMembers}
         onViewGraph={() => setShowVisualizerModal(true)}
         chatComponent={
-          // Chat Interface
-          // WORKAROUND: Pass ready={!loading} to prevent chat from initializing
-          // until graph data is loaded, avoiding Neon DB prepared statement race conditions
-          <ChatInterface
-            graphId={graphId}
-            ready={!loading}
-          />
+          graph.chatEnabled === false ? (
+            <div className="flex h-full items-center justify-center p-6 text-sm text-gray-500">
+              Chat is disabled for this graph.
+            </div>
+          ) : (
+            // Chat Interface
+            // WORKAROUND: Pass ready={!loading} to prevent chat from initializing
+            // until graph data is loaded, avoiding Neon DB prepared statement race conditions
+            <ChatInterface
+              graphId={graphId}
+              ready={!loading}
+            />
+          )
         }
       />
 
@@ -97,6 +97,9 @@ export function DocumentsList({ documents, onDocumentClick }: DocumentsListProps
               <p className="text-sm font-medium text-gray-900 truncate">
                 {doc.filename || 'Untitled Document'}
               </p>
+              {doc.contentPreview && (
+                <p className="text-xs text-gray-600 truncate">{doc.contentPreview}</p>
+              )}
               <div className="flex items-center space-x-2 text-xs text-gray-500">
                 <span className="flex items-center">
                   {doc.source === 'editor' ? (
@@ -132,6 +135,12 @@ export function DocumentsList({ documents, onDocumentClick }: DocumentsListProps
                     <span className="text-red-600">Failed</span>
                   </>
                 )}
+                {doc.status === 'empty' && (
+                  <>
+                    <span>•</span>
+                    <span className="text-gray-500" title={doc.errorMessage}>No text found</span>
+                  </>
+                )}
               </div>
             </div>
           </div>
@@ -100,6 +100,11 @@ export default function GraphCard({ graph, isCreator, onEdit, onDelete, onClick
           <span>
             {graph.documentCount} {graph.documentCount === 1 ? 'document' : 'documents'}
           </span>
+          {graph.memberCount !== undefined && (
+            <span className="ml-3">
+              {graph.memberCount} {graph.memberCount === 1 ? 'member' : 'members'}
+            </span>
+          )}
         </div>
         <span className="text-xs">{formatDate(graph.createdAt)}</span>
       </div>
@@ -1,5 +1,5 @@
-import { apiCall } from './client';
-import { setJWTToken } from '../auth/jwt';
+import { apiCall, API_BASE_URL, APIError } from './client';
+import { setJWTToken, clearJWTToken, getJWTToken } from '../auth/jwt';
 import type {
   Credentials,
   SignUpCredentials,
@@ -7,6 +7,7 @@ import type {
   OAuthProvider,
   ResetPasswordRequest,
   UpdatePasswordRequest,
+  DeleteAccountRequest,
 } from '../types';
 
 /**
@@ -97,3 +98,42 @@ export async function updatePassword(
     body: JSON.stringify(request),
   });
 }
+
+/**
+ * Permanently delete the current user's account and data
+ * Password is required unless the account only uses OAuth sign-in
+ */
+export async function deleteAccount(
+  request: DeleteAccountRequest
+): Promise<{ message: string }> {
+  const response = await apiCall<{ message: string }>('/api/auth/me', {
+    method: 'DELETE',
+    body: JSON.stringify(request),
+  });
+
+  // The token belongs to a user that no longer exists
+  clearJWTToken();
+
+  return response;
+}
+
+/**
+ * Download a ZIP archive of the current user's data
+ * Uses fetch directly since apiCall only handles JSON responses
+ */
+export async function exportAccount(): Promise<Blob> {
+  const token = getJWTToken();
+  const response = await fetch(`${API_BASE_URL}/api/auth/me/export`, {
+    headers: token ? { Authorization: `Bearer ${token}` } : {},
+  });
+
+  if (!response.ok) {
+    throw new APIError(
+      response.status,
+      'EXPORT_FAILED',
+      `Export failed with status ${response.status}`
+    );
+  }
+
+  return response.blob();
+}
@@ -1,71 +1,108 @@
-import { apiCall, APIError, API_BASE_URL } from './client';
+import { apiCall, apiCallAllPages, APIError, API_BASE_URL } from './client';
 import { getJWTToken } from '../auth/jwt';
-import type { ChatThread, ChatMessage, StreamEvent } from '../types';
+import type {
+  ChatThread,
+  ChatMessage,
+  FeedbackRating,
+  FeedbackSummary,
+  MessageFeedback,
+  Paginated,
+  StartChatResponse,
+  StreamEvent,
+  ThreadArchiveFilter,
+} from '../types';
 
 /**
- * List all threads for a graph
- * Implements defensive response parsing to handle multiple response formats
+ * List threads for a graph; archived threads are excluded unless requested
  */
-export async function listThreads(graphId: string): Promise<ChatThread[]> {
-  const response = await apiCall<ChatThread[] | { threads: ChatThread[] }>(
-    `/api/graphs/${graphId}/chat/threads`,
-    { method: 'GET' }
-  );
+export async function listThreads(
+  graphId: string,
+  archiveFilter: ThreadArchiveFilter = 'active'
+): Promise<ChatThread[]> {
+  const query =
+    archiveFilter === 'archived' ? '?archived=true' : archiveFilter === 'all' ? '?includeArchived=true' : '';
+  return apiCallAllPages<ChatThread>(`/api/graphs/${graphId}/chat/threads${query}`);
+}
 
-  // Defensive: Handle direct array response
-  if (Array.isArray(response)) {
-    return response;
-  }
+/**
+ * Create a new chat thread for a graph
+ * Threads are shared with the graph's members; pass isPrivate to keep it to yourself
+ */
+export async function createThread(graphId: string, isPrivate = false): Promise<ChatThread> {
+  return apiCall<ChatThread>(`/api/graphs/${graphId}/chat/threads`, {
+    method: 'POST',
+    body: JSON.stringify({ private: isPrivate }),
+  });
+}
 
-  // Defensive: Handle wrapped response format
-  if (response && typeof response === 'object' && 'threads' in response) {
-    return Array.isArray(response.threads) ? response.threads : [];
-  }
+/**
+ * Create a thread and send its first message in one call
+ * Open the stream with the returned thread and message IDs to get the AI response
+ */
+export async function startChat(graphId: string, content: string, isPrivate = false): Promise<StartChatResponse> {
+  return apiCall<StartChatResponse>(`/api/graphs/${graphId}/chat/start`, {
+    method: 'POST',
+    body: JSON.stringify({ content, private: isPrivate }),
+  });
+}
 
-  // Fallback to empty array
-  return [];
+/**
+ * Make a thread private, or share it with the graph's members again (thread creator only)
+ */
+export async function setThreadPrivate(graphId: string, threadId: string, isPrivate: boolean): Promise<ChatThread> {
+  return apiCall<ChatThread>(`/api/graphs/${graphId}/chat/threads/${threadId}/private`, {
+    method: isPrivate ? 'POST' : 'DELETE',
+  });
 }
 
 /**
- * Create a new chat thread for a graph
+ * Delete a thread and its messages (thread creator or graph owner only)
  */
-export async function createThread(graphId: string): Promise<ChatThread> {
-  return apiCall<ChatThread>(`/api/graphs/${graphId}/chat/threads`, {
+export async function deleteThread(graphId: string, threadId: string): Promise<void> {
+  return apiCall<void>(`/api/graphs/${graphId}/chat/threads/${threadId}`, {
+    method: 'DELETE',
+  });
+}
+
+/**
+ * Archive a thread, hiding it from the default thread list (thread creator or graph owner only)
+ */
+export async function archiveThread(graphId: string, threadId: string): Promise<ChatThread> {
+  return apiCall<ChatThread>(`/api/graphs/${graphId}/chat/threads/${threadId}/archive`, {
     method: 'POST',
   });
 }
 
+/**
+ * Unarchive a thread, returning it to the default thread list (thread creator or graph owner only)
+ */
+export async function unarchiveThread(graphId: string, threadId: string): Promise<ChatThread> {
+  return apiCall<ChatThread>(`/api/graphs/${graphId}/chat/threads/${threadId}/archive`, {
+    method: 'DELETE',
+  });
+}
+
 /**
  * Get messages for a specific thread with pagination
+ * By default offset 0 is the latest messages and higher offsets page back through older ones;
+ * pass order 'asc' to page forward from the first message. Each page is oldest first.
  */
 export async function getThreadMessages(
   graphId: string,
   threadId: string,
   limit: number = 50,
-  offset: number = 0
+  offset: number = 0,
+  order: 'asc' | 'desc' = 'desc'
 ): Promise<{ messages: ChatMessage[]; total: number; hasMore: boolean }> {
-  const response = await apiCall<{
-    messages: ChatMessage[];
-    total: number;
-    hasMore: boolean;
-  }>(`/api/graphs/${graphId}/chat/threads/${threadId}/messages?limit=${limit}&offset=${offset}`, {
-    method: 'GET',
-  });
-
-  // Handle defensive response format
-  if (response && typeof response === 'object') {
-    return {
-      messages: Array.isArray(response.messages) ? response.messages : [],
-      total: response.total || 0,
-      hasMore: response.hasMore || false,
-    };
-  }
+  const response = await apiCall<Paginated<ChatMessage>>(
+    `/api/graphs/${graphId}/chat/threads/${threadId}/messages?limit=${limit}&offset=${offset}&order=${order}`,
+    { method: 'GET' }
+  );
 
-  // Fallback to empty response
   return {
-    messages: [],
-    total: 0,
-    hasMore: false,
+    messages: Array.isArray(response?.items) ? response.items : [],
+    total: response?.pagination?.total || 0,
+    hasMore: response?.pagination?.hasMore || false,
   };
 }
 
@@ -87,6 +124,35 @@ export async function sendMessage(
   );
 }
 
+/**
+ * Rate an assistant message
+ * Rating the same message again replaces the previous rating
+ */
+export async function submitFeedback(
+  graphId: string,
+  threadId: string,
+  messageId: string,
+  rating: FeedbackRating,
+  comment?: string
+): Promise<MessageFeedback> {
+  return apiCall<MessageFeedback>(
+    `/api/graphs/${graphId}/chat/threads/${threadId}/messages/${messageId}/feedback`,
+    {
+      method: 'POST',
+      body: JSON.stringify({ rating, comment }),
+    }
+  );
+}
+
+/**
+ * Get aggregate feedback on a graph's assistant messages (graph creator only)
+ */
+export async function getFeedbackSummary(graphId: string): Promise<FeedbackSummary> {
+  return apiCall<FeedbackSummary>(`/api/graphs/${graphId}/chat/feedback`, {
+    method: 'GET',
+  });
+}
+
 /**
  * Connect to SSE stream for AI response
  * Returns a cleanup function to close the connection
@@ -102,6 +168,8 @@ export async function sendMessage(
  * @param onChunk - Callback for each content chunk
  * @param onDone - Callback when streaming is complete (receives assistant message ID)
  * @param onError - Callback for errors
+ * @param additionalGraphIds - Other graphs (the user must be a member of each) to search alongside graphId
+ * @param excludeDocumentIds - Documents of the searched graphs to leave out of the answer (at most 50)
  */
 export function connectChatStream(
   graphId: string,
@@ -109,7 +177,9 @@ export function connectChatStream(
   userMessageId: string,
   onChunk: (content: string) => void,
   onDone: (messageId: string) => void,
-  onError: (error: string) => void
+  onError: (error: string) => void,
+  additionalGraphIds: string[] = [],
+  excludeDocumentIds: string[] = []
 ): () => void {
   const token = getJWTToken();
   
@@ -120,7 +190,13 @@ export function connectChatStream(
 
   // Construct SSE URL with query parameters
   // The backend will use the userMessageId to generate a response
-  const url = `${API_BASE_URL}/api/graphs/${graphId}/chat/stream?threadId=${threadId}&userMessageId=${userMessageId}`;
+  let url = `${API_BASE_URL}/api/graphs/${graphId}/chat/stream?threadId=${threadId}&userMessageId=${userMessageId}`;
+  if (additionalGraphIds.length > 0) {
+    url += `&graphIds=${additionalGraphIds.map(encodeURIComponent).join(',')}`;
+  }
+  if (excludeDocumentIds.length > 0) {
+    url += `&excludeDocumentIds=${excludeDocumentIds.map(encodeURIComponent).join(',')}`;
+  }
   
   let abortController = new AbortController();
   let reconnectAttempts = 0;
@@ -1,5 +1,5 @@
 import { getJWTToken, clearJWTToken } from '../auth/jwt';
-import type { ErrorResponse } from '../types';
+import type { ErrorResponse, Paginated } from '../types';
 
 // Get API base URL
 // In production, use the backend service URL from environment variable
@@ -182,3 +182,24 @@ export async function apiCall<T>(
     );
   }
 }
+
+/**
+ * Fetch every item of a paginated listing, following nextCursor from page to page
+ * The endpoint may already have a query string
+ */
+export async function apiCallAllPages<T>(endpoint: string): Promise<T[]> {
+  const separator = endpoint.includes('?') ? '&' : '?';
+  const items: T[] = [];
+  let cursor: string | undefined;
+
+  do {
+    const page = await apiCall<Paginated<T>>(
+      `${endpoint}${separator}limit=200${cursor ? `&cursor=${encodeURIComponent(cursor)}` : ''}`,
+      { method: 'GET' }
+    );
+    items.push(...(Array.isArray(page?.items) ? page.items : []));
+    cursor = page?.pagination?.hasMore ? page.pagination.nextCursor : undefined;
+  } while (cursor);
+
+  return items;
+}
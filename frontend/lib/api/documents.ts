@@ -1,13 +1,19 @@
-import { apiCall } from './client';
-import type { Document } from '../types';
+import { apiCall, apiCallAllPages } from './client';
+import type { ContentFormat, Document, DocumentScope, FormatInfo, UploadPart, UploadSession } from '../types';
 
 /**
  * Submit content from the editor
+ * For formats other than 'lexical', content is the document in that format and lexicalState may be empty
  */
-export async function submitEditorContent(content: string, lexicalState: string, graphId: string): Promise<Document> {
+export async function submitEditorContent(
+  content: string,
+  lexicalState: string,
+  graphId: string,
+  contentFormat: ContentFormat = 'lexical'
+): Promise<Document> {
   return apiCall<Document>('/api/documents/editor', {
     method: 'POST',
-    body: JSON.stringify({ content, lexicalState, graphId }),
+    body: JSON.stringify({ content, lexicalState, graphId, contentFormat }),
   });
 }
 
@@ -26,47 +32,110 @@ export async function uploadFile(file: File, graphId: string): Promise<Document>
 }
 
 /**
- * List all documents for the authenticated user
+ * Start a resumable upload; the file is then sent in parts of session.partSize bytes
  */
-export async function listDocuments(): Promise<Document[]> {
-  const response = await apiCall<{ documents: Document[] } | Document[]>('/api/documents', {
-    method: 'GET',
+export async function initiateUpload(file: File, graphId: string): Promise<UploadSession> {
+  return apiCall<UploadSession>('/api/documents/uploads', {
+    method: 'POST',
+    body: JSON.stringify({
+      graphId,
+      filename: file.name,
+      contentType: file.type || 'application/octet-stream',
+      sizeBytes: file.size,
+    }),
   });
-  
-  // Handle both response formats defensively
-  if (Array.isArray(response)) {
-    return response;
-  }
-  
-  // Handle wrapped response format
-  if (response && typeof response === 'object' && 'documents' in response) {
-    return Array.isArray(response.documents) ? response.documents : [];
+}
+
+/**
+ * Get a resumable upload, including the parts received so far
+ */
+export async function getUpload(uploadId: string): Promise<UploadSession> {
+  return apiCall<UploadSession>(`/api/documents/uploads/${uploadId}`);
+}
+
+/**
+ * Upload one part (numbered from 1) of a resumable upload; sending a part again replaces it
+ */
+export async function uploadPart(session: UploadSession, file: File, partNumber: number): Promise<UploadPart> {
+  const start = (partNumber - 1) * session.partSize;
+  return apiCall<UploadPart>(`/api/documents/uploads/${session.uploadId}/parts/${partNumber}`, {
+    method: 'PUT',
+    body: file.slice(start, start + session.partSize),
+    timeout: 120000,
+  });
+}
+
+/**
+ * Complete a resumable upload, creating and processing its document like uploadFile
+ */
+export async function completeUpload(uploadId: string): Promise<Document> {
+  return apiCall<Document>(`/api/documents/uploads/${uploadId}/complete`, {
+    method: 'POST',
+    timeout: 120000,
+  });
+}
+
+/**
+ * Abort a resumable upload, discarding the parts received so far
+ */
+export async function abortUpload(uploadId: string): Promise<void> {
+  await apiCall<{ message: string }>(`/api/documents/uploads/${uploadId}`, {
+    method: 'DELETE',
+  });
+}
+
+/**
+ * Upload a file in parts. Pass the uploadId of an interrupted upload of the same file to
+ * resume it, sending only the parts that haven't been received
+ */
+export async function uploadFileResumable(
+  file: File,
+  graphId: string,
+  uploadId?: string,
+  onProgress?: (session: UploadSession, partsDone: number) => void
+): Promise<Document> {
+  const session = uploadId ? await getUpload(uploadId) : await initiateUpload(file, graphId);
+  const received = new Set(session.uploadedParts);
+
+  for (let partNumber = 1; partNumber <= session.partCount; partNumber++) {
+    if (!received.has(partNumber)) {
+      await uploadPart(session, file, partNumber);
+      received.add(partNumber);
+    }
+    onProgress?.(session, received.size);
   }
-  
-  // Fallback to empty array for unexpected formats
-  return [];
+
+  return completeUpload(session.uploadId);
 }
 
 /**
- * List documents for a specific graph
+ * List the formats that can be uploaded, with their capabilities and size and time limits
  */
-export async function listGraphDocuments(graphId: string): Promise<Document[]> {
-  const response = await apiCall<{ documents: Document[] } | Document[]>(`/api/graphs/${graphId}/documents`, {
+export async function listFormats(): Promise<FormatInfo[]> {
+  const response = await apiCall<{ formats: FormatInfo[] }>('/api/extraction/formats', {
     method: 'GET',
   });
-  
-  // Handle both response formats defensively
-  if (Array.isArray(response)) {
-    return response;
-  }
-  
-  // Handle wrapped response format
-  if (response && typeof response === 'object' && 'documents' in response) {
-    return Array.isArray(response.documents) ? response.documents : [];
-  }
-  
-  // Fallback to empty array for unexpected formats
-  return [];
+  return response.formats;
+}
+
+/**
+ * List documents in the graphs the authenticated user is a member of, each with its graph's name
+ * Pass 'authored' to only include documents the user created, includePreview
+ * to get the start of each document's text, and graphId to only list one graph's documents
+ */
+export async function listDocuments(scope: DocumentScope = 'accessible', includePreview = false, graphId?: string): Promise<Document[]> {
+  const preview = includePreview ? '&includePreview=true' : '';
+  const graph = graphId ? `&graphId=${encodeURIComponent(graphId)}` : '';
+  return apiCallAllPages<Document>(`/api/documents?scope=${scope}${preview}${graph}`);
+}
+
+/**
+ * List documents for a specific graph
+ * Pass includePreview to get the start of each document's text
+ */
+export async function listGraphDocuments(graphId: string, includePreview = false): Promise<Document[]> {
+  const query = includePreview ? '?includePreview=true' : '';
+  return apiCallAllPages<Document>(`/api/graphs/${graphId}/documents${query}`);
 }
 
 /**
@@ -78,29 +147,51 @@ export async function getDocument(documentId: string): Promise<Document> {
   });
 }
 
+/**
+ * Get the processing status of several documents in one call (up to 100)
+ * Fails if any document doesn't exist or isn't in one of the user's graphs
+ */
+export async function getDocumentStatuses(documentIds: string[]): Promise<Record<string, Document['status']>> {
+  const response = await apiCall<{ statuses: Record<string, Document['status']> }>('/api/documents/status', {
+    method: 'POST',
+    body: JSON.stringify({ documentIds }),
+  });
+  return response.statuses ?? {};
+}
+
 /**
  * Update document content
+ * Omit contentFormat to keep the document's current format
  */
-export async function updateDocument(documentId: string, content: string, lexicalState: string): Promise<Document> {
+export async function updateDocument(
+  documentId: string,
+  content: string,
+  lexicalState: string,
+  contentFormat?: ContentFormat
+): Promise<Document> {
   return apiCall<Document>(`/api/documents/${documentId}`, {
     method: 'PUT',
-    body: JSON.stringify({ content, lexicalState }),
+    body: JSON.stringify({ content, lexicalState, contentFormat }),
   });
 }
 
 /**
  * Get document content (returns both plain text and Lexical state)
+ * Markdown and HTML documents also return the source as content
  */
 export async function getDocumentContent(documentId: string): Promise<{
   plainText?: string;
   lexicalState?: string;
+  content?: string;
+  contentFormat?: ContentFormat;
   metadata?: Record<string, any>;
 }> {
   const response = await apiCall<{
     plainText?: string;
     lexicalState?: string;
+    contentFormat?: ContentFormat;
     metadata?: Record<string, any>;
-    content?: string; // Legacy format support
+    content?: string; // Markdown/HTML source, or plain text in the legacy format
   }>(`/api/documents/${documentId}/content`, {
     method: 'GET',
   });
@@ -110,6 +201,8 @@ export async function getDocumentContent(documentId: string): Promise<{
     return {
       plainText: response.plainText,
       lexicalState: response.lexicalState,
+      content: response.content,
+      contentFormat: response.contentFormat ?? 'lexical',
       metadata: response.metadata,
     };
   }
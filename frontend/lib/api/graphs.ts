@@ -1,35 +1,27 @@
-import { apiCall } from './client';
-import type { Graph, CreateGraphRequest, UpdateGraphRequest, GraphData, GraphMembership, AddMemberRequest } from '../types';
+import { apiCall, apiCallAllPages } from './client';
+import type { Graph, GraphRoleFilter, CreateGraphRequest, UpdateGraphRequest, GraphData, GraphMembership, AddMemberRequest, AddMembersEntry, AddMembersResponse, Document, GraphStats, GraphSearchResponse, ShareLink, CreateShareLinkRequest, SharedDocument, GraphReindexJob } from '../types';
 
 /**
  * List all graphs the authenticated user is a member of
+ * Pass 'owner' to only include graphs the user created, or 'member' for graphs shared with them
  */
-export async function listGraphs(): Promise<Graph[]> {
-  const response = await apiCall<{ graphs: Graph[] } | Graph[]>('/api/graphs', {
-    method: 'GET',
-  });
-  
-  // Handle both response formats defensively
-  if (Array.isArray(response)) {
-    return response;
-  }
-  
-  // Handle wrapped response format
-  if (response && typeof response === 'object' && 'graphs' in response) {
-    return Array.isArray(response.graphs) ? response.graphs : [];
-  }
-  
-  // Fallback to empty array for unexpected formats
-  return [];
+export async function listGraphs(role?: GraphRoleFilter): Promise<Graph[]> {
+  const query = role ? `?role=${role}` : '';
+  return apiCallAllPages<Graph>(`/api/graphs${query}`);
 }
 
 /**
  * Create a new graph
+ * Reuse the same idempotencyKey when retrying a failed request so at most one graph is created
  */
-export async function createGraph(request: CreateGraphRequest): Promise<Graph> {
+export async function createGraph(
+  request: CreateGraphRequest,
+  idempotencyKey?: string
+): Promise<Graph> {
   return apiCall<Graph>('/api/graphs', {
     method: 'POST',
     body: JSON.stringify(request),
+    headers: idempotencyKey ? { 'Idempotency-Key': idempotencyKey } : undefined,
   });
 }
 
@@ -71,6 +63,17 @@ export async function addMember(graphId: string, request: AddMemberRequest): Pro
   });
 }
 
+/**
+ * Add several members to a graph at once, by user ID or email
+ * Entries are processed independently; each result reports whether it was added
+ */
+export async function addMembers(graphId: string, members: AddMembersEntry[]): Promise<AddMembersResponse> {
+  return apiCall<AddMembersResponse>(`/api/graphs/${graphId}/members/bulk`, {
+    method: 'POST',
+    body: JSON.stringify({ members }),
+  });
+}
+
 /**
  * Remove a member from a graph
  */
@@ -84,22 +87,48 @@ export async function removeMember(graphId: string, userId: string): Promise<voi
  * List all members of a graph
  */
 export async function listMembers(graphId: string): Promise<GraphMembership[]> {
-  const response = await apiCall<{ members: GraphMembership[] } | GraphMembership[]>(`/api/graphs/${graphId}/members`, {
+  return apiCallAllPages<GraphMembership>(`/api/graphs/${graphId}/members`);
+}
+
+/**
+ * Get a graph's document counts by processing status
+ */
+export async function getGraphStats(graphId: string): Promise<GraphStats> {
+  return apiCall<GraphStats>(`/api/graphs/${graphId}/stats`, {
+    method: 'GET',
+  });
+}
+
+/**
+ * Search a graph's documents (by filename, title, preview and tags) and knowledge graph facts
+ * Matching documents are paged with limit and offset; knowledge graph facts are not.
+ * If one source fails, its results are empty and it is listed in `unavailable`
+ */
+export async function searchGraph(
+  graphId: string,
+  query: string,
+  limit: number = 50,
+  offset: number = 0
+): Promise<GraphSearchResponse> {
+  const params = new URLSearchParams({
+    q: query,
+    includePreview: 'true',
+    limit: String(limit),
+    offset: String(offset),
+  });
+  return apiCall<GraphSearchResponse>(`/api/graphs/${graphId}/search?${params}`, {
+    method: 'GET',
+  });
+}
+
+/**
+ * Get a document through the graph it belongs to
+ * Fails with DOCUMENT_NOT_FOUND if the document is not in this graph
+ */
+export async function getGraphDocument(graphId: string, documentId: string): Promise<Document> {
+  return apiCall<Document>(`/api/graphs/${graphId}/documents/${documentId}`, {
     method: 'GET',
   });
-  
-  // Handle both response formats defensively
-  if (Array.isArray(response)) {
-    return response;
-  }
-  
-  // Handle wrapped response format
-  if (response && typeof response === 'object' && 'members' in response) {
-    return Array.isArray(response.members) ? response.members : [];
-  }
-  
-  // Fallback to empty array for unexpected formats
-  return [];
 }
 
 /**
@@ -111,3 +140,96 @@ export async function getGraphVisualization(graphId: string, query?: string): Pr
     method: 'GET',
   });
 }
+
+/**
+ * Pin a graph to the top of the current user's graph list
+ */
+export async function addFavorite(graphId: string): Promise<void> {
+  return apiCall<void>(`/api/graphs/${graphId}/favorite`, {
+    method: 'POST',
+  });
+}
+
+/**
+ * Unpin a graph from the current user's graph list
+ */
+export async function removeFavorite(graphId: string): Promise<void> {
+  return apiCall<void>(`/api/graphs/${graphId}/favorite`, {
+    method: 'DELETE',
+  });
+}
+
+/**
+ * Create a read-only public share link to a graph's visualization and documents (creator only)
+ * The returned token is only available now; share it as part of the link URL
+ */
+export async function createShareLink(graphId: string, request: CreateShareLinkRequest = {}): Promise<ShareLink> {
+  return apiCall<ShareLink>(`/api/graphs/${graphId}/share-links`, {
+    method: 'POST',
+    body: JSON.stringify(request),
+  });
+}
+
+/**
+ * List a graph's share links, including revoked and expired ones (creator only)
+ */
+export async function listShareLinks(graphId: string): Promise<ShareLink[]> {
+  return apiCallAllPages<ShareLink>(`/api/graphs/${graphId}/share-links`);
+}
+
+/**
+ * Revoke a share link so it stops working (creator only)
+ */
+export async function revokeShareLink(graphId: string, linkId: string): Promise<void> {
+  return apiCall<void>(`/api/graphs/${graphId}/share-links/${linkId}`, {
+    method: 'DELETE',
+  });
+}
+
+/**
+ * Rebuild a graph's knowledge graph from its stored documents in the background (creator only)
+ * With clearGraph the existing knowledge graph is emptied first; poll getReindexStatus for progress
+ */
+export async function reindexGraph(graphId: string, clearGraph = false): Promise<GraphReindexJob> {
+  return apiCall<GraphReindexJob>(`/api/graphs/${graphId}/reindex`, {
+    method: 'POST',
+    body: JSON.stringify({ clearGraph }),
+  });
+}
+
+/**
+ * Re-extract a graph's uploaded documents read by an older extractor in the background (creator only)
+ * Pass contentType to limit it to one format; it runs as a reindex job, so poll getReindexStatus
+ */
+export async function reextractGraph(graphId: string, contentType?: string): Promise<GraphReindexJob> {
+  return apiCall<GraphReindexJob>(`/api/graphs/${graphId}/reextract`, {
+    method: 'POST',
+    body: JSON.stringify(contentType ? { contentType } : {}),
+  });
+}
+
+/**
+ * Get the progress of a graph's latest reindex or re-extraction (creator only)
+ */
+export async function getReindexStatus(graphId: string): Promise<GraphReindexJob> {
+  return apiCall<GraphReindexJob>(`/api/graphs/${graphId}/reindex`, {
+    method: 'GET',
+  });
+}
+
+/**
+ * Get graph visualization data through a share link (no sign-in required)
+ */
+export async function getSharedGraphVisualization(token: string, query?: string): Promise<GraphData> {
+  const queryParam = query ? `?query=${encodeURIComponent(query)}` : '';
+  return apiCall<GraphData>(`/api/shared/${encodeURIComponent(token)}/visualization${queryParam}`, {
+    method: 'GET',
+  });
+}
+
+/**
+ * List a graph's documents through a share link (no sign-in required)
+ */
+export async function listSharedGraphDocuments(token: string): Promise<SharedDocument[]> {
+  return apiCallAllPages<SharedDocument>(`/api/shared/${encodeURIComponent(token)}/documents`);
+}
@@ -11,6 +11,8 @@ export interface User {
 }
 
 // Document types
+export type DocumentScope = 'accessible' | 'authored';
+
 export interface Document {
   id: string;
   userId: string;
@@ -20,12 +22,61 @@ export interface Document {
   storageKey: string;
   sizeBytes: number;
   source: 'editor' | 'upload';
-  status: 'processing' | 'completed' | 'failed';
+  contentFormat?: ContentFormat; // Editor documents only
+  status: 'processing' | 'completed' | 'failed' | 'empty'; // 'empty': no text could be extracted
+  errorMessage?: string;
   createdAt: string;
   updatedAt: string;
+  processingAttempts: number;
+  lastAttemptAt?: string;
+  nextRetryAt?: string; // When a failed document is next retried automatically
+  permanentlyFailed: boolean; // Failed with no automatic retry left
+  contentPreview?: string; // Only in lists requested with includePreview
+  graphName?: string; // Only in listDocuments results
+}
+
+// Format of editor content; 'lexical' content is plain text plus Lexical state
+export type ContentFormat = 'lexical' | 'markdown' | 'html' | 'text';
+
+// A format that can be uploaded, with what extraction keeps from it and its limits
+export interface FormatInfo {
+  name: string;
+  extensions: string[];
+  mimeType: string;
+  extractor: string;
+  capabilities: {
+    ocr: boolean; // Text in images (scanned pages) is read; otherwise image-only files extract as empty
+    metadata: boolean; // Embedded title, author, page count, ...
+    links: boolean; // Hyperlinks are listed after the text
+    footnotes: boolean; // Footnotes and endnotes are listed after the text
+  };
+  maxFileSizeBytes: number;
+  maxTimeoutSeconds: number; // Extraction timeout for a file of the maximum size
+  maxRows?: number; // Spreadsheets only: rows read (0 = unlimited)
+}
+
+// Resumable upload of a large file, sent in parts
+export interface UploadSession {
+  uploadId: string;
+  documentId: string; // ID of the document created when the upload is completed
+  graphId: string;
+  filename: string;
+  contentType: string;
+  sizeBytes: number;
+  partSize: number; // Size of every part but the last, which holds the rest
+  partCount: number;
+  uploadedParts: number[]; // Part numbers received so far
+  expiresAt: string;
+}
+
+export interface UploadPart {
+  partNumber: number;
+  sizeBytes: number;
 }
 
 // Graph management types
+export type GraphRoleFilter = 'owner' | 'member';
+
 export interface Graph {
   id: string;
   creatorId: string;
@@ -33,10 +84,104 @@ export interface Graph {
   name: string;
   description?: string;
   documentCount: number;
+  memberCount?: number; // Only included when listing graphs
+  isFavorite: boolean;
+  isOwner: boolean; // Whether the authenticated user created the graph
+  createdAt: string;
+  updatedAt: string;
+  lastActivityAt: string;
+  allowedContentTypes: string[]; // MIME types that may be uploaded; empty = all supported formats
+  defaultMemberRole: MemberRole; // Role given to members added without one
+  chatEnabled: boolean; // Whether members can chat with the graph's documents
+}
+
+// Document counts by processing status, for the graph's ingestion health
+export interface GraphStats {
+  documentCount: number;
+  documentsByStatus: Record<Document['status'], number>;
+  geminiUsage?: GeminiUsage;
+}
+
+export interface MemoryResult {
+  content: string;
+  metadata: Record<string, unknown>;
+  score: number;
+}
+
+export interface GraphSearchResponse {
+  query: string;
+  documents: Paginated<Document>; // One page of matching documents
+  memory: MemoryResult[];
+  unavailable?: ('documents' | 'memory')[]; // Sources that failed; the others still return results
+}
+
+export interface ShareLink {
+  id: string;
+  graphId: string;
+  createdBy: string;
+  token?: string; // Only returned when the link is created
+  active: boolean; // False once revoked or expired
+  expiresAt?: string;
+  revokedAt?: string;
+  createdAt: string;
+}
+
+export interface CreateShareLinkRequest {
+  expiresAt?: string; // RFC 3339; omit for a link that lasts until revoked
+}
+
+// Background rebuild of a graph's knowledge graph from its stored documents
+export interface GraphReindexJob {
+  id: string;
+  graphId: string;
+  requestedBy: string;
+  clearGraph: boolean;
+  reextract: boolean; // Only documents read by an older extractor were reprocessed
+  contentType?: string; // Content type a re-extraction was limited to
+  status: 'running' | 'completed' | 'failed';
+  totalDocuments: number;
+  processedDocuments: number; // Including failed ones
+  failedDocuments: number;
+  errorMessage?: string;
+  createdAt: string;
+  updatedAt: string;
+  finishedAt?: string;
+}
+
+// Document as listed through a share link, without owner, storage or error details
+export interface SharedDocument {
+  id: string;
+  filename?: string;
+  contentType?: string;
+  sizeBytes: number;
+  source: string;
+  status: Document['status'];
+  tags: string[];
   createdAt: string;
   updatedAt: string;
 }
 
+export interface GeminiUsageDay {
+  day: string;
+  requestCount: number;
+  inputTokens: number;
+  outputTokens: number;
+}
+
+export interface GeminiUsage {
+  month: string;
+  requestCount: number;
+  inputTokens: number;
+  outputTokens: number;
+  monthlyTokenCap: number; // 0 = unlimited
+  days: GeminiUsageDay[];
+  monthlyRequestCap: number; // 0 = unlimited
+  remainingTokens: number | null; // null when unlimited
+  remainingRequests: number | null;
+  budgetExceeded: boolean;
+  resetsAt: string;
+}
+
 export interface GraphMembership {
   id: string;
   graphId: string;
@@ -48,16 +193,44 @@ export interface GraphMembership {
 export interface CreateGraphRequest {
   name: string;
   description?: string;
+  allowedContentTypes?: string[];
+  defaultMemberRole?: MemberRole;
+  chatEnabled?: boolean; // Default true
 }
 
 export interface UpdateGraphRequest {
   name?: string;
   description?: string;
+  allowedContentTypes?: string[]; // An empty list removes the restriction
+  defaultMemberRole?: MemberRole | ''; // An empty value resets it to 'member'
+  chatEnabled?: boolean;
 }
 
+// Roles members can be given; 'owner' is reserved for a graph's creator
+export type MemberRole = 'member' | 'editor' | 'viewer';
+
 export interface AddMemberRequest {
   userId: string;
-  role?: 'member' | 'editor' | 'viewer';
+  role?: MemberRole; // Defaults to the graph's default member role
+}
+
+// Bulk member addition: each entry gives exactly one of userId or email
+export interface AddMembersEntry {
+  userId?: string;
+  email?: string;
+  role?: MemberRole;
+}
+
+export interface AddMemberResult {
+  userId?: string;
+  email?: string;
+  role?: string;
+  status: 'added' | 'already_member' | 'not_found' | 'invalid' | 'failed';
+}
+
+export interface AddMembersResponse {
+  added: number;
+  results: AddMemberResult[];
 }
 
 // Graph visualization types with full Zep metadata
@@ -120,16 +293,25 @@ export interface UpdatePasswordRequest {
   newPassword: string;
 }
 
+export interface DeleteAccountRequest {
+  password?: string;
+}
+
 // Chat types
 export interface ChatThread {
   id: string;
   graphId: string;
   userId: string;
   summary: string | null;
+  private: boolean; // Only visible to the thread's author
+  archived: boolean;
+  archivedAt?: string;
   createdAt: string;
   updatedAt: string;
 }
 
+export type ThreadArchiveFilter = 'active' | 'archived' | 'all';
+
 export interface ChatMessage {
   id: string;
   threadId: string;
@@ -138,6 +320,30 @@ export interface ChatMessage {
   createdAt: string;
 }
 
+// Result of starting a conversation: the new thread and its first message, ready to stream
+export interface StartChatResponse {
+  thread: ChatThread;
+  message: ChatMessage;
+}
+
+export type FeedbackRating = 'up' | 'down';
+
+export interface MessageFeedback {
+  id: string;
+  messageId: string;
+  rating: FeedbackRating;
+  comment?: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface FeedbackSummary {
+  graphId: string;
+  upCount: number;
+  downCount: number;
+  commentCount: number;
+}
+
 export interface StreamEvent {
   type: 'chunk' | 'done' | 'error';
   content?: string;
@@ -150,6 +356,20 @@ export interface AuthResponse {
   user: User;
 }
 
+export interface Pagination {
+  limit: number;
+  offset: number;
+  total: number;
+  hasMore: boolean;
+  nextCursor?: string; // Pass as ?cursor= to get the next page; present while hasMore is true
+}
+
+// Envelope of every listing response
+export interface Paginated<T> {
+  items: T[];
+  pagination: Pagination;
+}
+
 export interface ErrorResponse {
   code: string;
   message: string;